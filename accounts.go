@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Account is a named GitHub or GitLab login besides the default one of its
+// platform, such as a GitHub Enterprise server or a second GitLab instance.
+// Projects use it through their account field.
+type Account struct {
+	Platform string `json:"platform" yaml:"platform"`
+	Host     string `json:"host" yaml:"host"`
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// projectAccounts maps tracked project names to the account they use, so
+// clients can be created for a run knowing only its project name. Projects
+// on the default logins are left out.
+var projectAccounts = make(map[string]string)
+
+// registerProjectAccounts records the accounts of projects for client
+// creation
+func registerProjectAccounts(projects []Project) {
+	for _, project := range projects {
+		if project.Account != "" {
+			projectAccounts[project.Name] = project.Account
+		} else {
+			delete(projectAccounts, project.Name)
+		}
+	}
+}
+
+// accountCredentials finds the token and host of the named account of
+// platform. The order is <PREFIX>_TOKEN_<HOST>, stored auth, then the gh or
+// glab CLI's login to the host. The --github-token and --gitlab-token
+// flags only stand in for the default logins.
+func accountCredentials(name, platform string) (token, host string, err error) {
+	var account Account
+	found := false
+	if authConfig, err := loadAuthConfig(); err == nil {
+		account, found = authConfig.Accounts[name]
+	}
+	if !found || account.Platform != platform {
+		return "", "", authRequired(fmt.Errorf("%s account %s not found. Run 'quick_workflow login %s --account %s <host>' to add it", platformName(platform), name, platform, name))
+	}
+
+	prefix, cliToken := "GITHUB_TOKEN", ghCLIToken
+	if platform == "gitlab" {
+		prefix, cliToken = "GITLAB_TOKEN", glabCLIToken
+	}
+	if token := os.Getenv(hostTokenEnvVar(prefix, account.Host)); token != "" {
+		return token, account.Host, nil
+	}
+	if account.Token != "" {
+		return account.Token, account.Host, nil
+	}
+	if token := cliToken(account.Host); token != "" {
+		return token, account.Host, nil
+	}
+	return "", "", fmt.Errorf("%s account %s has no token. Run 'quick_workflow login %s --account %s %s' to authenticate", platformName(platform), name, platform, name, account.Host)
+}
+
+// githubAPIURL returns the REST API base URL of a GitHub host, with a
+// trailing slash. GitHub Enterprise Server serves it under /api/v3.
+func githubAPIURL(host string) string {
+	if host == "" || host == "github.com" {
+		return "https://api.github.com/"
+	}
+	return "https://" + host + "/api/v3/"
+}
+
+// remoteHost returns the host of a git remote URL, whether it is a URL or
+// scp-like, e.g. ghe.example.com for git@ghe.example.com:owner/repo.git
+func remoteHost(remoteURL string) string {
+	if _, rest, ok := strings.Cut(remoteURL, "://"); ok {
+		remoteURL = rest
+	}
+	host, _, _ := strings.Cut(remoteURL, "/")
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	host, _, _ = strings.Cut(host, ":")
+	return strings.ToLower(host)
+}
+
+// remoteAccount returns the account a project with remoteURL uses: the
+// named one, or else the account on the remote's host. Accounts on
+// github.com and gitlab.com are only used by name, as those hosts have the
+// default logins. The name is empty for the default logins.
+func remoteAccount(name, remoteURL string) (string, *Account, error) {
+	authConfig, err := loadAuthConfig()
+	if err != nil {
+		authConfig = &AuthConfig{}
+	}
+	if name != "" {
+		account, ok := authConfig.Accounts[name]
+		if !ok {
+			return "", nil, fmt.Errorf("account %s not found. Run 'quick_workflow login <github|gitlab> --account %s <host>' to add it", name, name)
+		}
+		return name, &account, nil
+	}
+
+	host := remoteHost(remoteURL)
+	if host == "github.com" || host == "gitlab.com" {
+		return "", nil, nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(authConfig.Accounts)) {
+		if account := authConfig.Accounts[name]; strings.EqualFold(account.Host, host) {
+			return name, &account, nil
+		}
+	}
+	return "", nil, nil
+}
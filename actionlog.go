@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// ActionRecord is one change made through the tool: a trigger, cancel,
+// re-run, approval, job action, or secret or variable change
+type ActionRecord struct {
+	At time.Time `json:"at"`
+	// User is who ran the tool, and the account sudo was run from
+	User    string `json:"user"`
+	Host    string `json:"host,omitempty"`
+	Action  string `json:"action"`
+	Project string `json:"project"`
+	// Parameters never hold secret values
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Result sums up the provider's response, such as the run started
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// actionLogFile returns the path of the append-only action log, kept next
+// to the state file
+func actionLogFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "actions.jsonl")
+}
+
+// currentUser names who is running the tool, noting the original account
+// when run through sudo, as on shared jump hosts
+func currentUser() string {
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != name {
+		name = fmt.Sprintf("%s (sudo from %s)", name, sudoUser)
+	}
+	return firstNonEmpty(name, "unknown")
+}
+
+// recordAction appends a change and its outcome to the action log. The log
+// is only ever appended to; failing to write it warns without undoing the
+// change.
+func recordAction(config *Config, action, project string, parameters map[string]string, result string, actionErr error) {
+	record := ActionRecord{
+		At:         time.Now(),
+		User:       currentUser(),
+		Action:     action,
+		Project:    project,
+		Parameters: parameters,
+		Result:     result,
+	}
+	record.Host, _ = os.Hostname()
+	if actionErr != nil {
+		record.Result, record.Error = "", describeError(actionErr)
+	}
+
+	if err := appendActionRecord(actionLogFile(config), record); err != nil {
+		fmt.Printf("%s Failed to record %s in the action log: %v\n", qc.Colorize("Warning:", qc.ColorYellow), action, describeError(err))
+	}
+}
+
+// appendActionRecord appends a record while holding the file lock
+func appendActionRecord(path string, record ActionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	lock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readActionLog reads the action log, skipping lines truncated by an
+// interrupted write
+func readActionLog(path string) ([]ActionRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []ActionRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record ActionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// formatParameters renders parameters as sorted key=value pairs
+func formatParameters(parameters map[string]string) string {
+	keys := slices.Sorted(maps.Keys(parameters))
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + parameters[key]
+	}
+	return strings.Join(pairs, " ")
+}
+
+// showActionHistory lists recorded actions, newest last
+func showActionHistory(config *Config, args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	project := fs.String("project", "", "Only actions on this project")
+	action := fs.String("action", "", "Only this action, e.g. trigger, cancel, rerun, approve, or secret set")
+	userName := fs.String("user", "", "Only actions by users whose name contains this")
+	since := fs.Duration("since", 0, "Only actions within this long, e.g. 24h")
+	limit := fs.Int("limit", 50, "Number of most recent actions to show (0 for all)")
+	format := fs.String("format", "table", "Output format: table or json")
+	fs.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		fmt.Printf("%s Unsupported format: %s (expected table or json)\n", qc.Colorize("Error:", qc.ColorRed), *format)
+		return
+	}
+
+	records, err := readActionLog(actionLogFile(config))
+	if err != nil {
+		fmt.Printf("%s Failed to read the action log: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	var shown []ActionRecord
+	for _, record := range records {
+		switch {
+		case *project != "" && record.Project != *project:
+		case *action != "" && !strings.EqualFold(record.Action, *action):
+		case *userName != "" && !strings.Contains(record.User, *userName):
+		case *since > 0 && record.At.Before(time.Now().Add(-*since)):
+		default:
+			shown = append(shown, record)
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool { return shown[i].At.Before(shown[j].At) })
+	if *limit > 0 && len(shown) > *limit {
+		shown = shown[len(shown)-*limit:]
+	}
+
+	if *format == "json" {
+		if shown == nil {
+			shown = []ActionRecord{}
+		}
+		data, err := json.MarshalIndent(shown, "", "  ")
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(shown) == 0 {
+		fmt.Printf("%s No recorded actions\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+	fmt.Printf("%s\n", qc.Colorize("Actions taken through quick_workflow:", qc.ColorBlue))
+	for i, record := range shown {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		who := record.User
+		if record.Host != "" {
+			who += "@" + record.Host
+		}
+		entry := fmt.Sprintf("%s  %-20s %-14s %-30s %s",
+			record.At.In(displayLocation).Format("2006-01-02 15:04:05"), who, record.Action, record.Project, formatParameters(record.Parameters))
+		fmt.Println(qc.Colorize(entry, rowColor))
+
+		outcome := record.Result
+		switch {
+		case record.Error != "":
+			outcome = qc.Colorize("failed: "+record.Error, qc.ColorRed)
+		case outcome == "":
+			outcome = "ok"
+		}
+		fmt.Printf("    %s\n", outcome)
+	}
+}
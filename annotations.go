@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// showAnnotations prints the annotations of each job in a GitHub run,
+// grouped by job. Jobs without annotations are skipped.
+func showAnnotations(ctx context.Context, run WorkflowRun, jobs []Job) {
+	if run.Platform != "github" {
+		return
+	}
+	owner, repo, ok := strings.Cut(run.Project, "/")
+	if !ok {
+		return
+	}
+	client, err := NewGitHubClient(ctx, run.Project)
+	if err != nil {
+		fmt.Printf("%s Failed to get annotations: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		return
+	}
+
+	printedHeader := false
+	for _, job := range jobs {
+		annotations, err := client.GetJobAnnotations(owner, repo, job.ID)
+		if err != nil {
+			fmt.Printf("%s Failed to get annotations for %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), job.Name, describeError(err))
+			continue
+		}
+		if len(annotations) == 0 {
+			continue
+		}
+
+		if !printedHeader {
+			fmt.Printf("\n%s\n", qc.Colorize("Annotations:", qc.ColorBlue))
+			printedHeader = true
+		}
+		fmt.Printf("  %s\n", qc.ColorizeBold(job.Name, qc.ColorWhite))
+		for _, annotation := range annotations {
+			fmt.Printf("    %s %s\n", formatAnnotationLevel(annotation.Level), formatAnnotation(annotation))
+		}
+	}
+}
+
+// formatAnnotationLevel renders an annotation level as a colored label
+func formatAnnotationLevel(level string) string {
+	switch level {
+	case "failure":
+		return qc.Colorize("[error]", qc.ColorRed)
+	case "warning":
+		return qc.Colorize("[warning]", qc.ColorYellow)
+	default:
+		return qc.Colorize("["+level+"]", qc.ColorCyan)
+	}
+}
+
+// formatAnnotation renders an annotation as "path:line: title: message"
+func formatAnnotation(annotation Annotation) string {
+	var parts []string
+	if annotation.Path != "" && annotation.Path != ".github" {
+		location := annotation.Path
+		if annotation.StartLine > 0 {
+			location = fmt.Sprintf("%s:%d", location, annotation.StartLine)
+		}
+		parts = append(parts, location)
+	}
+	if annotation.Title != "" {
+		parts = append(parts, annotation.Title)
+	}
+	parts = append(parts, strings.ReplaceAll(strings.TrimSpace(annotation.Message), "\n", "\n      "))
+	return strings.Join(parts, ": ")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// apiLog receives a record of every API request. It discards everything
+// unless --verbose or --debug is given.
+var apiLog = slog.New(slog.DiscardHandler)
+
+// redacted replaces secrets in logged URLs and headers
+const redacted = "REDACTED"
+
+// configureAPILogging logs API requests to stderr: one line per request
+// with --verbose, plus request and response headers with --debug
+func configureAPILogging(verbose, debug bool) {
+	level := slog.LevelInfo
+	switch {
+	case debug:
+		level = slog.LevelDebug
+	case !verbose:
+		return
+	}
+	apiLog = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// loggingTransport logs each request's method, URL, status, duration, and
+// remaining rate limit
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	apiLog.Debug("api request", "method", req.Method, "url", redactURL(req.URL), "headers", redactHeaders(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		apiLog.Warn("api request failed", "method", req.Method, "url", redactURL(req.URL), "duration", duration, "error", err)
+		return resp, err
+	}
+
+	attrs := []any{"method", req.Method, "url", redactURL(req.URL), "status", resp.StatusCode, "duration", duration}
+	if remaining := rateLimitRemaining(resp.Header); remaining != "" {
+		attrs = append(attrs, "rate_limit_remaining", remaining)
+	}
+	level := slog.LevelInfo
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	apiLog.Log(req.Context(), level, "api response", attrs...)
+	apiLog.Debug("api response headers", "url", redactURL(req.URL), "headers", redactHeaders(resp.Header))
+	return resp, nil
+}
+
+// rateLimitRemaining returns the requests left in the rate limit window:
+// GitHub sends X-RateLimit-Remaining and GitLab RateLimit-Remaining
+func rateLimitRemaining(header http.Header) string {
+	if remaining := header.Get("X-RateLimit-Remaining"); remaining != "" {
+		return remaining
+	}
+	return header.Get("RateLimit-Remaining")
+}
+
+// isSecretName reports whether a query parameter or header likely holds a
+// credential
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range []string{"token", "secret", "password", "authorization", "cookie", "signature", "key", "sig", "code"} {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL returns a URL for logging with credentials in the user info
+// and query replaced
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	if u.User != nil {
+		redactedURL.User = url.User(redacted)
+	}
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if isSecretName(name) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		redactedURL.RawQuery = query.Encode()
+	}
+	return redactedURL.String()
+}
+
+// redactHeaders returns headers for logging with credentials replaced.
+// Values that are URLs, like the Location of the signed download URLs logs
+// and artifacts redirect to, have their credentials redacted too.
+func redactHeaders(header http.Header) http.Header {
+	clean := header.Clone()
+	for name, values := range clean {
+		if isSecretName(name) {
+			clean[name] = []string{redacted}
+			continue
+		}
+		for i, value := range values {
+			if u, err := url.Parse(value); err == nil && u.IsAbs() && u.Host != "" {
+				values[i] = redactURL(u)
+			}
+		}
+	}
+	return clean
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// approvalRequest is a run waiting for someone to let it proceed: a GitHub
+// deployment held by an environment protection rule or a GitLab manual job
+type approvalRequest struct {
+	Project       Project
+	Kind          string // "deployment" or "manual job"
+	Name          string // environment or job name
+	RunID         string
+	Ref           string
+	Since         time.Time
+	Reviewers     []string
+	CanApprove    bool
+	EnvironmentID int64  // GitHub deployments
+	JobID         string // GitLab manual jobs
+}
+
+// getApprovalRequests lists what is waiting on approval in a project
+func getApprovalRequests(ctx context.Context, project Project) ([]approvalRequest, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		pending, err := client.GetPendingDeployments(project.Owner, project.Repo)
+		if err != nil {
+			return nil, err
+		}
+		var requests []approvalRequest
+		for _, p := range pending {
+			requests = append(requests, approvalRequest{
+				Project:       project,
+				Kind:          "deployment",
+				Name:          p.Environment,
+				RunID:         p.RunID,
+				Ref:           p.Ref,
+				Since:         p.WaitingSince,
+				Reviewers:     p.Reviewers,
+				CanApprove:    p.CanApprove,
+				EnvironmentID: p.EnvironmentID,
+			})
+		}
+		return requests, nil
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		jobs, err := client.GetManualJobs(project.Name)
+		if err != nil {
+			return nil, err
+		}
+		var requests []approvalRequest
+		for _, job := range jobs {
+			request := approvalRequest{
+				Project:    project,
+				Kind:       "manual job",
+				Name:       job.Name,
+				RunID:      job.RunID,
+				CanApprove: true,
+				JobID:      job.ID,
+			}
+			if job.CreatedAt != nil {
+				request.Since = *job.CreatedAt
+			}
+			requests = append(requests, request)
+		}
+		return requests, nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// approveRequest approves a deployment or plays a manual job. GitLab has no
+// comment on play, so the comment is posted to the pipeline's commit.
+func approveRequest(ctx context.Context, request approvalRequest, comment string) error {
+	switch request.Project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, request.Project.Name)
+		if err != nil {
+			return err
+		}
+		return client.ApprovePendingDeployment(request.Project.Owner, request.Project.Repo, request.RunID, request.EnvironmentID, comment)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, request.Project.Name)
+		if err != nil {
+			return err
+		}
+		if err := client.PlayJob(request.Project.Name, request.JobID); err != nil {
+			return err
+		}
+		if comment != "" {
+			if _, err := client.CommentOnPipeline(request.Project.Name, request.RunID, comment); err != nil {
+				return fmt.Errorf("job started but the comment was not posted: %w", err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported platform: %s", request.Project.Platform)
+	}
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(reader *bufio.Reader, prompt string) bool {
+	fmt.Printf("%s", qc.Colorize(prompt+" [y/N]: ", qc.ColorYellow))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(input))
+	return answer == "y" || answer == "yes"
+}
+
+// handleApprove lists runs waiting on approval and approves the selected one
+// after confirmation
+func handleApprove(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("approve", flag.ExitOnError)
+	comment := fs.String("comment", "", "Comment to record with the approval")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	var requests []approvalRequest
+	for _, project := range projects {
+		found, err := getApprovalRequests(ctx, project)
+		if err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+		requests = append(requests, found...)
+	}
+	if len(requests) == 0 {
+		fmt.Printf("%s Nothing is waiting on approval\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	entries := make([]string, len(requests))
+	for i, request := range requests {
+		entries[i] = fmt.Sprintf("%-30s %-10s %-20s run %s", request.Project.Name, request.Kind, request.Name, request.RunID)
+		if request.Ref != "" {
+			entries[i] += "  " + request.Ref
+		}
+		if !request.Since.IsZero() {
+			entries[i] += "  waiting " + formatDuration(time.Since(request.Since))
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var request approvalRequest
+	if useFinder() {
+		for i, request := range requests {
+			if len(request.Reviewers) > 0 {
+				entries[i] += "  reviewers: " + strings.Join(request.Reviewers, ", ")
+			}
+			if !request.CanApprove {
+				entries[i] += "  (you can't approve)"
+			}
+		}
+		index, ok := pick("Waiting on approval", entries)
+		if !ok {
+			return
+		}
+		request = requests[index]
+	} else {
+		fmt.Printf("%s\n", qc.Colorize("Waiting on approval:", qc.ColorBlue))
+		for i, request := range requests {
+			line := qc.Colorize(fmt.Sprintf("%3d. %s", i+1, entries[i]), qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan))
+			if len(request.Reviewers) > 0 {
+				line += "  reviewers: " + strings.Join(request.Reviewers, ", ")
+			}
+			if !request.CanApprove {
+				line += "  " + qc.Colorize("(you can't approve)", qc.ColorRed)
+			}
+			fmt.Println(line)
+		}
+
+		fmt.Printf("%s", qc.Colorize("Select (number): ", qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || index < 1 || index > len(requests) {
+			fmt.Printf("%s Invalid selection\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		request = requests[index-1]
+	}
+
+	action, done := "Approve deployment to "+request.Name, "Approved deployment to "+request.Name
+	if request.Kind == "manual job" {
+		action, done = "Play manual job "+request.Name, "Started manual job "+request.Name
+	}
+	if !confirm(reader, fmt.Sprintf("%s for run %s of %s?", action, request.RunID, request.Project.Name)) {
+		fmt.Printf("%s Cancelled\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	err := approveRequest(ctx, request, *comment)
+	parameters := map[string]string{"run": request.RunID, "kind": request.Kind, "name": request.Name}
+	if *comment != "" {
+		parameters["comment"] = *comment
+	}
+	recordAction(config, "approve", request.Project.Name, parameters, strings.ToLower(done), err)
+	if err != nil {
+		fmt.Printf("%s Failed to approve: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s %s for run %s of %s\n", qc.Colorize("Success:", qc.ColorGreen), done, request.RunID, request.Project.Name)
+}
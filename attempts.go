@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// getRunAttempt retrieves one attempt of a re-run GitHub run. GitLab
+// retries jobs inside the same pipeline instead, so it has no attempts.
+func getRunAttempt(ctx context.Context, run WorkflowRun, attempt int) (*WorkflowRun, error) {
+	if run.Platform != "github" {
+		return nil, fmt.Errorf("run attempts are only available for GitHub")
+	}
+	owner, repo, ok := strings.Cut(run.Project, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+	}
+	client, err := NewGitHubClient(ctx, run.Project)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetWorkflowRunAttempt(owner, repo, run.ID, attempt)
+}
+
+// promptAttempts lets the user switch the details view between the
+// attempts of a re-run GitHub run
+func promptAttempts(ctx context.Context, config *Config, reader *bufio.Reader, run WorkflowRun, logLines int) {
+	latest := run.Attempt
+	if run.Platform != "github" || latest < 2 {
+		return
+	}
+
+	for {
+		fmt.Printf("\n%s", qc.Colorize(fmt.Sprintf("View attempt (1-%d, Enter to finish): ", latest), qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		input = strings.TrimSpace(input)
+		if input == "" || input == "q" {
+			return
+		}
+		attempt, err := strconv.Atoi(strings.TrimPrefix(input, "#"))
+		if err != nil || attempt < 1 || attempt > latest {
+			fmt.Printf("%s Enter an attempt between 1 and %d\n", qc.Colorize("Error:", qc.ColorRed), latest)
+			continue
+		}
+
+		selected, err := getRunAttempt(ctx, run, attempt)
+		if err != nil {
+			fmt.Printf("%s Failed to get attempt %d: %v\n", qc.Colorize("Error:", qc.ColorRed), attempt, describeError(err))
+			continue
+		}
+		fmt.Printf("\n%s\n", qc.ColorizeBold(fmt.Sprintf("Attempt %d of %d", attempt, latest), qc.ColorWhite))
+		showWorkflowDetails(ctx, config, *selected, logLines)
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// auditPriority orders findings by how urgently they need fixing
+type auditPriority int
+
+const (
+	// priorityHigh findings make jobs fail or stop running today
+	priorityHigh auditPriority = iota
+	// priorityMedium findings are deprecated and emit warnings
+	priorityMedium
+	// priorityLow findings still work but have better replacements
+	priorityLow
+)
+
+func (p auditPriority) String() string {
+	switch p {
+	case priorityHigh:
+		return "high"
+	case priorityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+func (p auditPriority) color() string {
+	switch p {
+	case priorityHigh:
+		return qc.ColorRed
+	case priorityMedium:
+		return qc.ColorYellow
+	default:
+		return qc.ColorCyan
+	}
+}
+
+// auditFinding is one deprecated usage in a CI file
+type auditFinding struct {
+	Project  string
+	Path     string
+	Line     int
+	Priority auditPriority
+	Message  string
+	Fix      string
+}
+
+// deprecatedRunner describes a retired hosted runner image
+type deprecatedRunner struct {
+	priority    auditPriority
+	status      string
+	replacement string
+}
+
+// deprecatedRunners lists retired GitHub-hosted runner images; jobs that
+// request them queue until they time out
+var deprecatedRunners = map[string]deprecatedRunner{
+	"ubuntu-18.04": {priorityHigh, "was retired in April 2023", "ubuntu-24.04"},
+	"ubuntu-20.04": {priorityHigh, "was retired in April 2025", "ubuntu-24.04"},
+	"macos-10.15":  {priorityHigh, "was retired in August 2022", "macos-15"},
+	"macos-11":     {priorityHigh, "was retired in June 2024", "macos-15"},
+	"macos-12":     {priorityHigh, "was retired in December 2024", "macos-15"},
+	"macos-13":     {priorityHigh, "was retired in December 2025", "macos-15"},
+	"windows-2016": {priorityHigh, "was retired in March 2022", "windows-2025"},
+	"windows-2019": {priorityHigh, "was retired in June 2025", "windows-2025"},
+}
+
+// deprecatedAction describes the oldest supported major version of an action
+type deprecatedAction struct {
+	minimum  int
+	priority auditPriority
+	reason   string
+}
+
+// deprecatedActions lists commonly used actions whose older majors run on
+// retired Node.js versions or depend on shut down services
+var deprecatedActions = map[string]deprecatedAction{
+	"actions/upload-artifact":               {4, priorityHigh, "v3 and earlier stopped working when the artifact service was upgraded"},
+	"actions/download-artifact":             {4, priorityHigh, "v3 and earlier stopped working when the artifact service was upgraded"},
+	"actions/cache":                         {4, priorityHigh, "v3 and earlier use the retired cache service"},
+	"actions/checkout":                      {4, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"actions/setup-node":                    {4, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"actions/setup-python":                  {5, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"actions/setup-go":                      {5, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"actions/setup-java":                    {4, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"actions/setup-dotnet":                  {4, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"actions/github-script":                 {7, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"docker/login-action":                   {3, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"docker/setup-buildx-action":            {3, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"docker/build-push-action":              {5, priorityMedium, "older versions run on Node.js 16 or earlier"},
+	"aws-actions/configure-aws-credentials": {4, priorityMedium, "older versions run on Node.js 16 or earlier"},
+}
+
+// workflowCommand describes a deprecated workflow command and its file based
+// replacement
+type workflowCommand struct {
+	priority auditPriority
+	status   string
+	fix      string
+}
+
+// deprecatedCommands lists workflow commands replaced by environment files
+var deprecatedCommands = map[string]workflowCommand{
+	"set-env":    {priorityHigh, "is disabled", `append "NAME=value" to $GITHUB_ENV`},
+	"add-path":   {priorityHigh, "is disabled", "append the directory to $GITHUB_PATH"},
+	"set-output": {priorityMedium, "is deprecated", `append "name=value" to $GITHUB_OUTPUT`},
+	"save-state": {priorityMedium, "is deprecated", `append "name=value" to $GITHUB_STATE`},
+}
+
+var (
+	// runnerLabel matches hosted runner labels anywhere on a line, so
+	// matrix values are found as well as runs-on
+	runnerLabel = regexp.MustCompile(`\b(ubuntu|macos|windows)-\d+(\.\d+)?\b`)
+	// actionRef matches "uses: owner/repo[/path]@vN"
+	actionRef = regexp.MustCompile(`uses:\s*["']?([\w.-]+/[\w.-]+)(/[^@\s"']*)?@v?(\d+)`)
+	// commandUse matches "::set-output" style workflow commands
+	commandUse = regexp.MustCompile(`::(set-env|add-path|set-output|save-state)\b`)
+	// gitlabTypes matches the types keyword that stages replaced
+	gitlabTypes = regexp.MustCompile(`^types:`)
+	// gitlabOnlyExcept matches job level only and except, superseded by rules
+	gitlabOnlyExcept = regexp.MustCompile(`^\s+(only|except):`)
+	// gitlabBuildVar matches the CI_BUILD_* variables renamed to CI_JOB_*
+	gitlabBuildVar = regexp.MustCompile(`\bCI_BUILD_[A-Z_]+\b`)
+)
+
+// scanWorkflowFile reports deprecated usages in a GitHub workflow file
+func scanWorkflowFile(project string, file WorkflowFile) []auditFinding {
+	var findings []auditFinding
+	for i, line := range strings.Split(file.Content, "\n") {
+		code := line
+		if comment := strings.Index(code, " #"); comment >= 0 {
+			code = code[:comment]
+		}
+		if strings.HasPrefix(strings.TrimSpace(code), "#") {
+			continue
+		}
+		add := func(priority auditPriority, message, fix string) {
+			findings = append(findings, auditFinding{
+				Project: project, Path: file.Path, Line: i + 1,
+				Priority: priority, Message: message, Fix: fix,
+			})
+		}
+
+		for _, label := range runnerLabel.FindAllString(code, -1) {
+			if runner, ok := deprecatedRunners[label]; ok {
+				add(runner.priority, fmt.Sprintf("runner image %s %s", label, runner.status), "use "+runner.replacement)
+			}
+		}
+		for _, match := range actionRef.FindAllStringSubmatch(code, -1) {
+			action, ok := deprecatedActions[strings.ToLower(match[1])]
+			if !ok {
+				continue
+			}
+			if major, err := strconv.Atoi(match[3]); err == nil && major < action.minimum {
+				add(action.priority, fmt.Sprintf("%s@v%d: %s", match[1], major, action.reason), fmt.Sprintf("upgrade to %s@v%d or later", match[1], action.minimum))
+			}
+		}
+		for _, match := range commandUse.FindAllStringSubmatch(code, -1) {
+			command := deprecatedCommands[match[1]]
+			add(command.priority, fmt.Sprintf("::%s %s", match[1], command.status), command.fix)
+		}
+	}
+	return findings
+}
+
+// scanGitLabCIFile reports deprecated usages in a .gitlab-ci.yml
+func scanGitLabCIFile(project string, file WorkflowFile) []auditFinding {
+	var findings []auditFinding
+	for i, line := range strings.Split(file.Content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if gitlabTypes.MatchString(line) {
+			findings = append(findings, auditFinding{
+				Project: project, Path: file.Path, Line: i + 1, Priority: priorityHigh,
+				Message: "the types keyword was removed", Fix: "rename it to stages",
+			})
+		}
+		if match := gitlabOnlyExcept.FindStringSubmatch(line); match != nil {
+			findings = append(findings, auditFinding{
+				Project: project, Path: file.Path, Line: i + 1, Priority: priorityLow,
+				Message: match[1] + " is no longer developed", Fix: "use rules",
+			})
+		}
+		for _, variable := range gitlabBuildVar.FindAllString(line, -1) {
+			findings = append(findings, auditFinding{
+				Project: project, Path: file.Path, Line: i + 1, Priority: priorityHigh,
+				Message: variable + " was removed", Fix: "use " + strings.Replace(variable, "CI_BUILD_", "CI_JOB_", 1),
+			})
+		}
+	}
+	return findings
+}
+
+// handleAudit handles the audit command
+func handleAudit(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 || args[0] != "workflows" {
+		fmt.Printf("%s Usage: quick_workflow audit workflows [project]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	auditWorkflows(ctx, config, args[1:])
+}
+
+// auditWorkflows scans the CI files of tracked projects for deprecated
+// runners, actions, and commands and prints the findings, most urgent first
+func auditWorkflows(ctx context.Context, config *Config, args []string) {
+	projects := config.Projects
+	if len(args) > 0 {
+		project := findProject(config, args[0])
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	var findings []auditFinding
+	for _, project := range projects {
+		files, err := loadMigrationSource(ctx, project, "")
+		if err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+		for _, file := range files {
+			if project.Platform == "gitlab" {
+				findings = append(findings, scanGitLabCIFile(project.Name, file)...)
+			} else {
+				findings = append(findings, scanWorkflowFile(project.Name, file)...)
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		fmt.Printf("%s No deprecated usage found in %d project(s)\n", qc.Colorize("Success:", qc.ColorGreen), len(projects))
+		return
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Upgrade report (%d findings):", len(findings)), qc.ColorBlue))
+	current := auditPriority(-1)
+	for _, finding := range findings {
+		if finding.Priority != current {
+			current = finding.Priority
+			fmt.Printf("\n%s\n", qc.ColorizeBold(strings.ToUpper(current.String())+" priority", current.color()))
+		}
+		fmt.Printf("  %s %s:%d\n", qc.ColorizeBold(finding.Project, qc.ColorWhite), finding.Path, finding.Line)
+		fmt.Printf("    %s\n", qc.Colorize(finding.Message, current.color()))
+		fmt.Printf("    Fix: %s\n", finding.Fix)
+	}
+
+	counts := make(map[string][3]int)
+	for _, finding := range findings {
+		c := counts[finding.Project]
+		c[finding.Priority]++
+		counts[finding.Project] = c
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := counts[names[i]], counts[names[j]]
+		for p := range a {
+			if a[p] != b[p] {
+				return a[p] > b[p]
+			}
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Printf("\n%s\n", qc.Colorize("By project:", qc.ColorBlue))
+	for i, name := range names {
+		c := counts[name]
+		fmt.Println(qc.Colorize(fmt.Sprintf("  %-40s %3d high %3d medium %3d low", name, c[priorityHigh], c[priorityMedium], c[priorityLow]),
+			qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)))
+	}
+}
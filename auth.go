@@ -1,31 +1,193 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
-	qc "github.com/bevelwork/quick_color"
+	qc "github.com/bevelwork/quick_workflow/internal/color"
 )
 
 // AuthConfig represents stored authentication configuration
 type AuthConfig struct {
-	GitHubToken string `json:"github_token,omitempty"`
-	GitLabToken string `json:"gitlab_token,omitempty"`
-	GitLabHost  string `json:"gitlab_host,omitempty"`
+	GitHubToken      string `json:"github_token,omitempty" yaml:"github_token,omitempty"`
+	GitLabToken      string `json:"gitlab_token,omitempty" yaml:"gitlab_token,omitempty"`
+	GitLabHost       string `json:"gitlab_host,omitempty" yaml:"gitlab_host,omitempty"`
+	DroneToken       string `json:"drone_token,omitempty" yaml:"drone_token,omitempty"`
+	DroneServer      string `json:"drone_server,omitempty" yaml:"drone_server,omitempty"`
+	WoodpeckerToken  string `json:"woodpecker_token,omitempty" yaml:"woodpecker_token,omitempty"`
+	WoodpeckerServer string `json:"woodpecker_server,omitempty" yaml:"woodpecker_server,omitempty"`
+	// Accounts are further GitHub and GitLab logins by name; see Account
+	Accounts map[string]Account `json:"accounts,omitempty" yaml:"accounts,omitempty"`
 }
 
+// droneCredentials returns the stored token and server of the drone or
+// woodpecker platform
+func (c *AuthConfig) droneCredentials(platform string) (token, server string) {
+	if platform == "woodpecker" {
+		return c.WoodpeckerToken, c.WoodpeckerServer
+	}
+	return c.DroneToken, c.DroneServer
+}
+
+// Token overrides supplied on the command line for a single invocation.
+// They take precedence over environment variables and stored auth config.
+var (
+	githubTokenOverride string
+	gitlabTokenOverride string
+)
+
+// hostTokenEnvVar returns the host-scoped token variable name, e.g.
+// GITLAB_TOKEN_GITLAB_EXAMPLE_COM for gitlab.example.com
+func hostTokenEnvVar(prefix, host string) string {
+	scoped := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(host))
+	return prefix + "_" + scoped
+}
+
+// resolveGitHubCredentials finds the GitHub token and host to use for a
+// project, or for no project in particular when it is empty. Projects with
+// an account use its credentials; for the rest the order is the
+// --github-token flag, GITHUB_TOKEN_GITHUB_COM, stored auth, GITHUB_TOKEN,
+// then the gh CLI's login.
+func resolveGitHubCredentials(project string) (token, host string, err error) {
+	if account := projectAccounts[project]; account != "" {
+		return accountCredentials(account, "github")
+	}
+
+	host = "github.com"
+	if githubTokenOverride != "" {
+		return githubTokenOverride, host, nil
+	}
+	if token := os.Getenv(hostTokenEnvVar("GITHUB_TOKEN", host)); token != "" {
+		return token, host, nil
+	}
+
+	authConfig, err := loadAuthConfig()
+	if err == nil && authConfig.GitHubToken != "" {
+		return authConfig.GitHubToken, host, nil
+	}
+
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token, host, nil
+	}
+	if token := ghCLIToken(host); token != "" {
+		return token, host, nil
+	}
+	return "", "", authRequired(fmt.Errorf("GitHub authentication required. Run 'quick_workflow login github' to authenticate"))
+}
+
+// resolveGitLabCredentials finds the GitLab token and host to use for a
+// project, or for no project in particular when it is empty. Projects with
+// an account use its credentials; for the rest the order is the
+// --gitlab-token flag, GITLAB_TOKEN_<HOST>, stored auth, GITLAB_TOKEN, then
+// the glab CLI's token for the host.
+func resolveGitLabCredentials(project string) (token, host string, err error) {
+	if account := projectAccounts[project]; account != "" {
+		return accountCredentials(account, "gitlab")
+	}
+
+	authConfig, authErr := loadAuthConfig()
+
+	host = os.Getenv("GITLAB_HOST")
+	if host == "" && authErr == nil {
+		host = authConfig.GitLabHost
+	}
+	if host == "" {
+		host = "gitlab.com"
+	}
+
+	if gitlabTokenOverride != "" {
+		return gitlabTokenOverride, host, nil
+	}
+	if token := os.Getenv(hostTokenEnvVar("GITLAB_TOKEN", host)); token != "" {
+		return token, host, nil
+	}
+
+	if authErr == nil && authConfig.GitLabToken != "" {
+		host = authConfig.GitLabHost
+		if host == "" {
+			host = "gitlab.com"
+		}
+		return authConfig.GitLabToken, host, nil
+	}
+
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		return token, host, nil
+	}
+	if token := glabCLIToken(host); token != "" {
+		return token, host, nil
+	}
+	return "", "", authRequired(fmt.Errorf("GitLab authentication required. Run 'quick_workflow login gitlab' to authenticate"))
+}
+
+// normalizeServerURL turns a host or URL into a base URL without a trailing
+// slash, defaulting to https
+func normalizeServerURL(server string) string {
+	server = strings.TrimSuffix(strings.TrimSpace(server), "/")
+	if server != "" && !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	return server
+}
+
+// resolveDroneCredentials finds the token and server URL of a Drone or
+// Woodpecker server, using the same variables as their CLIs. The server is
+// DRONE_SERVER or WOODPECKER_SERVER, else stored auth. The token order is
+// <PREFIX>_TOKEN_<HOST>, stored auth, then <PREFIX>_TOKEN.
+func resolveDroneCredentials(platform string) (token, server string, err error) {
+	prefix := strings.ToUpper(platform)
+	authConfig, authErr := loadAuthConfig()
+
+	var storedToken string
+	if authErr == nil {
+		storedToken, server = authConfig.droneCredentials(platform)
+	}
+	if env := os.Getenv(prefix + "_SERVER"); env != "" {
+		server = env
+	}
+	server = normalizeServerURL(server)
+	if server == "" {
+		return "", "", fmt.Errorf("%s server not configured. Run 'quick_workflow login %s <server>' or set %s_SERVER", platformName(platform), platform, prefix)
+	}
+
+	if token := os.Getenv(hostTokenEnvVar(prefix+"_TOKEN", requestHost(server))); token != "" {
+		return token, server, nil
+	}
+	if storedToken != "" {
+		return storedToken, server, nil
+	}
+	if token := os.Getenv(prefix + "_TOKEN"); token != "" {
+		return token, server, nil
+	}
+	return "", "", authRequired(fmt.Errorf("%s authentication required. Run 'quick_workflow login %s <server>' to authenticate", platformName(platform), platform))
+}
+
+// loginGitHub initiates GitHub authentication, of the default login or of
+// the named account on host
+func loginGitHub(ctx context.Context, account, host string) error {
+	if host == "" {
+		host = "github.com"
+	}
 
-// loginGitHub initiates GitHub authentication
-func loginGitHub() error {
 	fmt.Printf("%s\n", qc.Colorize("GitHub Authentication", qc.ColorBlue))
+	if account != "" {
+		fmt.Printf("Account: %s (%s)\n", qc.ColorizeBold(account, qc.ColorCyan), host)
+	}
 	fmt.Println()
 
 	fmt.Printf("%s\n", qc.Colorize("To authenticate with GitHub:", qc.ColorYellow))
-	fmt.Println("1. Go to https://github.com/settings/tokens")
+	fmt.Printf("1. Go to https://%s/settings/tokens\n", host)
 	fmt.Println("2. Click 'Generate new token (classic)'")
 	fmt.Println("3. Select scopes: repo, read:org, read:user, read:packages")
 	fmt.Println("4. Copy the generated token")
@@ -43,26 +205,34 @@ func loginGitHub() error {
 	}
 
 	// Test the token by making a simple API call
-	if err := testGitHubToken(token); err != nil {
-		return fmt.Errorf("invalid token: %v", err)
+	if err := testGitHubToken(ctx, host, token); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
 	}
 
 	// Save token
-	if err := saveAuthConfig(AuthConfig{GitHubToken: token}); err != nil {
+	config := AuthConfig{GitHubToken: token}
+	if account != "" {
+		config = AuthConfig{Accounts: map[string]Account{account: {Platform: "github", Host: host, Token: token}}}
+	}
+	if err := saveAuthConfig(config); err != nil {
 		return fmt.Errorf("failed to save authentication: %v", err)
 	}
 
-	fmt.Printf("%s Successfully authenticated with GitHub!\n", qc.Colorize("Success:", qc.ColorGreen))
+	fmt.Printf("%s Successfully authenticated with GitHub (%s)!\n", qc.Colorize("Success:", qc.ColorGreen), host)
 	return nil
 }
 
-// loginGitLab initiates GitLab authentication
-func loginGitLab(host string) error {
+// loginGitLab initiates GitLab authentication, of the default login or of
+// the named account on host
+func loginGitLab(ctx context.Context, account, host string) error {
 	if host == "" {
 		host = "gitlab.com"
 	}
 
 	fmt.Printf("%s\n", qc.Colorize("GitLab Authentication", qc.ColorBlue))
+	if account != "" {
+		fmt.Printf("Account: %s\n", qc.ColorizeBold(account, qc.ColorCyan))
+	}
 	fmt.Printf("Host: %s\n", qc.ColorizeBold(host, qc.ColorCyan))
 	fmt.Println()
 
@@ -83,12 +253,16 @@ func loginGitLab(host string) error {
 	}
 
 	// Test the token by making a simple API call
-	if err := testGitLabToken(host, token); err != nil {
-		return fmt.Errorf("invalid token: %v", err)
+	if err := testGitLabToken(ctx, host, token); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
 	}
 
 	// Save token
-	if err := saveAuthConfig(AuthConfig{GitLabToken: token, GitLabHost: host}); err != nil {
+	config := AuthConfig{GitLabToken: token, GitLabHost: host}
+	if account != "" {
+		config = AuthConfig{Accounts: map[string]Account{account: {Platform: "gitlab", Host: host, Token: token}}}
+	}
+	if err := saveAuthConfig(config); err != nil {
 		return fmt.Errorf("failed to save authentication: %v", err)
 	}
 
@@ -96,11 +270,56 @@ func loginGitLab(host string) error {
 	return nil
 }
 
+// loginDrone authenticates with a Drone or Woodpecker server
+func loginDrone(ctx context.Context, platform, server string) error {
+	server = normalizeServerURL(server)
+	if server == "" {
+		return fmt.Errorf("a server is required, e.g. 'quick_workflow login %s ci.example.com'", platform)
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(platformName(platform)+" Authentication", qc.ColorBlue))
+	fmt.Printf("Server: %s\n", qc.ColorizeBold(server, qc.ColorCyan))
+	fmt.Println()
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("To authenticate with %s:", platformName(platform)), qc.ColorYellow))
+	if platform == "woodpecker" {
+		fmt.Printf("1. Go to %s/user/cli-and-api\n", server)
+	} else {
+		fmt.Printf("1. Go to %s/account\n", server)
+	}
+	fmt.Println("2. Copy your personal token")
+	fmt.Println()
+
+	fmt.Printf("%s Enter your %s token: ", qc.Colorize("Token:", qc.ColorYellow), platformName(platform))
+
+	var token string
+	fmt.Scanln(&token)
+
+	if token == "" {
+		return fmt.Errorf("no token provided")
+	}
+
+	if err := testDroneToken(ctx, platform, server, token); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+
+	config := AuthConfig{DroneToken: token, DroneServer: server}
+	if platform == "woodpecker" {
+		config = AuthConfig{WoodpeckerToken: token, WoodpeckerServer: server}
+	}
+	if err := saveAuthConfig(config); err != nil {
+		return fmt.Errorf("failed to save authentication: %v", err)
+	}
+
+	fmt.Printf("%s Successfully authenticated with %s (%s)!\n", qc.Colorize("Success:", qc.ColorGreen), platformName(platform), server)
+	return nil
+}
+
 // testGitHubToken tests a GitHub token by making a simple API call
-func testGitHubToken(token string) error {
-	client := &http.Client{Timeout: 30 * time.Second}
+func testGitHubToken(ctx context.Context, host, token string) error {
+	client := newHTTPClient()
 	
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", githubAPIURL(host)+"user", nil)
 	if err != nil {
 		return err
 	}
@@ -114,22 +333,22 @@ func testGitHubToken(token string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return responseError("github", resp)
 	}
 
 	return nil
 }
 
 // testGitLabToken tests a GitLab token by making a simple API call
-func testGitLabToken(host, token string) error {
-	client := &http.Client{Timeout: 30 * time.Second}
+func testGitLabToken(ctx context.Context, host, token string) error {
+	client := newHTTPClient()
 	
 	baseURL := fmt.Sprintf("https://%s", host)
 	if !strings.HasPrefix(host, "http") {
 		baseURL = fmt.Sprintf("https://%s", host)
 	}
 	
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v4/user", baseURL), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/v4/user", baseURL), nil)
 	if err != nil {
 		return err
 	}
@@ -142,61 +361,145 @@ func testGitLabToken(host, token string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("GitLab API returned status %d", resp.StatusCode)
+		return responseError("gitlab", resp)
 	}
 
 	return nil
 }
 
+// testDroneToken tests a Drone or Woodpecker token by fetching its user
+func testDroneToken(ctx context.Context, platform, server, token string) error {
+	client := newHTTPClient()
 
-// saveAuthConfig saves authentication configuration to file
-func saveAuthConfig(config AuthConfig) error {
-	homeDir, err := os.UserHomeDir()
+	req, err := http.NewRequestWithContext(ctx, "GET", server+"/api/user", nil)
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(platform, resp)
+	}
+
+	return nil
+}
 
-	authDir := fmt.Sprintf("%s/.config/quick_workflow", homeDir)
+// authFilePath returns the path of the auth file in the config directory
+func authFilePath(authDir string) string {
+	return filepath.Join(authDir, "auth.json")
+}
+
+// updateAuthConfig applies change to the stored authentication
+// configuration while holding its lock, writing it back unless change fails
+func updateAuthConfig(change func(config *AuthConfig) error) error {
+	return rewriteAuthConfig(func(config *AuthConfig, encrypted *bool) error {
+		return change(config)
+	})
+}
+
+// rewriteAuthConfig is updateAuthConfig where change may also switch
+// whether the file is encrypted. An encrypted file stays encrypted with the
+// same passphrase.
+func rewriteAuthConfig(change func(config *AuthConfig, encrypted *bool) error) error {
+	authDir, err := configDir()
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(authDir, 0755); err != nil {
 		return err
 	}
 
-	authFile := fmt.Sprintf("%s/auth.json", authDir)
-	
-	// Load existing config if it exists
+	authFile := authFilePath(authDir)
+
+	lock, err := acquireLock(authFile)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	// Load existing config if it exists. One that can't be decrypted
+	// mustn't be overwritten.
 	existingConfig := AuthConfig{}
+	encrypted := false
 	if data, err := os.ReadFile(authFile); err == nil {
-		json.Unmarshal(data, &existingConfig)
+		plain, sealed, err := openAuthData(data)
+		if err != nil {
+			return err
+		}
+		encrypted = sealed
+		json.Unmarshal(plain, &existingConfig)
 	}
 
-	// Merge with existing config
-	if config.GitHubToken != "" {
-		existingConfig.GitHubToken = config.GitHubToken
-	}
-	if config.GitLabToken != "" {
-		existingConfig.GitLabToken = config.GitLabToken
-	}
-	if config.GitLabHost != "" {
-		existingConfig.GitLabHost = config.GitLabHost
+	if err := change(&existingConfig, &encrypted); err != nil {
+		return err
 	}
 
 	data, err := json.MarshalIndent(existingConfig, "", "  ")
 	if err != nil {
 		return err
 	}
+	if encrypted {
+		passphrase, err := readAuthPassphrase(true)
+		if err != nil {
+			return err
+		}
+		if data, err = sealAuthData(data, passphrase); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(authFile, data, 0600)
+}
 
-	return os.WriteFile(authFile, data, 0600)
+// saveAuthConfig merges the credentials set in config into the stored
+// authentication configuration
+func saveAuthConfig(config AuthConfig) error {
+	return updateAuthConfig(func(existingConfig *AuthConfig) error {
+		if config.GitHubToken != "" {
+			existingConfig.GitHubToken = config.GitHubToken
+		}
+		if config.GitLabToken != "" {
+			existingConfig.GitLabToken = config.GitLabToken
+		}
+		if config.GitLabHost != "" {
+			existingConfig.GitLabHost = config.GitLabHost
+		}
+		if config.DroneToken != "" {
+			existingConfig.DroneToken = config.DroneToken
+			existingConfig.DroneServer = config.DroneServer
+		}
+		if config.WoodpeckerToken != "" {
+			existingConfig.WoodpeckerToken = config.WoodpeckerToken
+			existingConfig.WoodpeckerServer = config.WoodpeckerServer
+		}
+		for name, account := range config.Accounts {
+			if existingConfig.Accounts == nil {
+				existingConfig.Accounts = make(map[string]Account)
+			}
+			existingConfig.Accounts[name] = account
+		}
+		return nil
+	})
 }
 
 // loadAuthConfig loads authentication configuration from file
 func loadAuthConfig() (*AuthConfig, error) {
-	homeDir, err := os.UserHomeDir()
+	authDir, err := configDir()
 	if err != nil {
 		return nil, err
 	}
 
-	authFile := fmt.Sprintf("%s/.config/quick_workflow/auth.json", homeDir)
-	data, err := os.ReadFile(authFile)
+	data, err := os.ReadFile(authFilePath(authDir))
+	if err != nil {
+		return nil, err
+	}
+	data, _, err = openAuthData(data)
 	if err != nil {
 		return nil, err
 	}
@@ -209,53 +512,105 @@ func loadAuthConfig() (*AuthConfig, error) {
 	return &config, nil
 }
 
-// showAuthStatus displays current authentication status
-func showAuthStatus() {
+// showAuthStatus displays current authentication status. Logins of the gh
+// and glab CLIs count when nothing is stored. GitHub and GitLab tokens are
+// checked against their APIs for the user, scopes, and expiry.
+func showAuthStatus(ctx context.Context) {
 	config, err := loadAuthConfig()
 	if err != nil {
-		fmt.Printf("%s No authentication found\n", qc.Colorize("Info:", qc.ColorCyan))
-		return
+		config = &AuthConfig{}
 	}
 
 	fmt.Printf("%s\n", qc.Colorize("Authentication Status:", qc.ColorBlue))
-	
-	if config.GitHubToken != "" {
+	if activeProfile != "" {
+		fmt.Printf("Profile: %s\n", qc.ColorizeBold(activeProfile, qc.ColorCyan))
+	}
+	if authFileEncrypted() {
+		fmt.Printf("Stored tokens: %s\n", qc.Colorize("encrypted", qc.ColorGreen))
+		if failure := authPassphraseFailure(); failure != nil {
+			fmt.Printf("%s Stored tokens unavailable: %v\n", qc.Colorize("Warning:", qc.ColorYellow), failure)
+		}
+	}
+
+	switch {
+	case config.GitHubToken != "":
 		fmt.Printf("GitHub: %s\n", qc.Colorize("✓ Authenticated", qc.ColorGreen))
-	} else {
+		showTokenInfo(ctx, "github", "github.com", config.GitHubToken)
+	case ghCLIToken("github.com") != "":
+		fmt.Printf("GitHub: %s\n", qc.Colorize("✓ Authenticated (gh CLI)", qc.ColorGreen))
+		showTokenInfo(ctx, "github", "github.com", ghCLIToken("github.com"))
+	default:
 		fmt.Printf("GitHub: %s\n", qc.Colorize("✗ Not authenticated", qc.ColorRed))
 	}
 
-	if config.GitLabToken != "" {
-		host := config.GitLabHost
-		if host == "" {
-			host = "gitlab.com"
-		}
-		fmt.Printf("GitLab (%s): %s\n", host, qc.Colorize("✓ Authenticated", qc.ColorGreen))
-	} else {
+	host := firstNonEmpty(os.Getenv("GITLAB_HOST"), config.GitLabHost, "gitlab.com")
+	switch {
+	case config.GitLabToken != "":
+		storedHost := firstNonEmpty(config.GitLabHost, "gitlab.com")
+		fmt.Printf("GitLab (%s): %s\n", storedHost, qc.Colorize("✓ Authenticated", qc.ColorGreen))
+		showTokenInfo(ctx, "gitlab", storedHost, config.GitLabToken)
+	case glabCLIToken(host) != "":
+		fmt.Printf("GitLab (%s): %s\n", host, qc.Colorize("✓ Authenticated (glab CLI)", qc.ColorGreen))
+		showTokenInfo(ctx, "gitlab", host, glabCLIToken(host))
+	default:
 		fmt.Printf("GitLab: %s\n", qc.Colorize("✗ Not authenticated", qc.ColorRed))
 	}
+
+	for _, platform := range []string{"drone", "woodpecker"} {
+		token, server := config.droneCredentials(platform)
+		if token != "" {
+			fmt.Printf("%s (%s): %s\n", platformName(platform), server, qc.Colorize("✓ Authenticated", qc.ColorGreen))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Accounts)) {
+		account := config.Accounts[name]
+		label := fmt.Sprintf("%s account %s (%s)", platformName(account.Platform), qc.ColorizeBold(name, qc.ColorCyan), account.Host)
+		token, host, err := accountCredentials(name, account.Platform)
+		if err != nil {
+			fmt.Printf("%s: %s\n", label, qc.Colorize("✗ Not authenticated", qc.ColorRed))
+			continue
+		}
+		fmt.Printf("%s: %s\n", label, qc.Colorize("✓ Authenticated", qc.ColorGreen))
+		showTokenInfo(ctx, account.Platform, host, token)
+	}
 }
 
 // logout removes authentication tokens
 func logout(platform string) error {
-	config, err := loadAuthConfig()
-	if err != nil {
+	if _, err := loadAuthConfig(); err != nil {
 		return fmt.Errorf("no authentication found")
 	}
 
-	switch platform {
-	case "github":
-		config.GitHubToken = ""
-	case "gitlab":
-		config.GitLabToken = ""
-		config.GitLabHost = ""
-	case "all":
-		config.GitHubToken = ""
-		config.GitLabToken = ""
-		config.GitLabHost = ""
-	default:
-		return fmt.Errorf("invalid platform: %s", platform)
-	}
+	return updateAuthConfig(func(config *AuthConfig) error {
+		switch platform {
+		case "github":
+			config.GitHubToken = ""
+		case "gitlab":
+			config.GitLabToken = ""
+			config.GitLabHost = ""
+		case "drone":
+			config.DroneToken = ""
+			config.DroneServer = ""
+		case "woodpecker":
+			config.WoodpeckerToken = ""
+			config.WoodpeckerServer = ""
+		case "all":
+			*config = AuthConfig{}
+		default:
+			return fmt.Errorf("invalid platform: %s", platform)
+		}
+		return nil
+	})
+}
 
-	return saveAuthConfig(*config)
+// logoutAccount removes a named account
+func logoutAccount(name string) error {
+	return updateAuthConfig(func(config *AuthConfig) error {
+		if _, ok := config.Accounts[name]; !ok {
+			return fmt.Errorf("account %s not found", name)
+		}
+		delete(config.Accounts, name)
+		return nil
+	})
 }
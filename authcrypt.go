@@ -0,0 +1,280 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// The passphrase of an encrypted auth file is read from authKeyEnv, else
+// from the file named by authKeyFileEnv, else prompted for
+const (
+	authKeyEnv     = "QUICK_WORKFLOW_KEY"
+	authKeyFileEnv = "QUICK_WORKFLOW_KEY_FILE"
+)
+
+// scrypt parameters deriving the secretbox key from the passphrase
+const (
+	authScryptN = 1 << 15
+	authScryptR = 8
+	authScryptP = 1
+)
+
+// sealedAuthFile is the auth file when encrypted
+type sealedAuthFile struct {
+	Encrypted *sealedAuth `json:"encrypted,omitempty"`
+}
+
+// sealedAuth is the JSON of an AuthConfig sealed with NaCl secretbox under
+// a key derived from the passphrase with scrypt
+type sealedAuth struct {
+	KDF   string `json:"kdf"`
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// The passphrase is asked for once per invocation. A failure is kept too,
+// so a wrong or missing passphrase is reported once rather than per token
+// lookup. Keys derived from it are kept by salt, so scrypt runs once per
+// salt rather than per load. Clients of several projects load the auth
+// file at once, so all of them are guarded by authPassphraseMu.
+var (
+	authPassphraseMu  sync.Mutex
+	authPassphrase    []byte
+	authPassphraseErr error
+	authKeys          = make(map[string]*[32]byte)
+)
+
+// readAuthPassphrase returns the passphrase of the auth file. A new
+// passphrase is prompted for twice.
+func readAuthPassphrase(confirmNew bool) ([]byte, error) {
+	authPassphraseMu.Lock()
+	defer authPassphraseMu.Unlock()
+	return readAuthPassphraseLocked(confirmNew)
+}
+
+// readAuthPassphraseLocked is readAuthPassphrase with authPassphraseMu held
+func readAuthPassphraseLocked(confirmNew bool) ([]byte, error) {
+	if authPassphrase != nil || authPassphraseErr != nil {
+		return authPassphrase, authPassphraseErr
+	}
+	authPassphrase, authPassphraseErr = promptAuthPassphrase(confirmNew)
+	return authPassphrase, authPassphraseErr
+}
+
+// resetAuthPassphrase forgets the passphrase, so the next one is asked for
+// again, and the keys derived from it
+func resetAuthPassphrase(err error) {
+	authPassphraseMu.Lock()
+	defer authPassphraseMu.Unlock()
+	authPassphrase, authPassphraseErr = nil, err
+	clear(authKeys)
+}
+
+// authPassphraseFailure returns why the passphrase couldn't be read or was
+// wrong, nil when it wasn't
+func authPassphraseFailure() error {
+	authPassphraseMu.Lock()
+	defer authPassphraseMu.Unlock()
+	return authPassphraseErr
+}
+
+// authKey returns the key of the auth file's salt, deriving it from the
+// passphrase the first time
+func authKey(salt []byte) (*[32]byte, error) {
+	authPassphraseMu.Lock()
+	defer authPassphraseMu.Unlock()
+	passphrase, err := readAuthPassphraseLocked(false)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := authKeys[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := deriveAuthKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	authKeys[string(salt)] = key
+	return key, nil
+}
+
+// promptAuthPassphrase reads the passphrase from the environment, a key
+// file, or the terminal, in that order
+func promptAuthPassphrase(confirmNew bool) ([]byte, error) {
+	if key := os.Getenv(authKeyEnv); key != "" {
+		return []byte(key), nil
+	}
+	if path := os.Getenv(authKeyFileEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %v", err)
+		}
+		key := strings.TrimRight(string(data), "\r\n")
+		if key == "" {
+			return nil, fmt.Errorf("key file %s is empty", path)
+		}
+		return []byte(key), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		if confirmNew {
+			return nil, fmt.Errorf("no terminal to ask for a passphrase; set %s or %s", authKeyEnv, authKeyFileEnv)
+		}
+		return nil, fmt.Errorf("auth.json is encrypted; set %s or %s", authKeyEnv, authKeyFileEnv)
+	}
+	// Prompts go to stderr so piped output stays clean
+	fmt.Fprintf(os.Stderr, "%s", qc.Colorize("Passphrase for auth.json: ", qc.ColorYellow))
+	key, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("no passphrase provided")
+	}
+	if confirmNew {
+		fmt.Fprintf(os.Stderr, "%s", qc.Colorize("Repeat passphrase: ", qc.ColorYellow))
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		if string(again) != string(key) {
+			return nil, fmt.Errorf("passphrases don't match")
+		}
+	}
+	return key, nil
+}
+
+// deriveAuthKey derives the secretbox key from a passphrase and salt
+func deriveAuthKey(passphrase, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key(passphrase, salt, authScryptN, authScryptR, authScryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// sealAuthData encrypts the JSON of an auth config with the passphrase,
+// under a fresh salt and nonce
+func sealAuthData(plain, passphrase []byte) ([]byte, error) {
+	var salt [16]byte
+	var nonce [24]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key, err := deriveAuthKey(passphrase, salt[:])
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := sealedAuthFile{Encrypted: &sealedAuth{
+		KDF:   "scrypt",
+		Salt:  salt[:],
+		Nonce: nonce[:],
+		Data:  secretbox.Seal(nil, plain, &nonce, key),
+	}}
+	return json.MarshalIndent(sealed, "", "  ")
+}
+
+// openAuthData returns the JSON of an auth config read from the auth file,
+// decrypting it when it is encrypted, and whether it was
+func openAuthData(data []byte) ([]byte, bool, error) {
+	var sealed sealedAuthFile
+	if err := json.Unmarshal(data, &sealed); err != nil || sealed.Encrypted == nil {
+		return data, false, nil
+	}
+	if sealed.Encrypted.KDF != "scrypt" || len(sealed.Encrypted.Nonce) != 24 {
+		return nil, true, fmt.Errorf("unsupported auth.json encryption")
+	}
+
+	key, err := authKey(sealed.Encrypted.Salt)
+	if err != nil {
+		return nil, true, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed.Encrypted.Nonce)
+	plain, ok := secretbox.Open(nil, sealed.Encrypted.Data, &nonce, key)
+	if !ok {
+		err := fmt.Errorf("wrong passphrase for auth.json")
+		resetAuthPassphrase(err)
+		return nil, true, err
+	}
+	return plain, true, nil
+}
+
+// authRequired returns err, or why stored tokens couldn't be read when the
+// auth file is encrypted and couldn't be decrypted
+func authRequired(err error) error {
+	if failure := authPassphraseFailure(); failure != nil {
+		return fmt.Errorf("stored tokens unavailable: %v", failure)
+	}
+	return err
+}
+
+// authFileEncrypted reports whether the auth file is stored encrypted,
+// without decrypting it
+func authFileEncrypted() bool {
+	authDir, err := configDir()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(authFilePath(authDir))
+	if err != nil {
+		return false
+	}
+	var sealed sealedAuthFile
+	return json.Unmarshal(data, &sealed) == nil && sealed.Encrypted != nil
+}
+
+// setAuthEncryption encrypts the auth file with a new passphrase, or
+// decrypts it back to plaintext
+func setAuthEncryption(encrypt bool) error {
+	return rewriteAuthConfig(func(config *AuthConfig, encrypted *bool) error {
+		if encrypt == *encrypted {
+			if encrypt {
+				return fmt.Errorf("auth.json is already encrypted")
+			}
+			return fmt.Errorf("auth.json isn't encrypted")
+		}
+		if encrypt {
+			// The passphrase is new, so an earlier one isn't reused
+			resetAuthPassphrase(nil)
+			if _, err := readAuthPassphrase(true); err != nil {
+				return err
+			}
+		}
+		*encrypted = encrypt
+		return nil
+	})
+}
+
+// handleAuthEncryption handles the auth encrypt and decrypt commands
+func handleAuthEncryption(action string) {
+	encrypt := action == "encrypt"
+	if err := setAuthEncryption(encrypt); err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if encrypt {
+		fmt.Printf("%s Encrypted auth.json. Set %s or %s to skip the passphrase prompt.\n", qc.Colorize("Success:", qc.ColorGreen), authKeyEnv, authKeyFileEnv)
+		return
+	}
+	fmt.Printf("%s Decrypted auth.json; tokens are stored in plaintext again\n", qc.Colorize("Success:", qc.ColorGreen))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSealOpenAuthDataRoundtrip(t *testing.T) {
+	t.Setenv(authKeyEnv, "correct horse")
+	resetAuthPassphrase(nil)
+	t.Cleanup(func() { resetAuthPassphrase(nil) })
+
+	plain := []byte(`{"github_token":"ghp_example"}`)
+	sealed, err := sealAuthData(plain, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("sealAuthData: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("ghp_example")) {
+		t.Fatalf("sealed data contains the token: %s", sealed)
+	}
+
+	opened, encrypted, err := openAuthData(sealed)
+	if err != nil {
+		t.Fatalf("openAuthData: %v", err)
+	}
+	if !encrypted {
+		t.Errorf("openAuthData reported the data as not encrypted")
+	}
+	if !bytes.Equal(opened, plain) {
+		t.Errorf("openAuthData = %s, want %s", opened, plain)
+	}
+
+	// Plaintext auth files pass through unchanged
+	opened, encrypted, err = openAuthData(plain)
+	if err != nil || encrypted || !bytes.Equal(opened, plain) {
+		t.Errorf("openAuthData(plaintext) = %s, %v, %v", opened, encrypted, err)
+	}
+}
+
+func TestOpenAuthDataWrongPassphrase(t *testing.T) {
+	t.Setenv(authKeyEnv, "wrong")
+	resetAuthPassphrase(nil)
+	t.Cleanup(func() { resetAuthPassphrase(nil) })
+
+	sealed, err := sealAuthData([]byte(`{}`), []byte("right"))
+	if err != nil {
+		t.Fatalf("sealAuthData: %v", err)
+	}
+	_, encrypted, err := openAuthData(sealed)
+	if err == nil || err.Error() != "wrong passphrase for auth.json" {
+		t.Fatalf("openAuthData error = %v, want wrong passphrase for auth.json", err)
+	}
+	if !encrypted {
+		t.Errorf("openAuthData reported the data as not encrypted")
+	}
+	if err := authRequired(nil); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("authRequired = %v, want the wrong passphrase reported", err)
+	}
+}
+
+func TestOpenAuthDataBadNonce(t *testing.T) {
+	t.Setenv(authKeyEnv, "correct horse")
+	resetAuthPassphrase(nil)
+	t.Cleanup(func() { resetAuthPassphrase(nil) })
+
+	sealed, err := sealAuthData([]byte(`{}`), []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("sealAuthData: %v", err)
+	}
+	var file sealedAuthFile
+	if err := json.Unmarshal(sealed, &file); err != nil {
+		t.Fatal(err)
+	}
+	file.Encrypted.Nonce = file.Encrypted.Nonce[:12]
+	truncated, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := openAuthData(truncated); err == nil || err.Error() != "unsupported auth.json encryption" {
+		t.Errorf("openAuthData error = %v, want unsupported auth.json encryption", err)
+	}
+}
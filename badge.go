@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// badgeColors are the right-hand colors of status badges per badge class,
+// matching the usual shields.io palette
+var badgeColors = map[string]string{
+	"success":   "#4c1",
+	"failure":   "#e05d44",
+	"running":   "#dfb317",
+	"cancelled": "#9f9f9f",
+	"neutral":   "#9f9f9f",
+}
+
+// badgeMessages are the right-hand texts of status badges per badge class
+var badgeMessages = map[string]string{
+	"success":   "passing",
+	"failure":   "failing",
+	"running":   "running",
+	"cancelled": "cancelled",
+}
+
+// badgeRequest selects the run a badge shows
+type badgeRequest struct {
+	Project  string
+	Workflow string
+	Branch   string
+	Label    string
+}
+
+// label returns the left-hand text of the badge: the one asked for, else
+// the workflow, else "build"
+func (r badgeRequest) label() string {
+	switch {
+	case r.Label != "":
+		return r.Label
+	case r.Workflow != "":
+		return r.Workflow
+	default:
+		return "build"
+	}
+}
+
+// badgeTextWidth estimates the rendered width of badge text in the 11px
+// Verdana the badge uses
+func badgeTextWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("ijlI.,:;!|' ", r):
+			width += 4
+		case strings.ContainsRune("mwMW@", r):
+			width += 10
+		case r >= 'A' && r <= 'Z':
+			width += 8
+		default:
+			width += 7
+		}
+	}
+	return width
+}
+
+// renderBadge renders a flat two-part SVG badge
+func renderBadge(label, message, color string) string {
+	labelWidth := badgeTextWidth(label) + 10
+	messageWidth := badgeTextWidth(message) + 10
+	width := labelWidth + messageWidth
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, message)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, message)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		labelWidth, labelWidth, messageWidth, color, width)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, labelWidth/2, label, labelWidth/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, labelWidth+messageWidth/2, message, labelWidth+messageWidth/2, message)
+	b.WriteString("</g></svg>\n")
+	return b.String()
+}
+
+// latestBadgeRun finds the newest run of a project matching the workflow
+// and branch of a badge, or nil when there is none
+func latestBadgeRun(ctx context.Context, project Project, request badgeRequest) (*WorkflowRun, error) {
+	runs, err := getWorkflowRunsForProject(ctx, project, request.Branch, 50)
+	if err != nil {
+		return nil, err
+	}
+	var latest *WorkflowRun
+	for i, run := range runs {
+		if request.Workflow != "" && !strings.EqualFold(run.Workflow, request.Workflow) {
+			continue
+		}
+		if latest == nil || run.CreatedAt.After(latest.CreatedAt) {
+			latest = &runs[i]
+		}
+	}
+	return latest, nil
+}
+
+// generateBadge renders the badge of a tracked project's latest matching run
+func generateBadge(ctx context.Context, config *Config, request badgeRequest) (string, error) {
+	project := findProject(config, request.Project)
+	if project == nil {
+		return "", fmt.Errorf("project not found: %s", request.Project)
+	}
+	message, color, err := badgeStatus(ctx, *project, request)
+	if err != nil {
+		return "", err
+	}
+	return renderBadge(request.label(), message, color), nil
+}
+
+// badgeStatus returns the right-hand text and color of a badge from the
+// project's latest matching run
+func badgeStatus(ctx context.Context, project Project, request badgeRequest) (string, string, error) {
+	run, err := latestBadgeRun(ctx, project, request)
+	if err != nil {
+		return "", "", err
+	}
+	if run == nil {
+		return "no runs", badgeColors["neutral"], nil
+	}
+	badge := badgeForRun(*run)
+	message, ok := badgeMessages[badge.Class]
+	if !ok {
+		message = badge.Label
+	}
+	return message, badgeColors[badge.Class], nil
+}
+
+// handleBadge handles the badge command
+func handleBadge(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("badge", flag.ExitOnError)
+	workflow := fs.String("workflow", "", "Only runs of this workflow (GitHub) or ref (GitLab)")
+	branch := fs.String("branch", "", "Only runs on this branch (default: any branch)")
+	label := fs.String("label", "", "Left-hand text (default: the workflow, else \"build\")")
+	out := fs.String("out", "", "Write the SVG to a file instead of stdout")
+	serve := fs.String("serve", "", "Serve badges over HTTP on this address instead, e.g. :8080")
+	cacheFor := fs.Duration("cache", time.Minute, "How long a served badge is reused before checking again")
+	fs.Parse(args)
+
+	if *serve != "" {
+		serveBadges(ctx, config, *serve, *cacheFor)
+		return
+	}
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow badge [--workflow <name>] [--branch <branch>] [--out file.svg] <project> or badge --serve <addr>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	svg, err := generateBadge(ctx, config, badgeRequest{Project: fs.Arg(0), Workflow: *workflow, Branch: *branch, Label: *label})
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if *out == "" {
+		fmt.Print(svg)
+		return
+	}
+	if err := writeFileAtomic(*out, []byte(svg), 0644); err != nil {
+		fmt.Printf("%s Failed to write badge: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Wrote badge for %s to %s\n", qc.Colorize("Success:", qc.ColorGreen), fs.Arg(0), *out)
+}
+
+// cachedBadge is the status a served badge shows and when it was checked
+type cachedBadge struct {
+	message string
+	color   string
+	checked time.Time
+}
+
+// serveBadges serves badges of tracked projects at
+// /badge.svg?project=<name>&workflow=<name>&branch=<branch>&label=<text>,
+// reusing each badge's status for cacheFor to stay clear of API rate
+// limits. Statuses are cached by tracked project, workflow, and branch;
+// the label only changes how the badge renders.
+func serveBadges(ctx context.Context, config *Config, addr string, cacheFor time.Duration) {
+	var mu sync.Mutex
+	cache := make(map[badgeRequest]cachedBadge)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/badge.svg", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		request := badgeRequest{
+			Project:  query.Get("project"),
+			Workflow: query.Get("workflow"),
+			Branch:   query.Get("branch"),
+			Label:    query.Get("label"),
+		}
+		if request.Project == "" {
+			http.Error(w, "missing project", http.StatusBadRequest)
+			return
+		}
+
+		// Only tracked projects are cached, so clients can't grow the cache
+		// with made up names
+		project := findProject(config, request.Project)
+		if project == nil {
+			http.Error(w, "project not found", http.StatusNotFound)
+			return
+		}
+		key := badgeRequest{Project: project.Name, Workflow: request.Workflow, Branch: request.Branch}
+
+		mu.Lock()
+		cached, ok := cache[key]
+		mu.Unlock()
+		if !ok || time.Since(cached.checked) > cacheFor {
+			message, color, err := badgeStatus(ctx, *project, request)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+				message, color = "unknown", badgeColors["neutral"]
+			}
+			cached = cachedBadge{message: message, color: color, checked: time.Now()}
+			mu.Lock()
+			for other, entry := range cache {
+				if time.Since(entry.checked) > cacheFor {
+					delete(cache, other)
+				}
+			}
+			cache[key] = cached
+			mu.Unlock()
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(cacheFor.Seconds())))
+		fmt.Fprint(w, renderBadge(request.label(), cached.message, cached.color))
+	})
+
+	// Stop accepting requests on Ctrl+C and let ones in flight finish
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("%s Serving badges on %s/badge.svg?project=<name> (Ctrl+C to stop)\n", qc.Colorize("Info:", qc.ColorCyan), addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Stopped serving badges\n", qc.Colorize("Info:", qc.ColorCyan))
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// runOperation is an action applied to runs by the cancel and rerun commands
+type runOperation struct {
+	verb string
+	done string
+	// action names the operation for planRunCall
+	action string
+	// eligible reports whether the operation applies to a run, and why
+	// not when it doesn't
+	eligible func(run WorkflowRun) (bool, string)
+	apply    func(ctx context.Context, run WorkflowRun) error
+}
+
+// cancelOperation cancels runs that haven't finished
+var cancelOperation = runOperation{
+	verb:   "Cancel",
+	done:   "Canceled",
+	action: "cancel",
+	eligible: func(run WorkflowRun) (bool, string) {
+		if isRunFinished(run.Status) {
+			return false, "already finished"
+		}
+		return true, ""
+	},
+	apply: func(ctx context.Context, run WorkflowRun) error {
+		switch run.Platform {
+		case "github":
+			owner, repo, ok := strings.Cut(run.Project, "/")
+			if !ok {
+				return fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+			}
+			client, err := NewGitHubClient(ctx, run.Project)
+			if err != nil {
+				return err
+			}
+			return client.CancelWorkflowRun(owner, repo, run.ID)
+		case "gitlab":
+			client, err := NewGitLabClient(ctx, run.Project)
+			if err != nil {
+				return err
+			}
+			return client.CancelPipeline(run.Project, run.ID)
+		case "drone", "woodpecker":
+			owner, repo, ok := strings.Cut(run.Project, "/")
+			if !ok {
+				return fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+			}
+			client, err := NewDroneClient(ctx, run.Platform)
+			if err != nil {
+				return err
+			}
+			return client.CancelBuild(owner, repo, run.ID)
+		case "mock":
+			client, err := NewMockClient()
+			if err != nil {
+				return err
+			}
+			return client.CancelRun(run.Project, run.ID)
+		default:
+			client, err := NewPluginClient(ctx, run.Platform)
+			if err != nil {
+				return err
+			}
+			return client.Cancel(run)
+		}
+	},
+}
+
+// rerunOperation re-runs finished runs, only their failed jobs when
+// failedOnly is set. GitLab always retries only the failed jobs, and Drone
+// and Woodpecker always restart the whole build.
+func rerunOperation(failedOnly bool) runOperation {
+	action := "rerun"
+	if failedOnly {
+		action = "rerun-failed"
+	}
+	return runOperation{
+		verb:   "Re-run",
+		done:   "Re-ran",
+		action: action,
+		eligible: func(run WorkflowRun) (bool, string) {
+			switch {
+			case !isRunFinished(run.Status):
+				return false, "still running"
+			case failedOnly && !isFailedConclusion(run.Conclusion):
+				return false, "did not fail"
+			}
+			return true, ""
+		},
+		apply: func(ctx context.Context, run WorkflowRun) error {
+			switch run.Platform {
+			case "github":
+				owner, repo, ok := strings.Cut(run.Project, "/")
+				if !ok {
+					return fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+				}
+				client, err := NewGitHubClient(ctx, run.Project)
+				if err != nil {
+					return err
+				}
+				return client.RerunWorkflowRun(owner, repo, run.ID, failedOnly)
+			case "gitlab":
+				client, err := NewGitLabClient(ctx, run.Project)
+				if err != nil {
+					return err
+				}
+				return client.RetryPipeline(run.Project, run.ID)
+			case "drone", "woodpecker":
+				owner, repo, ok := strings.Cut(run.Project, "/")
+				if !ok {
+					return fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+				}
+				client, err := NewDroneClient(ctx, run.Platform)
+				if err != nil {
+					return err
+				}
+				return client.RestartBuild(owner, repo, run.ID)
+			case "mock":
+				client, err := NewMockClient()
+				if err != nil {
+					return err
+				}
+				return client.RerunRun(run.Project, run.ID, failedOnly)
+			default:
+				client, err := NewPluginClient(ctx, run.Platform)
+				if err != nil {
+					return err
+				}
+				return client.Rerun(run, failedOnly)
+			}
+		},
+	}
+}
+
+// runSelection filters the runs a bulk operation applies to
+type runSelection struct {
+	project  string
+	branch   string
+	workflow string
+	since    time.Duration
+	limit    int
+	// superseded keeps only runs superseded by a newer unfinished run
+	superseded bool
+}
+
+// matches reports whether a run passes the workflow and age filters; the
+// project and branch are filtered when fetching
+func (s runSelection) matches(run WorkflowRun) bool {
+	if s.workflow != "" && !strings.EqualFold(run.Workflow, s.workflow) {
+		return false
+	}
+	if s.since > 0 && run.CreatedAt.Before(time.Now().Add(-s.since)) {
+		return false
+	}
+	return true
+}
+
+// selectRuns fetches the recent runs of the selected projects that match
+// the filters and the operation applies to
+func selectRuns(ctx context.Context, config *Config, selection runSelection, op runOperation) ([]WorkflowRun, error) {
+	projects := config.Projects
+	if selection.project != "" {
+		project := findProject(config, selection.project)
+		if project == nil {
+			return nil, fmt.Errorf("project not found: %s", selection.project)
+		}
+		projects = []Project{*project}
+	}
+
+	var selected []WorkflowRun
+	for _, project := range projects {
+		runs, err := getWorkflowRunsForProject(ctx, project, selection.branch, selection.limit)
+		if err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+		for _, run := range runs {
+			if ok, _ := op.eligible(run); ok && selection.matches(run) {
+				selected = append(selected, run)
+			}
+		}
+	}
+	if selection.superseded {
+		markSuperseded(selected)
+		selected = supersededRuns(selected)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].CreatedAt.After(selected[j].CreatedAt)
+	})
+	return selected, nil
+}
+
+// lookupRuns fetches runs by ID, skipping the ones the operation doesn't
+// apply to
+func lookupRuns(ctx context.Context, config *Config, projectName string, runIDs []string, op runOperation) []WorkflowRun {
+	var runs []WorkflowRun
+	for _, ref := range runIDs {
+		project, runID, err := resolveRun(config, projectName, ref)
+		if err != nil {
+			fmt.Printf("%s Skipping run %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), ref, describeError(err))
+			continue
+		}
+		run, err := getWorkflowRun(ctx, *project, runID)
+		if err != nil {
+			fmt.Printf("%s Skipping run %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), runID, describeError(err))
+			continue
+		}
+		if ok, reason := op.eligible(*run); !ok {
+			fmt.Printf("%s Skipping run %s: %s\n", qc.Colorize("Warning:", qc.ColorYellow), runID, reason)
+			continue
+		}
+		runs = append(runs, *run)
+	}
+	return runs
+}
+
+// handleCancel handles the cancel command
+func handleCancel(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
+	all := fs.Bool("all", false, "Cancel every unfinished run matching the filters")
+	selection, dryRun, yes := bulkFlags(fs)
+	fs.BoolVar(&selection.superseded, "superseded", false, "Cancel unfinished runs that a newer run of the same workflow and branch supersedes")
+	fs.Parse(args)
+
+	bulk := *all || selection.superseded
+	if bulk == (fs.NArg() > 0) {
+		fmt.Printf("%s Usage: quick_workflow cancel [--project <name>] <run-id>... or cancel --all|--superseded [filters]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	applyBulk(ctx, config, cancelOperation, bulk, selection, fs.Args(), *dryRun, *yes)
+}
+
+// handleRerun handles the rerun command
+func handleRerun(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("rerun", flag.ExitOnError)
+	failed := fs.Bool("failed", false, "Re-run the failed jobs of every failed run matching the filters")
+	selection, dryRun, yes := bulkFlags(fs)
+	fs.Parse(args)
+
+	if !*failed && fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow rerun [--failed] [--project <name>] <run-id>... or rerun --failed [filters]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	// With run IDs, --failed limits the re-run to failed jobs
+	applyBulk(ctx, config, rerunOperation(*failed), *failed && fs.NArg() == 0, selection, fs.Args(), *dryRun, *yes)
+}
+
+// bulkFlags defines the filters and safety flags shared by cancel and rerun
+func bulkFlags(fs *flag.FlagSet) (*runSelection, *bool, *bool) {
+	selection := &runSelection{}
+	fs.StringVar(&selection.project, "project", "", "Only runs of this project")
+	fs.StringVar(&selection.branch, "branch", "", "Only runs on this branch")
+	fs.StringVar(&selection.workflow, "workflow", "", "Only runs of this workflow (GitHub) or ref (GitLab)")
+	fs.DurationVar(&selection.since, "since", 0, "Only runs created within this long, e.g. 1h")
+	fs.IntVar(&selection.limit, "limit", 50, "Number of recent runs to consider per project")
+	dryRun := fs.Bool("dry-run", false, "List the matching runs and the API calls that would change them, without making them")
+	yes := fs.Bool("yes", false, "Don't ask for confirmation")
+	return selection, dryRun, yes
+}
+
+// applyBulk previews the runs an operation applies to, selected by the
+// filters or by ID, and applies it to each after confirmation
+func applyBulk(ctx context.Context, config *Config, op runOperation, bulk bool, selection *runSelection, runIDs []string, dryRun, yes bool) {
+	var runs []WorkflowRun
+	if bulk {
+		var err error
+		runs, err = selectRuns(ctx, config, *selection, op)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	} else {
+		runs = lookupRuns(ctx, config, selection.project, runIDs, op)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("%s No runs to %s\n", qc.Colorize("Info:", qc.ColorCyan), strings.ToLower(op.verb))
+		return
+	}
+
+	confirmAndApply(ctx, bufio.NewReader(os.Stdin), config, op, runs, dryRun, yes)
+}
+
+// confirmAndApply lists the runs an operation is about to change and
+// applies it to each once confirmed, reporting runs that fail
+func confirmAndApply(ctx context.Context, reader *bufio.Reader, config *Config, op runOperation, runs []WorkflowRun, dryRun, yes bool) {
+	columns, err := parseColumns("", config.Settings)
+	if err != nil {
+		columns = defaultRunColumns
+	}
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("%s %d run(s):", op.verb, len(runs)), qc.ColorBlue))
+	displayWorkflowRuns(runs, columns)
+	fmt.Println()
+
+	if dryRun {
+		var calls []apiCall
+		for _, run := range runs {
+			call, err := planRunCall(ctx, run, op.action)
+			if err != nil {
+				fmt.Printf("%s %s run %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), run.Project, run.ID, describeError(err))
+				continue
+			}
+			calls = append(calls, call)
+		}
+		printAPICalls(calls)
+		fmt.Printf("\n%s Dry run; no runs were changed\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+	if !yes && !confirm(reader, fmt.Sprintf("%s %d run(s)?", op.verb, len(runs))) {
+		fmt.Printf("%s Cancelled\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	failed := 0
+	for _, run := range runs {
+		err := op.apply(ctx, run)
+		recordAction(config, op.action, run.Project, map[string]string{"run": run.ID, "workflow": run.Workflow, "branch": run.Branch}, strings.ToLower(op.done)+" run "+run.ID, err)
+		if err != nil {
+			failed++
+			fmt.Printf("%s %s run %s: %v\n", qc.Colorize("Error:", qc.ColorRed), run.Project, run.ID, describeError(err))
+			continue
+		}
+		fmt.Printf("%s %s %s run %s (%s on %s)\n", qc.Colorize("Success:", qc.ColorGreen), op.done, run.Project, run.ID, run.Workflow, run.Branch)
+	}
+	if failed > 0 {
+		fmt.Printf("\n%s %d of %d run(s) could not be changed\n", qc.Colorize("Warning:", qc.ColorYellow), failed, len(runs))
+	}
+}
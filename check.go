@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// Exit statuses of the check command, for cron jobs and shell prompts
+const (
+	checkPassing = 0
+	checkError   = 1
+	checkFailing = 2
+	checkRunning = 3
+)
+
+// checkResult is the latest run of one workflow of a project
+type checkResult struct {
+	run    WorkflowRun
+	status int
+}
+
+// latestPerWorkflow returns the newest run of each workflow, by workflow
+// name
+func latestPerWorkflow(runs []WorkflowRun) []WorkflowRun {
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].CreatedAt.After(runs[j].CreatedAt) })
+	seen := make(map[string]bool)
+	var latest []WorkflowRun
+	for _, run := range runs {
+		if !seen[run.Workflow] {
+			seen[run.Workflow] = true
+			latest = append(latest, run)
+		}
+	}
+	return latest
+}
+
+// runCheckStatus returns the check exit status a run stands for
+func runCheckStatus(run WorkflowRun) int {
+	switch {
+	case !isRunFinished(run.Status):
+		return checkRunning
+	case run.Status == "failed" || isFailedConclusion(run.Conclusion):
+		return checkFailing
+	default:
+		return checkPassing
+	}
+}
+
+// worseCheckStatus returns the more severe of two check statuses: failing,
+// then running, then errors, then passing
+func worseCheckStatus(a, b int) int {
+	rank := map[int]int{checkPassing: 0, checkError: 1, checkRunning: 2, checkFailing: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// handleCheck checks the latest run of every workflow of the selected
+// projects, on the given branch or each project's default branch, and
+// exits 0 when all passed, 2 when any failed, 3 when any is still running,
+// or 1 when runs couldn't be fetched
+func handleCheck(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	projectNames := fs.String("project", "", "Comma separated projects to check (default: all tracked projects)")
+	branch := fs.String("branch", "", "Branch to check (default: each project's default branch, else the latest runs of any branch)")
+	quiet := fs.Bool("quiet", false, "Print nothing; only set the exit status")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if *projectNames != "" {
+		projects = nil
+		for _, name := range splitList(*projectNames) {
+			project := findProject(config, name)
+			if project == nil {
+				if !*quiet {
+					fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+				}
+				os.Exit(checkError)
+			}
+			projects = append(projects, *project)
+		}
+	}
+	if len(projects) == 0 {
+		if !*quiet {
+			fmt.Printf("%s No projects tracked. Use 'quick_workflow add .' to add a project.\n", qc.Colorize("Info:", qc.ColorCyan))
+		}
+		os.Exit(checkError)
+	}
+
+	status := checkPassing
+	var results []checkResult
+	for _, project := range projects {
+		runs, err := getWorkflowRunsForProject(ctx, project, firstNonEmpty(*branch, project.DefaultBranch), 20)
+		if ctx.Err() != nil {
+			os.Exit(checkError)
+		}
+		if err != nil {
+			if !*quiet {
+				fmt.Printf("%s Failed to get workflows for %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, describeError(err))
+			}
+			status = worseCheckStatus(status, checkError)
+			continue
+		}
+		for _, run := range latestPerWorkflow(runs) {
+			result := checkResult{run: run, status: runCheckStatus(run)}
+			results = append(results, result)
+			status = worseCheckStatus(status, result.status)
+		}
+	}
+
+	if !*quiet {
+		for _, result := range results {
+			symbol := qc.Colorize("✔", qc.ColorGreen)
+			switch result.status {
+			case checkFailing:
+				symbol = qc.Colorize("✖", qc.ColorRed)
+			case checkRunning:
+				symbol = qc.Colorize("●", qc.ColorYellow)
+			}
+			run := result.run
+			fmt.Printf("%s %-30s %-25s %-20s %s\n", symbol, run.Project, run.Workflow, run.Branch,
+				qc.Colorize(jobState(Job{Status: run.Status, Conclusion: run.Conclusion}), colorWorkflowStatus(run.Status, run.Conclusion)))
+		}
+		switch status {
+		case checkPassing:
+			fmt.Printf("%s Everything passed\n", qc.Colorize("Success:", qc.ColorGreen))
+		case checkFailing:
+			fmt.Printf("%s Some workflows are failing\n", qc.Colorize("Error:", qc.ColorRed))
+		case checkRunning:
+			fmt.Printf("%s Some workflows are still running\n", qc.Colorize("Info:", qc.ColorCyan))
+		}
+	}
+	os.Exit(status)
+}
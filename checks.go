@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// getExternalChecks retrieves statuses reported on a commit by systems other
+// than the platform's own CI. Only GitHub reports them separately; other
+// platforms return none.
+func getExternalChecks(ctx context.Context, project Project, ref string) ([]CommitCheck, error) {
+	if project.Platform != "github" || ref == "" {
+		return nil, nil
+	}
+	client, err := NewGitHubClient(ctx, project.Name)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetExternalChecks(project.Owner, project.Repo, ref)
+}
+
+// displayExternalChecks lists external checks with the given indent
+func displayExternalChecks(checks []CommitCheck, indent string) {
+	longest := 0
+	for _, check := range checks {
+		if len(check.Name) > longest {
+			longest = len(check.Name)
+		}
+	}
+
+	for _, check := range checks {
+		result := check.Conclusion
+		if result == "" {
+			result = check.Status
+		}
+		color := colorWorkflowStatus(check.Status, check.Conclusion)
+		if check.Conclusion == "error" {
+			color = qc.ColorRed
+		}
+
+		line := fmt.Sprintf("%s%-*s [%s] %s", indent, longest, check.Name, qc.Colorize(result, color), check.Source)
+		if check.Description != "" {
+			line += " - " + strings.TrimSpace(check.Description)
+		}
+		fmt.Println(line)
+	}
+}
+
+// setChecks records the checks reported on the branch's head commit,
+// keeping the latest of checks reported more than once, and marks those
+// named in required. Required checks that weren't reported are missing.
+func (s *MergeStatus) setChecks(checks []CommitCheck, required []string) {
+	latest := make(map[string]int)
+	for _, check := range checks {
+		check.Required = slices.Contains(required, check.Name)
+		if i, ok := latest[check.Name]; ok {
+			if check.UpdatedAt.After(s.Checks[i].UpdatedAt) {
+				s.Checks[i] = check
+			}
+			continue
+		}
+		latest[check.Name] = len(s.Checks)
+		s.Checks = append(s.Checks, check)
+	}
+	for _, name := range required {
+		if _, ok := latest[name]; !ok {
+			s.Missing = append(s.Missing, name)
+		}
+	}
+}
+
+// checkPassed reports whether a finished check doesn't block merging
+func checkPassed(check CommitCheck) bool {
+	switch firstNonEmpty(check.Conclusion, check.Status) {
+	case "success", "neutral", "skipped":
+		return true
+	}
+	return false
+}
+
+// verdict sums up whether the required checks allow merging: blocked when
+// one failed, pending while one is unfinished or missing, else mergeable
+func (s *MergeStatus) verdict() (string, string) {
+	pending := len(s.Missing) > 0
+	for _, check := range s.Checks {
+		if !check.Required {
+			continue
+		}
+		switch {
+		case !isRunFinished(check.Status):
+			pending = true
+		case !checkPassed(check):
+			return "blocked", qc.ColorRed
+		}
+	}
+	if pending {
+		return "pending", qc.ColorYellow
+	}
+	return "mergeable", qc.ColorGreen
+}
+
+// getMergeStatus retrieves which checks a branch needs to pass to merge
+func getMergeStatus(ctx context.Context, project Project, branch string) (*MergeStatus, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetMergeStatus(project.Owner, project.Repo, branch)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetMergeStatus(project.Name, branch)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// displayMergeStatus lists a branch's required checks apart from its
+// optional ones, followed by the merge verdict
+func displayMergeStatus(platform string, status *MergeStatus) {
+	heading := fmt.Sprintf("Checks for merging into %s", status.Target)
+	if ref := pullRequestRef(WorkflowRun{Platform: platform, PullRequest: status.PullRequest}); ref != "" {
+		heading = fmt.Sprintf("Checks for %s into %s", ref, status.Target)
+	}
+	fmt.Printf("\n%s\n", qc.Colorize(heading+":", qc.ColorBlue))
+
+	var required, optional []CommitCheck
+	for _, check := range status.Checks {
+		if check.Required {
+			required = append(required, check)
+		} else {
+			optional = append(optional, check)
+		}
+	}
+	if len(required) == 0 && len(status.Missing) == 0 {
+		fmt.Printf("  %s\n", qc.Colorize("No required checks", qc.ColorCyan))
+	} else {
+		fmt.Printf("  %s\n", qc.ColorizeBold("Required", qc.ColorWhite))
+		displayExternalChecks(required, "    ")
+		for _, name := range status.Missing {
+			fmt.Printf("    %s [%s]\n", name, qc.Colorize("not reported", qc.ColorYellow))
+		}
+	}
+	if len(optional) > 0 {
+		fmt.Printf("  %s\n", qc.ColorizeBold("Optional", qc.ColorWhite))
+		displayExternalChecks(optional, "    ")
+	}
+
+	verdict, color := status.verdict()
+	fmt.Printf("\n%s %s\n", qc.Colorize("Merge:", qc.ColorBlue), qc.Colorize(verdict, color))
+}
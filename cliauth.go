@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cliTokenTimeout bounds how long `gh auth token` may take, as it can
+// reach into the system keyring
+const cliTokenTimeout = 5 * time.Second
+
+// cliTokens caches the tokens found in the gh and glab configuration by
+// tool and host, so each is only looked up once per invocation, even by
+// the concurrent requests of badge --serve
+var (
+	cliTokens   = make(map[string]string)
+	cliTokensMu sync.Mutex
+)
+
+// cachedCLIToken returns the cached token for key, looking it up on first
+// use. The lock is held during the lookup, so concurrent callers wait for
+// it rather than running the CLI again.
+func cachedCLIToken(key string, lookup func() string) string {
+	cliTokensMu.Lock()
+	defer cliTokensMu.Unlock()
+	if token, ok := cliTokens[key]; ok {
+		return token
+	}
+	token := lookup()
+	cliTokens[key] = token
+	return token
+}
+
+// ghConfigDir returns where the gh CLI keeps its configuration, following
+// its GH_CONFIG_DIR and XDG_CONFIG_HOME lookup
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gh")
+}
+
+// glabConfigDir returns where the glab CLI keeps its configuration,
+// following its GLAB_CONFIG_DIR and XDG_CONFIG_HOME lookup
+func glabConfigDir() string {
+	if dir := os.Getenv("GLAB_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "glab-cli")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "glab-cli")
+}
+
+// ghCLIToken returns the token the gh CLI is logged in to host with, empty
+// when it isn't. `gh auth token` is asked first, as recent versions keep
+// tokens in the system keyring; otherwise hosts.yml is read directly.
+func ghCLIToken(host string) string {
+	return cachedCLIToken("gh:"+host, func() string {
+		return lookupGHToken(host)
+	})
+}
+
+// lookupGHToken asks gh for its token for host, or reads it from hosts.yml
+func lookupGHToken(host string) string {
+	token := ""
+	if path, err := exec.LookPath("gh"); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), cliTokenTimeout)
+		out, err := exec.CommandContext(ctx, path, "auth", "token", "--hostname", host).Output()
+		cancel()
+		if err == nil {
+			token = strings.TrimSpace(string(out))
+		}
+	}
+	if token == "" {
+		var hosts map[string]struct {
+			OAuthToken string `yaml:"oauth_token"`
+		}
+		if data, err := os.ReadFile(filepath.Join(ghConfigDir(), "hosts.yml")); err == nil && yaml.Unmarshal(data, &hosts) == nil {
+			token = hosts[host].OAuthToken
+		}
+	}
+	return token
+}
+
+// glabCLIToken returns the token the glab CLI is configured with for host,
+// empty when it has none
+func glabCLIToken(host string) string {
+	return cachedCLIToken("glab:"+host, func() string {
+		return lookupGlabToken(host)
+	})
+}
+
+// lookupGlabToken reads glab's token for host from its config.yml
+func lookupGlabToken(host string) string {
+	var config struct {
+		Hosts map[string]struct {
+			Token string `yaml:"token"`
+		} `yaml:"hosts"`
+	}
+	token := ""
+	if data, err := os.ReadFile(filepath.Join(glabConfigDir(), "config.yml")); err == nil && yaml.Unmarshal(data, &config) == nil {
+		token = config.Hosts[host].Token
+	}
+	return token
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// clipboardTool is a command that copies its standard input to the system
+// clipboard
+type clipboardTool struct {
+	name string
+	args []string
+}
+
+// clipboardTools returns the clipboard commands to try on this system, in
+// order of preference
+func clipboardTools() []clipboardTool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardTool{{name: "pbcopy"}}
+	case "windows":
+		return []clipboardTool{{name: "clip.exe"}}
+	}
+	tools := []clipboardTool{
+		{name: "xclip", args: []string{"-selection", "clipboard"}},
+		{name: "xsel", args: []string{"--clipboard", "--input"}},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append([]clipboardTool{{name: "wl-copy"}}, tools...)
+	}
+	// WSL shares the Windows clipboard
+	return append(tools, clipboardTool{name: "clip.exe"})
+}
+
+// copyToClipboard copies text to the system clipboard with the first
+// clipboard command installed
+func copyToClipboard(text string) error {
+	var names []string
+	for _, tool := range clipboardTools() {
+		names = append(names, tool.name)
+		path, err := exec.LookPath(tool.name)
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, tool.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %v %s", tool.name, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard command found (install %s)", strings.Join(names, " or "))
+}
+
+// jobLogSnippet returns the end of a job's log: the lines of its failed
+// steps, as the details view shows them, or else the last n lines
+func jobLogSnippet(ctx context.Context, run WorkflowRun, job Job, n int) (string, error) {
+	if n <= 0 {
+		n = defaultLogLines
+	}
+	log, err := fetchJobLog(ctx, run, job)
+	if err != nil {
+		return "", err
+	}
+	lines := parseLogLines(log)
+
+	var snippet []logLine
+	for _, step := range job.Steps {
+		if isFailedConclusion(step.Conclusion) && step.Name != job.Name {
+			snippet = append(snippet, tailLines(stepLines(lines, step), n)...)
+		}
+	}
+	if len(snippet) == 0 {
+		snippet = tailLines(lines, n)
+	}
+
+	texts := make([]string, len(snippet))
+	for i, line := range snippet {
+		texts[i] = line.Text
+	}
+	return strings.Join(texts, "\n") + "\n", nil
+}
+
+// clipboardText returns what to copy of a run: "url", "sha", or "log", the
+// log snippet of the first failed job
+func clipboardText(ctx context.Context, run WorkflowRun, jobs []Job, what string, logLines int) (string, error) {
+	switch what {
+	case "url":
+		if run.URL == "" {
+			return "", fmt.Errorf("run %s has no URL", run.ID)
+		}
+		return run.URL, nil
+	case "sha":
+		if run.Commit == "" {
+			return "", fmt.Errorf("run %s has no commit", run.ID)
+		}
+		return run.Commit, nil
+	case "log":
+		for _, job := range jobs {
+			if isFailedConclusion(job.Conclusion) {
+				return jobLogSnippet(ctx, run, job, logLines)
+			}
+		}
+		return "", fmt.Errorf("run %s has no failed job", run.ID)
+	default:
+		return "", fmt.Errorf("unknown copy target: %s (expected url, sha, or log)", what)
+	}
+}
+
+// copyRunText copies part of a run to the clipboard, reporting the outcome
+func copyRunText(ctx context.Context, run WorkflowRun, jobs []Job, what string, logLines int) {
+	text, err := clipboardText(ctx, run, jobs, what, logLines)
+	if err == nil {
+		err = copyToClipboard(text)
+	}
+	if err != nil {
+		fmt.Printf("%s Failed to copy: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Copied the %s to the clipboard\n", qc.Colorize("Success:", qc.ColorGreen), copyTargetName(what))
+}
+
+// copyTargetName describes a copy target in messages
+func copyTargetName(what string) string {
+	switch what {
+	case "url":
+		return "run URL"
+	case "sha":
+		return "commit SHA"
+	default:
+		return "log snippet"
+	}
+}
+
+// promptCopy lets the user copy the run URL, commit SHA, or the log
+// snippet of a job, by the job's number, from the details view
+func promptCopy(ctx context.Context, reader *bufio.Reader, run WorkflowRun, jobs []Job, logLines int) {
+	prompt := "Copy (u run URL, s commit SHA"
+	if len(jobs) > 0 {
+		prompt += ", l<n> job log"
+	}
+	prompt += ", Enter to continue): "
+
+	for {
+		fmt.Printf("\n%s", qc.Colorize(prompt, qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		input = strings.TrimSpace(input)
+		switch {
+		case input == "" || input == "q":
+			return
+		case input == "u":
+			copyRunText(ctx, run, jobs, "url", logLines)
+		case input == "s":
+			copyRunText(ctx, run, jobs, "sha", logLines)
+		case strings.HasPrefix(input, "l"):
+			index, err := strconv.Atoi(strings.TrimSpace(input[1:]))
+			if err != nil || index < 1 || index > len(jobs) {
+				fmt.Printf("%s No job %s\n", qc.Colorize("Error:", qc.ColorRed), strings.TrimSpace(input[1:]))
+				continue
+			}
+			snippet, err := jobLogSnippet(ctx, run, jobs[index-1], logLines)
+			if err == nil {
+				err = copyToClipboard(snippet)
+			}
+			if err != nil {
+				fmt.Printf("%s Failed to copy: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+				continue
+			}
+			fmt.Printf("%s Copied the log of %s to the clipboard\n", qc.Colorize("Success:", qc.ColorGreen), jobs[index-1].Name)
+		default:
+			fmt.Printf("%s Enter u, s, or l followed by a job number, e.g. l3\n", qc.Colorize("Error:", qc.ColorRed))
+		}
+	}
+}
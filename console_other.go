@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// enableANSI reports whether colors can be shown. Unix terminals process
+// escape codes without being asked.
+func enableANSI() bool {
+	return true
+}
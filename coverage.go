@@ -0,0 +1,339 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// defaultCoveragePattern finds percentages following "coverage" or "total"
+// on the same line, as printed by go test -cover, coverage.py, and most
+// summary reporters
+const defaultCoveragePattern = `(?i)(?:coverage|total)[^\n%]*?(\d+(?:\.\d+)?)\s*%`
+
+// coverageFile returns the path of the coverage read from finished runs,
+// kept so charting coverage doesn't download the same reports again
+func coverageFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "coverage.json")
+}
+
+// coveragePattern compiles a project's coverage pattern, or the default
+func coveragePattern(project Project) (*regexp.Regexp, error) {
+	if project.CoveragePattern == "" {
+		return regexp.MustCompile(defaultCoveragePattern), nil
+	}
+	pattern, err := regexp.Compile(project.CoveragePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coverage pattern: %v", err)
+	}
+	if pattern.NumSubexp() < 1 {
+		return nil, fmt.Errorf("coverage pattern needs a group capturing the percentage")
+	}
+	return pattern, nil
+}
+
+// parseCoverage returns the percentage captured by the last match of
+// pattern in text, as the total usually comes after per-package lines
+func parseCoverage(text string, pattern *regexp.Regexp) (float64, bool) {
+	matches := pattern.FindAllStringSubmatch(text, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		if coverage, err := strconv.ParseFloat(matches[i][1], 64); err == nil {
+			return coverage, true
+		}
+	}
+	return 0, false
+}
+
+// parseCoverageArchive reads coverage from a zip archive: the line rate of
+// a Cobertura XML report when there is one, otherwise the last match of
+// pattern in any of its files
+func parseCoverageArchive(data []byte, pattern *regexp.Regexp) (float64, bool, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, false, err
+	}
+
+	var found float64
+	var ok bool
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return 0, false, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return 0, false, err
+		}
+
+		if strings.EqualFold(filepath.Ext(file.Name), ".xml") {
+			var report struct {
+				XMLName  xml.Name
+				LineRate string `xml:"line-rate,attr"`
+			}
+			if xml.Unmarshal(content, &report) == nil && report.XMLName.Local == "coverage" && report.LineRate != "" {
+				if rate, err := strconv.ParseFloat(report.LineRate, 64); err == nil {
+					return rate * 100, true, nil
+				}
+			}
+		}
+		if coverage, matched := parseCoverage(string(content), pattern); matched {
+			found, ok = coverage, true
+		}
+	}
+	return found, ok, nil
+}
+
+// getRunCoverage retrieves the coverage a run reported, and false when it
+// reported none. GitLab parses coverage itself; GitHub runs are read from
+// the project's coverage artifact, or else its job logs when a coverage
+// pattern is set.
+func getRunCoverage(ctx context.Context, project Project, run WorkflowRun) (float64, bool, error) {
+	switch project.Platform {
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return 0, false, err
+		}
+		return client.GetPipelineCoverage(project.Name, run.ID)
+	case "github":
+		if project.CoverageArtifact == "" && project.CoveragePattern == "" {
+			return 0, false, nil
+		}
+		pattern, err := coveragePattern(project)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if project.CoverageArtifact != "" {
+			client, err := NewGitHubClient(ctx, project.Name)
+			if err != nil {
+				return 0, false, err
+			}
+			data, err := client.GetArtifactArchive(project.Owner, project.Repo, run.ID, project.CoverageArtifact)
+			if err != nil || data == nil {
+				return 0, false, err
+			}
+			return parseCoverageArchive(data, pattern)
+		}
+
+		jobs, err := getJobsForRun(ctx, run)
+		if err != nil {
+			return 0, false, err
+		}
+		var found float64
+		var ok bool
+		for _, job := range jobs {
+			if job.Conclusion != "success" {
+				continue
+			}
+			log, err := fetchJobLog(ctx, run, job)
+			if err != nil {
+				return 0, false, err
+			}
+			if coverage, matched := parseCoverage(log, pattern); matched {
+				found, ok = coverage, true
+			}
+		}
+		return found, ok, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// runCoverages retrieves the coverage of runs, keyed by run ID, leaving out
+// runs that reported none. Coverage of finished runs is kept in the
+// coverage file, so only runs not read before are fetched.
+func runCoverages(ctx context.Context, config *Config, project Project, runs []WorkflowRun) (map[string]float64, error) {
+	path := coverageFile(config)
+	lock, err := acquireLock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	// Runs that reported no coverage are kept as null so they aren't
+	// fetched again either
+	cache := make(map[string]*float64)
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &cache); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	coverages := make(map[string]float64)
+	changed := false
+	var fetchErr error
+	for _, run := range runs {
+		key := run.Project + "#" + run.ID
+		if cached, ok := cache[key]; ok {
+			if cached != nil {
+				coverages[run.ID] = *cached
+			}
+			continue
+		}
+
+		coverage, ok, err := getRunCoverage(ctx, project, run)
+		if err != nil {
+			fetchErr = err
+			break
+		}
+		if ok {
+			coverages[run.ID] = coverage
+		}
+		if isRunFinished(run.Status) {
+			if ok {
+				cache[key] = &coverage
+			} else {
+				cache[key] = nil
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		data, err := json.MarshalIndent(cache, "", "  ")
+		if err != nil {
+			return coverages, err
+		}
+		if err := writeFileAtomic(path, data, 0644); err != nil {
+			return coverages, err
+		}
+	}
+	return coverages, fetchErr
+}
+
+// showCoverageTrend charts the coverage of a project's newest successful
+// runs, up to limit, and reports whether the latest dropped from the one
+// before
+func showCoverageTrend(ctx context.Context, config *Config, project Project, runs []WorkflowRun, limit int) bool {
+	var successful []WorkflowRun
+	for _, run := range runs {
+		if isRunFinished(run.Status) && run.Conclusion == "success" {
+			successful = append(successful, run)
+		}
+	}
+	sort.Slice(successful, func(i, j int) bool {
+		return successful[i].CreatedAt.After(successful[j].CreatedAt)
+	})
+	if len(successful) > limit {
+		successful = successful[:limit]
+	}
+
+	coverages, err := runCoverages(ctx, config, project, successful)
+	if err != nil {
+		fmt.Printf("  %s Failed to get coverage: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	var values []float64
+	for i := len(successful) - 1; i >= 0; i-- {
+		if coverage, ok := coverages[successful[i].ID]; ok {
+			values = append(values, coverage)
+		}
+	}
+	if len(values) == 0 {
+		if project.Platform == "github" && project.CoverageArtifact == "" && project.CoveragePattern == "" {
+			fmt.Printf("  %s No coverage source set. Use 'quick_workflow coverage %s --artifact <name>' or '--pattern <regex>'.\n", qc.Colorize("Info:", qc.ColorCyan), project.Name)
+		} else {
+			fmt.Printf("  %s No coverage reported\n", qc.Colorize("Info:", qc.ColorCyan))
+		}
+		return false
+	}
+
+	latest := values[len(values)-1]
+	summary := fmt.Sprintf("latest %.1f%%", latest)
+	dropped := false
+	verdict := ""
+	if len(values) > 1 {
+		previous := values[len(values)-2]
+		summary += fmt.Sprintf(", previous %.1f%%, %+.1f", previous, latest-previous)
+		if latest < previous {
+			dropped = true
+			verdict = qc.Colorize(" dropped", qc.ColorRed)
+		}
+	}
+	fmt.Printf("  %s %s %s%s\n", qc.Colorize("coverage", qc.ColorBlue), sparkline(values), summary, verdict)
+	return dropped
+}
+
+// handleCoverage shows or sets where a GitHub project's runs report
+// coverage. GitLab reports coverage itself from the coverage keyword.
+func handleCoverage(config *Config, args []string) {
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	artifact := fs.String("artifact", "", "Artifact holding a Cobertura XML report or a coverage summary (empty to clear)")
+	pattern := fs.String("pattern", "", "Regex capturing the percentage from the artifact or job logs (empty for the default)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow coverage <project> [--artifact name] [--pattern regex]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	name := fs.Arg(0)
+	project := findProject(config, name)
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+		return
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if len(set) == 0 {
+		switch {
+		case project.Platform == "gitlab":
+			fmt.Printf("%s: coverage parsed by GitLab with the coverage keyword\n", project.Name)
+		case project.CoverageArtifact != "":
+			fmt.Printf("%s: artifact %s, pattern %s\n", project.Name, project.CoverageArtifact, firstNonEmpty(project.CoveragePattern, "(default)"))
+		case project.CoveragePattern != "":
+			fmt.Printf("%s: job logs, pattern %s\n", project.Name, project.CoveragePattern)
+		default:
+			fmt.Printf("%s: no coverage source set\n", project.Name)
+		}
+		return
+	}
+	if project.Platform != "github" {
+		fmt.Printf("%s Coverage sources are only set for GitHub projects; GitLab reports coverage from the coverage keyword\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	if set["pattern"] && *pattern != "" {
+		if _, err := coveragePattern(Project{CoveragePattern: *pattern}); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+	}
+
+	err := updateProjects(config, func(config *Config) error {
+		project := findProject(config, name)
+		if project == nil {
+			return fmt.Errorf("project not found: %s", name)
+		}
+		if set["artifact"] {
+			project.CoverageArtifact = *artifact
+		}
+		if set["pattern"] {
+			project.CoveragePattern = *pattern
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s Failed to save projects: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Updated coverage source of %s\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(name, qc.ColorGreen))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/term"
+)
+
+// deadProjectFailures is how many fetches in a row must find a project
+// missing or forbidden before it is suggested for archiving
+const deadProjectFailures = 3
+
+// activeProjects returns the projects that aren't archived
+func activeProjects(projects []Project) []Project {
+	var active []Project
+	for _, project := range projects {
+		if !project.Archived {
+			active = append(active, project)
+		}
+	}
+	return active
+}
+
+// deadProjectReason explains why a project looks dead from its cached
+// runs: its repository keeps returning not found or forbidden, or it has
+// had no runs for idle. It returns "" for projects that look alive or
+// haven't been fetched yet.
+func deadProjectReason(project Project, cached cachedRuns, idle time.Duration, now time.Time) string {
+	if cached.Failures >= deadProjectFailures && cached.FailingSince != nil {
+		switch cached.FailureKind {
+		case ErrNotFound:
+			return fmt.Sprintf("not found by the last %d fetches, the first %s", cached.Failures, formatAgo(now.Sub(*cached.FailingSince)))
+		case ErrForbidden:
+			return fmt.Sprintf("access denied to the last %d fetches, the first %s", cached.Failures, formatAgo(now.Sub(*cached.FailingSince)))
+		}
+	}
+	if cached.FetchedAt.IsZero() || idle <= 0 {
+		return ""
+	}
+
+	var latest time.Time
+	for _, run := range cached.Runs {
+		if run.CreatedAt.After(latest) {
+			latest = run.CreatedAt
+		}
+	}
+	if latest.IsZero() {
+		// Never ran; give projects added recently time to get workflows
+		added, err := time.Parse(time.RFC3339, project.AddedAt)
+		if err == nil && now.Sub(added) > idle {
+			return fmt.Sprintf("no runs since it was added %s", formatAgo(now.Sub(added)))
+		}
+		return ""
+	}
+	if now.Sub(latest) > idle {
+		return fmt.Sprintf("last run %s", formatAgo(now.Sub(latest)))
+	}
+	return ""
+}
+
+// deadProjects returns the reasons the projects that look dead do, by
+// project name, skipping archived ones
+func deadProjects(config *Config, idle time.Duration) map[string]string {
+	cache := readRunCache(config)
+	reasons := make(map[string]string)
+	for _, project := range config.Projects {
+		if project.Archived {
+			continue
+		}
+		if reason := deadProjectReason(project, cache[project.Name], idle, time.Now()); reason != "" {
+			reasons[project.Name] = reason
+		}
+	}
+	return reasons
+}
+
+// promptDeadProjects asks what to do with each project that looks dead:
+// archive it, remove it, or keep it. Off a terminal it only says how.
+func promptDeadProjects(config *Config, reasons map[string]string) {
+	if len(reasons) == 0 {
+		return
+	}
+	fmt.Printf("\n%s %d project(s) look unused. Archiving hides a project from watch and list but keeps it tracked.\n", qc.Colorize("Info:", qc.ColorCyan), len(reasons))
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Println("Use 'quick_workflow archive <project>' or 'quick_workflow remove <project>' to clean them up.")
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, name := range slices.Sorted(maps.Keys(reasons)) {
+		fmt.Printf("%s", qc.Colorize(fmt.Sprintf("%s (%s): a archive, r remove, Enter to keep: ", name, reasons[name]), qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch strings.TrimSpace(input) {
+		case "a":
+			setArchived(config, name, true)
+		case "r":
+			removeProject(config, name)
+		}
+	}
+}
+
+// setArchived archives a project, hiding it from watch and list, or brings
+// it back
+func setArchived(config *Config, name string, archived bool) {
+	found := false
+	err := updateProjects(config, func(config *Config) error {
+		for i := range config.Projects {
+			if config.Projects[i].Name == name {
+				config.Projects[i].Archived = archived
+				found = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s Failed to save projects: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if !found {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+		return
+	}
+	if archived {
+		fmt.Printf("%s Archived project: %s\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(name, qc.ColorGreen))
+	} else {
+		fmt.Printf("%s Unarchived project: %s\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(name, qc.ColorGreen))
+	}
+}
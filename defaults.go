@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// refIsWorkflow reports whether a platform's workflows are the branches
+// they run on, as start lists branches to trigger for GitLab, Drone, and
+// Woodpecker
+func refIsWorkflow(platform string) bool {
+	return platform == "gitlab" || platform == "drone" || platform == "woodpecker"
+}
+
+// handleDefaults shows or sets the default workflow and branch of a
+// project
+func handleDefaults(config *Config, args []string) {
+	fs := flag.NewFlagSet("defaults", flag.ExitOnError)
+	workflow := fs.String("workflow", "", "Workflow start triggers without asking (empty to clear)")
+	branch := fs.String("branch", "", "Branch start runs on and status reports (empty to clear; default: the repository's default branch)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow defaults <project> [--workflow name] [--branch ref]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	name := fs.Arg(0)
+	// Flags may also follow the project name
+	fs.Parse(fs.Args()[1:])
+	project := findProject(config, name)
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+		return
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if len(set) == 0 {
+		fmt.Printf("%s: workflow %s, branch %s\n", project.Name,
+			firstNonEmpty(project.DefaultWorkflow, "(ask)"), firstNonEmpty(project.DefaultBranch, "(repository default)"))
+		return
+	}
+	if set["workflow"] && *workflow != "" && refIsWorkflow(project.Platform) {
+		fmt.Printf("%s %s runs are started per branch; set --branch instead\n", qc.Colorize("Error:", qc.ColorRed), platformName(project.Platform))
+		return
+	}
+
+	err := updateProjects(config, func(config *Config) error {
+		project := findProject(config, name)
+		if project == nil {
+			return fmt.Errorf("project not found: %s", name)
+		}
+		if set["workflow"] {
+			project.DefaultWorkflow = *workflow
+		}
+		if set["branch"] {
+			project.DefaultBranch = *branch
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s Failed to save projects: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Updated defaults of %s\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(name, qc.ColorGreen))
+}
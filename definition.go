@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+var (
+	// yamlKeyLine splits a YAML line into indentation, an optional list
+	// dash, a key, and the rest after the colon
+	yamlKeyLine = regexp.MustCompile(`^(\s*)(- )?([^\s#'"{\[][^:#]*?|"[^"]*"|'[^']*'):(\s.*|)$`)
+	// yamlListLine splits a YAML list item into indentation and its value
+	yamlListLine = regexp.MustCompile(`^(\s*)- (.*)$`)
+	// yamlLiteral matches booleans, nulls, and numbers
+	yamlLiteral = regexp.MustCompile(`^(true|false|null|~|-?[0-9][0-9_.]*)$`)
+	// workflowExpression matches ${{ }} expressions and GitLab $VARIABLES
+	workflowExpression = regexp.MustCompile(`\$\{\{.*?\}\}|\$\{?[A-Za-z_][A-Za-z0-9_]*\}?`)
+)
+
+// getRunDefinition retrieves the CI file a run executed
+func getRunDefinition(ctx context.Context, run WorkflowRun) (*RunDefinition, error) {
+	switch run.Platform {
+	case "github":
+		owner, repo, ok := strings.Cut(run.Project, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+		}
+		client, err := NewGitHubClient(ctx, run.Project)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetRunDefinition(owner, repo, run.ID)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, run.Project)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPipelineDefinition(run.Project, run.ID)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", run.Platform)
+	}
+}
+
+// splitYAMLComment separates a trailing comment from a YAML value, leaving
+// # inside quotes alone
+func splitYAMLComment(value string) (string, string) {
+	var quote rune
+	for i, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || value[i-1] == ' '):
+			return value[:i], value[i:]
+		}
+	}
+	return value, ""
+}
+
+// highlightYAMLValue colors a scalar value: literals, quoted strings, block
+// indicators, and expressions each stand out
+func highlightYAMLValue(value string) string {
+	value, comment := splitYAMLComment(value)
+	trimmed := strings.TrimSpace(value)
+	var colored string
+	switch {
+	case trimmed == "":
+		colored = value
+	case strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, ">"):
+		colored = qc.Colorize(value, qc.ColorPurple)
+	case yamlLiteral.MatchString(trimmed):
+		colored = qc.Colorize(value, qc.ColorYellow)
+	default:
+		colored = workflowExpression.ReplaceAllStringFunc(value, func(expression string) string {
+			return qc.Colorize(expression, qc.ColorCyan) + qc.ColorGreen
+		})
+		colored = qc.Colorize(colored, qc.ColorGreen)
+	}
+	if comment != "" {
+		colored += qc.Dim(comment)
+	}
+	return colored
+}
+
+// highlightYAML colors a YAML document line by line: keys, values, list
+// items, comments, and the contents of block scalars such as scripts
+func highlightYAML(content string) []string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	highlighted := make([]string, len(lines))
+	// blockIndent is the indentation of the key that opened a block
+	// scalar, or -1 outside of one
+	blockIndent := -1
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		trimmed := strings.TrimSpace(line)
+		if blockIndent >= 0 {
+			if trimmed == "" || indent > blockIndent {
+				highlighted[i] = workflowExpression.ReplaceAllStringFunc(line, func(expression string) string {
+					return qc.Colorize(expression, qc.ColorCyan)
+				})
+				continue
+			}
+			blockIndent = -1
+		}
+
+		switch {
+		case trimmed == "":
+			highlighted[i] = line
+		case strings.HasPrefix(trimmed, "#"):
+			highlighted[i] = qc.Dim(line)
+		case yamlKeyLine.MatchString(line):
+			match := yamlKeyLine.FindStringSubmatch(line)
+			dash := ""
+			if match[2] != "" {
+				dash = qc.Colorize(match[2], qc.ColorPurple)
+			}
+			highlighted[i] = match[1] + dash + qc.Colorize(match[3], qc.ColorBlue) + ":" + highlightYAMLValue(match[4])
+			if value, _ := splitYAMLComment(match[4]); strings.HasPrefix(strings.TrimSpace(value), "|") || strings.HasPrefix(strings.TrimSpace(value), ">") {
+				blockIndent = len(match[1]) + len(match[2])
+			}
+		case yamlListLine.MatchString(line):
+			match := yamlListLine.FindStringSubmatch(line)
+			highlighted[i] = match[1] + qc.Colorize("- ", qc.ColorPurple) + highlightYAMLValue(match[2])
+		default:
+			highlighted[i] = highlightYAMLValue(line)
+		}
+	}
+	return highlighted
+}
+
+// handleWorkflow handles the workflow command
+func handleWorkflow(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Printf("%s Usage: quick_workflow workflow show [--project <name>] [--plain] <run-id>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	showRunDefinition(ctx, config, args[1:])
+}
+
+// showRunDefinition prints the CI file a run executed, read at the run's
+// commit rather than the current default branch
+func showRunDefinition(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("workflow show", flag.ExitOnError)
+	projectName := fs.String("project", "", "Project the run belongs to (default: found from local history)")
+	plain := fs.Bool("plain", false, "Print the file as is, without colors or line numbers")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow workflow show [--project <name>] [--plain] <run-id>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project, runID, err := resolveRun(config, *projectName, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	run, err := getWorkflowRun(ctx, *project, runID)
+	if err != nil {
+		fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	definition, err := getRunDefinition(ctx, *run)
+	if err != nil {
+		fmt.Printf("%s Failed to get the workflow file: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	if *plain {
+		fmt.Print(definition.File.Content)
+		return
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("%s at %s (run %s, %s):", definition.File.Path, shortSHA(definition.Commit), run.ID, run.Workflow), qc.ColorBlue))
+	for _, reusable := range definition.Reusable {
+		fmt.Printf("  %s %s\n", qc.Colorize("Calls:", qc.ColorCyan), reusable)
+	}
+	fmt.Println()
+
+	lines := highlightYAML(definition.File.Content)
+	width := len(fmt.Sprint(len(lines)))
+	for i, line := range lines {
+		fmt.Printf("%s  %s%s\n", qc.Dim(fmt.Sprintf("%*d", width, i+1)), line, qc.ColorReset)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// maxDispatchPayloadKeys is how many top-level properties GitHub accepts
+// in a repository_dispatch client payload
+const maxDispatchPayloadKeys = 10
+
+// buildDispatchPayload merges the --payload JSON object with --field
+// values, which take precedence
+func buildDispatchPayload(payloadJSON string, fields map[string]string) (map[string]any, error) {
+	payload := make(map[string]any)
+	if payloadJSON != "" {
+		var parsed any
+		if err := json.Unmarshal([]byte(payloadJSON), &parsed); err != nil {
+			return nil, fmt.Errorf("invalid --payload: expected a JSON object: %v", err)
+		}
+		// null, arrays, and scalars are valid JSON but not a payload
+		object, ok := parsed.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid --payload: expected a JSON object, got %s", payloadJSON)
+		}
+		payload = object
+	}
+	for key, value := range fields {
+		payload[key] = value
+	}
+	if len(payload) > maxDispatchPayloadKeys {
+		return nil, fmt.Errorf("the payload has %d top-level properties; GitHub allows at most %d, so nest the rest in an object", len(payload), maxDispatchPayloadKeys)
+	}
+	return payload, nil
+}
+
+// planDispatch returns the call dispatchEvent would make
+func planDispatch(project Project, eventType string, payload map[string]any) apiCall {
+	body := map[string]any{"event_type": eventType, "client_payload": payload}
+	return apiCall{Method: "POST", Path: fmt.Sprintf("/repos/%s/%s/dispatches", project.Owner, project.Repo), Body: body}
+}
+
+// dispatchEvent sends a repository_dispatch event to a GitHub repository
+func dispatchEvent(ctx context.Context, project Project, eventType string, payload map[string]any) error {
+	client, err := NewGitHubClient(ctx, project.Name)
+	if err != nil {
+		return err
+	}
+	return client.DispatchEvent(project.Owner, project.Repo, eventType, payload)
+}
+
+// handleDispatch sends a repository_dispatch event with a custom event
+// type and client payload, for workflows triggered by events rather than
+// workflow_dispatch
+func handleDispatch(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("dispatch", flag.ExitOnError)
+	fields := variableFlags{}
+	fs.Var(fields, "field", "Client payload string property as KEY=VALUE; repeatable")
+	payloadJSON := fs.String("payload", "", "Client payload as a JSON object, e.g. '{\"env\":\"prod\",\"replicas\":3}'")
+	dryRun := fs.Bool("dry-run", false, "Print the API call that would send the event without making it")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Printf("%s Usage: quick_workflow dispatch [--payload <json>] [--field KEY=VALUE]... [--dry-run] <project> <event-type>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project := findProject(config, fs.Arg(0))
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+		return
+	}
+	if project.Platform != "github" {
+		fmt.Printf("%s repository_dispatch events are only supported on GitHub; use 'quick_workflow start' for %s\n", qc.Colorize("Error:", qc.ColorRed), project.Name)
+		return
+	}
+	eventType := fs.Arg(1)
+	if len(eventType) > 100 {
+		fmt.Printf("%s The event type is longer than GitHub's limit of 100 characters\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	payload, err := buildDispatchPayload(*payloadJSON, fields)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	if *dryRun {
+		printAPICalls([]apiCall{planDispatch(*project, eventType, payload)})
+		fmt.Printf("\n%s Dry run; nothing was sent\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	err = dispatchEvent(ctx, *project, eventType, payload)
+	parameters := map[string]string{"event_type": eventType}
+	if data, marshalErr := json.Marshal(payload); marshalErr == nil && len(payload) > 0 {
+		parameters["client_payload"] = string(data)
+	}
+	recordAction(config, "dispatch", project.Name, parameters, "sent repository_dispatch "+eventType, err)
+	if err != nil {
+		fmt.Printf("%s Failed to send the event: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Sent repository_dispatch event '%s' to %s\n", qc.Colorize("Success:", qc.ColorGreen), eventType, project.Name)
+	fmt.Printf("%s Workflows listening for it start shortly; 'quick_workflow watch' shows them\n", qc.Colorize("Info:", qc.ColorCyan))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// getDownstreamPipelines retrieves the pipelines a run triggered. Only
+// GitLab has bridge jobs; other platforms return none.
+func getDownstreamPipelines(ctx context.Context, run WorkflowRun) ([]DownstreamPipeline, error) {
+	if run.Platform != "gitlab" {
+		return nil, nil
+	}
+	client, err := NewGitLabClient(ctx, run.Project)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetDownstreamPipelines(run.Project, run.ID)
+}
+
+// displayDownstreamPipelines lists downstream pipelines numbered d1, d2, ...
+// for drill-down, each with its jobs nested underneath
+func displayDownstreamPipelines(ctx context.Context, pipelines []DownstreamPipeline) {
+	if len(pipelines) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", qc.Colorize("Downstream pipelines:", qc.ColorBlue))
+	for i, pipeline := range pipelines {
+		run := pipeline.Run
+		kind, target := "child", "pipeline "+run.ID
+		if pipeline.MultiProject {
+			kind, target = "multi-project", run.Project+" pipeline "+run.ID
+		}
+		entry := fmt.Sprintf("  d%-2d %s → %s", i+1, pipeline.Bridge, target)
+		fmt.Printf("%s [%s] %s\n",
+			qc.Colorize(entry, qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)),
+			qc.Colorize(run.Status, colorWorkflowStatus(run.Status, run.Conclusion)),
+			qc.Colorize("("+kind+")", qc.ColorCyan))
+
+		jobs, err := getJobsForRun(ctx, run)
+		if err != nil {
+			fmt.Printf("        %s Failed to get jobs: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+			continue
+		}
+		for _, job := range jobs {
+			fmt.Printf("        %s %-40s %s\n",
+				qc.Colorize(stepMarker(Step{Status: job.Status, Conclusion: job.Conclusion}), colorJobStatus(job.Status, job.Conclusion)),
+				job.Name,
+				elapsedBetween(job.StartedAt, job.CompletedAt))
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DroneClient talks to a Drone or Woodpecker server. Woodpecker forked
+// Drone and kept its API shape, but calls builds pipelines and stages
+// workflows, and addresses repos by numeric ID.
+type DroneClient struct {
+	platform string
+	server   string
+	token    string
+	http     *http.Client
+	ctx      context.Context
+	// repoIDs caches Woodpecker repo IDs by owner/repo
+	repoIDs map[string]int64
+}
+
+// droneBuild is a Drone build or Woodpecker pipeline. Fields the two name
+// differently are both listed; whichever the server sent is used.
+type droneBuild struct {
+	Number int64  `json:"number"`
+	Status string `json:"status"`
+	Event  string `json:"event"`
+	// Message is the commit message on both
+	Message string `json:"message"`
+	// Drone
+	Target      string       `json:"target"`
+	After       string       `json:"after"`
+	AuthorLogin string       `json:"author_login"`
+	Created     int64        `json:"created"`
+	Started     int64        `json:"started"`
+	Finished    int64        `json:"finished"`
+	Updated     int64        `json:"updated"`
+	Stages      []droneStage `json:"stages"`
+	// Woodpecker
+	Branch     string       `json:"branch"`
+	Commit     string       `json:"commit"`
+	Author     string       `json:"author"`
+	CreatedAt  int64        `json:"created_at"`
+	StartedAt  int64        `json:"started_at"`
+	FinishedAt int64        `json:"finished_at"`
+	UpdatedAt  int64        `json:"updated_at"`
+	Workflows  []droneStage `json:"workflows"`
+}
+
+// droneStage is a Drone stage or Woodpecker workflow
+type droneStage struct {
+	Number int64  `json:"number"`
+	Name   string `json:"name"`
+	// Drone
+	Status  string      `json:"status"`
+	Started int64       `json:"started"`
+	Stopped int64       `json:"stopped"`
+	Steps   []droneStep `json:"steps"`
+	// Woodpecker
+	State     string      `json:"state"`
+	StartTime int64       `json:"start_time"`
+	EndTime   int64       `json:"end_time"`
+	Children  []droneStep `json:"children"`
+}
+
+// droneStep is a step of a stage or workflow
+type droneStep struct {
+	ID     int64  `json:"id"`
+	Number int64  `json:"number"`
+	Name   string `json:"name"`
+	// Drone
+	Status  string `json:"status"`
+	Started int64  `json:"started"`
+	Stopped int64  `json:"stopped"`
+	// Woodpecker
+	State     string `json:"state"`
+	StartTime int64  `json:"start_time"`
+	EndTime   int64  `json:"end_time"`
+}
+
+// droneLogLine is a log line; Drone sends text in out, Woodpecker base64
+// in data
+type droneLogLine struct {
+	Out  string `json:"out"`
+	Data []byte `json:"data"`
+}
+
+// NewDroneClient creates a client for the drone or woodpecker platform
+// whose requests are cancelled with ctx
+func NewDroneClient(ctx context.Context, platform string) (*DroneClient, error) {
+	token, server, err := resolveDroneCredentials(platform)
+	if err != nil {
+		return nil, err
+	}
+	return &DroneClient{
+		platform: platform,
+		server:   server,
+		token:    token,
+		http:     newHTTPClient(),
+		ctx:      ctx,
+		repoIDs:  make(map[string]int64),
+	}, nil
+}
+
+// do sends an API request, encoding body as JSON when set, and decodes the
+// response into out when set
+func (d *DroneClient) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, method, d.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+d.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError(d.platform, resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// repoPath returns the API path of a repo: /api/repos/owner/repo on Drone
+// and /api/repos/<id> on Woodpecker
+func (d *DroneClient) repoPath(owner, repo string) (string, error) {
+	if d.platform != "woodpecker" {
+		return fmt.Sprintf("/api/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo)), nil
+	}
+
+	name := owner + "/" + repo
+	if id, ok := d.repoIDs[name]; ok {
+		return fmt.Sprintf("/api/repos/%d", id), nil
+	}
+	var found struct {
+		ID int64 `json:"id"`
+	}
+	if err := d.do("GET", fmt.Sprintf("/api/repos/lookup/%s/%s", url.PathEscape(owner), url.PathEscape(repo)), nil, &found); err != nil {
+		return "", err
+	}
+	d.repoIDs[name] = found.ID
+	return fmt.Sprintf("/api/repos/%d", found.ID), nil
+}
+
+// buildsPath returns the API path of a repo's builds or pipelines
+func (d *DroneClient) buildsPath(owner, repo string) (string, error) {
+	path, err := d.repoPath(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	if d.platform == "woodpecker" {
+		return path + "/pipelines", nil
+	}
+	return path + "/builds", nil
+}
+
+// firstNonZero returns the first value that is set
+func firstNonZero(values ...int64) int64 {
+	for _, value := range values {
+		if value != 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+// firstNonEmpty returns the first string that is set
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// unixTime converts Unix seconds to a time, keeping 0 as the zero time
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// unixTimePtr converts Unix seconds to a time pointer, nil for 0
+func unixTimePtr(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0)
+	return &t
+}
+
+// droneStatus maps Drone and Woodpecker statuses to the GitLab-style
+// statuses the rest of the tool understands
+func droneStatus(status string) string {
+	switch status {
+	case "success":
+		return "success"
+	case "failure", "error":
+		return "failed"
+	case "killed":
+		return "canceled"
+	case "skipped", "declined":
+		return "skipped"
+	case "running":
+		return "running"
+	default:
+		// pending, blocked, and waiting_on_dependencies
+		return "pending"
+	}
+}
+
+// convertBuild converts a build or pipeline to the unified format. Like
+// GitLab pipelines, runs are named after their branch.
+func (d *DroneClient) convertBuild(owner, repo string, build droneBuild) WorkflowRun {
+	status := droneStatus(build.Status)
+	branch := firstNonEmpty(build.Branch, build.Target)
+	run := WorkflowRun{
+		ID:            strconv.FormatInt(build.Number, 10),
+		Project:       owner + "/" + repo,
+		Workflow:      branch,
+		Status:        status,
+		Conclusion:    status,
+		CreatedAt:     unixTime(firstNonZero(build.CreatedAt, build.Created)),
+		StartedAt:     unixTime(firstNonZero(build.StartedAt, build.Started)),
+		UpdatedAt:     unixTime(firstNonZero(build.FinishedAt, build.Finished, build.UpdatedAt, build.Updated)),
+		Platform:      d.platform,
+		Branch:        branch,
+		Commit:        firstNonEmpty(build.Commit, build.After),
+		CommitMessage: build.Message,
+		TriggeredBy:   firstNonEmpty(build.Author, build.AuthorLogin),
+	}
+	if run.UpdatedAt.IsZero() {
+		run.UpdatedAt = run.CreatedAt
+	}
+	if d.platform == "woodpecker" {
+		if id, ok := d.repoIDs[run.Project]; ok {
+			run.URL = fmt.Sprintf("%s/repos/%d/pipeline/%d", d.server, id, build.Number)
+		}
+	} else {
+		run.URL = fmt.Sprintf("%s/%s/%s/%d", d.server, owner, repo, build.Number)
+	}
+	return run
+}
+
+// GetBuilds retrieves recent builds of a repo, optionally on one branch
+func (d *DroneClient) GetBuilds(owner, repo, branch string, limit int) ([]WorkflowRun, error) {
+	path, err := d.buildsPath(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	// Branch filters differ between versions, so filter here and fetch
+	// more to make up for it
+	perPage := limit
+	if branch != "" {
+		perPage = max(limit, 50)
+	}
+	perPage = min(perPage, maxPageSize)
+
+	var runs []WorkflowRun
+	scanned := 0
+	for page := 1; len(runs) < limit; page++ {
+		var builds []droneBuild
+		if err := d.do("GET", fmt.Sprintf("%s?page=%d&per_page=%d&perPage=%d", path, page, perPage, perPage), nil, &builds); err != nil {
+			return nil, err
+		}
+		for _, build := range builds {
+			run := d.convertBuild(owner, repo, build)
+			if branch != "" && run.Branch != branch {
+				continue
+			}
+			runs = append(runs, run)
+			if len(runs) == limit {
+				break
+			}
+		}
+		// Servers cap the page size below what was asked for, so only an
+		// empty page marks the end. Branch filtering could otherwise page
+		// through a long history for nothing.
+		scanned += len(builds)
+		if len(builds) == 0 || scanned >= maxListRuns {
+			break
+		}
+	}
+	return runs, nil
+}
+
+// getBuild retrieves a build or pipeline with its stages
+func (d *DroneClient) getBuild(owner, repo, number string) (*droneBuild, error) {
+	path, err := d.buildsPath(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var build droneBuild
+	if err := d.do("GET", path+"/"+url.PathEscape(number), nil, &build); err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+// GetBuild retrieves a single build or pipeline
+func (d *DroneClient) GetBuild(owner, repo, number string) (*WorkflowRun, error) {
+	build, err := d.getBuild(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	run := d.convertBuild(owner, repo, *build)
+	return &run, nil
+}
+
+// GetBuildJobs retrieves the steps of a build as jobs. Logs are kept per
+// step, so each step is a job named "stage / step", and the job ID locates
+// its log: "stage/step" numbers on Drone, the step ID on Woodpecker.
+func (d *DroneClient) GetBuildJobs(owner, repo, number string) ([]Job, error) {
+	build, err := d.getBuild(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	stages := build.Stages
+	if d.platform == "woodpecker" {
+		stages = build.Workflows
+	}
+
+	var jobs []Job
+	for _, stage := range stages {
+		steps := stage.Steps
+		if d.platform == "woodpecker" {
+			steps = stage.Children
+		}
+		for _, step := range steps {
+			status := droneStatus(firstNonEmpty(step.State, step.Status))
+			id := fmt.Sprintf("%d/%d", stage.Number, step.Number)
+			if d.platform == "woodpecker" {
+				id = strconv.FormatInt(step.ID, 10)
+			}
+			name := step.Name
+			if len(stages) > 1 {
+				name = stage.Name + " / " + step.Name
+			}
+			started := unixTimePtr(firstNonZero(step.StartTime, step.Started))
+			completed := unixTimePtr(firstNonZero(step.EndTime, step.Stopped))
+			jobs = append(jobs, Job{
+				ID:          id,
+				RunID:       number,
+				Name:        name,
+				Status:      status,
+				Conclusion:  status,
+				StartedAt:   started,
+				CompletedAt: completed,
+				Steps: []Step{{
+					Name:        name,
+					Status:      status,
+					Conclusion:  status,
+					StartedAt:   started,
+					CompletedAt: completed,
+				}},
+			})
+		}
+	}
+	return jobs, nil
+}
+
+// GetStepLog retrieves the log of a step, given the job ID of GetBuildJobs
+func (d *DroneClient) GetStepLog(owner, repo, number, jobID string) (string, error) {
+	path, err := d.repoPath(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	if d.platform == "woodpecker" {
+		path = fmt.Sprintf("%s/logs/%s/%s", path, url.PathEscape(number), url.PathEscape(jobID))
+	} else {
+		path = fmt.Sprintf("%s/builds/%s/logs/%s", path, url.PathEscape(number), jobID)
+	}
+
+	var lines []droneLogLine
+	if err := d.do("GET", path, nil, &lines); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		text := line.Out
+		if text == "" {
+			text = string(line.Data)
+		}
+		b.WriteString(strings.TrimSuffix(text, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// GetBranches lists the branches builds can be started on. Drone has no
+// branch API, so it offers the default branch and recently built ones.
+func (d *DroneClient) GetBranches(owner, repo string) ([]string, error) {
+	path, err := d.repoPath(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if d.platform == "woodpecker" {
+		var branches []string
+		if err := d.do("GET", path+"/branches", nil, &branches); err != nil {
+			return nil, err
+		}
+		return branches, nil
+	}
+
+	var info struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := d.do("GET", path, nil, &info); err != nil {
+		return nil, err
+	}
+	runs, err := d.GetBuilds(owner, repo, "", 50)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{info.DefaultBranch: true}
+	var recent []string
+	for _, run := range runs {
+		if run.Branch != "" && !seen[run.Branch] {
+			seen[run.Branch] = true
+			recent = append(recent, run.Branch)
+		}
+	}
+	sort.Strings(recent)
+	return append([]string{info.DefaultBranch}, recent...), nil
+}
+
+// TriggerBuild starts a build on a branch with the given parameters
+// (Drone) or variables (Woodpecker) and returns it
+func (d *DroneClient) TriggerBuild(owner, repo, branch string, variables map[string]string) (*WorkflowRun, error) {
+	path, err := d.buildsPath(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var build droneBuild
+	if d.platform == "woodpecker" {
+		body := map[string]any{"branch": branch, "variables": variables}
+		err = d.do("POST", path, body, &build)
+	} else {
+		query := url.Values{"branch": {branch}}
+		for key, value := range variables {
+			query.Set(key, value)
+		}
+		err = d.do("POST", path+"?"+query.Encode(), nil, &build)
+	}
+	if err != nil {
+		return nil, err
+	}
+	run := d.convertBuild(owner, repo, build)
+	return &run, nil
+}
+
+// CancelBuild stops a running build
+func (d *DroneClient) CancelBuild(owner, repo, number string) error {
+	path, err := d.buildsPath(owner, repo)
+	if err != nil {
+		return err
+	}
+	if d.platform == "woodpecker" {
+		return d.do("POST", path+"/"+url.PathEscape(number)+"/cancel", nil, nil)
+	}
+	return d.do("DELETE", path+"/"+url.PathEscape(number), nil, nil)
+}
+
+// RestartBuild starts a finished build again as a new build
+func (d *DroneClient) RestartBuild(owner, repo, number string) error {
+	path, err := d.buildsPath(owner, repo)
+	if err != nil {
+		return err
+	}
+	return d.do("POST", path+"/"+url.PathEscape(number), nil, nil)
+}
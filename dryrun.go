@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// apiCall is a request a dry run would have made. Paths are relative to
+// the provider's API root; mock and plugin calls are described instead.
+type apiCall struct {
+	Method string
+	Path   string
+	Body   any
+}
+
+// String renders a call as its method, path, and JSON body
+func (c apiCall) String() string {
+	line := c.Method + " " + c.Path
+	if c.Body != nil {
+		if data, err := json.Marshal(c.Body); err == nil {
+			line += " " + string(data)
+		}
+	}
+	return line
+}
+
+// printAPICalls lists the calls a dry run would have made
+func printAPICalls(calls []apiCall) {
+	fmt.Printf("%s\n", qc.Colorize("Would call:", qc.ColorBlue))
+	for _, call := range calls {
+		fmt.Printf("  %s\n", call)
+	}
+}
+
+// gitlabProjectPath returns the API path of a GitLab project
+func gitlabProjectPath(project string) string {
+	return "/projects/" + url.PathEscape(project)
+}
+
+// planTrigger returns the call triggerWorkflow would make. Finding the
+// GitHub workflow file, default branch, and Woodpecker repository ID still
+// takes read-only requests.
+func planTrigger(ctx context.Context, project Project, workflowName, ref string, variables map[string]string) (apiCall, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return apiCall{}, err
+		}
+		wf, err := client.findWorkflow(project.Owner, project.Repo, workflowName)
+		if err != nil {
+			return apiCall{}, err
+		}
+		if ref == "" {
+			if ref, err = client.GetDefaultBranch(project.Owner, project.Repo); err != nil {
+				return apiCall{}, err
+			}
+		}
+		body := map[string]any{"ref": ref}
+		if len(variables) > 0 {
+			body["inputs"] = variables
+		}
+		dispatch := fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", project.Owner, project.Repo, url.PathEscape(path.Base(wf.GetPath())))
+		return apiCall{Method: "POST", Path: dispatch, Body: body}, nil
+	case "gitlab":
+		var gitlabVars []map[string]string
+		for _, key := range slices.Sorted(maps.Keys(variables)) {
+			gitlabVars = append(gitlabVars, map[string]string{"key": key, "value": variables[key]})
+		}
+		body := map[string]any{"ref": workflowName}
+		if len(gitlabVars) > 0 {
+			body["variables"] = gitlabVars
+		}
+		return apiCall{Method: "POST", Path: gitlabProjectPath(project.Name) + "/pipeline", Body: body}, nil
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, project.Platform)
+		if err != nil {
+			return apiCall{}, err
+		}
+		builds, err := client.buildsPath(project.Owner, project.Repo)
+		if err != nil {
+			return apiCall{}, err
+		}
+		if project.Platform == "woodpecker" {
+			return apiCall{Method: "POST", Path: builds, Body: map[string]any{"branch": workflowName, "variables": variables}}, nil
+		}
+		query := url.Values{"branch": {workflowName}}
+		for key, value := range variables {
+			query.Set(key, value)
+		}
+		return apiCall{Method: "POST", Path: builds + "?" + query.Encode()}, nil
+	case "mock":
+		return apiCall{Method: "mock", Path: fmt.Sprintf("start %s in %s", workflowName, project.Name)}, nil
+	default:
+		return apiCall{Method: "plugin", Path: fmt.Sprintf("%s trigger %s in %s", project.Platform, workflowName, project.Name), Body: variables}, nil
+	}
+}
+
+// planRunCall returns the call cancelling or re-running a run would make,
+// for action "cancel", "rerun", or "rerun-failed"
+func planRunCall(ctx context.Context, run WorkflowRun, action string) (apiCall, error) {
+	owner, repo, _ := strings.Cut(run.Project, "/")
+	switch run.Platform {
+	case "github":
+		return apiCall{Method: "POST", Path: fmt.Sprintf("/repos/%s/%s/actions/runs/%s/%s", owner, repo, run.ID, strings.Replace(action, "rerun-failed", "rerun-failed-jobs", 1))}, nil
+	case "gitlab":
+		// GitLab only retries failed jobs
+		verb := "retry"
+		if action == "cancel" {
+			verb = "cancel"
+		}
+		return apiCall{Method: "POST", Path: fmt.Sprintf("%s/pipelines/%s/%s", gitlabProjectPath(run.Project), run.ID, verb)}, nil
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, run.Platform)
+		if err != nil {
+			return apiCall{}, err
+		}
+		builds, err := client.buildsPath(owner, repo)
+		if err != nil {
+			return apiCall{}, err
+		}
+		build := builds + "/" + url.PathEscape(run.ID)
+		switch {
+		case action != "cancel":
+			return apiCall{Method: "POST", Path: build}, nil
+		case run.Platform == "woodpecker":
+			return apiCall{Method: "POST", Path: build + "/cancel"}, nil
+		default:
+			return apiCall{Method: "DELETE", Path: build}, nil
+		}
+	case "mock":
+		return apiCall{Method: "mock", Path: fmt.Sprintf("%s run %s of %s", action, run.ID, run.Project)}, nil
+	default:
+		return apiCall{Method: "plugin", Path: fmt.Sprintf("%s %s run %s of %s", run.Platform, action, run.ID, run.Project)}, nil
+	}
+}
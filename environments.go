@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// getDeployments retrieves what each environment of a project is running
+func getDeployments(ctx context.Context, project Project) ([]Deployment, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetDeployments(project.Owner, project.Repo)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetDeployments(project.Name)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// getPendingDeployments retrieves a project's deployments waiting on approval
+func getPendingDeployments(ctx context.Context, project Project) ([]PendingDeployment, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPendingDeployments(project.Owner, project.Repo)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPendingDeployments(project.Name)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// showEnvironments lists what is deployed to each environment of the given
+// project, or of every tracked project, and deployments awaiting approval
+func showEnvironments(ctx context.Context, config *Config, args []string) {
+	projects := config.Projects
+	if len(args) > 0 {
+		project := findProject(config, args[0])
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	for i, project := range projects {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s)\n", qc.ColorizeBold(project.Name, qc.ColorWhite), qc.Colorize(project.Platform, colorPlatform(project.Platform)))
+
+		deployments, err := getDeployments(ctx, project)
+		if err != nil {
+			fmt.Printf("  %s Failed to get environments: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			continue
+		}
+		if len(deployments) == 0 {
+			fmt.Printf("  %s\n", qc.Colorize("No deployments", qc.ColorYellow))
+		}
+		displayDeployments(deployments)
+
+		pending, err := getPendingDeployments(ctx, project)
+		if err != nil {
+			fmt.Printf("  %s Failed to get pending deployments: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+			continue
+		}
+		if len(pending) > 0 {
+			fmt.Printf("  %s\n", qc.Colorize("Awaiting approval:", qc.ColorYellow))
+			displayPendingDeployments(pending)
+		}
+	}
+}
+
+// displayDeployments prints one line per environment
+func displayDeployments(deployments []Deployment) {
+	longest := 0
+	for _, d := range deployments {
+		if len(d.Environment) > longest {
+			longest = len(d.Environment)
+		}
+	}
+
+	for i, d := range deployments {
+		color := qc.ColorYellow
+		switch d.Status {
+		case "success":
+			color = qc.ColorGreen
+		case "failure", "failed", "error":
+			color = qc.ColorRed
+		case "in_progress", "running":
+			color = qc.ColorBlue
+		}
+
+		line := fmt.Sprintf("  %-*s ", longest, d.Environment)
+		details := []string{fmt.Sprintf("%s@%s", d.Ref, shortSHA(d.SHA))}
+		if d.RunID != "" {
+			details = append(details, "run "+d.RunID)
+		}
+		if d.Creator != "" {
+			details = append(details, "by "+d.Creator)
+		}
+		details = append(details, formatRunTime(d.DeployedAt))
+
+		fmt.Printf("%s[%s] %s\n",
+			qc.Colorize(line, qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)),
+			qc.Colorize(d.Status, color),
+			strings.Join(details, "  "))
+		if d.URL != "" {
+			fmt.Printf("  %-*s %s\n", longest, "", d.URL)
+		}
+	}
+}
+
+// displayPendingDeployments prints deployments waiting on approval with who
+// can approve them
+func displayPendingDeployments(pending []PendingDeployment) {
+	for _, p := range pending {
+		line := fmt.Sprintf("    %s  run %s", p.Environment, p.RunID)
+		if p.Ref != "" {
+			line += "  " + p.Ref
+		}
+		if !p.WaitingSince.IsZero() {
+			line += "  waiting " + formatDuration(time.Since(p.WaitingSince))
+		}
+		if len(p.Reviewers) > 0 {
+			line += "  reviewers: " + strings.Join(p.Reviewers, ", ")
+		}
+		if p.CanApprove {
+			line += "  " + qc.Colorize("(you can approve)", qc.ColorGreen)
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"github.com/xanzy/go-gitlab"
+)
+
+// ErrorKind classifies why a provider request failed
+type ErrorKind string
+
+const (
+	ErrUnauthorized    ErrorKind = "unauthorized"
+	ErrForbidden       ErrorKind = "forbidden"
+	ErrSAMLEnforced    ErrorKind = "saml_enforced"
+	ErrActionsDisabled ErrorKind = "actions_disabled"
+	ErrNotFound        ErrorKind = "not_found"
+	ErrRateLimited     ErrorKind = "rate_limited"
+	ErrInvalidRequest  ErrorKind = "invalid_request"
+	ErrUnavailable     ErrorKind = "unavailable"
+	ErrNetwork         ErrorKind = "network"
+	ErrOffline         ErrorKind = "offline"
+)
+
+// ProviderError is a failed provider request with a message and next step
+// meant for the user
+type ProviderError struct {
+	Platform string
+	Kind     ErrorKind
+	Message  string
+	Hint     string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	if e.Hint == "" {
+		return e.Message
+	}
+	return e.Message + "\n  Hint: " + e.Hint
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// describeError returns the message to show for an error, explaining
+// provider failures and suggesting what to do next
+func describeError(err error) string {
+	// A request cut short by Ctrl+C isn't a provider failure
+	if errors.Is(err, context.Canceled) {
+		return "interrupted"
+	}
+
+	providerErr := classifyError(err)
+	if providerErr == nil {
+		return err.Error()
+	}
+
+	// Keep context added by wrapping, e.g. "invalid token: "
+	var matched error = providerErr
+	if providerErr.Err != nil {
+		matched = providerErr.Err
+	}
+	prefix, _ := strings.CutSuffix(err.Error(), matched.Error())
+	return prefix + providerErr.Error()
+}
+
+// classifyError maps GitHub, GitLab, and network errors to a ProviderError.
+// It returns nil for errors that did not come from a provider request.
+func classifyError(err error) *ProviderError {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr
+	}
+
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return &ProviderError{
+			Platform: "github",
+			Kind:     ErrRateLimited,
+			Message:  "GitHub API rate limit exceeded",
+			Hint:     fmt.Sprintf("the limit resets at %s; authenticate to get a higher limit", formatAbsoluteTime(rateErr.Rate.Reset.Time)),
+			Err:      rateErr,
+		}
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		hint := "wait a minute before retrying and avoid running many commands at once"
+		if retryAfter := abuseErr.GetRetryAfter(); retryAfter > 0 {
+			hint = fmt.Sprintf("retry in %s", formatDuration(retryAfter))
+		}
+		return &ProviderError{Platform: "github", Kind: ErrRateLimited, Message: "GitHub secondary rate limit exceeded", Hint: hint, Err: abuseErr}
+	}
+
+	var githubErr *github.ErrorResponse
+	if errors.As(err, &githubErr) && githubErr.Response != nil {
+		providerErr := classifyResponse("github", githubErr.Response, githubErr.Message)
+		providerErr.Err = githubErr
+		return providerErr
+	}
+
+	var gitlabErr *gitlab.ErrorResponse
+	if errors.As(err, &gitlabErr) && gitlabErr.Response != nil {
+		providerErr := classifyResponse("gitlab", gitlabErr.Response, gitlabErr.Message)
+		providerErr.Err = gitlabErr
+		return providerErr
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		hint := "check your network connection and any proxy settings"
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			hint = fmt.Sprintf("could not resolve %s; check the host name and your DNS settings", dnsErr.Name)
+		}
+		if urlErr.Timeout() {
+			hint = "the request timed out; the provider may be slow or unreachable"
+		}
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			hint = "the server's TLS certificate isn't trusted; if a proxy intercepts TLS, pass its CA with --ca-bundle or 'settings set ca_bundle <file>'"
+		}
+		return &ProviderError{
+			Platform: platformForHost(urlErr.URL),
+			Kind:     ErrNetwork,
+			Message:  fmt.Sprintf("could not reach %s", requestHost(urlErr.URL)),
+			Hint:     hint,
+			Err:      urlErr,
+		}
+	}
+
+	return nil
+}
+
+// responseError builds a ProviderError from a raw HTTP response, reading the
+// provider's message from the body
+func responseError(platform string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return classifyResponse(platform, resp, providerMessage(body))
+}
+
+// providerMessage extracts the "message" field GitHub and GitLab put in
+// error bodies, falling back to the raw body
+func providerMessage(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	if _, rest, ok := strings.Cut(text, `"message":`); ok {
+		rest = strings.TrimSpace(rest)
+		if unquoted, err := strconv.QuotedPrefix(rest); err == nil {
+			if message, err := strconv.Unquote(unquoted); err == nil {
+				return message
+			}
+		}
+	}
+	return text
+}
+
+// classifyResponse classifies a failed response by status, headers, and
+// the provider's message
+func classifyResponse(platform string, resp *http.Response, message string) *ProviderError {
+	name := platformName(platform)
+	loginHint := fmt.Sprintf("run 'quick_workflow login %s' or check %s_TOKEN", platform, strings.ToUpper(platform))
+	lower := strings.ToLower(message)
+	e := &ProviderError{Platform: platform}
+
+	switch status := resp.StatusCode; {
+	case status == http.StatusUnauthorized:
+		e.Kind = ErrUnauthorized
+		e.Message = fmt.Sprintf("%s rejected the access token (it may be expired or revoked)", name)
+		e.Hint = loginHint
+
+	case status == http.StatusForbidden && resp.Header.Get("X-GitHub-SSO") != "":
+		e.Kind = ErrSAMLEnforced
+		e.Message = "the organization requires SAML single sign-on for this token"
+		e.Hint = "authorize the token for the organization"
+		if _, ssoURL, ok := strings.Cut(resp.Header.Get("X-GitHub-SSO"), "url="); ok {
+			e.Hint += " at " + ssoURL
+		}
+
+	case status == http.StatusTooManyRequests || (status == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"):
+		e.Kind = ErrRateLimited
+		e.Message = fmt.Sprintf("%s API rate limit exceeded", name)
+		e.Hint = "wait before retrying"
+		if reset := rateLimitReset(resp); !reset.IsZero() {
+			e.Hint = fmt.Sprintf("the limit resets at %s", formatAbsoluteTime(reset))
+		}
+
+	case status == http.StatusForbidden && strings.Contains(lower, "actions") && strings.Contains(lower, "disabled"):
+		e.Kind = ErrActionsDisabled
+		e.Message = "GitHub Actions is disabled for this repository"
+		e.Hint = "enable Actions under the repository's Settings > Actions, or ask an admin"
+
+	case status == http.StatusForbidden:
+		e.Kind = ErrForbidden
+		e.Message = fmt.Sprintf("%s denied access", name)
+		switch {
+		case missingScopes(resp) != "":
+			e.Hint = fmt.Sprintf("the token needs one of these scopes: %s", missingScopes(resp))
+		case strings.Contains(lower, "insufficient_scope"):
+			e.Hint = "the token needs the api scope"
+		default:
+			e.Hint = "check that the token can access this project and has the required scopes"
+		}
+
+	case status == http.StatusNotFound:
+		e.Kind = ErrNotFound
+		e.Message = fmt.Sprintf("%s could not find the requested project or resource", name)
+		e.Hint = "check the project name; private projects also return not found when the token lacks access"
+
+	case status == http.StatusUnprocessableEntity || status == http.StatusBadRequest:
+		e.Kind = ErrInvalidRequest
+		e.Message = fmt.Sprintf("%s rejected the request", name)
+
+	case status >= 500:
+		e.Kind = ErrUnavailable
+		e.Message = fmt.Sprintf("%s is having problems (status %d)", name, status)
+		e.Hint = "try again shortly; check the provider's status page if it persists"
+
+	default:
+		e.Message = fmt.Sprintf("%s request failed with status %d", name, status)
+	}
+
+	if message != "" && e.Kind != ErrUnavailable {
+		e.Message += ": " + message
+	}
+	return e
+}
+
+// missingScopes returns the scopes GitHub accepts for a request when the
+// token has none of them
+func missingScopes(resp *http.Response) string {
+	accepted := splitList(resp.Header.Get("X-Accepted-OAuth-Scopes"))
+	if len(accepted) == 0 {
+		return ""
+	}
+	granted := splitList(resp.Header.Get("X-OAuth-Scopes"))
+	for _, scope := range accepted {
+		if slices.Contains(granted, scope) {
+			return ""
+		}
+	}
+	return strings.Join(accepted, ", ")
+}
+
+// rateLimitReset reads when a rate limit resets from GitHub or GitLab headers
+func rateLimitReset(resp *http.Response) time.Time {
+	for _, header := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if seconds, err := strconv.ParseInt(resp.Header.Get(header), 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return time.Time{}
+}
+
+// platformName returns the display name of a platform
+func platformName(platform string) string {
+	switch platform {
+	case "github":
+		return "GitHub"
+	case "gitlab":
+		return "GitLab"
+	case "drone":
+		return "Drone"
+	case "woodpecker":
+		return "Woodpecker"
+	case "mock":
+		return "Mock"
+	default:
+		return platform
+	}
+}
+
+// platformForHost guesses the platform from a request URL
+func platformForHost(rawURL string) string {
+	if strings.Contains(requestHost(rawURL), "github") {
+		return "github"
+	}
+	return "gitlab"
+}
+
+// requestHost returns the host of a request URL
+func requestHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
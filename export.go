@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// exportSeparators are the field separators of the spreadsheet formats
+var exportSeparators = map[string]rune{
+	"csv": ',',
+	"tsv": '\t',
+}
+
+// isExportFormat reports whether a --format value is a spreadsheet format
+func isExportFormat(format string) bool {
+	_, ok := exportSeparators[format]
+	return ok
+}
+
+// writeDelimited writes a header and rows as CSV or TSV. Values holding the
+// separator, quotes, or line breaks are quoted with inner quotes doubled.
+func writeDelimited(w io.Writer, format string, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = exportSeparators[format]
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	return writer.WriteAll(rows)
+}
+
+// parseFields validates a comma separated field list against the available
+// fields, falling back to defaults when empty
+func parseFields(spec string, available []string, defaults []string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(available, name) {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(available, ", "))
+		}
+		fields = append(fields, name)
+	}
+	if len(fields) == 0 {
+		return defaults, nil
+	}
+	return fields, nil
+}
+
+// runFieldValue returns a run's value for an export field. Unlike table
+// cells, times are absolute, durations are whole seconds, and commits are
+// full SHAs so spreadsheets can sort and sum them.
+func runFieldValue(run WorkflowRun, field string) string {
+	switch field {
+	case "status":
+		return run.Status
+	case "created":
+		if run.CreatedAt.IsZero() {
+			return ""
+		}
+		return run.CreatedAt.In(displayLocation).Format(time.RFC3339)
+	case "duration":
+		if run.CreatedAt.IsZero() || !isRunFinished(run.Status) {
+			return ""
+		}
+		return strconv.Itoa(int(run.UpdatedAt.Sub(run.CreatedAt).Seconds()))
+	case "queued":
+		if run.QueueTime <= 0 {
+			return ""
+		}
+		return strconv.Itoa(int(run.QueueTime.Seconds()))
+	case "commit":
+		return run.Commit
+	case "attempt":
+		return strconv.Itoa(max(run.Attempt, 1))
+	case "pr":
+		if run.PullRequest == 0 {
+			return ""
+		}
+		return strconv.Itoa(run.PullRequest)
+	default:
+		return runColumns[field].value(run)
+	}
+}
+
+// writeRunsDelimited writes runs as CSV or TSV with one column per field
+func writeRunsDelimited(w io.Writer, format string, runs []WorkflowRun, fields []string) error {
+	rows := make([][]string, len(runs))
+	for i, run := range runs {
+		row := make([]string, len(fields))
+		for j, field := range fields {
+			row[j] = runFieldValue(run, field)
+		}
+		rows[i] = row
+	}
+	return writeDelimited(w, format, fields, rows)
+}
+
+// exportRuns fetches the recent runs of every tracked project matching
+// search, newest first, and hands them to write for printing to stdout.
+// Fetch failures go to stderr so the output stays parseable.
+func exportRuns(ctx context.Context, config *Config, search runSearch, limit int, queuedOver time.Duration, queueTimes bool, write func(runs []WorkflowRun) error) {
+	projects := activeProjects(config.Projects)
+	warnRateLimit(ctx, os.Stderr, projects, fetchRequests(queueTimes || queuedOver > 0, limit))
+	runs, failures := search.collect(ctx, os.Stderr, config, projects, limit)
+	printFetchFailures(os.Stderr, failures)
+	if queueTimes || queuedOver > 0 {
+		fillQueueTimes(ctx, runs)
+	}
+	runs = filterQueuedOver(runs, queuedOver)
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+
+	if err := write(runs); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Failed to write runs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+	}
+}
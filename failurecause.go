@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// Failure kinds: infra failures are the CI environment's fault and worth
+// re-running, code failures are the fault of what was built
+const (
+	causeInfra = "infra"
+	causeCode  = "code"
+)
+
+// FailureSignature is a pattern in the logs of failed jobs that points to
+// why a run failed
+type FailureSignature struct {
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Kind is infra or code
+	Kind string `json:"kind" yaml:"kind"`
+}
+
+// validate checks the signature's pattern and kind
+func (s FailureSignature) validate() error {
+	if s.Pattern == "" {
+		return fmt.Errorf("a signature needs a pattern")
+	}
+	if _, err := regexp.Compile(s.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", s.Pattern, err)
+	}
+	switch s.Kind {
+	case causeInfra, causeCode:
+	default:
+		return fmt.Errorf("invalid kind %q (expected infra or code)", s.Kind)
+	}
+	return nil
+}
+
+// namedSignature is a failure signature with its name and compiled pattern
+type namedSignature struct {
+	Name    string
+	Kind    string
+	Pattern *regexp.Regexp
+}
+
+// defaultFailureSignatures are matched after the configured signatures, in
+// this order, so the more specific infra causes win over a test failure
+// they caused
+var defaultFailureSignatures = []struct {
+	Name string
+	FailureSignature
+}{
+	{"docker-rate-limit", FailureSignature{Kind: causeInfra, Pattern: `(?i)toomanyrequests|reached your pull rate limit|pull rate limit exceeded`}},
+	{"oom", FailureSignature{Kind: causeInfra, Pattern: `(?i)out of memory|OOMKilled|oom-kill|exit code 137\b|cannot allocate memory|heap out of memory`}},
+	{"network-timeout", FailureSignature{Kind: causeInfra, Pattern: `(?i)i/o timeout|tls handshake timeout|connection (reset|refused|timed out)|ETIMEDOUT|ECONNRESET|ECONNREFUSED|could not resolve host|temporary failure in name resolution|network is unreachable`}},
+	{"test-failure", FailureSignature{Kind: causeCode, Pattern: `^\s*(--- FAIL|FAIL\b|FAILED\b)|(?i)\b[1-9]\d* (tests? )?failed\b|Tests run:.*Failures: [1-9]|AssertionError`}},
+}
+
+// failureSignatures returns the signatures to match, the configured ones
+// by name and then the defaults they don't replace
+func failureSignatures(settings Settings) []namedSignature {
+	var signatures []namedSignature
+	names := slices.Sorted(maps.Keys(settings.FailureSignatures))
+	for _, name := range names {
+		s := settings.FailureSignatures[name]
+		// Invalid signatures are refused when set, so only a hand edited
+		// config has them
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			continue
+		}
+		signatures = append(signatures, namedSignature{Name: name, Kind: s.Kind, Pattern: pattern})
+	}
+	for _, s := range defaultFailureSignatures {
+		if _, ok := settings.FailureSignatures[s.Name]; ok {
+			continue
+		}
+		signatures = append(signatures, namedSignature{Name: s.Name, Kind: s.Kind, Pattern: regexp.MustCompile(s.Pattern)})
+	}
+	return signatures
+}
+
+// signaturesFingerprint identifies a set of signatures, so causes found
+// with other signatures are found again
+func signaturesFingerprint(signatures []namedSignature) string {
+	h := sha256.New()
+	for _, s := range signatures {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\n", s.Name, s.Kind, s.Pattern)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// FailureCause is the probable cause of a failed run: the first signature,
+// in order, matching a line of a failed job's log. Signature is empty when
+// none matched.
+type FailureCause struct {
+	Signature string `json:"signature,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Job       string `json:"job,omitempty"`
+	Line      string `json:"line,omitempty"`
+	// Attempt is the attempt of the run the logs were of
+	Attempt int `json:"attempt,omitempty"`
+}
+
+// String names the cause, "unknown" when no signature matched
+func (c FailureCause) String() string {
+	if c.Signature == "" {
+		return "unknown"
+	}
+	return c.Kind + ": " + c.Signature
+}
+
+// causeCache is the failure causes kept next to the state file, by
+// project#run
+type causeCache struct {
+	// Signatures is the fingerprint of the signatures the causes were
+	// found with
+	Signatures string                  `json:"signatures"`
+	Causes     map[string]FailureCause `json:"causes"`
+}
+
+// causeCacheFile returns the path of the failure cause cache
+func causeCacheFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "causes.json")
+}
+
+// readCauseCache reads the failure cause cache, empty when missing or
+// unreadable, since causes can be found again
+func readCauseCache(config *Config) causeCache {
+	var cache causeCache
+	if data, err := os.ReadFile(causeCacheFile(config)); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	if cache.Causes == nil {
+		cache.Causes = make(map[string]FailureCause)
+	}
+	return cache
+}
+
+// writeCauseCache adds found causes to the failure cause cache, replacing
+// causes found with other signatures
+func writeCauseCache(config *Config, fingerprint string, found map[string]FailureCause) error {
+	path := causeCacheFile(config)
+	lock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	cache := readCauseCache(config)
+	if cache.Signatures != fingerprint {
+		cache = causeCache{Signatures: fingerprint, Causes: make(map[string]FailureCause)}
+	}
+	for key, cause := range found {
+		cache.Causes[key] = cause
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// isFailedRun reports whether a finished run failed
+func isFailedRun(run WorkflowRun) bool {
+	return isRunFinished(run.Status) && (run.Status == "failed" || isFailedConclusion(run.Conclusion))
+}
+
+// classifyFailure fetches the logs of a run's failed jobs and matches them
+// against the signatures
+func classifyFailure(ctx context.Context, run WorkflowRun, signatures []namedSignature) (FailureCause, error) {
+	jobs, err := getJobsForRun(ctx, run)
+	if err != nil {
+		return FailureCause{}, err
+	}
+	type jobLines struct {
+		job   string
+		lines []logLine
+	}
+	var logs []jobLines
+	for _, job := range jobs {
+		if !isFailedConclusion(job.Conclusion) {
+			continue
+		}
+		log, err := fetchJobLog(ctx, run, job)
+		if err != nil {
+			return FailureCause{}, err
+		}
+		logs = append(logs, jobLines{job: job.Name, lines: parseLogLines(log)})
+	}
+
+	cause := FailureCause{Attempt: run.Attempt}
+	for _, s := range signatures {
+		for _, log := range logs {
+			for _, line := range log.lines {
+				if s.Pattern.MatchString(line.Text) {
+					cause.Signature, cause.Kind = s.Name, s.Kind
+					cause.Job, cause.Line = log.job, strings.TrimSpace(line.Text)
+					return cause, nil
+				}
+			}
+		}
+	}
+	return cause, nil
+}
+
+// fillFailureCauses sets the probable cause of each failed run, from the
+// cache or by reading the logs of its failed jobs. Runs whose logs can't
+// be fetched are left without one.
+func fillFailureCauses(ctx context.Context, config *Config, runs []WorkflowRun) {
+	signatures := failureSignatures(config.Settings)
+	fingerprint := signaturesFingerprint(signatures)
+	cache := readCauseCache(config)
+	if cache.Signatures != fingerprint {
+		cache.Causes = make(map[string]FailureCause)
+	}
+
+	found := make(map[string]FailureCause)
+	for i := range runs {
+		if !isFailedRun(runs[i]) {
+			continue
+		}
+		key := runs[i].Project + "#" + runs[i].ID
+		cause, ok := cache.Causes[key]
+		if !ok || cause.Attempt != runs[i].Attempt {
+			var err error
+			cause, err = classifyFailure(ctx, runs[i], signatures)
+			if err != nil {
+				continue
+			}
+			found[key] = cause
+		}
+		runs[i].Cause = &cause
+	}
+	if len(found) > 0 {
+		if err := writeCauseCache(config, fingerprint, found); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Failed to cache failure causes: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		}
+	}
+}
+
+// causeColor colors a cause: infra yellow, since a re-run may pass, and
+// code red
+func causeColor(run WorkflowRun) string {
+	if run.Cause == nil {
+		return ""
+	}
+	switch run.Cause.Kind {
+	case causeInfra:
+		return qc.ColorYellow
+	case causeCode:
+		return qc.ColorRed
+	}
+	return ""
+}
+
+// handleSignatureSettings handles settings signature, which lists, sets,
+// and deletes failure signatures
+func handleSignatureSettings(config *Config, args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		fmt.Printf("%s\n", qc.Colorize("Failure Signatures (matched in order):", qc.ColorBlue))
+		for _, s := range failureSignatures(config.Settings) {
+			source := "built in"
+			if _, ok := config.Settings.FailureSignatures[s.Name]; ok {
+				source = "configured"
+			}
+			fmt.Printf("  %s %-5s %s %s\n", qc.ColorizeBold(fmt.Sprintf("%-18s", s.Name), qc.ColorGreen), s.Kind, s.Pattern, qc.Dim("("+source+")"))
+		}
+		return
+	}
+
+	switch args[0] {
+	case "set":
+		fs := flag.NewFlagSet("settings signature set", flag.ExitOnError)
+		pattern := fs.String("pattern", "", "Regular expression matched against each line of failed job logs")
+		kind := fs.String("kind", causeInfra, "infra for failures of the CI environment, code for failures of what was built")
+		if len(args) < 2 {
+			fmt.Printf("%s Usage: quick_workflow settings signature set <name> --pattern <regex> [--kind infra|code]\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		name := args[1]
+		fs.Parse(args[2:])
+
+		signature := FailureSignature{Pattern: *pattern, Kind: *kind}
+		if err := signature.validate(); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		err := updateProjects(config, func(config *Config) error {
+			if config.Settings.FailureSignatures == nil {
+				config.Settings.FailureSignatures = make(map[string]FailureSignature)
+			}
+			config.Settings.FailureSignatures[name] = signature
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Saved signature %s\n", qc.Colorize("Success:", qc.ColorGreen), name)
+	case "delete":
+		if len(args) < 2 {
+			fmt.Printf("%s Usage: quick_workflow settings signature delete <name>\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		name := args[1]
+		err := updateProjects(config, func(config *Config) error {
+			if _, ok := config.Settings.FailureSignatures[name]; !ok {
+				return fmt.Errorf("no configured signature %s; built-in signatures can be replaced with set, not deleted", name)
+			}
+			delete(config.Settings.FailureSignatures, name)
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Deleted signature %s\n", qc.Colorize("Success:", qc.ColorGreen), name)
+	default:
+		fmt.Printf("%s Usage: quick_workflow settings signature [list | set <name> --pattern <regex> [--kind infra|code] | delete <name>]\n", qc.Colorize("Error:", qc.ColorRed))
+	}
+}
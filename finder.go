@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/term"
+)
+
+// finderRows is how many matches the fuzzy finder shows at once
+const finderRows = 10
+
+// numberedPrompts turns the fuzzy finder off in favor of numbered prompts,
+// from the numbered_prompts setting
+var numberedPrompts bool
+
+// useFinder reports whether selection prompts use the fuzzy finder: when
+// run on a terminal, unless the numbered_prompts setting is on. Piped
+// input keeps the numbered prompts, so scripts can answer them.
+func useFinder() bool {
+	return !numberedPrompts && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// fuzzyScore matches the characters of query in order within text,
+// ignoring case. Consecutive characters and characters starting a word
+// score higher, so "api" ranks "acme/api" above "acme/platform-index". ok
+// is false when text doesn't contain them.
+func fuzzyScore(text, query string) (score int, ok bool) {
+	textRunes := []rune(strings.ToLower(text))
+	previous := -2
+	i := 0
+	for _, q := range strings.ToLower(query) {
+		if unicode.IsSpace(q) {
+			continue
+		}
+		for i < len(textRunes) && textRunes[i] != q {
+			i++
+		}
+		if i == len(textRunes) {
+			return 0, false
+		}
+		score++
+		if i == previous+1 {
+			score += 4
+		}
+		if i == 0 || strings.ContainsRune(" /-_.:#[(", textRunes[i-1]) {
+			score += 2
+		}
+		previous = i
+		i++
+	}
+	return score, true
+}
+
+// finder is an fzf-style selector: typing filters the items, the arrow
+// keys move between matches, and Enter chooses. With multi, Tab marks
+// several items to choose at once.
+type finder struct {
+	title    string
+	items    []string
+	multi    bool
+	query    []rune
+	matches  []int
+	cursor   int
+	offset   int
+	selected map[int]bool
+}
+
+// pick asks the user to choose one of items with the fuzzy finder,
+// returning its index. ok is false when the user cancels.
+func pick(title string, items []string) (index int, ok bool) {
+	chosen, ok := runFinder(&finder{title: title, items: items})
+	if !ok {
+		return -1, false
+	}
+	return chosen[0], true
+}
+
+// pickMany asks the user to choose any number of items with the fuzzy
+// finder, returning their indexes in the items' order. Enter without
+// marking any chooses the highlighted one.
+func pickMany(title string, items []string) ([]int, bool) {
+	return runFinder(&finder{title: title, items: items, multi: true})
+}
+
+// runFinder runs the finder on the terminal until the user chooses or
+// cancels with Esc or Ctrl+C
+func runFinder(f *finder) ([]int, bool) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		return nil, false
+	}
+	f.selected = make(map[int]bool)
+	f.filter()
+
+	chosen, ok := func() ([]int, bool) {
+		buf := make([]byte, 64)
+		for {
+			f.draw()
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return nil, false
+			}
+			if done, chosen, ok := f.handleKeys(buf[:n]); done {
+				return chosen, ok
+			}
+		}
+	}()
+
+	fmt.Print("\r\x1b[J")
+	term.Restore(fd, state)
+	if ok {
+		labels := make([]string, len(chosen))
+		for i, index := range chosen {
+			labels[i] = strings.Join(strings.Fields(f.items[index]), " ")
+		}
+		fmt.Printf("%s %s\n", qc.Colorize(f.title+":", qc.ColorBlue), strings.Join(labels, ", "))
+	}
+	return chosen, ok
+}
+
+// handleKeys applies the keys read in one go, reporting whether the user
+// chose or cancelled
+func (f *finder) handleKeys(keys []byte) (done bool, chosen []int, ok bool) {
+	// A lone Esc cancels; longer input starting with it is an escape
+	// sequence, such as an arrow key
+	if len(keys) == 1 && keys[0] == 0x1b {
+		return true, nil, false
+	}
+	for len(keys) > 0 {
+		switch {
+		case keys[0] == 0x1b:
+			switch seq := string(keys[:min(3, len(keys))]); seq {
+			case "\x1b[A", "\x1bOA":
+				f.move(-1)
+			case "\x1b[B", "\x1bOB":
+				f.move(1)
+			}
+			// Skip the rest of the sequence, which ends with a letter or ~
+			end := 1
+			for end < len(keys) && (end < 2 || !(keys[end] >= 0x40 && keys[end] <= 0x7e)) {
+				end++
+			}
+			keys = keys[min(end+1, len(keys)):]
+			continue
+		case keys[0] == 3:
+			return true, nil, false
+		case keys[0] == '\r' || keys[0] == '\n':
+			if chosen := f.chosen(); len(chosen) > 0 {
+				return true, chosen, true
+			}
+		case keys[0] == 0x7f || keys[0] == 8:
+			if len(f.query) > 0 {
+				f.query = f.query[:len(f.query)-1]
+				f.filter()
+			}
+		case keys[0] == 0x15:
+			f.query = nil
+			f.filter()
+		case keys[0] == 0x10:
+			f.move(-1)
+		case keys[0] == 0x0e:
+			f.move(1)
+		case keys[0] == '\t' && f.multi && len(f.matches) > 0:
+			index := f.matches[f.cursor]
+			f.selected[index] = !f.selected[index]
+			f.move(1)
+		case keys[0] == 0x01 && f.multi:
+			// Ctrl+A marks every match, or unmarks them when all are marked
+			all := true
+			for _, index := range f.matches {
+				all = all && f.selected[index]
+			}
+			for _, index := range f.matches {
+				f.selected[index] = !all
+			}
+		case keys[0] >= 0x20:
+			r, size := utf8.DecodeRune(keys)
+			if r != utf8.RuneError {
+				f.query = append(f.query, r)
+				f.filter()
+			}
+			keys = keys[size:]
+			continue
+		}
+		keys = keys[1:]
+	}
+	return false, nil, false
+}
+
+// filter matches the items against the query, best first, keeping the
+// items' order among equal scores
+func (f *finder) filter() {
+	query := string(f.query)
+	scores := make(map[int]int)
+	f.matches = f.matches[:0]
+	for i, item := range f.items {
+		if score, ok := fuzzyScore(item, query); ok {
+			scores[i] = score
+			f.matches = append(f.matches, i)
+		}
+	}
+	sort.SliceStable(f.matches, func(a, b int) bool { return scores[f.matches[a]] > scores[f.matches[b]] })
+	f.cursor, f.offset = 0, 0
+}
+
+// move moves the highlight by delta matches, scrolling to keep it shown
+func (f *finder) move(delta int) {
+	if len(f.matches) == 0 {
+		return
+	}
+	f.cursor = max(0, min(len(f.matches)-1, f.cursor+delta))
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if f.cursor >= f.offset+finderRows {
+		f.offset = f.cursor - finderRows + 1
+	}
+}
+
+// chosen returns the marked items in their order, or the highlighted one
+func (f *finder) chosen() []int {
+	var chosen []int
+	for i := range f.items {
+		if f.selected[i] {
+			chosen = append(chosen, i)
+		}
+	}
+	if len(chosen) == 0 && len(f.matches) > 0 {
+		chosen = []int{f.matches[f.cursor]}
+	}
+	return chosen
+}
+
+// draw redraws the prompt and the shown matches in place. Rows are cut to
+// the terminal's width so none wraps and throws off the redraw.
+func (f *finder) draw() {
+	width := terminalWidth()
+	if width <= 0 {
+		width = 80
+	}
+
+	var b strings.Builder
+	b.WriteString("\r\x1b[J")
+	prompt := f.title + "> "
+	b.WriteString(qc.Colorize(prompt, qc.ColorYellow))
+	b.WriteString(string(f.query))
+
+	lines := 0
+	for row := f.offset; row < len(f.matches) && row < f.offset+finderRows; row++ {
+		index := f.matches[row]
+		prefix := "  "
+		if row == f.cursor {
+			prefix = "> "
+		}
+		if f.multi {
+			if f.selected[index] {
+				prefix += "[x] "
+			} else {
+				prefix += "[ ] "
+			}
+		}
+		text := truncate(prefix+f.items[index], width-1)
+		if row == f.cursor {
+			text = qc.ColorizeBold(text, qc.ColorCyan)
+		}
+		b.WriteString("\r\n" + text)
+		lines++
+	}
+
+	help := "↑/↓ to move, Enter to choose, Esc to cancel"
+	if f.multi {
+		help = "↑/↓ to move, Tab to mark, Ctrl+A to mark all, Enter to choose, Esc to cancel"
+	}
+	status := truncate(fmt.Sprintf("  %d/%d  %s", len(f.matches), len(f.items), help), width-1)
+	b.WriteString("\r\n" + qc.Colorize(status, qc.ColorBlue))
+	lines++
+
+	// Put the cursor back after the query
+	fmt.Fprintf(&b, "\x1b[%dA\r\x1b[%dC", lines, utf8.RuneCountInString(prompt)+len(f.query))
+	fmt.Print(b.String())
+}
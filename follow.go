@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// defaultFollowInterval is how often a followed run is polled
+const defaultFollowInterval = 10 * time.Second
+
+// jobState is what a job's transitions are tracked by
+func jobState(job Job) string {
+	if job.Conclusion != "" && job.Conclusion != job.Status {
+		return job.Status + "/" + job.Conclusion
+	}
+	return job.Status
+}
+
+// printTransition prints a timestamped line for a change while following
+func printTransition(name, from, to, color string) {
+	stamp := time.Now().In(displayLocation).Format("15:04:05")
+	change := to
+	if from != "" {
+		change = from + " → " + to
+	}
+	fmt.Printf("%s %s %s\n", qc.Colorize(stamp, qc.ColorCyan), name, qc.Colorize(change, color))
+}
+
+// followRun polls a run every interval, printing its jobs' transitions as
+// they happen, until it finishes. It returns the exit status the run
+// stands for: 0 when it succeeded, 1 otherwise, including when following
+// was cut short.
+func followRun(ctx context.Context, project Project, run WorkflowRun, interval time.Duration) int {
+	fmt.Printf("%s %s run %s of %s (polling every %s, Ctrl+C to stop)\n",
+		qc.Colorize("Following", qc.ColorBlue), run.Workflow, run.ID, qc.ColorizeBold(run.Project, qc.ColorGreen), interval)
+
+	runState := ""
+	jobStates := make(map[string]string)
+	for {
+		state := run.Status
+		if isRunFinished(run.Status) {
+			state = firstNonEmpty(run.Conclusion, run.Status)
+		}
+		if state != runState {
+			printTransition("run", runState, state, colorWorkflowStatus(run.Status, run.Conclusion))
+			runState = state
+		}
+
+		jobs, err := getJobsForRun(ctx, run)
+		if err != nil && ctx.Err() == nil {
+			fmt.Printf("%s Failed to get jobs: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		}
+		for _, job := range jobs {
+			state := jobState(job)
+			if previous, seen := jobStates[job.ID]; !seen || previous != state {
+				printTransition("  "+job.Name, previous, state, colorJobStatus(job.Status, job.Conclusion))
+				jobStates[job.ID] = state
+			}
+		}
+
+		if isRunFinished(run.Status) {
+			break
+		}
+		if sleepContext(ctx, interval) != nil {
+			return 1
+		}
+		latest, err := getWorkflowRun(ctx, project, run.ID)
+		if ctx.Err() != nil {
+			return 1
+		}
+		if err != nil {
+			fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+			continue
+		}
+		run = *latest
+	}
+
+	if firstNonEmpty(run.Conclusion, run.Status) != "success" {
+		fmt.Printf("%s Run %s finished with %s (%s)\n", qc.Colorize("Error:", qc.ColorRed), run.ID, firstNonEmpty(run.Conclusion, run.Status), runDuration(run))
+		return 1
+	}
+	fmt.Printf("%s Run %s succeeded (%s)\n", qc.Colorize("Success:", qc.ColorGreen), run.ID, runDuration(run))
+	return 0
+}
+
+// followRunByID follows a run given on the command line, polling every
+// refresh (default defaultFollowInterval), and exits with status 1 unless
+// it succeeded
+func followRunByID(ctx context.Context, config *Config, projectName, runID, refresh string) {
+	interval := defaultFollowInterval
+	if refresh != "" {
+		d, err := time.ParseDuration(refresh)
+		if err != nil || d <= 0 {
+			fmt.Printf("%s Invalid refresh interval: %s\n", qc.Colorize("Error:", qc.ColorRed), refresh)
+			os.Exit(1)
+		}
+		interval = d
+	}
+
+	project, runID, err := resolveRun(config, projectName, runID)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		os.Exit(1)
+	}
+	run, err := getWorkflowRun(ctx, *project, runID)
+	if err != nil {
+		fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		os.Exit(1)
+	}
+	if code := followRun(ctx, *project, *run, interval); code != 0 && ctx.Err() == nil {
+		os.Exit(code)
+	}
+}
+
+// promptFollow offers to follow an unfinished run shown in the details
+// view, returning its exit status, or -1 when it wasn't followed
+func promptFollow(ctx context.Context, config *Config, reader *bufio.Reader, run WorkflowRun) int {
+	project := findProject(config, run.Project)
+	if project == nil || isRunFinished(run.Status) {
+		return -1
+	}
+	fmt.Println()
+	if !confirm(reader, "Follow this run until it finishes?") {
+		return -1
+	}
+	return followRun(ctx, *project, run, defaultFollowInterval)
+}
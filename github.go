@@ -2,45 +2,64 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"math"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v62/github"
+	"golang.org/x/crypto/nacl/box"
 	"golang.org/x/oauth2"
 )
 
+const (
+	// dispatchPollInterval and dispatchPollAttempts bound how long to wait for
+	// a dispatched workflow run to become visible in the API
+	dispatchPollInterval = 3 * time.Second
+	dispatchPollAttempts = 10
+	// dispatchClockSkew widens the creation-time window used to match runs
+	dispatchClockSkew = 10 * time.Second
+)
+
 // GitHubClient wraps the GitHub API client
 type GitHubClient struct {
 	client *github.Client
 	ctx    context.Context
 }
 
-// NewGitHubClient creates a new GitHub client
-func NewGitHubClient() (*GitHubClient, error) {
-	ctx := context.Background()
-	
-	// Try to get token from stored auth config first
-	authConfig, err := loadAuthConfig()
-	var token string
-	if err == nil && authConfig.GitHubToken != "" {
-		token = authConfig.GitHubToken
-	} else {
-		// Fall back to environment variable
-		token = os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			return nil, fmt.Errorf("GitHub authentication required. Run 'quick_workflow login github' to authenticate")
-		}
+// NewGitHubClient creates a new GitHub client whose requests are cancelled
+// with ctx, logged in with the account of the named project, or the default
+// login when project is empty
+func NewGitHubClient(ctx context.Context, project string) (*GitHubClient, error) {
+	token, host, err := resolveGitHubCredentials(project)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create OAuth2 client
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(ctx, ts)
+	tc := newHTTPClient()
+	tc.Transport = &oauth2.Transport{Source: ts, Base: tc.Transport}
 
 	// Create GitHub client
 	client := github.NewClient(tc)
+	if host != "github.com" {
+		if client, err = client.WithEnterpriseURLs(githubAPIURL(host), "https://"+host+"/api/uploads/"); err != nil {
+			return nil, err
+		}
+	}
 
 	return &GitHubClient{
 		client: client,
@@ -48,105 +67,208 @@ func NewGitHubClient() (*GitHubClient, error) {
 	}, nil
 }
 
-// GetWorkflowRuns retrieves workflow runs for a repository
-func (g *GitHubClient) GetWorkflowRuns(owner, repo string, limit int) ([]WorkflowRun, error) {
-	runs, _, err := g.client.Actions.ListRepositoryWorkflowRuns(
-		g.ctx,
-		owner,
-		repo,
-		&github.ListWorkflowRunsOptions{
-			ListOptions: github.ListOptions{
-				PerPage: limit,
-			},
+// GetWorkflowRuns retrieves up to limit workflow runs for a repository,
+// newest first, optionally restricted to a single branch
+func (g *GitHubClient) GetWorkflowRuns(owner, repo, branch string, limit int) ([]WorkflowRun, error) {
+	opts := &github.ListWorkflowRunsOptions{
+		Branch: branch,
+		ListOptions: github.ListOptions{
+			PerPage: min(limit, maxPageSize),
 		},
-	)
-	if err != nil {
-		return nil, err
 	}
 
 	var workflowRuns []WorkflowRun
-	for _, run := range runs.WorkflowRuns {
-		workflowRun := WorkflowRun{
-			ID:         fmt.Sprintf("%d", run.GetID()),
-			Project:    fmt.Sprintf("%s/%s", owner, repo),
-			Workflow:   run.GetName(),
-			Status:     run.GetStatus(),
-			Conclusion: run.GetConclusion(),
-			CreatedAt:  run.GetCreatedAt().Time,
-			UpdatedAt:  run.GetUpdatedAt().Time,
-			URL:        run.GetHTMLURL(),
-			Platform:   "github",
-			Branch:     run.GetHeadBranch(),
-			Commit:     run.GetHeadSHA(),
-			TriggeredBy: run.GetTriggeringActor().GetLogin(),
+	for len(workflowRuns) < limit {
+		runs, resp, err := g.client.Actions.ListRepositoryWorkflowRuns(g.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs.WorkflowRuns {
+			workflowRuns = append(workflowRuns, convertGitHubRun(owner, repo, run))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(workflowRuns) > limit {
+		workflowRuns = workflowRuns[:limit]
+	}
+	return workflowRuns, nil
+}
+
+// GetWorkflowRunsForCommit retrieves up to limit workflow runs of a commit,
+// which may be given as an abbreviated SHA
+func (g *GitHubClient) GetWorkflowRunsForCommit(owner, repo, sha string, limit int) ([]WorkflowRun, error) {
+	if len(sha) < 40 {
+		full, err := g.ResolveCommit(owner, repo, sha)
+		if err != nil {
+			return nil, err
 		}
-		workflowRuns = append(workflowRuns, workflowRun)
+		sha = full
 	}
 
+	runs, _, err := g.client.Actions.ListRepositoryWorkflowRuns(g.ctx, owner, repo, &github.ListWorkflowRunsOptions{
+		HeadSHA:     sha,
+		ListOptions: github.ListOptions{PerPage: min(limit, maxPageSize)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var workflowRuns []WorkflowRun
+	for _, run := range runs.WorkflowRuns {
+		workflowRuns = append(workflowRuns, convertGitHubRun(owner, repo, run))
+	}
 	return workflowRuns, nil
 }
 
-// GetWorkflowJobs retrieves jobs for a specific workflow run
-func (g *GitHubClient) GetWorkflowJobs(owner, repo string, runID string) ([]Job, error) {
+// convertGitHubRun converts a GitHub workflow run to the unified format.
+// GitHub only lists pull requests whose head is in the same repository.
+func convertGitHubRun(owner, repo string, run *github.WorkflowRun) WorkflowRun {
+	workflowRun := WorkflowRun{
+		ID:            fmt.Sprintf("%d", run.GetID()),
+		Project:       fmt.Sprintf("%s/%s", owner, repo),
+		Workflow:      run.GetName(),
+		Status:        run.GetStatus(),
+		Conclusion:    run.GetConclusion(),
+		CreatedAt:     run.GetCreatedAt().Time,
+		UpdatedAt:     run.GetUpdatedAt().Time,
+		URL:           run.GetHTMLURL(),
+		Platform:      "github",
+		Branch:        run.GetHeadBranch(),
+		Commit:        run.GetHeadSHA(),
+		CommitMessage: run.GetHeadCommit().GetMessage(),
+		TriggeredBy:   run.GetTriggeringActor().GetLogin(),
+		Attempt:       run.GetRunAttempt(),
+		StartedAt:     run.GetRunStartedAt().Time,
+	}
+	if len(run.PullRequests) > 0 {
+		workflowRun.PullRequest = run.PullRequests[0].GetNumber()
+	}
+	return workflowRun
+}
+
+// GetWorkflowRun retrieves a single workflow run
+func (g *GitHubClient) GetWorkflowRun(owner, repo, runID string) (*WorkflowRun, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	run, _, err := g.client.Actions.GetWorkflowRunByID(g.ctx, owner, repo, runIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowRun := convertGitHubRun(owner, repo, run)
+	return &workflowRun, nil
+}
+
+// GetWorkflowJobs retrieves jobs for a specific workflow run. Attempts
+// above zero fetch the jobs of that attempt instead of the latest one, so a
+// re-run's jobs aren't mixed up with the attempt that failed.
+func (g *GitHubClient) GetWorkflowJobs(owner, repo string, runID string, attempt int) ([]Job, error) {
 	runIDInt, err := strconv.ParseInt(runID, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 	
-	jobs, _, err := g.client.Actions.ListWorkflowJobs(
-		g.ctx,
-		owner,
-		repo,
-		runIDInt,
-		&github.ListWorkflowJobsOptions{},
-	)
+	var jobs *github.Jobs
+	if attempt > 0 {
+		jobs, _, err = g.client.Actions.ListWorkflowJobsAttempt(g.ctx, owner, repo, runIDInt, int64(attempt), &github.ListOptions{PerPage: 100})
+	} else {
+		jobs, _, err = g.client.Actions.ListWorkflowJobs(
+			g.ctx,
+			owner,
+			repo,
+			runIDInt,
+			&github.ListWorkflowJobsOptions{},
+		)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	var jobList []Job
 	for _, job := range jobs.Jobs {
-		jobItem := Job{
-			ID:         fmt.Sprintf("%d", job.GetID()),
-			RunID:      fmt.Sprintf("%d", job.GetRunID()),
-			Name:       job.GetName(),
-			Status:     job.GetStatus(),
-			Conclusion: job.GetConclusion(),
-			URL:        job.GetHTMLURL(),
-		}
+		jobList = append(jobList, convertGitHubJob(job))
+	}
+
+	return jobList, nil
+}
 
-		// Add timing information
-		if job.StartedAt != nil {
-			startedAt := job.StartedAt.Time
-			jobItem.StartedAt = &startedAt
+// convertGitHubJob converts a GitHub workflow job with its steps
+func convertGitHubJob(job *github.WorkflowJob) Job {
+	jobItem := Job{
+		ID:         fmt.Sprintf("%d", job.GetID()),
+		RunID:      fmt.Sprintf("%d", job.GetRunID()),
+		Name:       job.GetName(),
+		Status:     job.GetStatus(),
+		Conclusion: job.GetConclusion(),
+		URL:        job.GetHTMLURL(),
+	}
+
+	if job.GetRunnerName() != "" || len(job.Labels) > 0 {
+		jobItem.Runner = &JobRunner{
+			Name:   job.GetRunnerName(),
+			Kind:   "github-hosted",
+			Labels: job.Labels,
+			Group:  job.GetRunnerGroupName(),
 		}
-		if job.CompletedAt != nil {
-			completedAt := job.CompletedAt.Time
-			jobItem.CompletedAt = &completedAt
+		if slices.Contains(job.Labels, "self-hosted") {
+			jobItem.Runner.Kind = "self-hosted"
 		}
+	}
 
-		// Add steps
-		for _, step := range job.Steps {
-			stepItem := Step{
-				Name:       step.GetName(),
-				Status:     step.GetStatus(),
-				Conclusion: step.GetConclusion(),
-			}
-			if step.StartedAt != nil {
-				startedAt := step.StartedAt.Time
-				stepItem.StartedAt = &startedAt
-			}
-			if step.CompletedAt != nil {
-				completedAt := step.CompletedAt.Time
-				stepItem.CompletedAt = &completedAt
-			}
-			jobItem.Steps = append(jobItem.Steps, stepItem)
+	// Add timing information
+	if job.CreatedAt != nil {
+		createdAt := job.CreatedAt.Time
+		jobItem.CreatedAt = &createdAt
+	}
+	if job.StartedAt != nil {
+		startedAt := job.StartedAt.Time
+		jobItem.StartedAt = &startedAt
+	}
+	if job.CompletedAt != nil {
+		completedAt := job.CompletedAt.Time
+		jobItem.CompletedAt = &completedAt
+	}
+
+	// Add steps
+	for _, step := range job.Steps {
+		stepItem := Step{
+			Name:       step.GetName(),
+			Status:     step.GetStatus(),
+			Conclusion: step.GetConclusion(),
+		}
+		if step.StartedAt != nil {
+			startedAt := step.StartedAt.Time
+			stepItem.StartedAt = &startedAt
+		}
+		if step.CompletedAt != nil {
+			completedAt := step.CompletedAt.Time
+			stepItem.CompletedAt = &completedAt
 		}
+		jobItem.Steps = append(jobItem.Steps, stepItem)
+	}
+	return jobItem
+}
 
-		jobList = append(jobList, jobItem)
+// GetWorkflowRunAttempt retrieves one attempt of a workflow run
+func (g *GitHubClient) GetWorkflowRunAttempt(owner, repo, runID string, attempt int) (*WorkflowRun, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, err
 	}
 
-	return jobList, nil
+	run, _, err := g.client.Actions.GetWorkflowRunAttempt(g.ctx, owner, repo, runIDInt, attempt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowRun := convertGitHubRun(owner, repo, run)
+	return &workflowRun, nil
 }
 
 // GetWorkflows retrieves available workflows for a repository
@@ -169,9 +291,1405 @@ func (g *GitHubClient) GetWorkflows(owner, repo string) ([]string, error) {
 	return workflowNames, nil
 }
 
-// TriggerWorkflow triggers a workflow dispatch
-func (g *GitHubClient) TriggerWorkflow(owner, repo, workflowID, ref string, inputs map[string]string) error {
-	// For now, we'll implement a simplified version that just returns an error
-	// indicating that workflow triggering is not yet implemented
-	return fmt.Errorf("workflow triggering not yet implemented for GitHub")
+// GetWorkflowStates lists the workflows of a repository with their state:
+// active, or disabled manually, for inactivity, or by a fork
+func (g *GitHubClient) GetWorkflowStates(owner, repo string) ([]WorkflowState, error) {
+	workflows, _, err := g.client.Actions.ListWorkflows(
+		g.ctx,
+		owner,
+		repo,
+		&github.ListOptions{PerPage: 100},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []WorkflowState
+	for _, workflow := range workflows.Workflows {
+		states = append(states, WorkflowState{
+			Name:  workflow.GetName(),
+			Path:  workflow.GetPath(),
+			State: workflow.GetState(),
+		})
+	}
+	return states, nil
+}
+
+// SetWorkflowEnabled enables or disables a workflow, found by name, path,
+// or file name. Disabled workflows don't run on any trigger.
+func (g *GitHubClient) SetWorkflowEnabled(owner, repo, workflow string, enabled bool) error {
+	wf, err := g.findWorkflow(owner, repo, workflow)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		_, err = g.client.Actions.EnableWorkflowByID(g.ctx, owner, repo, wf.GetID())
+	} else {
+		_, err = g.client.Actions.DisableWorkflowByID(g.ctx, owner, repo, wf.GetID())
+	}
+	return err
+}
+
+// GetDefaultBranch retrieves the default branch of a repository
+func (g *GitHubClient) GetDefaultBranch(owner, repo string) (string, error) {
+	repository, _, err := g.client.Repositories.Get(g.ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	return repository.GetDefaultBranch(), nil
+}
+
+// findWorkflow resolves a workflow by name, path, or file name
+func (g *GitHubClient) findWorkflow(owner, repo, workflow string) (*github.Workflow, error) {
+	workflows, _, err := g.client.Actions.ListWorkflows(
+		g.ctx,
+		owner,
+		repo,
+		&github.ListOptions{PerPage: 100},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wf := range workflows.Workflows {
+		if wf.GetName() == workflow || wf.GetPath() == workflow || path.Base(wf.GetPath()) == workflow {
+			return wf, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow not found: %s", workflow)
+}
+
+// DispatchEvent sends a repository_dispatch event, starting the workflows
+// listening for its event type with the payload as
+// github.event.client_payload
+func (g *GitHubClient) DispatchEvent(owner, repo, eventType string, payload map[string]any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(data)
+	_, _, err = g.client.Repositories.Dispatch(g.ctx, owner, repo, github.DispatchRequestOptions{
+		EventType:     eventType,
+		ClientPayload: &raw,
+	})
+	return err
+}
+
+// TriggerWorkflow triggers a workflow dispatch and waits briefly for the run
+// it created to appear. The returned run is nil if it could not be located
+// before the wait expired.
+func (g *GitHubClient) TriggerWorkflow(owner, repo, workflow, ref string, inputs map[string]string) (*WorkflowRun, error) {
+	wf, err := g.findWorkflow(owner, repo, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	event := github.CreateWorkflowDispatchEventRequest{Ref: ref}
+	if len(inputs) > 0 {
+		event.Inputs = make(map[string]interface{}, len(inputs))
+		for key, value := range inputs {
+			event.Inputs[key] = value
+		}
+	}
+
+	// Allow for clock skew between this machine and GitHub
+	dispatchedAt := time.Now().Add(-dispatchClockSkew)
+
+	if _, err := g.client.Actions.CreateWorkflowDispatchEventByID(g.ctx, owner, repo, wf.GetID(), event); err != nil {
+		return nil, err
+	}
+
+	return g.findDispatchedRun(owner, repo, wf.GetID(), ref, dispatchedAt)
+}
+
+// findDispatchedRun polls for the oldest workflow_dispatch run of a workflow
+// on ref created at or after since
+func (g *GitHubClient) findDispatchedRun(owner, repo string, workflowID int64, ref string, since time.Time) (*WorkflowRun, error) {
+	opts := &github.ListWorkflowRunsOptions{
+		Branch:      ref,
+		Event:       "workflow_dispatch",
+		Created:     ">=" + since.UTC().Format(time.RFC3339),
+		ListOptions: github.ListOptions{PerPage: 10},
+	}
+
+	for attempt := 0; attempt < dispatchPollAttempts; attempt++ {
+		if err := sleepContext(g.ctx, dispatchPollInterval); err != nil {
+			return nil, err
+		}
+
+		runs, _, err := g.client.Actions.ListWorkflowRunsByID(g.ctx, owner, repo, workflowID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var match *github.WorkflowRun
+		for _, run := range runs.WorkflowRuns {
+			if match == nil || run.GetCreatedAt().Before(match.GetCreatedAt().Time) {
+				match = run
+			}
+		}
+		if match != nil {
+			run := convertGitHubRun(owner, repo, match)
+			return &run, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CommentOnRun posts a comment about a workflow run to its pull request, or
+// to the head commit when the run is not associated with one. It returns the
+// URL of the created comment.
+func (g *GitHubClient) CommentOnRun(owner, repo, runID, body string) (string, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	run, _, err := g.client.Actions.GetWorkflowRunByID(g.ctx, owner, repo, runIDInt)
+	if err != nil {
+		return "", err
+	}
+
+	if len(run.PullRequests) > 0 {
+		comment, _, err := g.client.Issues.CreateComment(
+			g.ctx,
+			owner,
+			repo,
+			run.PullRequests[0].GetNumber(),
+			&github.IssueComment{Body: &body},
+		)
+		if err != nil {
+			return "", err
+		}
+		return comment.GetHTMLURL(), nil
+	}
+
+	comment, _, err := g.client.Repositories.CreateComment(
+		g.ctx,
+		owner,
+		repo,
+		run.GetHeadSHA(),
+		&github.RepositoryComment{Body: &body},
+	)
+	if err != nil {
+		return "", err
+	}
+	return comment.GetHTMLURL(), nil
+}
+
+// GetWorkflowFiles retrieves the workflow definitions in .github/workflows at
+// ref; an empty ref uses the default branch
+func (g *GitHubClient) GetWorkflowFiles(owner, repo, ref string) ([]WorkflowFile, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	_, entries, _, err := g.client.Repositories.GetContents(g.ctx, owner, repo, ".github/workflows", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []WorkflowFile
+	for _, entry := range entries {
+		name := entry.GetName()
+		if entry.GetType() != "file" || !(strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")) {
+			continue
+		}
+
+		file, _, _, err := g.client.Repositories.GetContents(g.ctx, owner, repo, entry.GetPath(), opts)
+		if err != nil {
+			return nil, err
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, WorkflowFile{Path: entry.GetPath(), Content: content})
+	}
+	return files, nil
+}
+
+// GetJobLog downloads the plain text log of a workflow job
+func (g *GitHubClient) GetJobLog(owner, repo, jobID string) (string, error) {
+	jobIDInt, err := strconv.ParseInt(jobID, 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	logURL, _, err := g.client.Actions.GetWorkflowJobLogs(g.ctx, owner, repo, jobIDInt, 3)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(g.ctx, http.MethodGet, logURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError("github", resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// maxTestArtifactSize skips artifacts too large to be test reports rather
+// than downloading build outputs
+const maxTestArtifactSize = 50 << 20
+
+// testArtifactPattern matches the names artifacts holding test reports are
+// usually given
+var testArtifactPattern = regexp.MustCompile(`(?i)test|junit|report|result`)
+
+// GetTestReports parses the JUnit XML files in a run's artifacts. Only
+// artifacts named like test reports are downloaded, unless artifact names
+// the one to read.
+func (g *GitHubClient) GetTestReports(owner, repo, runID, artifact string) ([]TestResult, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, _, err := g.client.Actions.ListWorkflowRunArtifacts(g.ctx, owner, repo, runIDInt, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TestResult
+	for _, a := range artifacts.Artifacts {
+		if a.GetExpired() || a.GetSizeInBytes() > maxTestArtifactSize {
+			continue
+		}
+		if artifact != "" && a.GetName() != artifact || artifact == "" && !testArtifactPattern.MatchString(a.GetName()) {
+			continue
+		}
+
+		data, err := g.downloadArtifact(owner, repo, a)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := parseJUnitArchive(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact %s: %v", a.GetName(), err)
+		}
+		results = append(results, found...)
+	}
+	return results, nil
+}
+
+// GetRunLogArchive downloads the zip archive of every log of a workflow
+// run, from the short-lived URL GitHub redirects to
+func (g *GitHubClient) GetRunLogArchive(owner, repo, runID string) ([]byte, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	archiveURL, _, err := g.client.Actions.GetWorkflowRunLogs(g.ctx, owner, repo, runIDInt, 3)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(g.ctx, http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError("github", resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// GetArtifactArchive downloads the zip archive of a run's artifact by name,
+// nil when the run has no such artifact
+func (g *GitHubClient) GetArtifactArchive(owner, repo, runID, name string) ([]byte, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, _, err := g.client.Actions.ListWorkflowRunArtifacts(g.ctx, owner, repo, runIDInt, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range artifacts.Artifacts {
+		if a.GetName() == name && !a.GetExpired() {
+			return g.downloadArtifact(owner, repo, a)
+		}
+	}
+	return nil, nil
+}
+
+// downloadArtifact downloads an artifact's zip archive from the short-lived
+// URL GitHub redirects to
+func (g *GitHubClient) downloadArtifact(owner, repo string, a *github.Artifact) ([]byte, error) {
+	archiveURL, _, err := g.client.Actions.DownloadArtifact(g.ctx, owner, repo, a.GetID(), 3)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(g.ctx, http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download artifact %s: %s", a.GetName(), resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// GetJobAnnotations retrieves the annotations of a workflow job. A job's ID
+// is also the ID of its check run.
+func (g *GitHubClient) GetJobAnnotations(owner, repo, jobID string) ([]Annotation, error) {
+	jobIDInt, err := strconv.ParseInt(jobID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, _, err := g.client.Checks.ListCheckRunAnnotations(
+		g.ctx,
+		owner,
+		repo,
+		jobIDInt,
+		&github.ListOptions{PerPage: 100},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Annotation
+	for _, annotation := range annotations {
+		result = append(result, Annotation{
+			Level:     annotation.GetAnnotationLevel(),
+			Path:      annotation.GetPath(),
+			StartLine: annotation.GetStartLine(),
+			Title:     annotation.GetTitle(),
+			Message:   annotation.GetMessage(),
+		})
+	}
+	return result, nil
+}
+
+// webhookEvents are the events sent to hooks created by quick_workflow
+var webhookEvents = []string{"workflow_run", "workflow_job"}
+
+// CreateWebhook adds a repository webhook for workflow events signed with
+// secret and returns its ID
+func (g *GitHubClient) CreateWebhook(owner, repo, url, secret string) (int64, error) {
+	hook, _, err := g.client.Repositories.CreateHook(g.ctx, owner, repo, &github.Hook{
+		Config: &github.HookConfig{
+			URL:         github.String(url),
+			ContentType: github.String("json"),
+			Secret:      github.String(secret),
+		},
+		Events: webhookEvents,
+		Active: github.Bool(true),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return hook.GetID(), nil
+}
+
+// UpdateWebhookSecret replaces the secret of a repository webhook. The URL
+// is resent because GitHub replaces the whole hook configuration.
+func (g *GitHubClient) UpdateWebhookSecret(owner, repo string, hookID int64, url, secret string) error {
+	_, _, err := g.client.Repositories.EditHook(g.ctx, owner, repo, hookID, &github.Hook{
+		Config: &github.HookConfig{
+			URL:         github.String(url),
+			ContentType: github.String("json"),
+			Secret:      github.String(secret),
+		},
+	})
+	return err
+}
+
+// DeleteWebhook removes a repository webhook
+func (g *GitHubClient) DeleteWebhook(owner, repo string, hookID int64) error {
+	_, err := g.client.Repositories.DeleteHook(g.ctx, owner, repo, hookID)
+	return err
+}
+
+// PingWebhook asks GitHub to send a ping event to a webhook
+func (g *GitHubClient) PingWebhook(owner, repo string, hookID int64) error {
+	_, err := g.client.Repositories.PingHook(g.ctx, owner, repo, hookID)
+	return err
+}
+
+// GetWebhookDeliveries retrieves the most recent deliveries of a webhook
+func (g *GitHubClient) GetWebhookDeliveries(owner, repo string, hookID int64, limit int) ([]WebhookDelivery, error) {
+	deliveries, _, err := g.client.Repositories.ListHookDeliveries(g.ctx, owner, repo, hookID, &github.ListCursorOptions{PerPage: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []WebhookDelivery
+	for _, delivery := range deliveries {
+		result = append(result, WebhookDelivery{
+			At:         delivery.GetDeliveredAt().Time,
+			Event:      delivery.GetEvent(),
+			StatusCode: delivery.GetStatusCode(),
+			Status:     delivery.GetStatus(),
+		})
+	}
+	return result, nil
+}
+
+// GetExternalChecks retrieves the commit statuses and check runs reported on
+// ref by systems other than GitHub Actions, whose runs are already listed
+func (g *GitHubClient) GetExternalChecks(owner, repo, ref string) ([]CommitCheck, error) {
+	return g.getCommitChecks(owner, repo, ref, false)
+}
+
+// getCommitChecks retrieves the commit statuses and check runs reported on
+// ref, including the jobs of GitHub Actions runs when includeActions is set
+func (g *GitHubClient) getCommitChecks(owner, repo, ref string, includeActions bool) ([]CommitCheck, error) {
+	combined, _, err := g.client.Repositories.GetCombinedStatus(g.ctx, owner, repo, ref, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []CommitCheck
+	for _, status := range combined.Statuses {
+		// Commit statuses use state for both progress and outcome
+		state := status.GetState()
+		check := CommitCheck{
+			Name:        status.GetContext(),
+			Source:      "status",
+			Status:      "completed",
+			Conclusion:  state,
+			Description: status.GetDescription(),
+			URL:         status.GetTargetURL(),
+			UpdatedAt:   status.GetUpdatedAt().Time,
+		}
+		if state == "pending" {
+			check.Status, check.Conclusion = "in_progress", ""
+		}
+		checks = append(checks, check)
+	}
+
+	results, _, err := g.client.Checks.ListCheckRunsForRef(g.ctx, owner, repo, ref, &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range results.CheckRuns {
+		if !includeActions && run.GetApp().GetSlug() == "github-actions" {
+			continue
+		}
+		check := CommitCheck{
+			Name:       run.GetName(),
+			Source:     "check",
+			Status:     run.GetStatus(),
+			Conclusion: run.GetConclusion(),
+			URL:        run.GetHTMLURL(),
+		}
+		if run.CompletedAt != nil {
+			check.UpdatedAt = run.GetCompletedAt().Time
+		} else if run.StartedAt != nil {
+			check.UpdatedAt = run.GetStartedAt().Time
+		}
+		if app := run.GetApp().GetName(); app != "" {
+			check.Description = app
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// GetMergeStatus retrieves the checks on a branch's head commit, marking
+// those that branch protection or rulesets of the target branch require.
+// The target is the base of the branch's open pull request, or the branch
+// itself when it has none.
+func (g *GitHubClient) GetMergeStatus(owner, repo, branch string) (*MergeStatus, error) {
+	head, _, err := g.client.Repositories.GetBranch(g.ctx, owner, repo, branch, 3)
+	if err != nil {
+		return nil, err
+	}
+	status := &MergeStatus{Branch: branch, Target: branch}
+
+	pulls, _, err := g.client.PullRequests.List(g.ctx, owner, repo, &github.PullRequestListOptions{
+		State:       "open",
+		Head:        owner + ":" + branch,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	target := head
+	if len(pulls) > 0 {
+		status.PullRequest = pulls[0].GetNumber()
+		status.Target = pulls[0].GetBase().GetRef()
+		if target, _, err = g.client.Repositories.GetBranch(g.ctx, owner, repo, status.Target, 3); err != nil {
+			return nil, err
+		}
+	}
+
+	required, err := g.getRequiredChecks(owner, repo, target)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := g.getCommitChecks(owner, repo, head.GetCommit().GetSHA(), true)
+	if err != nil {
+		return nil, err
+	}
+	status.setChecks(checks, required)
+	return status, nil
+}
+
+// GetPullStatus retrieves the checks of the open pull request into
+// owner/repo from head, a branch given as owner:branch of a fork, marking
+// those its base branch requires. Pull request CI from a fork runs in the
+// base repository, which lists it on the pull request's head commit.
+func (g *GitHubClient) GetPullStatus(owner, repo, head string) (*MergeStatus, error) {
+	pulls, _, err := g.client.PullRequests.List(g.ctx, owner, repo, &github.PullRequestListOptions{
+		State:       "open",
+		Head:        head,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, fmt.Errorf("no open pull request from %s into %s/%s", head, owner, repo)
+	}
+	pull := pulls[0]
+	status := &MergeStatus{Branch: head, Target: pull.GetBase().GetRef(), PullRequest: pull.GetNumber()}
+
+	target, _, err := g.client.Repositories.GetBranch(g.ctx, owner, repo, status.Target, 3)
+	if err != nil {
+		return nil, err
+	}
+	required, err := g.getRequiredChecks(owner, repo, target)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := g.getCommitChecks(owner, repo, pull.GetHead().GetSHA(), true)
+	if err != nil {
+		return nil, err
+	}
+	status.setChecks(checks, required)
+	return status, nil
+}
+
+// GetForkParent returns the repository a fork was made from, as owner and
+// name, failing when owner/repo isn't a fork
+func (g *GitHubClient) GetForkParent(owner, repo string) (string, string, error) {
+	repository, _, err := g.client.Repositories.Get(g.ctx, owner, repo)
+	if err != nil {
+		return "", "", err
+	}
+	parent := repository.GetParent()
+	if parent == nil {
+		return "", "", fmt.Errorf("%s/%s isn't a fork", owner, repo)
+	}
+	return parent.GetOwner().GetLogin(), parent.GetName(), nil
+}
+
+// getRequiredChecks collects the status checks a branch's protection and
+// the rulesets applying to it require
+func (g *GitHubClient) getRequiredChecks(owner, repo string, branch *github.Branch) ([]string, error) {
+	var required []string
+	if checks := branch.GetProtection().GetRequiredStatusChecks(); checks != nil {
+		if checks.Contexts != nil {
+			required = append(required, *checks.Contexts...)
+		}
+		if checks.Checks != nil {
+			for _, check := range *checks.Checks {
+				if !slices.Contains(required, check.Context) {
+					required = append(required, check.Context)
+				}
+			}
+		}
+	}
+
+	rules, _, err := g.client.Repositories.GetRulesForBranch(g.ctx, owner, repo, branch.GetName())
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Type != "required_status_checks" || rule.Parameters == nil {
+			continue
+		}
+		var params github.RequiredStatusChecksRuleParameters
+		if err := json.Unmarshal(*rule.Parameters, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse ruleset %d: %v", rule.RulesetID, err)
+		}
+		for _, check := range params.RequiredStatusChecks {
+			if !slices.Contains(required, check.Context) {
+				required = append(required, check.Context)
+			}
+		}
+	}
+	return required, nil
+}
+
+// githubRunURL matches the run ID in Actions URLs
+var githubRunURL = regexp.MustCompile(`/actions/runs/(\d+)`)
+
+// GetDeployments retrieves what each environment of a repository is
+// running. Only the most recent deployments of each environment are checked
+// for a successful one.
+func (g *GitHubClient) GetDeployments(owner, repo string) ([]Deployment, error) {
+	environments, _, err := g.client.Repositories.ListEnvironments(g.ctx, owner, repo, &github.EnvironmentListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Deployment
+	for _, environment := range environments.Environments {
+		deployments, _, err := g.client.Repositories.ListDeployments(g.ctx, owner, repo, &github.DeploymentsListOptions{
+			Environment: environment.GetName(),
+			ListOptions: github.ListOptions{PerPage: 10},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var current *Deployment
+		for _, deployment := range deployments {
+			statuses, _, err := g.client.Repositories.ListDeploymentStatuses(g.ctx, owner, repo, deployment.GetID(), &github.ListOptions{PerPage: 1})
+			if err != nil {
+				return nil, err
+			}
+
+			d := Deployment{
+				Environment: environment.GetName(),
+				Ref:         deployment.GetRef(),
+				SHA:         deployment.GetSHA(),
+				Status:      "pending",
+				Creator:     deployment.GetCreator().GetLogin(),
+				DeployedAt:  deployment.GetCreatedAt().Time,
+			}
+			if len(statuses) > 0 {
+				status := statuses[0]
+				d.Status = status.GetState()
+				d.URL = status.GetEnvironmentURL()
+				d.DeployedAt = status.GetCreatedAt().Time
+				if match := githubRunURL.FindStringSubmatch(status.GetLogURL() + " " + status.GetTargetURL()); match != nil {
+					d.RunID = match[1]
+				}
+			}
+
+			if current == nil {
+				current = &d
+			}
+			if d.Status == "success" {
+				current = &d
+				break
+			}
+		}
+		if current != nil {
+			result = append(result, *current)
+		}
+	}
+	return result, nil
+}
+
+// githubPendingDeployment is an entry of the pending deployments of a run,
+// which go-github can review but not list
+type githubPendingDeployment struct {
+	Environment struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"environment"`
+	WaitTimerStartedAt    *github.Timestamp `json:"wait_timer_started_at"`
+	CurrentUserCanApprove bool              `json:"current_user_can_approve"`
+	Reviewers             []struct {
+		Type     string `json:"type"`
+		Reviewer struct {
+			Login string `json:"login"`
+			Slug  string `json:"slug"`
+		} `json:"reviewer"`
+	} `json:"reviewers"`
+}
+
+// GetPendingDeployments retrieves deployments of waiting runs that need a
+// reviewer's approval
+func (g *GitHubClient) GetPendingDeployments(owner, repo string) ([]PendingDeployment, error) {
+	runs, _, err := g.client.Actions.ListRepositoryWorkflowRuns(g.ctx, owner, repo, &github.ListWorkflowRunsOptions{
+		Status:      "waiting",
+		ListOptions: github.ListOptions{PerPage: 50},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PendingDeployment
+	for _, run := range runs.WorkflowRuns {
+		req, err := g.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/actions/runs/%d/pending_deployments", owner, repo, run.GetID()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var pending []githubPendingDeployment
+		if _, err := g.client.Do(g.ctx, req, &pending); err != nil {
+			return nil, err
+		}
+
+		for _, p := range pending {
+			d := PendingDeployment{
+				Environment:   p.Environment.Name,
+				EnvironmentID: p.Environment.ID,
+				RunID:         strconv.FormatInt(run.GetID(), 10),
+				Ref:           run.GetHeadBranch(),
+				CanApprove:    p.CurrentUserCanApprove,
+				WaitingSince:  run.GetUpdatedAt().Time,
+			}
+			if p.WaitTimerStartedAt != nil {
+				d.WaitingSince = p.WaitTimerStartedAt.Time
+			}
+			for _, reviewer := range p.Reviewers {
+				name := reviewer.Reviewer.Login
+				if reviewer.Type == "Team" {
+					name = owner + "/" + reviewer.Reviewer.Slug
+				}
+				d.Reviewers = append(d.Reviewers, name)
+			}
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// ApprovePendingDeployment approves a run's deployment to an environment
+// that is waiting on a required reviewer
+func (g *GitHubClient) ApprovePendingDeployment(owner, repo, runID string, environmentID int64, comment string) error {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Actions.PendingDeployments(g.ctx, owner, repo, runIDInt, &github.PendingDeploymentsRequest{
+		EnvironmentIDs: []int64{environmentID},
+		State:          "approved",
+		Comment:        comment,
+	})
+	return err
+}
+
+// ResolveCommit returns the commit SHA a branch, tag, or SHA refers to
+func (g *GitHubClient) ResolveCommit(owner, repo, ref string) (string, error) {
+	sha, _, err := g.client.Repositories.GetCommitSHA1(g.ctx, owner, repo, ref, "")
+	if err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// GetTags retrieves the names of a repository's most recent tags
+func (g *GitHubClient) GetTags(owner, repo string) ([]string, error) {
+	tags, _, err := g.client.Repositories.ListTags(g.ctx, owner, repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.GetName())
+	}
+	return names, nil
+}
+
+// GetReleases retrieves up to limit of a repository's newest releases with
+// their assets. Repositories that publish no releases have their tags
+// listed instead.
+func (g *GitHubClient) GetReleases(owner, repo string, limit int) ([]Release, error) {
+	found, _, err := g.client.Repositories.ListReleases(g.ctx, owner, repo, &github.ListOptions{PerPage: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	for _, r := range found {
+		release := Release{
+			Tag:        r.GetTagName(),
+			Name:       r.GetName(),
+			CreatedAt:  r.GetCreatedAt().Time,
+			Draft:      r.GetDraft(),
+			Prerelease: r.GetPrerelease(),
+			URL:        r.GetHTMLURL(),
+		}
+		if r.PublishedAt != nil {
+			release.CreatedAt = r.GetPublishedAt().Time
+		}
+		for _, asset := range r.Assets {
+			release.Assets = append(release.Assets, ReleaseAsset{
+				Name:      asset.GetName(),
+				Size:      asset.GetSize(),
+				Downloads: asset.GetDownloadCount(),
+			})
+		}
+		releases = append(releases, release)
+	}
+	if len(releases) > 0 {
+		return releases, nil
+	}
+
+	tags, _, err := g.client.Repositories.ListTags(g.ctx, owner, repo, &github.ListOptions{PerPage: limit})
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		releases = append(releases, Release{Tag: tag.GetName(), Commit: tag.GetCommit().GetSHA()})
+	}
+	return releases, nil
+}
+
+// OpenPullRequest commits updated files to a new branch cut from the
+// default branch and opens a pull request for it
+func (g *GitHubClient) OpenPullRequest(owner, repo, branch, title, body string, files []WorkflowFile) (string, error) {
+	base, err := g.GetDefaultBranch(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	baseRef, _, err := g.client.Git.GetRef(g.ctx, owner, repo, "refs/heads/"+base)
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := g.client.Git.CreateRef(g.ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: baseRef.Object.SHA},
+	}); err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	for _, file := range files {
+		current, _, _, err := g.client.Repositories.GetContents(g.ctx, owner, repo, file.Path, &github.RepositoryContentGetOptions{Ref: branch})
+		if err != nil {
+			return "", err
+		}
+		if _, _, err := g.client.Repositories.UpdateFile(g.ctx, owner, repo, file.Path, &github.RepositoryContentFileOptions{
+			Message: github.String(title),
+			Content: []byte(file.Content),
+			SHA:     current.SHA,
+			Branch:  github.String(branch),
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	pr, _, err := g.client.PullRequests.Create(g.ctx, owner, repo, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(branch),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return "", err
+	}
+	return pr.GetHTMLURL(), nil
+}
+
+// GetRepoAccess reports the token's role in a repository. The role comes
+// from the repository's permissions for the token's user; classic tokens
+// also report their OAuth scopes.
+func (g *GitHubClient) GetRepoAccess(owner, repo string) (RepoAccess, error) {
+	repository, resp, err := g.client.Repositories.Get(g.ctx, owner, repo)
+	if err != nil {
+		return RepoAccess{}, err
+	}
+
+	access := RepoAccess{Role: "read"}
+	permissions := repository.Permissions
+	switch {
+	case permissions["admin"]:
+		access.Role = "admin"
+	case permissions["maintain"]:
+		access.Role = "maintain"
+	case permissions["push"]:
+		access.Role = "write"
+	case permissions["triage"]:
+		access.Role = "triage"
+	}
+	access.CanTrigger = permissions["push"]
+	access.CanComment = permissions["push"] || !repository.GetPrivate()
+	access.CanManageHooks = permissions["admin"]
+
+	if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+		access.Scopes = splitList(scopes)
+		fullRepo := slices.Contains(access.Scopes, "repo")
+		if !fullRepo && !(slices.Contains(access.Scopes, "public_repo") && !repository.GetPrivate()) {
+			access.CanTrigger, access.CanComment = false, false
+		}
+		// The repo scope includes webhooks
+		if !fullRepo && !slices.Contains(access.Scopes, "admin:repo_hook") && !slices.Contains(access.Scopes, "write:repo_hook") {
+			access.CanManageHooks = false
+		}
+	}
+	return access, nil
+}
+
+// GetRunners lists the self-hosted runners registered to a repository
+func (g *GitHubClient) GetRunners(owner, repo string) ([]SelfHostedRunner, error) {
+	runners, _, err := g.client.Actions.ListRunners(g.ctx, owner, repo, &github.ListRunnersOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, err
+	}
+	return convertGitHubRunners(runners.Runners, "repo "+owner+"/"+repo), nil
+}
+
+// GetOrganizationRunners lists the self-hosted runners registered to an
+// organization. This needs an organization admin token.
+func (g *GitHubClient) GetOrganizationRunners(org string) ([]SelfHostedRunner, error) {
+	runners, _, err := g.client.Actions.ListOrganizationRunners(g.ctx, org, &github.ListRunnersOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, err
+	}
+	return convertGitHubRunners(runners.Runners, "org "+org), nil
+}
+
+func convertGitHubRunners(runners []*github.Runner, scope string) []SelfHostedRunner {
+	var converted []SelfHostedRunner
+	for _, runner := range runners {
+		item := SelfHostedRunner{
+			Platform: "github",
+			ID:       fmt.Sprintf("%d", runner.GetID()),
+			Name:     runner.GetName(),
+			Scope:    scope,
+			OS:       runner.GetOS(),
+			Status:   runner.GetStatus(),
+			Busy:     runner.GetBusy(),
+		}
+		for _, label := range runner.Labels {
+			item.Labels = append(item.Labels, label.GetName())
+		}
+		converted = append(converted, item)
+	}
+	return converted
+}
+
+// maxUsageRuns caps the runs whose usage is fetched for one repository and
+// month, since each needs its own request
+const maxUsageRuns = 500
+
+// GetRunUsage retrieves the billable time of the runs created in a month.
+// GitHub bills each job rounded up to the minute, so minutes are summed per
+// job. Runs on public repositories and self-hosted runners aren't billed
+// and report no minutes.
+func (g *GitHubClient) GetRunUsage(owner, repo string, month time.Time) ([]RunUsage, error) {
+	end := month.AddDate(0, 1, -1)
+	opts := &github.ListWorkflowRunsOptions{
+		Created:     month.Format("2006-01-02") + ".." + end.Format("2006-01-02"),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var usage []RunUsage
+	for len(usage) < maxUsageRuns {
+		runs, resp, err := g.client.Actions.ListRepositoryWorkflowRuns(g.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs.WorkflowRuns {
+			runUsage, _, err := g.client.Actions.GetWorkflowRunUsageByID(g.ctx, owner, repo, run.GetID())
+			if err != nil {
+				return nil, err
+			}
+			item := RunUsage{
+				RunID:    strconv.FormatInt(run.GetID(), 10),
+				Workflow: run.GetName(),
+				Minutes:  make(map[string]float64),
+			}
+			if runUsage.Billable != nil {
+				for machine, bill := range *runUsage.Billable {
+					if len(bill.JobRuns) == 0 {
+						item.Minutes[machine] += math.Ceil(float64(bill.GetTotalMS()) / 60000)
+					}
+					for _, job := range bill.JobRuns {
+						item.Minutes[machine] += math.Ceil(float64(job.GetDurationMS()) / 60000)
+					}
+				}
+			}
+			usage = append(usage, item)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return usage, nil
+}
+
+// GetActionsBilling retrieves the Actions minutes of an organization, or of
+// a user account when owner is not an organization, for the current billing
+// cycle. Both need a token allowed to read the account's billing.
+func (g *GitHubClient) GetActionsBilling(owner string) (*BillingSummary, error) {
+	billing, _, err := g.client.Billing.GetActionsBillingOrg(g.ctx, owner)
+	if err != nil {
+		var userErr error
+		if billing, _, userErr = g.client.Billing.GetActionsBillingUser(g.ctx, owner); userErr != nil {
+			return nil, err
+		}
+	}
+	return &BillingSummary{
+		Account:         owner,
+		MinutesUsed:     billing.TotalMinutesUsed,
+		PaidMinutesUsed: billing.TotalPaidMinutesUsed,
+		IncludedMinutes: billing.IncludedMinutes,
+		Breakdown:       billing.MinutesUsedBreakdown,
+	}, nil
+}
+
+// GetSecrets lists the Actions secrets of a repository; values are never
+// returned by GitHub
+func (g *GitHubClient) GetSecrets(owner, repo string) ([]CIVariable, error) {
+	var result []CIVariable
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		secrets, resp, err := g.client.Actions.ListRepoSecrets(g.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range secrets.Secrets {
+			result = append(result, CIVariable{Name: secret.Name, Masked: true, UpdatedAt: secret.UpdatedAt.Time})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// SetSecret creates or replaces an Actions secret. GitHub only accepts
+// values sealed with the repository's public key, so the plain value never
+// leaves this process.
+func (g *GitHubClient) SetSecret(owner, repo, name, value string) error {
+	key, _, err := g.client.Actions.GetRepoPublicKey(g.ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key.GetKey())
+	if err != nil || len(decoded) != 32 {
+		return fmt.Errorf("invalid public key for %s/%s", owner, repo)
+	}
+	var publicKey [32]byte
+	copy(publicKey[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &publicKey, rand.Reader)
+	if err != nil {
+		return err
+	}
+	_, err = g.client.Actions.CreateOrUpdateRepoSecret(g.ctx, owner, repo, &github.EncryptedSecret{
+		Name:           name,
+		KeyID:          key.GetKeyID(),
+		EncryptedValue: base64.StdEncoding.EncodeToString(sealed),
+	})
+	return err
+}
+
+// DeleteSecret removes an Actions secret
+func (g *GitHubClient) DeleteSecret(owner, repo, name string) error {
+	_, err := g.client.Actions.DeleteRepoSecret(g.ctx, owner, repo, name)
+	return err
+}
+
+// GetVariables lists the Actions variables of a repository with their values
+func (g *GitHubClient) GetVariables(owner, repo string) ([]CIVariable, error) {
+	var result []CIVariable
+	opts := &github.ListOptions{PerPage: 30}
+	for {
+		variables, resp, err := g.client.Actions.ListRepoVariables(g.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, variable := range variables.Variables {
+			result = append(result, CIVariable{Name: variable.Name, Value: variable.Value, UpdatedAt: variable.GetUpdatedAt().Time})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// SetVariable updates an Actions variable, creating it when it doesn't exist
+func (g *GitHubClient) SetVariable(owner, repo, name, value string) error {
+	variable := &github.ActionsVariable{Name: name, Value: value}
+	resp, err := g.client.Actions.UpdateRepoVariable(g.ctx, owner, repo, variable)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		_, err = g.client.Actions.CreateRepoVariable(g.ctx, owner, repo, variable)
+	}
+	return err
+}
+
+// DeleteVariable removes an Actions variable
+func (g *GitHubClient) DeleteVariable(owner, repo, name string) error {
+	_, err := g.client.Actions.DeleteRepoVariable(g.ctx, owner, repo, name)
+	return err
+}
+
+// GetRunDefinition retrieves the workflow file a run executed at the commit
+// it ran from. pull_request_target runs take their workflow from the base
+// branch rather than the pull request.
+func (g *GitHubClient) GetRunDefinition(owner, repo, runID string) (*RunDefinition, error) {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	run, _, err := g.client.Actions.GetWorkflowRunByID(g.ctx, owner, repo, runIDInt)
+	if err != nil {
+		return nil, err
+	}
+	workflow, _, err := g.client.Actions.GetWorkflowByID(g.ctx, owner, repo, run.GetWorkflowID())
+	if err != nil {
+		return nil, err
+	}
+
+	commit := run.GetHeadSHA()
+	if run.GetEvent() == "pull_request_target" && len(run.PullRequests) > 0 {
+		commit = run.PullRequests[0].GetBase().GetSHA()
+	}
+	file, _, _, err := g.client.Repositories.GetContents(g.ctx, owner, repo, workflow.GetPath(), &github.RepositoryContentGetOptions{Ref: commit})
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is not a file", workflow.GetPath())
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+
+	definition := &RunDefinition{File: WorkflowFile{Path: workflow.GetPath(), Content: content}, Commit: commit}
+	for _, referenced := range run.ReferencedWorkflows {
+		definition.Reusable = append(definition.Reusable, fmt.Sprintf("%s (%s)", referenced.GetPath(), shortSHA(referenced.GetSHA())))
+	}
+	return definition, nil
+}
+
+// CancelWorkflowRun cancels a queued or in-progress workflow run
+func (g *GitHubClient) CancelWorkflowRun(owner, repo, runID string) error {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = g.client.Actions.CancelWorkflowRunByID(g.ctx, owner, repo, runIDInt)
+	return err
+}
+
+// RerunWorkflowRun starts a new attempt of a finished workflow run, of only
+// its failed jobs and their dependents when failedOnly is set
+func (g *GitHubClient) RerunWorkflowRun(owner, repo, runID string, failedOnly bool) error {
+	runIDInt, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return err
+	}
+	if failedOnly {
+		_, err = g.client.Actions.RerunFailedJobsByID(g.ctx, owner, repo, runIDInt)
+	} else {
+		_, err = g.client.Actions.RerunWorkflowByID(g.ctx, owner, repo, runIDInt)
+	}
+	return err
+}
+
+// GetRateLimits retrieves the remaining core, search, and GraphQL quota.
+// Checking doesn't count against the limit.
+func (g *GitHubClient) GetRateLimits() ([]RateQuota, error) {
+	limits, _, err := g.client.RateLimit.Get(g.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []struct {
+		name string
+		rate *github.Rate
+	}{
+		{"core", limits.Core},
+		{"search", limits.Search},
+		{"graphql", limits.GraphQL},
+	}
+	var quotas []RateQuota
+	for _, resource := range resources {
+		if resource.rate == nil {
+			continue
+		}
+		quotas = append(quotas, RateQuota{
+			Platform:  "github",
+			Resource:  resource.name,
+			Limit:     resource.rate.Limit,
+			Remaining: resource.rate.Remaining,
+			Reset:     resource.rate.Reset.Time,
+		})
+	}
+	return quotas, nil
+}
+
+const (
+	// graphQLBatchSize is how many repositories one GraphQL request asks for
+	graphQLBatchSize = 25
+	// graphQLBranches is how many recently committed branches of each
+	// repository have their head commit's runs fetched
+	graphQLBranches = 10
+)
+
+// graphQLRunsFragment selects the workflow runs on the head commits of a
+// repository's most recently committed branches. GraphQL has no list of a
+// repository's runs, so runs are found through the check suites of commits.
+const graphQLRunsFragment = `
+fragment runs on Repository {
+  refs(refPrefix: "refs/heads/", first: %d, orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) {
+    nodes {
+      name
+      target {
+        ... on Commit {
+          oid
+          message
+          associatedPullRequests(first: 1) { nodes { number } }
+          checkSuites(first: 20) {
+            nodes {
+              status
+              conclusion
+              creator { login }
+              workflowRun {
+                databaseId
+                url
+                createdAt
+                updatedAt
+                workflow { name }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type graphQLRepository struct {
+	Refs struct {
+		Nodes []struct {
+			Name   string `json:"name"`
+			Target struct {
+				OID                    string `json:"oid"`
+				Message                string `json:"message"`
+				AssociatedPullRequests struct {
+					Nodes []struct {
+						Number int `json:"number"`
+					} `json:"nodes"`
+				} `json:"associatedPullRequests"`
+				CheckSuites struct {
+					Nodes []struct {
+						Status     string `json:"status"`
+						Conclusion string `json:"conclusion"`
+						Creator    *struct {
+							Login string `json:"login"`
+						} `json:"creator"`
+						WorkflowRun *struct {
+							DatabaseID int64     `json:"databaseId"`
+							URL        string    `json:"url"`
+							CreatedAt  time.Time `json:"createdAt"`
+							UpdatedAt  time.Time `json:"updatedAt"`
+							Workflow   struct {
+								Name string `json:"name"`
+							} `json:"workflow"`
+						} `json:"workflowRun"`
+					} `json:"nodes"`
+				} `json:"checkSuites"`
+			} `json:"target"`
+		} `json:"nodes"`
+	} `json:"refs"`
+}
+
+// GetLatestRunsBatch retrieves the latest runs of many repositories with a
+// GraphQL request per graphQLBatchSize repositories, keyed by owner/repo.
+// Runs come from the head commits of recently committed branches and lack
+// the attempt and start time, which need GetWorkflowRun. Repositories
+// GraphQL couldn't read are left out, for the caller to fetch over REST.
+func (g *GitHubClient) GetLatestRunsBatch(projects []Project, limit int) (map[string][]WorkflowRun, error) {
+	found := make(map[string][]WorkflowRun)
+	for start := 0; start < len(projects); start += graphQLBatchSize {
+		batch := projects[start:min(start+graphQLBatchSize, len(projects))]
+
+		var params, fields []string
+		variables := make(map[string]any)
+		for i, project := range batch {
+			params = append(params, fmt.Sprintf("$o%d: String!, $n%d: String!", i, i))
+			fields = append(fields, fmt.Sprintf("r%d: repository(owner: $o%d, name: $n%d) { ...runs }", i, i, i))
+			variables[fmt.Sprintf("o%d", i)] = project.Owner
+			variables[fmt.Sprintf("n%d", i)] = project.Repo
+		}
+		query := fmt.Sprintf("query(%s) {\n%s\n}\n", strings.Join(params, ", "), strings.Join(fields, "\n")) +
+			fmt.Sprintf(graphQLRunsFragment, min(graphQLBranches, limit))
+
+		req, err := g.client.NewRequest(http.MethodPost, "graphql", map[string]any{"query": query, "variables": variables})
+		if err != nil {
+			return nil, err
+		}
+		// Missing repositories come back as errors next to the data of
+		// the others, so only the data is used
+		var resp struct {
+			Data map[string]*graphQLRepository `json:"data"`
+		}
+		if _, err := g.client.Do(g.ctx, req, &resp); err != nil {
+			return nil, err
+		}
+
+		for i, project := range batch {
+			repository := resp.Data[fmt.Sprintf("r%d", i)]
+			if repository == nil {
+				continue
+			}
+			runs := convertGraphQLRuns(project.Owner, project.Repo, repository)
+			sort.Slice(runs, func(a, b int) bool {
+				return runs[a].CreatedAt.After(runs[b].CreatedAt)
+			})
+			if len(runs) > limit {
+				runs = runs[:limit]
+			}
+			found[project.Owner+"/"+project.Repo] = runs
+		}
+	}
+	return found, nil
+}
+
+// convertGraphQLRuns converts the workflow runs of a GraphQL repository to
+// the unified format, with statuses lowercased as REST returns them
+func convertGraphQLRuns(owner, repo string, repository *graphQLRepository) []WorkflowRun {
+	var runs []WorkflowRun
+	for _, ref := range repository.Refs.Nodes {
+		for _, suite := range ref.Target.CheckSuites.Nodes {
+			if suite.WorkflowRun == nil {
+				continue
+			}
+			run := WorkflowRun{
+				ID:            strconv.FormatInt(suite.WorkflowRun.DatabaseID, 10),
+				Project:       fmt.Sprintf("%s/%s", owner, repo),
+				Workflow:      suite.WorkflowRun.Workflow.Name,
+				Status:        strings.ToLower(suite.Status),
+				Conclusion:    strings.ToLower(suite.Conclusion),
+				CreatedAt:     suite.WorkflowRun.CreatedAt,
+				UpdatedAt:     suite.WorkflowRun.UpdatedAt,
+				URL:           suite.WorkflowRun.URL,
+				Platform:      "github",
+				Branch:        ref.Name,
+				Commit:        ref.Target.OID,
+				StartedAt:     suite.WorkflowRun.CreatedAt,
+				CommitMessage: ref.Target.Message,
+			}
+			if pulls := ref.Target.AssociatedPullRequests.Nodes; len(pulls) > 0 {
+				run.PullRequest = pulls[0].Number
+			}
+			if suite.Creator != nil {
+				run.TriggeredBy = suite.Creator.Login
+			}
+			runs = append(runs, run)
+		}
+	}
+	return runs
 }
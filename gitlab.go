@@ -1,10 +1,18 @@
 package main
 
 import (
+	"archive/zip"
+	"bufio"
 	"context"
 	"fmt"
-	"os"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/xanzy/go-gitlab"
 )
@@ -15,34 +23,21 @@ type GitLabClient struct {
 	ctx    context.Context
 }
 
-// NewGitLabClient creates a new GitLab client
-func NewGitLabClient() (*GitLabClient, error) {
-	ctx := context.Background()
-	
-	// Try to get token from stored auth config first
-	authConfig, err := loadAuthConfig()
-	var token string
-	var host string
-	if err == nil && authConfig.GitLabToken != "" {
-		token = authConfig.GitLabToken
-		host = authConfig.GitLabHost
-		if host == "" {
-			host = "gitlab.com"
-		}
-	} else {
-		// Fall back to environment variable
-		token = os.Getenv("GITLAB_TOKEN")
-		host = os.Getenv("GITLAB_HOST")
-		if host == "" {
-			host = "gitlab.com"
-		}
-		if token == "" {
-			return nil, fmt.Errorf("GitLab authentication required. Run 'quick_workflow login gitlab' to authenticate")
-		}
+// NewGitLabClient creates a new GitLab client logged in with the account
+// of the named project, or the default login when project is empty
+func NewGitLabClient(ctx context.Context, project string) (*GitLabClient, error) {
+	token, host, err := resolveGitLabCredentials(project)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create GitLab client with host
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(fmt.Sprintf("https://%s/api/v4", host)))
+	client, err := gitlab.NewClient(token,
+		gitlab.WithBaseURL(fmt.Sprintf("https://%s/api/v4", host)),
+		gitlab.WithHTTPClient(newHTTPClient()),
+		// Every request is cancelled with ctx
+		gitlab.WithRequestOptions(gitlab.WithContext(ctx)),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -53,42 +48,152 @@ func NewGitLabClient() (*GitLabClient, error) {
 	}, nil
 }
 
-// GetPipelineRuns retrieves pipeline runs for a project
-func (g *GitLabClient) GetPipelineRuns(projectID string, limit int) ([]WorkflowRun, error) {
-	pipelines, _, err := g.client.Pipelines.ListProjectPipelines(
-		projectID,
-		&gitlab.ListProjectPipelinesOptions{
-			ListOptions: gitlab.ListOptions{
-				PerPage: limit,
-			},
+// GetPipelineRuns retrieves up to limit pipeline runs for a project, newest
+// first, optionally restricted to a single ref
+func (g *GitLabClient) GetPipelineRuns(projectID, ref string, limit int) ([]WorkflowRun, error) {
+	opts := &gitlab.ListProjectPipelinesOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: min(limit, maxPageSize),
 		},
-	)
+	}
+	if ref != "" {
+		opts.Ref = &ref
+	}
+
+	var pipelines []*gitlab.PipelineInfo
+	for len(pipelines) < limit {
+		page, resp, err := g.client.Pipelines.ListProjectPipelines(projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if len(pipelines) > limit {
+		pipelines = pipelines[:limit]
+	}
+
+	var workflowRuns []WorkflowRun
+	for _, pipeline := range pipelines {
+		workflowRuns = append(workflowRuns, convertGitLabPipelineInfo(projectID, pipeline))
+	}
+
+	return workflowRuns, nil
+}
+
+// convertGitLabPipelineInfo converts a listed GitLab pipeline to the
+// unified format
+func convertGitLabPipelineInfo(projectID string, pipeline *gitlab.PipelineInfo) WorkflowRun {
+	return WorkflowRun{
+		ID:          fmt.Sprintf("%d", pipeline.ID),
+		Project:     projectID,
+		Workflow:    pipeline.Ref,
+		Status:      string(pipeline.Status),
+		Conclusion:  string(pipeline.Status), // GitLab uses status for both
+		CreatedAt:   *pipeline.CreatedAt,
+		UpdatedAt:   *pipeline.UpdatedAt,
+		URL:         pipeline.WebURL,
+		Platform:    "gitlab",
+		Branch:      pipeline.Ref,
+		Commit:      pipeline.SHA,
+		TriggeredBy: "system", // GitLab doesn't always have user info
+		PullRequest: mergeRequestIID(pipeline.Ref),
+	}
+}
+
+// mergeRequestIID returns the merge request of a merge request pipeline
+// from its refs/merge-requests/<iid>/head ref, or 0 for other pipelines
+func mergeRequestIID(ref string) int {
+	rest, ok := strings.CutPrefix(ref, "refs/merge-requests/")
+	if !ok {
+		return 0
+	}
+	iid, _, _ := strings.Cut(rest, "/")
+	n, _ := strconv.Atoi(iid)
+	return n
+}
+
+// GetPipelineRunsForCommit retrieves up to limit pipeline runs of a commit,
+// which may be given as an abbreviated SHA
+func (g *GitLabClient) GetPipelineRunsForCommit(projectID, sha string, limit int) ([]WorkflowRun, error) {
+	commit, _, err := g.client.Commits.GetCommit(projectID, sha, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	pipelines, _, err := g.client.Pipelines.ListProjectPipelines(projectID, &gitlab.ListProjectPipelinesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: min(limit, maxPageSize)},
+		SHA:         gitlab.Ptr(commit.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
 	var workflowRuns []WorkflowRun
 	for _, pipeline := range pipelines {
-		workflowRun := WorkflowRun{
-			ID:         fmt.Sprintf("%d", pipeline.ID),
-			Project:    projectID,
-			Workflow:   pipeline.Ref,
-			Status:     string(pipeline.Status),
-			Conclusion: string(pipeline.Status), // GitLab uses status for both
-			CreatedAt:  *pipeline.CreatedAt,
-			UpdatedAt:  *pipeline.UpdatedAt,
-			URL:        pipeline.WebURL,
-			Platform:   "gitlab",
-			Branch:     pipeline.Ref,
-			Commit:     pipeline.SHA,
-			TriggeredBy: "system", // GitLab doesn't always have user info
-		}
-		workflowRuns = append(workflowRuns, workflowRun)
+		run := convertGitLabPipelineInfo(projectID, pipeline)
+		run.CommitMessage = commit.Message
+		workflowRuns = append(workflowRuns, run)
 	}
-
 	return workflowRuns, nil
 }
 
+// GetCommitMessage retrieves the full message of a commit
+func (g *GitLabClient) GetCommitMessage(projectID, sha string) (string, error) {
+	commit, _, err := g.client.Commits.GetCommit(projectID, sha, nil)
+	if err != nil {
+		return "", err
+	}
+	return commit.Message, nil
+}
+
+// GetPipelineRun retrieves a single pipeline
+func (g *GitLabClient) GetPipelineRun(projectID, pipelineID string) (*WorkflowRun, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, _, err := g.client.Pipelines.GetPipeline(projectID, pipelineIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	run := convertGitLabPipeline(projectID, pipeline)
+	return &run, nil
+}
+
+// convertGitLabPipeline converts a full GitLab pipeline to the unified format
+func convertGitLabPipeline(projectID string, pipeline *gitlab.Pipeline) WorkflowRun {
+	run := WorkflowRun{
+		ID:          fmt.Sprintf("%d", pipeline.ID),
+		Project:     projectID,
+		Workflow:    pipeline.Ref,
+		Status:      pipeline.Status,
+		Conclusion:  pipeline.Status,
+		URL:         pipeline.WebURL,
+		Platform:    "gitlab",
+		Branch:      pipeline.Ref,
+		Commit:      pipeline.SHA,
+		PullRequest: mergeRequestIID(pipeline.Ref),
+	}
+	if pipeline.CreatedAt != nil {
+		run.CreatedAt = *pipeline.CreatedAt
+	}
+	if pipeline.UpdatedAt != nil {
+		run.UpdatedAt = *pipeline.UpdatedAt
+	}
+	if pipeline.StartedAt != nil {
+		run.StartedAt = *pipeline.StartedAt
+	}
+	if pipeline.User != nil {
+		run.TriggeredBy = pipeline.User.Username
+	}
+	return run
+}
+
 // GetPipelineJobs retrieves jobs for a specific pipeline
 func (g *GitLabClient) GetPipelineJobs(projectID string, pipelineID string) ([]Job, error) {
 	pipelineIDInt, err := strconv.Atoi(pipelineID)
@@ -115,8 +220,15 @@ func (g *GitLabClient) GetPipelineJobs(projectID string, pipelineID string) ([]J
 			Conclusion: string(job.Status),
 			URL:        job.WebURL,
 		}
+		if job.Runner.ID != 0 || len(job.TagList) > 0 {
+			jobItem.Runner = gitlabJobRunner(job)
+		}
 
 		// Add timing information
+		if job.CreatedAt != nil {
+			createdAt := *job.CreatedAt
+			jobItem.CreatedAt = &createdAt
+		}
 		if job.StartedAt != nil {
 			startedAt := *job.StartedAt
 			jobItem.StartedAt = &startedAt
@@ -169,8 +281,9 @@ func (g *GitLabClient) GetPipelines(projectID string) ([]string, error) {
 	return pipelineNames, nil
 }
 
-// TriggerPipeline triggers a pipeline for a specific ref
-func (g *GitLabClient) TriggerPipeline(projectID, ref string, variables map[string]string) error {
+// TriggerPipeline triggers a pipeline for a specific ref and returns the
+// pipeline it created
+func (g *GitLabClient) TriggerPipeline(projectID, ref string, variables map[string]string) (*WorkflowRun, error) {
 	// Convert variables to GitLab format
 	var gitlabVars []*gitlab.PipelineVariableOptions
 	for key, value := range variables {
@@ -180,12 +293,1148 @@ func (g *GitLabClient) TriggerPipeline(projectID, ref string, variables map[stri
 		})
 	}
 	
-	_, _, err := g.client.Pipelines.CreatePipeline(
+	pipeline, _, err := g.client.Pipelines.CreatePipeline(
 		projectID,
 		&gitlab.CreatePipelineOptions{
 			Ref:       &ref,
 			Variables: &gitlabVars,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	run := convertGitLabPipeline(projectID, pipeline)
+	return &run, nil
+}
+
+// GetPipelineDotenvVariables collects the variables exported by dotenv
+// reports of a pipeline's successful jobs, mirroring how GitLab passes them
+// to downstream pipelines. GitLab does not serve report artifacts through
+// the API, so the dotenv file must also be listed under artifacts:paths to
+// be found in the job's artifact archive.
+func (g *GitLabClient) GetPipelineDotenvVariables(projectID string, pipelineID int) (map[string]string, error) {
+	pipeline, _, err := g.client.Pipelines.GetPipeline(projectID, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.Status != "success" && pipeline.Status != "failed" && pipeline.Status != "canceled" {
+		return nil, fmt.Errorf("pipeline %d has not completed (status: %s)", pipelineID, pipeline.Status)
+	}
+
+	jobs, _, err := g.client.Jobs.ListPipelineJobs(
+		projectID,
+		pipelineID,
+		&gitlab.ListJobsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply jobs in creation order so later jobs override earlier ones
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID < jobs[j].ID
+	})
+
+	variables := make(map[string]string)
+	for _, job := range jobs {
+		if job.Status != "success" || !hasDotenvReport(job) {
+			continue
+		}
+
+		archive, _, err := g.client.Jobs.GetJobArtifacts(projectID, job.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download artifacts for job %s: %w", job.Name, err)
+		}
+
+		zr, err := zip.NewReader(archive, archive.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifacts for job %s: %v", job.Name, err)
+		}
+
+		for _, file := range zr.File {
+			if !strings.HasSuffix(file.Name, ".env") {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			parsed, err := parseDotenv(rc)
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s from job %s: %v", file.Name, job.Name, err)
+			}
+			for key, value := range parsed {
+				variables[key] = value
+			}
+		}
+	}
+
+	return variables, nil
+}
+
+// hasDotenvReport reports whether a job uploaded a dotenv report artifact
+func hasDotenvReport(job *gitlab.Job) bool {
+	for _, artifact := range job.Artifacts {
+		if artifact.FileType == "dotenv" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDotenv parses KEY=VALUE lines, ignoring blanks and comments
+func parseDotenv(r io.Reader) (map[string]string, error) {
+	variables := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line: %s", line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		variables[key] = value
+	}
+	return variables, scanner.Err()
+}
+
+// CommentOnPipeline posts a note about a pipeline to the merge request of its
+// commit, or to the commit itself when there is none. It returns a link to
+// where the note was posted.
+func (g *GitLabClient) CommentOnPipeline(projectID, pipelineID, body string) (string, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return "", err
+	}
+
+	pipeline, _, err := g.client.Pipelines.GetPipeline(projectID, pipelineIDInt)
+	if err != nil {
+		return "", err
+	}
+
+	mergeRequests, _, err := g.client.Commits.ListMergeRequestsByCommit(projectID, pipeline.SHA)
+	if err == nil && len(mergeRequests) > 0 {
+		mr := mergeRequests[0]
+		note, _, err := g.client.Notes.CreateMergeRequestNote(
+			projectID,
+			mr.IID,
+			&gitlab.CreateMergeRequestNoteOptions{Body: &body},
+		)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s#note_%d", mr.WebURL, note.ID), nil
+	}
+
+	if _, _, err := g.client.Commits.PostCommitComment(
+		projectID,
+		pipeline.SHA,
+		&gitlab.PostCommitCommentOptions{Note: &body},
+	); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("commit %s", pipeline.SHA), nil
+}
+
+// GetCIConfigFile retrieves .gitlab-ci.yml at ref; an empty ref uses the
+// default branch
+func (g *GitLabClient) GetCIConfigFile(projectID, ref string) (*WorkflowFile, error) {
+	opts := &gitlab.GetRawFileOptions{}
+	if ref != "" {
+		opts.Ref = &ref
+	}
+
+	content, _, err := g.client.RepositoryFiles.GetRawFile(projectID, ".gitlab-ci.yml", opts)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkflowFile{Path: ".gitlab-ci.yml", Content: string(content)}, nil
+}
+
+// GetJobLog retrieves the trace of a job
+func (g *GitLabClient) GetJobLog(projectID, jobID string) (string, error) {
+	jobIDInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return "", err
+	}
+
+	trace, _, err := g.client.Jobs.GetTraceFile(projectID, jobIDInt)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(trace)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CreateProjectWebhook adds a project webhook for pipeline and job events
+// authenticated with secret and returns its ID
+func (g *GitLabClient) CreateProjectWebhook(projectID, url, secret string) (int, error) {
+	hook, _, err := g.client.Projects.AddProjectHook(projectID, &gitlab.AddProjectHookOptions{
+		URL:                   gitlab.Ptr(url),
+		Token:                 gitlab.Ptr(secret),
+		PipelineEvents:        gitlab.Ptr(true),
+		JobEvents:             gitlab.Ptr(true),
+		PushEvents:            gitlab.Ptr(false),
+		EnableSSLVerification: gitlab.Ptr(true),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return hook.ID, nil
+}
+
+// UpdateProjectWebhookSecret replaces the secret token of a project webhook
+func (g *GitLabClient) UpdateProjectWebhookSecret(projectID string, hookID int, url, secret string) error {
+	_, _, err := g.client.Projects.EditProjectHook(projectID, hookID, &gitlab.EditProjectHookOptions{
+		URL:   gitlab.Ptr(url),
+		Token: gitlab.Ptr(secret),
+	})
+	return err
+}
+
+// DeleteProjectWebhook removes a project webhook
+func (g *GitLabClient) DeleteProjectWebhook(projectID string, hookID int) error {
+	_, err := g.client.Projects.DeleteProjectHook(projectID, hookID)
 	return err
 }
+
+// TestProjectWebhook asks GitLab to send a test pipeline event to a webhook.
+// GitLab reports an error when the receiver does not accept the delivery.
+func (g *GitLabClient) TestProjectWebhook(projectID string, hookID int) error {
+	path := fmt.Sprintf("projects/%s/hooks/%d/test/pipeline_events", gitlab.PathEscape(projectID), hookID)
+	req, err := g.client.NewRequest(http.MethodPost, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = g.client.Do(req, nil)
+	return err
+}
+
+// GetDeployments retrieves the last successful deployment of each available
+// environment. The environment list omits deployments, so each environment
+// is fetched on its own.
+func (g *GitLabClient) GetDeployments(projectID string) ([]Deployment, error) {
+	environments, _, err := g.client.Environments.ListEnvironments(projectID, &gitlab.ListEnvironmentsOptions{
+		States:      gitlab.Ptr("available"),
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Deployment
+	for _, environment := range environments {
+		env, _, err := g.client.Environments.GetEnvironment(projectID, environment.ID)
+		if err != nil {
+			return nil, err
+		}
+		last := env.LastDeployment
+		if last == nil {
+			continue
+		}
+
+		d := Deployment{
+			Environment: env.Name,
+			URL:         env.ExternalURL,
+			Ref:         last.Ref,
+			SHA:         last.SHA,
+			Status:      last.Status,
+		}
+		if last.Deployable.Pipeline.ID != 0 {
+			d.RunID = strconv.Itoa(last.Deployable.Pipeline.ID)
+		}
+		if last.User != nil {
+			d.Creator = last.User.Username
+		}
+		if last.Deployable.FinishedAt != nil {
+			d.DeployedAt = *last.Deployable.FinishedAt
+		} else if last.CreatedAt != nil {
+			d.DeployedAt = *last.CreatedAt
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// GetPendingDeployments retrieves deployments blocked on an approval rule of
+// a protected environment
+func (g *GitLabClient) GetPendingDeployments(projectID string) ([]PendingDeployment, error) {
+	deployments, _, err := g.client.Deployments.ListProjectDeployments(projectID, &gitlab.ListProjectDeploymentsOptions{
+		Status:      gitlab.Ptr("blocked"),
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("desc"),
+		ListOptions: gitlab.ListOptions{PerPage: 50},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PendingDeployment
+	for _, deployment := range deployments {
+		d := PendingDeployment{
+			DeploymentID: int64(deployment.ID),
+			RunID:        strconv.Itoa(deployment.Deployable.Pipeline.ID),
+			Ref:          deployment.Ref,
+		}
+		if deployment.Environment != nil {
+			d.Environment = deployment.Environment.Name
+			d.EnvironmentID = int64(deployment.Environment.ID)
+		}
+		if deployment.CreatedAt != nil {
+			d.WaitingSince = *deployment.CreatedAt
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// GetManualJobs retrieves jobs waiting to be started by hand, newest first
+func (g *GitLabClient) GetManualJobs(projectID string) ([]Job, error) {
+	jobs, _, err := g.client.Jobs.ListProjectJobs(projectID, &gitlab.ListJobsOptions{
+		Scope:       &[]gitlab.BuildStateValue{gitlab.Manual},
+		ListOptions: gitlab.ListOptions{PerPage: 50},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Job
+	for _, job := range jobs {
+		result = append(result, Job{
+			ID:         strconv.Itoa(job.ID),
+			RunID:      strconv.Itoa(job.Pipeline.ID),
+			Name:       job.Name,
+			Status:     job.Status,
+			Conclusion: job.Status,
+			CreatedAt:  job.CreatedAt,
+			URL:        job.WebURL,
+		})
+	}
+	return result, nil
+}
+
+// PlayJob starts a manual job
+func (g *GitLabClient) PlayJob(projectID, jobID string) error {
+	jobIDInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Jobs.PlayJob(projectID, jobIDInt, nil)
+	return err
+}
+
+// RetryJob starts a new attempt of a finished job and returns the new job's ID
+func (g *GitLabClient) RetryJob(projectID, jobID string) (string, error) {
+	jobIDInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return "", err
+	}
+	job, _, err := g.client.Jobs.RetryJob(projectID, jobIDInt)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(job.ID), nil
+}
+
+// CancelJob cancels a pending or running job
+func (g *GitLabClient) CancelJob(projectID, jobID string) error {
+	jobIDInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Jobs.CancelJob(projectID, jobIDInt)
+	return err
+}
+
+// GetDownstreamPipelines retrieves the pipelines triggered by a pipeline's
+// bridge jobs. Multi-project pipelines are reported under their own
+// project's path.
+func (g *GitLabClient) GetDownstreamPipelines(projectID, pipelineID string) ([]DownstreamPipeline, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	bridges, _, err := g.client.Jobs.ListPipelineBridges(projectID, pipelineIDInt, &gitlab.ListJobsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []DownstreamPipeline
+	for _, bridge := range bridges {
+		downstream := bridge.DownstreamPipeline
+		if downstream == nil {
+			continue
+		}
+
+		project := projectID
+		multiProject := downstream.ProjectID != bridge.Pipeline.ProjectID
+		if multiProject {
+			project = strconv.Itoa(downstream.ProjectID)
+			if p, _, err := g.client.Projects.GetProject(downstream.ProjectID, nil); err == nil {
+				project = p.PathWithNamespace
+			}
+		}
+
+		run := WorkflowRun{
+			ID:         strconv.Itoa(downstream.ID),
+			Project:    project,
+			Workflow:   bridge.Name,
+			Status:     downstream.Status,
+			Conclusion: downstream.Status,
+			URL:        downstream.WebURL,
+			Platform:   "gitlab",
+			Branch:     downstream.Ref,
+			Commit:     downstream.SHA,
+		}
+		if downstream.CreatedAt != nil {
+			run.CreatedAt = *downstream.CreatedAt
+		}
+		if downstream.UpdatedAt != nil {
+			run.UpdatedAt = *downstream.UpdatedAt
+		}
+		result = append(result, DownstreamPipeline{Bridge: bridge.Name, MultiProject: multiProject, Run: run})
+	}
+	return result, nil
+}
+
+// GetProjectAccess reports the token's role in a project, taking the higher
+// of its project and group access levels
+func (g *GitLabClient) GetProjectAccess(projectID string) (RepoAccess, error) {
+	project, _, err := g.client.Projects.GetProject(projectID, nil)
+	if err != nil {
+		return RepoAccess{}, err
+	}
+
+	level := gitlab.NoPermissions
+	if project.Permissions != nil {
+		if project.Permissions.ProjectAccess != nil {
+			level = project.Permissions.ProjectAccess.AccessLevel
+		}
+		if group := project.Permissions.GroupAccess; group != nil && group.AccessLevel > level {
+			level = group.AccessLevel
+		}
+	}
+
+	access := RepoAccess{
+		CanTrigger:     level >= gitlab.DeveloperPermissions,
+		CanComment:     level >= gitlab.ReporterPermissions || project.Visibility == gitlab.PublicVisibility,
+		CanManageHooks: level >= gitlab.MaintainerPermissions,
+	}
+	switch {
+	case level >= gitlab.OwnerPermissions:
+		access.Role = "owner"
+	case level >= gitlab.MaintainerPermissions:
+		access.Role = "maintainer"
+	case level >= gitlab.DeveloperPermissions:
+		access.Role = "developer"
+	case level >= gitlab.ReporterPermissions:
+		access.Role = "reporter"
+	case level >= gitlab.GuestPermissions:
+		access.Role = "guest"
+	default:
+		access.Role = "none"
+	}
+	return access, nil
+}
+
+// gitlabJobRunner describes the runner of a job, named by its description
+// as the GitLab UI does
+func gitlabJobRunner(job *gitlab.Job) *JobRunner {
+	runner := &JobRunner{Labels: job.TagList}
+	if job.Runner.ID == 0 {
+		return runner
+	}
+	runner.Name = job.Runner.Description
+	if runner.Name == "" {
+		runner.Name = fmt.Sprintf("#%d", job.Runner.ID)
+	}
+	runner.Kind = "project/group"
+	if job.Runner.IsShared {
+		runner.Kind = "shared"
+	}
+	return runner
+}
+
+// GetProjectRunners lists the project and group runners available to a
+// project. Shared instance runners are left out since they aren't managed
+// by the project's owners.
+func (g *GitLabClient) GetProjectRunners(projectID string) ([]SelfHostedRunner, error) {
+	runners, _, err := g.client.Runners.ListProjectRunners(projectID, &gitlab.ListProjectRunnersOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var converted []SelfHostedRunner
+	for _, runner := range runners {
+		if runner.IsShared {
+			continue
+		}
+		item := SelfHostedRunner{
+			Platform: "gitlab",
+			ID:       strconv.Itoa(runner.ID),
+			Name:     runner.Description,
+			Scope:    strings.TrimSuffix(runner.RunnerType, "_type"),
+			Status:   runner.Status,
+		}
+		if item.Name == "" {
+			item.Name = "#" + item.ID
+		}
+		if runner.Paused {
+			item.Status = "paused"
+		}
+		if item.Scope == "project" {
+			item.Scope = "project " + projectID
+		}
+
+		// Tags and running jobs need a call per runner
+		if details, _, err := g.client.Runners.GetRunnerDetails(runner.ID); err == nil {
+			item.Labels = details.TagList
+			item.OS = details.Platform
+		}
+		running, _, err := g.client.Runners.ListRunnerJobs(runner.ID, &gitlab.ListRunnerJobsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 1},
+			Status:      gitlab.Ptr("running"),
+		})
+		if err == nil {
+			item.Busy = len(running) > 0
+		}
+		converted = append(converted, item)
+	}
+	return converted, nil
+}
+
+// GetPipelineUsage retrieves the time jobs of pipelines created in a month
+// spent on shared runners, which is what counts toward compute minutes.
+// Cost factors of the runners are not applied.
+func (g *GitLabClient) GetPipelineUsage(projectID string, month time.Time) ([]RunUsage, error) {
+	end := month.AddDate(0, 1, 0)
+	opts := &gitlab.ListProjectPipelinesOptions{
+		ListOptions:  gitlab.ListOptions{PerPage: 100},
+		UpdatedAfter: gitlab.Ptr(month),
+	}
+
+	var usage []RunUsage
+	for len(usage) < maxUsageRuns {
+		pipelines, resp, err := g.client.Pipelines.ListProjectPipelines(projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pipeline := range pipelines {
+			// Pipelines updated in the month may have been created before it
+			if pipeline.CreatedAt == nil || pipeline.CreatedAt.Before(month) || !pipeline.CreatedAt.Before(end) {
+				continue
+			}
+			jobs, _, err := g.client.Jobs.ListPipelineJobs(projectID, pipeline.ID, &gitlab.ListJobsOptions{
+				ListOptions: gitlab.ListOptions{PerPage: 100},
+			})
+			if err != nil {
+				return nil, err
+			}
+			item := RunUsage{
+				RunID:    strconv.Itoa(pipeline.ID),
+				Workflow: pipeline.Ref,
+				Minutes:  make(map[string]float64),
+			}
+			for _, job := range jobs {
+				if job.Runner.IsShared {
+					item.Minutes["shared"] += job.Duration / 60
+				}
+			}
+			usage = append(usage, item)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return usage, nil
+}
+
+// gitlabNamespaceQuota holds the compute minute limits of a namespace,
+// which go-gitlab's Namespace leaves out
+type gitlabNamespaceQuota struct {
+	FullPath                       string `json:"full_path"`
+	SharedRunnersMinutesLimit      *int   `json:"shared_runners_minutes_limit"`
+	ExtraSharedRunnersMinutesLimit *int   `json:"extra_shared_runners_minutes_limit"`
+}
+
+// GetComputeQuota retrieves the monthly compute minute quota of a project's
+// namespace. The limits are only visible to administrators and namespace
+// owners; nil limits mean unlimited or not visible.
+func (g *GitLabClient) GetComputeQuota(projectID string) (*BillingSummary, error) {
+	project, _, err := g.client.Projects.GetProject(projectID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if project.Namespace == nil {
+		return nil, fmt.Errorf("project %s has no namespace", projectID)
+	}
+
+	req, err := g.client.NewRequest(http.MethodGet, fmt.Sprintf("namespaces/%d", project.Namespace.ID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var quota gitlabNamespaceQuota
+	if _, err := g.client.Do(req, &quota); err != nil {
+		return nil, err
+	}
+
+	summary := &BillingSummary{Account: quota.FullPath}
+	if quota.SharedRunnersMinutesLimit != nil {
+		summary.IncludedMinutes = float64(*quota.SharedRunnersMinutesLimit)
+	}
+	if quota.ExtraSharedRunnersMinutesLimit != nil {
+		summary.IncludedMinutes += float64(*quota.ExtraSharedRunnersMinutesLimit)
+	}
+	return summary, nil
+}
+
+// GetProjectVariables lists the CI/CD variables of a project with their
+// values, masked ones included
+func (g *GitLabClient) GetProjectVariables(projectID string) ([]CIVariable, error) {
+	var result []CIVariable
+	opts := &gitlab.ListProjectVariablesOptions{PerPage: 100}
+	for {
+		variables, resp, err := g.client.ProjectVariables.ListVariables(projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, variable := range variables {
+			result = append(result, CIVariable{
+				Name:        variable.Key,
+				Value:       variable.Value,
+				Masked:      variable.Masked,
+				Protected:   variable.Protected,
+				Environment: variable.EnvironmentScope,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// SetProjectVariable updates a CI/CD variable of every environment, creating
+// it when it doesn't exist. GitLab rejects masked values it can't hide in
+// job logs, such as values shorter than eight characters.
+func (g *GitLabClient) SetProjectVariable(projectID, key, value string, masked, protected bool) error {
+	_, resp, err := g.client.ProjectVariables.UpdateVariable(projectID, key, &gitlab.UpdateProjectVariableOptions{
+		Value:     gitlab.Ptr(value),
+		Masked:    gitlab.Ptr(masked),
+		Protected: gitlab.Ptr(protected),
+		Filter:    &gitlab.VariableFilter{EnvironmentScope: "*"},
+	})
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		_, _, err = g.client.ProjectVariables.CreateVariable(projectID, &gitlab.CreateProjectVariableOptions{
+			Key:       gitlab.Ptr(key),
+			Value:     gitlab.Ptr(value),
+			Masked:    gitlab.Ptr(masked),
+			Protected: gitlab.Ptr(protected),
+		})
+	}
+	return err
+}
+
+// DeleteProjectVariable removes the CI/CD variable of every environment
+func (g *GitLabClient) DeleteProjectVariable(projectID, key string) error {
+	_, err := g.client.ProjectVariables.RemoveVariable(projectID, key, &gitlab.RemoveProjectVariableOptions{
+		Filter: &gitlab.VariableFilter{EnvironmentScope: "*"},
+	})
+	return err
+}
+
+// convertGitLabSchedule converts a GitLab pipeline schedule
+func convertGitLabSchedule(schedule *gitlab.PipelineSchedule) PipelineSchedule {
+	result := PipelineSchedule{
+		ID:          schedule.ID,
+		Description: schedule.Description,
+		Ref:         schedule.Ref,
+		Cron:        schedule.Cron,
+		Timezone:    schedule.CronTimezone,
+		Active:      schedule.Active,
+		NextRunAt:   schedule.NextRunAt,
+	}
+	if schedule.Owner != nil {
+		result.Owner = schedule.Owner.Username
+	}
+	if len(schedule.Variables) > 0 {
+		result.Variables = make(map[string]string, len(schedule.Variables))
+		for _, variable := range schedule.Variables {
+			result.Variables[variable.Key] = variable.Value
+		}
+	}
+	return result
+}
+
+// GetPipelineSchedules lists the pipeline schedules of a project, without
+// their variables
+func (g *GitLabClient) GetPipelineSchedules(projectID string) ([]PipelineSchedule, error) {
+	var result []PipelineSchedule
+	opts := &gitlab.ListPipelineSchedulesOptions{PerPage: 100}
+	for {
+		schedules, resp, err := g.client.PipelineSchedules.ListPipelineSchedules(projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, schedule := range schedules {
+			result = append(result, convertGitLabSchedule(schedule))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GetPipelineSchedule retrieves a pipeline schedule with its variables
+func (g *GitLabClient) GetPipelineSchedule(projectID string, scheduleID int) (*PipelineSchedule, error) {
+	schedule, _, err := g.client.PipelineSchedules.GetPipelineSchedule(projectID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	result := convertGitLabSchedule(schedule)
+	return &result, nil
+}
+
+// CreatePipelineSchedule creates a pipeline schedule with its variables
+func (g *GitLabClient) CreatePipelineSchedule(projectID string, changes scheduleChanges) (*PipelineSchedule, error) {
+	schedule, _, err := g.client.PipelineSchedules.CreatePipelineSchedule(projectID, &gitlab.CreatePipelineScheduleOptions{
+		Description:  changes.Description,
+		Ref:          changes.Ref,
+		Cron:         changes.Cron,
+		CronTimezone: changes.Timezone,
+		Active:       changes.Active,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range slices.Sorted(maps.Keys(changes.Variables)) {
+		_, _, err := g.client.PipelineSchedules.CreatePipelineScheduleVariable(projectID, schedule.ID, &gitlab.CreatePipelineScheduleVariableOptions{
+			Key:   gitlab.Ptr(key),
+			Value: gitlab.Ptr(changes.Variables[key]),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("created schedule %d but failed to set variable %s: %w", schedule.ID, key, err)
+		}
+	}
+	return g.GetPipelineSchedule(projectID, schedule.ID)
+}
+
+// EditPipelineSchedule changes the given fields of a pipeline schedule,
+// setting and removing variables. existing are the variables it has.
+func (g *GitLabClient) EditPipelineSchedule(projectID string, scheduleID int, changes scheduleChanges, existing map[string]string) error {
+	if changes.Description != nil || changes.Ref != nil || changes.Cron != nil || changes.Timezone != nil || changes.Active != nil {
+		_, _, err := g.client.PipelineSchedules.EditPipelineSchedule(projectID, scheduleID, &gitlab.EditPipelineScheduleOptions{
+			Description:  changes.Description,
+			Ref:          changes.Ref,
+			Cron:         changes.Cron,
+			CronTimezone: changes.Timezone,
+			Active:       changes.Active,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(changes.Variables)) {
+		var err error
+		if _, ok := existing[key]; ok {
+			_, _, err = g.client.PipelineSchedules.EditPipelineScheduleVariable(projectID, scheduleID, key, &gitlab.EditPipelineScheduleVariableOptions{
+				Value: gitlab.Ptr(changes.Variables[key]),
+			})
+		} else {
+			_, _, err = g.client.PipelineSchedules.CreatePipelineScheduleVariable(projectID, scheduleID, &gitlab.CreatePipelineScheduleVariableOptions{
+				Key:   gitlab.Ptr(key),
+				Value: gitlab.Ptr(changes.Variables[key]),
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set variable %s: %w", key, err)
+		}
+	}
+	for _, key := range changes.Unset {
+		if _, ok := existing[key]; !ok {
+			continue
+		}
+		if _, _, err := g.client.PipelineSchedules.DeletePipelineScheduleVariable(projectID, scheduleID, key); err != nil {
+			return fmt.Errorf("failed to remove variable %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// RunPipelineSchedule starts a pipeline of a schedule now
+func (g *GitLabClient) RunPipelineSchedule(projectID string, scheduleID int) error {
+	_, err := g.client.PipelineSchedules.RunPipelineSchedule(projectID, scheduleID)
+	return err
+}
+
+// DeletePipelineSchedule removes a pipeline schedule
+func (g *GitLabClient) DeletePipelineSchedule(projectID string, scheduleID int) error {
+	_, err := g.client.PipelineSchedules.DeletePipelineSchedule(projectID, scheduleID)
+	return err
+}
+
+// LintCIConfig validates CI configuration content with the project's CI
+// Lint API, which resolves includes and reports errors and warnings
+func (g *GitLabClient) LintCIConfig(projectID, content string) ([]string, []string, error) {
+	result, _, err := g.client.Validate.ProjectNamespaceLint(projectID, &gitlab.ProjectNamespaceLintOptions{
+		Content: gitlab.Ptr(content),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !result.Valid && len(result.Errors) == 0 {
+		return []string{"configuration is invalid"}, result.Warnings, nil
+	}
+	return result.Errors, result.Warnings, nil
+}
+
+// GetPipelineDefinition retrieves the CI configuration file of a pipeline
+// at the pipeline's commit, honoring a custom CI config path
+func (g *GitLabClient) GetPipelineDefinition(projectID, pipelineID string) (*RunDefinition, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, _, err := g.client.Pipelines.GetPipeline(projectID, pipelineIDInt)
+	if err != nil {
+		return nil, err
+	}
+	project, _, err := g.client.Projects.GetProject(projectID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	path := project.CIConfigPath
+	if path == "" {
+		path = ".gitlab-ci.yml"
+	}
+	if strings.Contains(path, "@") || strings.Contains(path, "://") {
+		return nil, fmt.Errorf("the CI configuration %s is kept outside the project", path)
+	}
+	content, _, err := g.client.RepositoryFiles.GetRawFile(projectID, path, &gitlab.GetRawFileOptions{Ref: gitlab.Ptr(pipeline.SHA)})
+	if err != nil {
+		return nil, err
+	}
+	return &RunDefinition{File: WorkflowFile{Path: path, Content: string(content)}, Commit: pipeline.SHA}, nil
+}
+
+// CancelPipeline cancels the pending and running jobs of a pipeline
+func (g *GitLabClient) CancelPipeline(projectID, pipelineID string) error {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Pipelines.CancelPipelineBuild(projectID, pipelineIDInt)
+	return err
+}
+
+// RetryPipeline retries the failed and canceled jobs of a pipeline. GitLab
+// has no way to re-run a whole pipeline in place.
+func (g *GitLabClient) RetryPipeline(projectID, pipelineID string) error {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Pipelines.RetryPipelineBuild(projectID, pipelineIDInt)
+	return err
+}
+
+// GetRateLimit reads the throttling headers GitLab sends with API
+// responses. Instances without rate limits send none, leaving Limit 0.
+func (g *GitLabClient) GetRateLimit() (RateQuota, error) {
+	_, resp, err := g.client.Users.CurrentUser()
+	if err != nil {
+		return RateQuota{}, err
+	}
+
+	quota := RateQuota{Platform: "gitlab", Resource: "api"}
+	quota.Limit, _ = strconv.Atoi(resp.Header.Get("RateLimit-Limit"))
+	quota.Remaining, _ = strconv.Atoi(resp.Header.Get("RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+		quota.Reset = time.Unix(reset, 0)
+	}
+	return quota, nil
+}
+
+// GetPipelineTestReport retrieves the test report GitLab builds from the
+// artifacts:reports:junit files of a pipeline's jobs
+func (g *GitLabClient) GetPipelineTestReport(projectID, pipelineID string) ([]TestResult, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	report, _, err := g.client.Pipelines.GetPipelineTestReport(projectID, pipelineIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TestResult
+	for _, suite := range report.TestSuites {
+		for _, tc := range suite.TestCases {
+			result := TestResult{
+				Suite:    firstNonEmpty(suite.Name, tc.Classname),
+				Name:     tc.Name,
+				Status:   tc.Status,
+				Duration: time.Duration(tc.ExecutionTime * float64(time.Second)),
+			}
+			// GitLab reports success rather than passed
+			if result.Status == "success" {
+				result.Status = "passed"
+			}
+			if result.Status != "passed" {
+				result.Message, _, _ = strings.Cut(strings.TrimSpace(tc.StackTrace), "\n")
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// GetPipelineCoverage returns the coverage GitLab parsed from a pipeline's
+// job logs with the coverage keyword, and false when it reported none
+func (g *GitLabClient) GetPipelineCoverage(projectID, pipelineID string) (float64, bool, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return 0, false, err
+	}
+	pipeline, _, err := g.client.Pipelines.GetPipeline(projectID, pipelineIDInt)
+	if err != nil {
+		return 0, false, err
+	}
+	if pipeline.Coverage == "" {
+		return 0, false, nil
+	}
+	coverage, err := strconv.ParseFloat(pipeline.Coverage, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid coverage %q: %v", pipeline.Coverage, err)
+	}
+	return coverage, true, nil
+}
+
+// GetDefaultBranch retrieves the default branch of a project
+func (g *GitLabClient) GetDefaultBranch(projectID string) (string, error) {
+	project, _, err := g.client.Projects.GetProject(projectID, nil)
+	if err != nil {
+		return "", err
+	}
+	return project.DefaultBranch, nil
+}
+
+// securityReportTypes are the report artifact types of GitLab's security
+// scanners
+var securityReportTypes = []string{"sast", "dependency_scanning", "secret_detection", "container_scanning"}
+
+// GetPipelineSecurityFindings reads the security reports uploaded by a
+// pipeline's scanner jobs. GitLab does not serve report artifacts through
+// the API, so reports are read from the job's artifact archive and must
+// also be listed under artifacts:paths. Reports that aren't there are
+// named in the returned error, alongside the findings of those that are.
+func (g *GitLabClient) GetPipelineSecurityFindings(projectID, pipelineID string) ([]SecurityFinding, error) {
+	pipelineIDInt, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	jobs, _, err := g.client.Jobs.ListPipelineJobs(
+		projectID,
+		pipelineIDInt,
+		&gitlab.ListJobsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []SecurityFinding
+	var missing []string
+	for _, job := range jobs {
+		for _, artifact := range job.Artifacts {
+			if !slices.Contains(securityReportTypes, artifact.FileType) {
+				continue
+			}
+			report, resp, err := g.client.Jobs.DownloadSingleArtifactsFile(projectID, job.ID, artifact.Filename)
+			if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+				missing = append(missing, fmt.Sprintf("%s (job %s)", artifact.Filename, job.Name))
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to download %s from job %s: %w", artifact.Filename, job.Name, err)
+			}
+			found, err := parseSecurityReport(report, artifact.FileType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s from job %s: %v", artifact.Filename, job.Name, err)
+			}
+			findings = append(findings, found...)
+		}
+	}
+	if len(missing) > 0 {
+		return findings, fmt.Errorf("reports not in the artifact archive, add them to artifacts:paths: %s", strings.Join(missing, ", "))
+	}
+	return findings, nil
+}
+
+// GetReleases retrieves up to limit of a project's most recently updated
+// tags. GitLab releases always belong to a tag, so the tags cover them.
+func (g *GitLabClient) GetReleases(projectID string, limit int) ([]Release, error) {
+	tags, _, err := g.client.Tags.ListTags(projectID, &gitlab.ListTagsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: limit},
+		OrderBy:     gitlab.Ptr("updated"),
+		Sort:        gitlab.Ptr("desc"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	for _, tag := range tags {
+		release := Release{Tag: tag.Name}
+		if tag.Commit != nil {
+			release.Commit = tag.Commit.ID
+			if tag.Commit.CreatedAt != nil {
+				release.CreatedAt = *tag.Commit.CreatedAt
+			}
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// GetMergeStatus retrieves the jobs of the pipeline a branch would be
+// merged on: the head pipeline of its open merge request, or else the
+// branch's latest pipeline. When the project only allows merging after the
+// pipeline succeeds, every job not allowed to fail is required.
+func (g *GitLabClient) GetMergeStatus(projectID, branch string) (*MergeStatus, error) {
+	project, _, err := g.client.Projects.GetProject(projectID, nil)
+	if err != nil {
+		return nil, err
+	}
+	status := &MergeStatus{Branch: branch, Target: branch}
+
+	requests, _, err := g.client.MergeRequests.ListProjectMergeRequests(projectID, &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions:  gitlab.ListOptions{PerPage: 1},
+		State:        gitlab.Ptr("opened"),
+		SourceBranch: gitlab.Ptr(branch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pipelineID := 0
+	if len(requests) > 0 {
+		status.PullRequest = requests[0].IID
+		status.Target = requests[0].TargetBranch
+		request, _, err := g.client.MergeRequests.GetMergeRequest(projectID, requests[0].IID, nil)
+		if err != nil {
+			return nil, err
+		}
+		if request.HeadPipeline != nil {
+			pipelineID = request.HeadPipeline.ID
+		}
+	}
+	if pipelineID == 0 {
+		pipelines, _, err := g.client.Pipelines.ListProjectPipelines(projectID, &gitlab.ListProjectPipelinesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 1},
+			Ref:         gitlab.Ptr(branch),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(pipelines) > 0 {
+			pipelineID = pipelines[0].ID
+		}
+	}
+
+	if err := g.setPipelineChecks(status, projectID, pipelineID, project.OnlyAllowMergeIfPipelineSucceeds); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetForkMergeStatus retrieves the checks of the open merge request into
+// upstreamID from branch of the fork forkID. Its head pipeline runs in the
+// fork or, when the upstream project runs fork pipelines itself, upstream.
+func (g *GitLabClient) GetForkMergeStatus(upstreamID, forkID, branch string) (*MergeStatus, error) {
+	upstream, _, err := g.client.Projects.GetProject(upstreamID, nil)
+	if err != nil {
+		return nil, err
+	}
+	fork, _, err := g.client.Projects.GetProject(forkID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, _, err := g.client.MergeRequests.ListProjectMergeRequests(upstreamID, &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions:  gitlab.ListOptions{PerPage: 100},
+		State:        gitlab.Ptr("opened"),
+		SourceBranch: gitlab.Ptr(branch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range requests {
+		if request.SourceProjectID != fork.ID {
+			continue
+		}
+		status := &MergeStatus{Branch: branch, Target: request.TargetBranch, PullRequest: request.IID}
+		request, _, err := g.client.MergeRequests.GetMergeRequest(upstreamID, request.IID, nil)
+		if err != nil {
+			return nil, err
+		}
+		pipelineID, pipelineProject := 0, 0
+		if request.HeadPipeline != nil {
+			pipelineID, pipelineProject = request.HeadPipeline.ID, request.HeadPipeline.ProjectID
+		}
+		if err := g.setPipelineChecks(status, pipelineProject, pipelineID, upstream.OnlyAllowMergeIfPipelineSucceeds); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
+	return nil, fmt.Errorf("no open merge request from %s:%s into %s", fork.PathWithNamespace, branch, upstream.PathWithNamespace)
+}
+
+// setPipelineChecks records a pipeline's jobs as the checks of a merge
+// status, all but those allowed to fail required when required is set. A
+// zero pipelineID means none ran, which is missing when one is required.
+func (g *GitLabClient) setPipelineChecks(status *MergeStatus, projectID any, pipelineID int, required bool) error {
+	if pipelineID == 0 {
+		if required {
+			status.Missing = []string{"pipeline"}
+		}
+		return nil
+	}
+	jobs, _, err := g.client.Jobs.ListPipelineJobs(projectID, pipelineID, &gitlab.ListJobsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}})
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		status.Checks = append(status.Checks, CommitCheck{
+			Name:       job.Name,
+			Source:     "job",
+			Status:     job.Status,
+			Conclusion: job.Status, // GitLab uses status for both
+			URL:        job.WebURL,
+			Required:   required && !job.AllowFailure,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// RunRecord is one observation of a workflow run's state, stored in the
+// local history file
+type RunRecord struct {
+	ObservedAt time.Time `json:"observed_at"`
+	WorkflowRun
+}
+
+// historyFile returns the path of the JSON lines run history, kept next to
+// the state file
+func historyFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "history.jsonl")
+}
+
+// queryHistory reads matching run observations from the configured store,
+// oldest first
+func queryHistory(config *Config, filter HistoryFilter) ([]RunRecord, error) {
+	store, err := openHistoryStore(config)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.Query(filter)
+}
+
+// recordRuns stores observations for runs whose state changed since they
+// were last recorded, pruning the history by the retention settings once a
+// day so it doesn't grow without bound
+func recordRuns(config *Config, runs []WorkflowRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	store, err := openHistoryStore(config)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if err := store.Record(runs, time.Now()); err != nil {
+		return err
+	}
+	return autoPrune(config, store)
+}
+
+// historyKey identifies a run across platforms
+func historyKey(run WorkflowRun) string {
+	return run.Platform + ":" + run.Project + ":" + run.ID
+}
+
+// statusAt returns, per project, the latest run created at or before t with
+// the most recent state recorded for it at that time. Runs created before t
+// whose first recorded state is later than t are reported as in progress.
+func statusAt(records []RunRecord, t time.Time) map[string]RunRecord {
+	// Latest state of each run as known at t
+	runs := make(map[string]RunRecord)
+	for _, record := range records {
+		if record.CreatedAt.After(t) {
+			continue
+		}
+
+		key := historyKey(record.WorkflowRun)
+		existing, seen := runs[key]
+		if stateTime(record).After(t) {
+			// The run existed at t but its state was only recorded later
+			if !seen {
+				inferred := record
+				inferred.Status = "in_progress"
+				inferred.Conclusion = ""
+				inferred.UpdatedAt = record.CreatedAt
+				runs[key] = inferred
+			}
+			continue
+		}
+		if !seen || !stateTime(record).Before(stateTime(existing)) {
+			runs[key] = record
+		}
+	}
+
+	// Newest run per project
+	latest := make(map[string]RunRecord)
+	for _, record := range runs {
+		current, ok := latest[record.Project]
+		if !ok || record.CreatedAt.After(current.CreatedAt) {
+			latest[record.Project] = record
+		}
+	}
+	return latest
+}
+
+// stateTime returns when a recorded state took effect
+func stateTime(record RunRecord) time.Time {
+	if record.UpdatedAt.IsZero() {
+		return record.ObservedAt
+	}
+	return record.UpdatedAt
+}
+
+// parseTimeArg parses a user supplied time in the display timezone
+func parseTimeArg(value string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, displayLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q (expected e.g. \"2025-01-05 14:00\")", value)
+}
+
+// showStatus shows the latest recorded run state of each project, optionally
+// as it was at a point in the past
+func showStatus(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	at := fs.String("at", "", "Show state as of this time, e.g. \"2025-01-05 14:00\" (default: now)")
+	withChecks := fs.Bool("checks", false, "Also fetch external commit statuses and check runs (GitHub)")
+	oneline := fs.Bool("oneline", false, "Print a single line like \"✔ 12 ✖ 1 ● 3\" for tmux status bars and prompts")
+	cacheFor := fs.Duration("cache", 30*time.Second, "How long --oneline reuses fetched counts")
+	color := fs.String("color", "none", "Color of --oneline: none, ansi, or tmux")
+	fs.Parse(args)
+
+	if *oneline {
+		if *color != "none" && *color != "ansi" && *color != "tmux" {
+			fmt.Printf("%s Unsupported color: %s (expected none, ansi, or tmux)\n", qc.Colorize("Error:", qc.ColorRed), *color)
+			return
+		}
+		printStatusLine(ctx, config, *cacheFor, *color)
+		return
+	}
+
+	t := time.Now()
+	if *at != "" {
+		var err error
+		t, err = parseTimeArg(*at)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	}
+
+	records, err := queryHistory(config, HistoryFilter{CreatedBefore: t})
+	if err != nil {
+		fmt.Printf("%s Failed to read history: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	// Projects with a default branch report runs on it only
+	branches := make(map[string]string)
+	for _, project := range config.Projects {
+		if project.DefaultBranch != "" {
+			branches[project.Name] = project.DefaultBranch
+		}
+	}
+	relevant := records[:0]
+	for _, record := range records {
+		if branch, ok := branches[record.Project]; !ok || record.Branch == branch {
+			relevant = append(relevant, record)
+		}
+	}
+	latest := statusAt(relevant, t)
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Latest recorded runs as of %s:", t.In(displayLocation).Format("2006-01-02 15:04")), qc.ColorBlue))
+	fmt.Println()
+
+	names := make([]string, 0, len(config.Projects))
+	longestProject := 0
+	for _, project := range config.Projects {
+		names = append(names, project.Name)
+		if len(project.Name) > longestProject {
+			longestProject = len(project.Name)
+		}
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+
+		record, ok := latest[name]
+		if !ok {
+			entry := fmt.Sprintf("%3d. %-*s %s", i+1, longestProject, name, "no recorded runs")
+			fmt.Println(qc.Colorize(entry, rowColor))
+			continue
+		}
+
+		status := record.Status
+		if record.Conclusion != "" && record.Conclusion != record.Status {
+			status = record.Conclusion
+		}
+		entry := fmt.Sprintf(
+			"%3d. %-*s %-20s %s [%s] %s",
+			i+1, longestProject, name, record.Workflow,
+			record.CreatedAt.In(displayLocation).Format("2006-01-02 15:04"),
+			qc.Colorize(status, colorWorkflowStatus(record.Status, record.Conclusion)),
+			record.Branch,
+		)
+		fmt.Println(qc.Colorize(entry, rowColor))
+
+		if *withChecks {
+			project := findProject(config, name)
+			checks, err := getExternalChecks(ctx, *project, record.Commit)
+			if err != nil {
+				fmt.Printf("       %s %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+				continue
+			}
+			displayExternalChecks(checks, "       ")
+		}
+	}
+}
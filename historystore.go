@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// historyDSNEnv holds the connection string for the postgres history backend.
+// It is read from the environment so credentials stay out of the state file.
+const historyDSNEnv = "QUICK_WORKFLOW_HISTORY_DSN"
+
+// HistoryFilter narrows a history query; zero fields match everything
+type HistoryFilter struct {
+	Platform string
+	Project  string
+	RunID    string
+	// CreatedBefore keeps runs created at or before this time
+	CreatedBefore time.Time
+}
+
+// matches reports whether a record passes the filter
+func (f HistoryFilter) matches(record RunRecord) bool {
+	return (f.Platform == "" || record.Platform == f.Platform) &&
+		(f.Project == "" || record.Project == f.Project) &&
+		(f.RunID == "" || record.ID == f.RunID) &&
+		(f.CreatedBefore.IsZero() || !record.CreatedAt.After(f.CreatedBefore))
+}
+
+// HistoryStore persists observations of run state
+type HistoryStore interface {
+	// Record stores an observation for each run whose state changed since
+	// it was last recorded
+	Record(runs []WorkflowRun, observedAt time.Time) error
+	// Query returns matching observations, oldest first
+	Query(filter HistoryFilter) ([]RunRecord, error)
+	// Prune deletes every observation of the runs the policy drops and
+	// returns them; with dryRun it only returns them
+	Prune(policy RetentionPolicy, now time.Time, dryRun bool) ([]WorkflowRun, error)
+	Close() error
+}
+
+// openHistoryStore opens the history backend selected in settings. SQLite is
+// the default; postgres lets a team share one history.
+func openHistoryStore(config *Config) (HistoryStore, error) {
+	dir := filepath.Dir(config.StateFile)
+	switch config.Settings.HistoryBackend {
+	case "", "sqlite":
+		return openSQLiteHistory(filepath.Join(dir, "history.db"), historyFile(config))
+	case "postgres":
+		dsn := os.Getenv(historyDSNEnv)
+		if dsn == "" {
+			return nil, fmt.Errorf("postgres history backend requires %s", historyDSNEnv)
+		}
+		return openSQLHistory("postgres", dsn)
+	case "jsonl":
+		return &jsonlHistoryStore{path: historyFile(config)}, nil
+	default:
+		return nil, fmt.Errorf("unknown history backend: %s", config.Settings.HistoryBackend)
+	}
+}
+
+// jsonlHistoryStore appends observations to a JSON lines file
+type jsonlHistoryStore struct {
+	path string
+}
+
+// Query reads the file, skipping lines truncated by an interrupted write
+func (s *jsonlHistoryStore) Query(filter HistoryFilter) ([]RunRecord, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []RunRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// Record appends changed runs while holding the file lock
+func (s *jsonlHistoryStore) Record(runs []WorkflowRun, observedAt time.Time) error {
+	lock, err := acquireLock(s.path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	records, err := s.Query(HistoryFilter{})
+	if err != nil {
+		return err
+	}
+	latest := make(map[string]RunRecord)
+	for _, record := range records {
+		latest[historyKey(record.WorkflowRun)] = record
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, run := range runs {
+		if previous, ok := latest[historyKey(run)]; ok && sameRunState(previous.WorkflowRun, run) {
+			continue
+		}
+		data, err := json.Marshal(RunRecord{ObservedAt: observedAt, WorkflowRun: run})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonlHistoryStore) Close() error {
+	return nil
+}
+
+// sameRunState reports whether two observations of a run show the same state
+func sameRunState(a, b WorkflowRun) bool {
+	return a.Status == b.Status && a.Conclusion == b.Conclusion && a.UpdatedAt.Equal(b.UpdatedAt)
+}
+
+// sqlHistoryStore keeps observations in a SQL table. Filtered columns are
+// stored alongside the full run as JSON; times are unix nanoseconds so the
+// same queries work on SQLite and postgres.
+type sqlHistoryStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// historySchema creates the history table for each driver
+var historySchema = map[string]string{
+	"sqlite": `CREATE TABLE IF NOT EXISTS run_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	observed_at BIGINT NOT NULL,
+	platform TEXT NOT NULL,
+	project TEXT NOT NULL,
+	run_id TEXT NOT NULL,
+	status TEXT NOT NULL,
+	conclusion TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	data TEXT NOT NULL
+)`,
+	"postgres": `CREATE TABLE IF NOT EXISTS run_history (
+	id BIGSERIAL PRIMARY KEY,
+	observed_at BIGINT NOT NULL,
+	platform TEXT NOT NULL,
+	project TEXT NOT NULL,
+	run_id TEXT NOT NULL,
+	status TEXT NOT NULL,
+	conclusion TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	data TEXT NOT NULL
+)`,
+}
+
+// historyIndexes speed up per-run lookups and point in time queries
+var historyIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS run_history_run ON run_history (platform, project, run_id, id)`,
+	`CREATE INDEX IF NOT EXISTS run_history_created ON run_history (created_at)`,
+}
+
+// openSQLiteHistory opens the SQLite history database, importing the legacy
+// JSON lines history the first time the database is created. The lock keeps
+// processes opening a new profile at once from each importing it.
+func openSQLiteHistory(path, legacyPath string) (HistoryStore, error) {
+	lock, err := acquireLock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	_, statErr := os.Stat(path)
+	created := os.IsNotExist(statErr)
+
+	dsn := "file:" + path + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)"
+	store, err := openSQLHistory("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return store, nil
+	}
+
+	legacy, err := (&jsonlHistoryStore{path: legacyPath}).Query(HistoryFilter{})
+	if err == nil && len(legacy) > 0 {
+		err = store.insert(legacy)
+	}
+	if err != nil {
+		store.Close()
+		// The import is tried again next time, so nothing of the database
+		// may be left behind
+		for _, file := range []string{path, path + "-wal", path + "-shm"} {
+			os.Remove(file)
+		}
+		return nil, fmt.Errorf("failed to import %s: %v", legacyPath, err)
+	}
+	return store, nil
+}
+
+// openSQLHistory connects to a database and ensures the schema exists
+func openSQLHistory(driver, dsn string) (*sqlHistoryStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range append([]string{historySchema[driver]}, historyIndexes...) {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to prepare history database: %v", err)
+		}
+	}
+	return &sqlHistoryStore{db: db, driver: driver}, nil
+}
+
+// rebind rewrites ? placeholders as $n for postgres
+func (s *sqlHistoryStore) rebind(query string) string {
+	if s.driver != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Record inserts changed runs in a single transaction
+func (s *sqlHistoryStore) Record(runs []WorkflowRun, observedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	latest := s.rebind(`SELECT status, conclusion, updated_at FROM run_history
+		WHERE platform = ? AND project = ? AND run_id = ? ORDER BY id DESC LIMIT 1`)
+
+	var changed []RunRecord
+	for _, run := range runs {
+		var previous WorkflowRun
+		var updatedAt int64
+		err := tx.QueryRow(latest, run.Platform, run.Project, run.ID).Scan(&previous.Status, &previous.Conclusion, &updatedAt)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil {
+			previous.UpdatedAt = fromUnixNano(updatedAt)
+			if sameRunState(previous, run) {
+				continue
+			}
+		}
+		changed = append(changed, RunRecord{ObservedAt: observedAt, WorkflowRun: run})
+	}
+
+	if err := s.insertTx(tx, changed); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insert stores records as they are, used when importing history
+func (s *sqlHistoryStore) insert(records []RunRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.insertTx(tx, records); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertTx writes records within a transaction
+func (s *sqlHistoryStore) insertTx(tx *sql.Tx, records []RunRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	stmt, err := tx.Prepare(s.rebind(`INSERT INTO run_history
+		(observed_at, platform, project, run_id, status, conclusion, created_at, updated_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		data, err := json.Marshal(record.WorkflowRun)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(
+			unixNano(record.ObservedAt), record.Platform, record.Project, record.ID,
+			record.Status, record.Conclusion, unixNano(record.CreatedAt), unixNano(record.UpdatedAt),
+			string(data),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query selects matching observations in insertion order
+func (s *sqlHistoryStore) Query(filter HistoryFilter) ([]RunRecord, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Platform != "" {
+		conditions = append(conditions, "platform = ?")
+		args = append(args, filter.Platform)
+	}
+	if filter.Project != "" {
+		conditions = append(conditions, "project = ?")
+		args = append(args, filter.Project)
+	}
+	if filter.RunID != "" {
+		conditions = append(conditions, "run_id = ?")
+		args = append(args, filter.RunID)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, unixNano(filter.CreatedBefore))
+	}
+
+	query := "SELECT observed_at, data FROM run_history"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id"
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []RunRecord
+	for rows.Next() {
+		var observedAt int64
+		var data string
+		if err := rows.Scan(&observedAt, &data); err != nil {
+			return nil, err
+		}
+		record := RunRecord{ObservedAt: fromUnixNano(observedAt)}
+		if err := json.Unmarshal([]byte(data), &record.WorkflowRun); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (s *sqlHistoryStore) Close() error {
+	return s.db.Close()
+}
+
+// unixNano converts a time for storage, keeping the zero time as 0
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano reverses unixNano
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
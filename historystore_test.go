@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		driver string
+		query  string
+		want   string
+	}{
+		{"sqlite", "SELECT 1 WHERE a = ? AND b = ?", "SELECT 1 WHERE a = ? AND b = ?"},
+		{"postgres", "SELECT 1", "SELECT 1"},
+		{"postgres", "SELECT 1 WHERE a = ?", "SELECT 1 WHERE a = $1"},
+		{"postgres", "VALUES (?, ?, ?)", "VALUES ($1, $2, $3)"},
+	}
+	for _, tt := range tests {
+		s := &sqlHistoryStore{driver: tt.driver}
+		if got := s.rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%s, %q) = %q, want %q", tt.driver, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteHistoryRecordQuery(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openSQLiteHistory(filepath.Join(dir, "history.db"), filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatalf("openSQLiteHistory: %v", err)
+	}
+	defer store.Close()
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	older := WorkflowRun{ID: "1", Project: "o/r", Platform: "github", Status: "completed", Conclusion: "success", CreatedAt: created, UpdatedAt: created}
+	newer := WorkflowRun{ID: "2", Project: "o/r", Platform: "github", Status: "in_progress", CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(time.Hour)}
+
+	observed := created.Add(2 * time.Hour)
+	if err := store.Record([]WorkflowRun{older, newer}, observed); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	// Unchanged runs aren't recorded again; a changed one is
+	finished := newer
+	finished.Status, finished.Conclusion = "completed", "failure"
+	finished.UpdatedAt = created.Add(90 * time.Minute)
+	if err := store.Record([]WorkflowRun{older, finished}, observed.Add(time.Minute)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	records, err := store.Query(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Query returned %d records, want 3: %+v", len(records), records)
+	}
+	if got := records[2]; got.ID != "2" || got.Conclusion != "failure" || !got.UpdatedAt.Equal(finished.UpdatedAt) {
+		t.Errorf("last record = %+v, want run 2 finished", got)
+	}
+
+	records, err = store.Query(HistoryFilter{CreatedBefore: created.Add(30 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "1" {
+		t.Errorf("Query(CreatedBefore) = %+v, want only run 1", records)
+	}
+
+	records, err = store.Query(HistoryFilter{RunID: "2"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Query(RunID) returned %d records, want 2", len(records))
+	}
+}
@@ -0,0 +1,139 @@
+// Package color wraps quick_color so output can go without ANSI codes, for
+// NO_COLOR, --color never, and output that isn't a terminal, and so colors
+// can be remapped by a theme. It is imported as qc in place of quick_color.
+package color
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	qc "github.com/bevelwork/quick_color"
+)
+
+// Colors are variables rather than constants so a theme can remap them and
+// disabling color can blank them, which also covers codes concatenated
+// directly rather than through Colorize
+var (
+	ColorReset  = qc.ColorReset
+	ColorRed    = qc.ColorRed
+	ColorGreen  = qc.ColorGreen
+	ColorYellow = qc.ColorYellow
+	ColorBlue   = qc.ColorBlue
+	ColorPurple = qc.ColorPurple
+	ColorCyan   = qc.ColorCyan
+	ColorWhite  = qc.ColorWhite
+	ColorBold   = qc.ColorBold
+)
+
+// names are the colors a theme can remap, and remap them to
+var names = map[string]string{
+	"red":    qc.ColorRed,
+	"green":  qc.ColorGreen,
+	"yellow": qc.ColorYellow,
+	"blue":   qc.ColorBlue,
+	"purple": qc.ColorPurple,
+	"cyan":   qc.ColorCyan,
+	"white":  qc.ColorWhite,
+}
+
+var (
+	enabled = true
+	theme   map[string]string
+)
+
+// Names returns the color names a theme uses, in order
+func Names() []string {
+	return slices.Sorted(maps.Keys(names))
+}
+
+// Enabled reports whether ANSI codes are written
+func Enabled() bool {
+	return enabled
+}
+
+// SetEnabled turns ANSI codes on or off
+func SetEnabled(on bool) {
+	enabled = on
+	apply()
+}
+
+// ParseTheme checks a theme of color names mapped to the names replacing
+// them, e.g. {"red": "purple", "green": "blue"}
+func ParseTheme(remap map[string]string) error {
+	for from, to := range remap {
+		if _, ok := names[from]; !ok {
+			return fmt.Errorf("unknown color %q in theme (expected %s)", from, strings.Join(Names(), ", "))
+		}
+		if _, ok := names[to]; !ok {
+			return fmt.Errorf("unknown color %q in theme (expected %s)", to, strings.Join(Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// SetTheme remaps colors by name; see ParseTheme. An empty theme restores
+// the default colors.
+func SetTheme(remap map[string]string) error {
+	if err := ParseTheme(remap); err != nil {
+		return err
+	}
+	theme = remap
+	apply()
+	return nil
+}
+
+// apply sets the color variables from the theme and whether color is on
+func apply() {
+	code := func(name string) string {
+		if !enabled {
+			return ""
+		}
+		if to, ok := theme[name]; ok {
+			return names[to]
+		}
+		return names[name]
+	}
+	ColorRed = code("red")
+	ColorGreen = code("green")
+	ColorYellow = code("yellow")
+	ColorBlue = code("blue")
+	ColorPurple = code("purple")
+	ColorCyan = code("cyan")
+	ColorWhite = code("white")
+
+	ColorReset, ColorBold = qc.ColorReset, qc.ColorBold
+	if !enabled {
+		ColorReset, ColorBold = "", ""
+	}
+}
+
+// Colorize applies a color and any number of style codes, then resets
+func Colorize(text, colorCode string, styles ...string) string {
+	if !enabled {
+		return text
+	}
+	return qc.Colorize(text, colorCode, styles...)
+}
+
+// ColorizeBold wraps text with the color, adds bold, and resets
+func ColorizeBold(text, colorCode string) string {
+	if !enabled {
+		return text
+	}
+	return qc.ColorizeBold(text, colorCode)
+}
+
+// Dim dims text
+func Dim(text string) string {
+	if !enabled {
+		return text
+	}
+	return qc.Dim(text)
+}
+
+// AlternatingColor returns two colors alternating by index (even/odd)
+func AlternatingColor(index int, evenColor, oddColor string) string {
+	return qc.AlternatingColor(index, evenColor, oddColor)
+}
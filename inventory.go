@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// usesRef matches the action or reusable workflow referenced by "uses:"
+	usesRef = regexp.MustCompile(`uses:\s*["']?([^\s"'#]+)`)
+	// fullSHA matches a full commit SHA
+	fullSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// versionTag matches version tags like v4, v1.2.3, or 2.0
+	versionTag = regexp.MustCompile(`^v?\d+(\.\d+)*([-+.][0-9A-Za-z.-]+)?$`)
+	// claimedTag matches the tag a SHA pin claims in its trailing comment,
+	// e.g. "# v4.1.1" or "# tag=v4.1.1"
+	claimedTag = regexp.MustCompile(`#\s*(?:tag=|pin@)?(v?\d+(?:\.\d+)*\S*)`)
+)
+
+// actionUse is one reference to an external action, reusable workflow, or
+// CI include in a project's CI files
+type actionUse struct {
+	Project string
+	Path    string
+	Line    int
+	Kind    string // action, workflow, docker, project, component, remote, template
+	Name    string
+	Ref     string
+	Pin     string // sha, digest, tag, branch, none
+	// Claimed is the tag named in a trailing comment of a GitHub reference
+	Claimed string
+}
+
+// pinStyle classifies how firmly a git ref pins what it refers to
+func pinStyle(ref string) string {
+	switch {
+	case ref == "":
+		return "none"
+	case fullSHA.MatchString(ref):
+		return "sha"
+	case versionTag.MatchString(ref):
+		return "tag"
+	default:
+		return "branch"
+	}
+}
+
+// parseUses splits a "uses:" value into an actionUse. Local actions return
+// false since they are versioned with the repository itself.
+func parseUses(value string) (actionUse, bool) {
+	if strings.HasPrefix(value, "./") || strings.HasPrefix(value, "../") {
+		return actionUse{}, false
+	}
+
+	if image, ok := strings.CutPrefix(value, "docker://"); ok {
+		use := actionUse{Kind: "docker", Name: image, Pin: "none"}
+		if name, digest, ok := strings.Cut(image, "@"); ok {
+			use.Name, use.Ref, use.Pin = name, digest, "digest"
+		} else if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			use.Name, use.Ref, use.Pin = image[:i], image[i+1:], "tag"
+			if use.Ref == "latest" {
+				use.Pin = "branch"
+			}
+		}
+		return use, true
+	}
+
+	name, ref, _ := strings.Cut(value, "@")
+	use := actionUse{Kind: "action", Name: name, Ref: ref, Pin: pinStyle(ref)}
+	if strings.Contains(name, "/.github/workflows/") {
+		use.Kind = "workflow"
+	}
+	return use, true
+}
+
+// scanWorkflowUses lists the external actions and reusable workflows a
+// GitHub workflow file uses
+func scanWorkflowUses(project string, file WorkflowFile) []actionUse {
+	var uses []actionUse
+	for i, line := range strings.Split(file.Content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, match := range usesRef.FindAllStringSubmatch(line, -1) {
+			use, ok := parseUses(match[1])
+			if !ok {
+				continue
+			}
+			use.Project, use.Path, use.Line = project, file.Path, i+1
+			if claimed := claimedTag.FindStringSubmatch(line); claimed != nil {
+				use.Claimed = claimed[1]
+			}
+			uses = append(uses, use)
+		}
+	}
+	return uses
+}
+
+// scanGitLabIncludes lists the external includes of a .gitlab-ci.yml:
+// other projects' files, CI/CD components, remote URLs, and templates
+func scanGitLabIncludes(project string, file WorkflowFile) []actionUse {
+	root, err := parseWorkflowFile(file)
+	if err != nil {
+		return nil
+	}
+	include := mapGet(root, "include")
+	if include == nil {
+		return nil
+	}
+
+	entries := []*yaml.Node{include}
+	if include.Kind == yaml.SequenceNode {
+		entries = include.Content
+	}
+
+	var uses []actionUse
+	for _, entry := range entries {
+		use := actionUse{Project: project, Path: file.Path, Line: entry.Line}
+		switch entry.Kind {
+		case yaml.ScalarNode:
+			// A bare string is a remote URL or a local file
+			if !strings.Contains(entry.Value, "://") {
+				continue
+			}
+			use.Kind, use.Name, use.Pin = "remote", entry.Value, "none"
+		case yaml.MappingNode:
+			switch {
+			case mapGet(entry, "project") != nil:
+				use.Kind, use.Name = "project", mapGet(entry, "project").Value
+				if ref := mapGet(entry, "ref"); ref != nil {
+					use.Ref = ref.Value
+				}
+				use.Pin = pinStyle(use.Ref)
+				if files := scalarValues(mapGet(entry, "file")); len(files) > 0 {
+					use.Name += ":" + strings.Join(files, ",")
+				}
+			case mapGet(entry, "component") != nil:
+				name, ref, _ := strings.Cut(mapGet(entry, "component").Value, "@")
+				use.Kind, use.Name, use.Ref = "component", name, ref
+				use.Pin = pinStyle(ref)
+				if strings.HasPrefix(ref, "~latest") {
+					use.Pin = "branch"
+				}
+			case mapGet(entry, "remote") != nil:
+				use.Kind, use.Name, use.Pin = "remote", mapGet(entry, "remote").Value, "none"
+			case mapGet(entry, "template") != nil:
+				// Templates ship with the GitLab instance
+				use.Kind, use.Name, use.Pin = "template", mapGet(entry, "template").Value, "tag"
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+		uses = append(uses, use)
+	}
+	return uses
+}
+
+// colorPin colors pin styles from safest to riskiest, padded to a fixed width
+func colorPin(pin string) string {
+	padded := fmt.Sprintf("%-6s", pin)
+	switch pin {
+	case "sha", "digest":
+		return qc.Colorize(padded, qc.ColorGreen)
+	case "tag":
+		return qc.Colorize(padded, qc.ColorYellow)
+	default:
+		return qc.Colorize(padded, qc.ColorRed)
+	}
+}
+
+// handleInventory handles the inventory command
+func handleInventory(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow inventory actions|verify [options] [project]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	switch args[0] {
+	case "actions":
+		inventoryActions(ctx, config, args[1:])
+	case "verify":
+		verifyPins(ctx, config, args[1:])
+	default:
+		fmt.Printf("%s Unknown inventory command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+	}
+}
+
+// inventoryActions lists every external action and include used across
+// tracked projects, with the versions in use and how they are pinned
+func inventoryActions(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("inventory actions", flag.ExitOnError)
+	unpinned := fs.Bool("unpinned", false, "Only show references not pinned to a commit SHA or digest")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	var uses []actionUse
+	for _, project := range projects {
+		files, err := loadMigrationSource(ctx, project, "")
+		if err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+		for _, file := range files {
+			if project.Platform == "gitlab" {
+				uses = append(uses, scanGitLabIncludes(project.Name, file)...)
+			} else {
+				uses = append(uses, scanWorkflowUses(project.Name, file)...)
+			}
+		}
+	}
+
+	pins := make(map[string]int)
+	for _, use := range uses {
+		pins[use.Pin]++
+	}
+	if *unpinned {
+		var filtered []actionUse
+		for _, use := range uses {
+			if use.Pin != "sha" && use.Pin != "digest" {
+				filtered = append(filtered, use)
+			}
+		}
+		uses = filtered
+	}
+
+	if len(uses) == 0 {
+		fmt.Printf("%s No external actions or includes found in %d project(s)\n", qc.Colorize("Info:", qc.ColorCyan), len(projects))
+		return
+	}
+
+	// name -> ref -> uses
+	byName := make(map[string]map[string][]actionUse)
+	for _, use := range uses {
+		if byName[use.Name] == nil {
+			byName[use.Name] = make(map[string][]actionUse)
+		}
+		byName[use.Name][use.Ref] = append(byName[use.Name][use.Ref], use)
+	}
+	names := slices.Sorted(maps.Keys(byName))
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Actions and includes across %d project(s):", len(projects)), qc.ColorBlue))
+	for _, name := range names {
+		refs := make([]string, 0, len(byName[name]))
+		longest := 0
+		for ref := range byName[name] {
+			refs = append(refs, ref)
+			if len(ref) > longest {
+				longest = len(ref)
+			}
+		}
+		sort.Strings(refs)
+
+		fmt.Printf("\n%s %s\n", qc.ColorizeBold(name, qc.ColorWhite), qc.Colorize("("+byName[name][refs[0]][0].Kind+")", qc.ColorCyan))
+		for _, ref := range refs {
+			refUses := byName[name][ref]
+			var projectNames []string
+			for _, use := range refUses {
+				if !slices.Contains(projectNames, use.Project) {
+					projectNames = append(projectNames, use.Project)
+				}
+			}
+			label := ref
+			if label == "" {
+				label = "-"
+			}
+			fmt.Printf("  %-*s  %s  %s\n", max(longest, 1), label, colorPin(refUses[0].Pin), strings.Join(projectNames, ", "))
+		}
+	}
+
+	fmt.Printf("\n%s %d pinned to a SHA or digest, %d to a tag, %d to a branch, %d unpinned\n",
+		qc.Colorize("Pinning:", qc.ColorBlue), pins["sha"]+pins["digest"], pins["tag"], pins["branch"], pins["none"])
+}
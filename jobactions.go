@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// jobAction plays, retries, or cancels a GitLab job
+type jobAction struct {
+	verb string
+	done string
+	// after is the job's status once the action succeeds
+	after   string
+	allowed func(status string) bool
+	run     func(client *GitLabClient, projectID string, job Job) (string, error)
+}
+
+// jobActions are keyed by the letter typed before the job number
+var jobActions = map[string]jobAction{
+	"p": {
+		verb:    "Play",
+		done:    "Started",
+		after:   "pending",
+		allowed: func(status string) bool { return status == "manual" },
+		run: func(client *GitLabClient, projectID string, job Job) (string, error) {
+			return "", client.PlayJob(projectID, job.ID)
+		},
+	},
+	"r": {
+		verb: "Retry",
+		done: "Retried",
+		// The retry is a new job; this one stays finished
+		after: "retried",
+		allowed: func(status string) bool {
+			return status == "failed" || status == "canceled" || status == "success"
+		},
+		run: func(client *GitLabClient, projectID string, job Job) (string, error) {
+			newID, err := client.RetryJob(projectID, job.ID)
+			if err != nil {
+				return "", err
+			}
+			return "new job " + newID, nil
+		},
+	},
+	"c": {
+		verb:  "Cancel",
+		done:  "Canceled",
+		after: "canceled",
+		allowed: func(status string) bool {
+			switch status {
+			case "created", "pending", "running", "preparing", "scheduled", "waiting_for_resource":
+				return true
+			}
+			return false
+		},
+		run: func(client *GitLabClient, projectID string, job Job) (string, error) {
+			return "", client.CancelJob(projectID, job.ID)
+		},
+	},
+}
+
+// promptJobActions lets the user play manual jobs and retry or cancel jobs
+// of a GitLab run shown in the details view, by the job's number, and open
+// the details of a downstream pipeline with d<n>
+func promptJobActions(ctx context.Context, config *Config, reader *bufio.Reader, run WorkflowRun, jobs []Job, downstream []DownstreamPipeline, logLines int) {
+	if run.Platform != "gitlab" || (len(jobs) == 0 && len(downstream) == 0) {
+		return
+	}
+
+	prompt := "Job action (p<n> play, r<n> retry, c<n> cancel"
+	if len(downstream) > 0 {
+		prompt += ", d<n> open downstream pipeline"
+	}
+	prompt += ", Enter to finish): "
+
+	for {
+		fmt.Printf("\n%s", qc.Colorize(prompt, qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		input = strings.TrimSpace(input)
+		if input == "" || input == "q" {
+			return
+		}
+
+		if strings.HasPrefix(input, "d") {
+			index, err := strconv.Atoi(strings.TrimSpace(input[1:]))
+			if err != nil || index < 1 || index > len(downstream) {
+				fmt.Printf("%s No downstream pipeline %s\n", qc.Colorize("Error:", qc.ColorRed), input)
+				continue
+			}
+			child := downstream[index-1].Run
+			childJobs, childDownstream := showWorkflowDetails(ctx, config, child, logLines)
+			promptJobActions(ctx, config, reader, child, childJobs, childDownstream, logLines)
+			continue
+		}
+
+		action, ok := jobActions[input[:1]]
+		index, err := strconv.Atoi(strings.TrimSpace(input[1:]))
+		if !ok || err != nil || index < 1 || index > len(jobs) {
+			fmt.Printf("%s Enter an action letter followed by a job number, e.g. r3\n", qc.Colorize("Error:", qc.ColorRed))
+			continue
+		}
+		job := &jobs[index-1]
+		if !action.allowed(job.Status) {
+			fmt.Printf("%s Can't %s %s while it is %s\n", qc.Colorize("Error:", qc.ColorRed), strings.ToLower(action.verb), job.Name, job.Status)
+			continue
+		}
+		if !confirm(reader, fmt.Sprintf("%s job %s?", action.verb, job.Name)) {
+			continue
+		}
+
+		client, err := NewGitLabClient(ctx, run.Project)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		detail, err := action.run(client, run.Project, *job)
+		recordAction(config, "job "+strings.ToLower(action.verb), run.Project, map[string]string{"run": run.ID, "job": job.Name},
+			strings.TrimSpace(strings.ToLower(action.done)+" job "+job.ID+" "+detail), err)
+		if err != nil {
+			fmt.Printf("%s Failed to %s job: %v\n", qc.Colorize("Error:", qc.ColorRed), strings.ToLower(action.verb), describeError(err))
+			continue
+		}
+
+		message := fmt.Sprintf("%s job %s", action.done, job.Name)
+		if detail != "" {
+			message += " (" + detail + ")"
+		}
+		fmt.Printf("%s %s\n", qc.Colorize("Success:", qc.ColorGreen), message)
+		// Keep the same job from being acted on twice from stale state
+		job.Status = action.after
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// jobGroup is a job, or the matrix jobs sharing a base name, in the order
+// they first appear in a run
+type jobGroup struct {
+	Name string
+	// Indexes point into the run's jobs so numbering stays the same
+	// whether a group is collapsed or not
+	Indexes []int
+	Matrix  bool
+}
+
+// groupMatrixJobs groups matrix jobs under their base job name. A matrix
+// job without siblings is left on its own.
+func groupMatrixJobs(platform string, jobs []Job) []jobGroup {
+	var groups []jobGroup
+	byName := make(map[string]int)
+	for i, job := range jobs {
+		base, _, ok := parseMatrixJobName(platform, job.Name)
+		if !ok {
+			groups = append(groups, jobGroup{Name: job.Name, Indexes: []int{i}})
+			continue
+		}
+		if g, seen := byName[base]; seen {
+			groups[g].Indexes = append(groups[g].Indexes, i)
+			groups[g].Matrix = true
+			continue
+		}
+		byName[base] = len(groups)
+		groups = append(groups, jobGroup{Name: base, Indexes: []int{i}})
+	}
+	return groups
+}
+
+// matrixJobPassed reports whether a matrix job can be folded into its
+// group's summary: it finished without anything to look into
+func matrixJobPassed(job Job) bool {
+	return job.Conclusion == "success" || job.Conclusion == "skipped"
+}
+
+// jobGroupSummary counts a group's jobs by outcome
+func jobGroupSummary(jobs []Job, group jobGroup) string {
+	var passed, failed, skipped, other int
+	for _, i := range group.Indexes {
+		switch job := jobs[i]; {
+		case job.Conclusion == "success":
+			passed++
+		case isFailedConclusion(job.Conclusion):
+			failed++
+		case job.Conclusion == "skipped":
+			skipped++
+		default:
+			other++
+		}
+	}
+
+	parts := []string{qc.Colorize(fmt.Sprintf("%d passed", passed), qc.ColorGreen)}
+	if failed > 0 {
+		parts = append(parts, qc.Colorize(fmt.Sprintf("%d failed", failed), qc.ColorRed))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", skipped))
+	}
+	if other > 0 {
+		parts = append(parts, qc.Colorize(fmt.Sprintf("%d not finished", other), qc.ColorYellow))
+	}
+	return fmt.Sprintf("%d matrix jobs: %s", len(group.Indexes), strings.Join(parts, ", "))
+}
+
+// slowestStep returns the index and duration of a job's slowest step, or
+// -1 when no step has timing
+func slowestStep(job Job) (int, time.Duration) {
+	slowest := -1
+	var longest time.Duration
+	for i, step := range job.Steps {
+		if d, ok := stepDuration(step); ok && d > longest {
+			slowest, longest = i, d
+		}
+	}
+	return slowest, longest
+}
+
+// displayJob prints a numbered job with its steps
+func displayJob(platform string, index int, job Job, indent string) {
+	rowColor := qc.AlternatingColor(index, qc.ColorWhite, qc.ColorCyan)
+	statusColor := colorJobStatus(job.Status, job.Conclusion)
+
+	entry := fmt.Sprintf(
+		"%s%3d. %-30s [%s] %s",
+		indent, index+1, job.Name,
+		qc.Colorize(job.Status, statusColor),
+		elapsedBetween(job.StartedAt, job.CompletedAt),
+	)
+	if wait, ok := jobQueueTime(job); ok && wait >= time.Second {
+		entry += ", queued " + formatDuration(wait)
+	}
+	fmt.Println(qc.Colorize(entry, rowColor))
+	if job.Runner != nil {
+		fmt.Printf("%s      %s\n", indent, qc.Colorize(describeRunner(platform, *job.Runner), qc.ColorCyan))
+	}
+
+	// GitLab jobs have a single step standing in for the job itself
+	if len(job.Steps) == 1 && job.Steps[0].Name == job.Name {
+		return
+	}
+
+	slowest, _ := slowestStep(job)
+	for j, step := range job.Steps {
+		line := fmt.Sprintf("%s      %s %-40s %s",
+			indent,
+			qc.Colorize(stepMarker(step), colorJobStatus(step.Status, step.Conclusion)),
+			step.Name,
+			elapsedBetween(step.StartedAt, step.CompletedAt),
+		)
+		if j == slowest && len(job.Steps) > 1 {
+			line += qc.Colorize(" ← slowest", qc.ColorYellow)
+		}
+		fmt.Println(line)
+	}
+}
+
+// describeRunner summarizes where a job ran, e.g. "runner: build-07
+// (self-hosted, group linux) labels: self-hosted, x64"
+func describeRunner(platform string, runner JobRunner) string {
+	text := "runner: "
+	if runner.Name == "" {
+		text += "not assigned yet"
+	} else {
+		text += runner.Name
+		details := []string{runner.Kind}
+		// Hosted runners all share the default group
+		if runner.Group != "" && runner.Kind == "self-hosted" {
+			details = append(details, "group "+runner.Group)
+		}
+		text += " (" + strings.Join(details, ", ") + ")"
+	}
+	if len(runner.Labels) > 0 {
+		label := " labels: "
+		if platform == "gitlab" {
+			label = " tags: "
+		}
+		text += label + strings.Join(runner.Labels, ", ")
+	}
+	return text
+}
+
+// displayJobGroups prints a run's jobs with matrix jobs under their base
+// name. Matrix jobs that passed are only counted in the group's summary.
+func displayJobGroups(platform string, jobs []Job) {
+	for _, group := range groupMatrixJobs(platform, jobs) {
+		if !group.Matrix {
+			displayJob(platform, group.Indexes[0], jobs[group.Indexes[0]], "  ")
+			continue
+		}
+
+		fmt.Printf("  %s  %s\n", qc.ColorizeBold(group.Name, qc.ColorWhite), jobGroupSummary(jobs, group))
+		hidden := 0
+		for _, i := range group.Indexes {
+			if matrixJobPassed(jobs[i]) {
+				hidden++
+				continue
+			}
+			displayJob(platform, i, jobs[i], "    ")
+		}
+		if hidden > 0 {
+			fmt.Printf("    %s\n", qc.Colorize(fmt.Sprintf("… %d passed or skipped job(s) hidden", hidden), qc.ColorCyan))
+		}
+	}
+}
+
+// promptMatrixGroups offers to show every job of the matrix groups the
+// details view collapsed
+func promptMatrixGroups(reader *bufio.Reader, run WorkflowRun, jobs []Job) {
+	var collapsed []jobGroup
+	for _, group := range groupMatrixJobs(run.Platform, jobs) {
+		if !group.Matrix {
+			continue
+		}
+		for _, i := range group.Indexes {
+			if matrixJobPassed(jobs[i]) {
+				collapsed = append(collapsed, group)
+				break
+			}
+		}
+	}
+	if len(collapsed) == 0 {
+		return
+	}
+
+	for {
+		fmt.Printf("\n%s", qc.Colorize("Expand matrix jobs (job name or 'all', Enter to continue): ", qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		input = strings.TrimSpace(input)
+		if input == "" || input == "q" {
+			return
+		}
+
+		found := false
+		for _, group := range collapsed {
+			if input != "all" && group.Name != input {
+				continue
+			}
+			found = true
+			fmt.Printf("\n  %s  %s\n", qc.ColorizeBold(group.Name, qc.ColorWhite), jobGroupSummary(jobs, group))
+			for _, i := range group.Indexes {
+				displayJob(run.Platform, i, jobs[i], "    ")
+			}
+		}
+		if !found {
+			fmt.Printf("%s No collapsed matrix job named %s\n", qc.Colorize("Error:", qc.ColorRed), input)
+		}
+	}
+}
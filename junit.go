@@ -0,0 +1,110 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// junitSuite is a <testsuite>, which may nest further suites
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Cases  []junitCase  `xml:"testcase"`
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+// junitCase is a <testcase>. A failure, error, or skipped child element
+// marks how it ended; without one it passed.
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitOutcome `xml:"failure"`
+	Error     *junitOutcome `xml:"error"`
+	Skipped   *junitOutcome `xml:"skipped"`
+}
+
+type junitOutcome struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// summary returns the message attribute, or the first line of the body
+// when there is none
+func (o *junitOutcome) summary() string {
+	if message := strings.TrimSpace(o.Message); message != "" {
+		return message
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(o.Text), "\n")
+	return line
+}
+
+// parseJUnit reads the test cases of a JUnit XML report, whose root is
+// either <testsuites> or a single <testsuite>
+func parseJUnit(r io.Reader) ([]TestResult, error) {
+	var root struct {
+		XMLName xml.Name
+		junitSuite
+	}
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "testsuites" && root.XMLName.Local != "testsuite" {
+		return nil, fmt.Errorf("not a JUnit report: root element is <%s>", root.XMLName.Local)
+	}
+
+	var results []TestResult
+	var walk func(suite junitSuite)
+	walk = func(suite junitSuite) {
+		for _, tc := range suite.Cases {
+			result := TestResult{Suite: firstNonEmpty(suite.Name, tc.Classname), Name: tc.Name, Status: "passed"}
+			switch {
+			case tc.Failure != nil:
+				result.Status, result.Message = "failed", tc.Failure.summary()
+			case tc.Error != nil:
+				result.Status, result.Message = "error", tc.Error.summary()
+			case tc.Skipped != nil:
+				result.Status, result.Message = "skipped", tc.Skipped.summary()
+			}
+			if seconds, err := time.ParseDuration(tc.Time + "s"); err == nil {
+				result.Duration = seconds
+			}
+			results = append(results, result)
+		}
+		for _, child := range suite.Suites {
+			walk(child)
+		}
+	}
+	walk(root.junitSuite)
+	return results, nil
+}
+
+// parseJUnitArchive reads the JUnit reports in a zip archive. XML files
+// that aren't JUnit reports are skipped.
+func parseJUnitArchive(data []byte) ([]TestResult, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var results []TestResult
+	for _, file := range zr.File {
+		if !strings.HasSuffix(strings.ToLower(file.Name), ".xml") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		found, err := parseJUnit(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		results = append(results, found...)
+	}
+	return results, nil
+}
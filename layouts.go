@@ -0,0 +1,261 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// WatchLayout is a named preset for the watch view
+type WatchLayout struct {
+	Columns  string   `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Projects []string `json:"projects,omitempty" yaml:"projects,omitempty"`
+	Branches []string `json:"branches,omitempty" yaml:"branches,omitempty"`
+	Statuses []string `json:"statuses,omitempty" yaml:"statuses,omitempty"`
+	Sort     string   `json:"sort,omitempty" yaml:"sort,omitempty"`         // created (default), project, status, duration
+	GroupBy  string   `json:"group_by,omitempty" yaml:"group_by,omitempty"` // project, status, branch, or empty
+	Refresh  string   `json:"refresh,omitempty" yaml:"refresh,omitempty"`   // e.g. 30s; empty shows once
+}
+
+// validate checks the layout's sort, grouping, refresh, and columns
+func (l WatchLayout) validate() error {
+	switch l.Sort {
+	case "", "created", "project", "status", "duration":
+	default:
+		return fmt.Errorf("invalid sort %q (expected created, project, status, or duration)", l.Sort)
+	}
+	switch l.GroupBy {
+	case "", "project", "status", "branch":
+	default:
+		return fmt.Errorf("invalid group_by %q (expected project, status, or branch)", l.GroupBy)
+	}
+	if _, err := l.refreshInterval(); err != nil {
+		return err
+	}
+	if l.Columns != "" {
+		if _, err := parseColumns(l.Columns, Settings{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshInterval parses the refresh setting; zero means render once
+func (l WatchLayout) refreshInterval() (time.Duration, error) {
+	if l.Refresh == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(l.Refresh)
+	if err != nil {
+		return 0, fmt.Errorf("invalid refresh interval %q", l.Refresh)
+	}
+	if interval < 5*time.Second {
+		return 0, fmt.Errorf("refresh interval must be at least 5s")
+	}
+	return interval, nil
+}
+
+// selectProjects returns the tracked projects included by the layout:
+// those it names, or else every project that isn't archived
+func (l WatchLayout) selectProjects(projects []Project) []Project {
+	if len(l.Projects) == 0 {
+		return activeProjects(projects)
+	}
+	var selected []Project
+	for _, project := range projects {
+		if slices.Contains(l.Projects, project.Name) {
+			selected = append(selected, project)
+		}
+	}
+	return selected
+}
+
+// filterRuns keeps runs matching the layout's branch and status filters.
+// A status filter matches either the status or the conclusion.
+func (l WatchLayout) filterRuns(runs []WorkflowRun) []WorkflowRun {
+	var filtered []WorkflowRun
+	for _, run := range runs {
+		if len(l.Branches) > 0 && !slices.Contains(l.Branches, run.Branch) {
+			continue
+		}
+		if len(l.Statuses) > 0 && !slices.Contains(l.Statuses, run.Status) && !slices.Contains(l.Statuses, run.Conclusion) {
+			continue
+		}
+		filtered = append(filtered, run)
+	}
+	return filtered
+}
+
+// sortRuns orders runs by the layout's group, then its sort key. Newest
+// runs come first within equal keys.
+func (l WatchLayout) sortRuns(runs []WorkflowRun) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		if gi, gj := groupKey(runs[i], l.GroupBy), groupKey(runs[j], l.GroupBy); gi != gj {
+			return gi < gj
+		}
+		switch l.Sort {
+		case "project":
+			if runs[i].Project != runs[j].Project {
+				return runs[i].Project < runs[j].Project
+			}
+		case "status":
+			if runs[i].Status != runs[j].Status {
+				return runs[i].Status < runs[j].Status
+			}
+		case "duration":
+			di, dj := runElapsed(runs[i]), runElapsed(runs[j])
+			if di != dj {
+				return di > dj
+			}
+		}
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+}
+
+// groupKey returns the value runs are grouped by
+func groupKey(run WorkflowRun, groupBy string) string {
+	switch groupBy {
+	case "project":
+		return run.Project
+	case "status":
+		return run.Status
+	case "branch":
+		return run.Branch
+	default:
+		return ""
+	}
+}
+
+// runElapsed returns how long a run took or has been running
+func runElapsed(run WorkflowRun) time.Duration {
+	if isRunFinished(run.Status) {
+		return run.UpdatedAt.Sub(run.CreatedAt)
+	}
+	return time.Since(run.CreatedAt)
+}
+
+// displayGroupedRuns displays runs with a header for each group. Numbering
+// runs across groups so selections refer to the same index.
+func displayGroupedRuns(runs []WorkflowRun, columns []string, groupBy string) {
+	if groupBy == "" {
+		displayWorkflowRuns(runs, columns)
+		return
+	}
+
+	widths := layoutColumns(runs, columns, terminalWidth())
+	current := ""
+	for i, run := range runs {
+		if key := groupKey(run, groupBy); i == 0 || key != current {
+			current = key
+			if key == "" {
+				key = "(none)"
+			}
+			fmt.Printf("%s\n", qc.ColorizeBold(key, qc.ColorBlue))
+		}
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		fmt.Println(formatRunRow(i+1, run, columns, widths, rowColor))
+	}
+}
+
+// splitList splits a comma separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// handleLayoutSettings manages named watch layouts
+func handleLayoutSettings(config *Config, args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		if len(config.Settings.Layouts) == 0 {
+			fmt.Printf("%s No layouts defined. Use 'quick_workflow settings layout set <name> [options]'.\n", qc.Colorize("Info:", qc.ColorCyan))
+			return
+		}
+		names := slices.Sorted(maps.Keys(config.Settings.Layouts))
+
+		fmt.Printf("%s\n", qc.Colorize("Watch Layouts:", qc.ColorBlue))
+		for _, name := range names {
+			l := config.Settings.Layouts[name]
+			fmt.Printf("  %s columns=%s projects=%s branches=%s statuses=%s sort=%s group_by=%s refresh=%s\n",
+				qc.ColorizeBold(name, qc.ColorGreen), l.Columns,
+				strings.Join(l.Projects, ","), strings.Join(l.Branches, ","), strings.Join(l.Statuses, ","),
+				l.Sort, l.GroupBy, l.Refresh)
+		}
+		return
+	}
+
+	switch args[0] {
+	case "set":
+		fs := flag.NewFlagSet("settings layout set", flag.ExitOnError)
+		columns := fs.String("columns", "", "Comma separated columns")
+		projects := fs.String("projects", "", "Comma separated projects to include (default: all)")
+		branches := fs.String("branches", "", "Comma separated branches to include")
+		statuses := fs.String("statuses", "", "Comma separated statuses or conclusions to include")
+		sortBy := fs.String("sort", "", "Sort by created, project, status, or duration")
+		groupBy := fs.String("group-by", "", "Group by project, status, or branch")
+		refresh := fs.String("refresh", "", "Refresh interval, e.g. 30s (default: show once)")
+		if len(args) < 2 {
+			fmt.Printf("%s Usage: quick_workflow settings layout set <name> [options]\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		name := args[1]
+		fs.Parse(args[2:])
+
+		layout := WatchLayout{
+			Columns:  *columns,
+			Projects: splitList(*projects),
+			Branches: splitList(*branches),
+			Statuses: splitList(*statuses),
+			Sort:     *sortBy,
+			GroupBy:  *groupBy,
+			Refresh:  *refresh,
+		}
+		if err := layout.validate(); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+
+		err := updateProjects(config, func(config *Config) error {
+			if config.Settings.Layouts == nil {
+				config.Settings.Layouts = make(map[string]WatchLayout)
+			}
+			config.Settings.Layouts[name] = layout
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Saved layout %s\n", qc.Colorize("Success:", qc.ColorGreen), name)
+	case "delete":
+		if len(args) < 2 {
+			fmt.Printf("%s Usage: quick_workflow settings layout delete <name>\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		name := args[1]
+		err := updateProjects(config, func(config *Config) error {
+			if _, ok := config.Settings.Layouts[name]; !ok {
+				return fmt.Errorf("layout not found: %s", name)
+			}
+			delete(config.Settings.Layouts, name)
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Deleted layout %s\n", qc.Colorize("Success:", qc.ColorGreen), name)
+	default:
+		fmt.Printf("%s Unknown layout command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+	}
+}
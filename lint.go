@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"gopkg.in/yaml.v3"
+)
+
+// lintProblem is one problem found in a CI file. Line is 0 when the
+// problem can't be tied to a line.
+type lintProblem struct {
+	Path    string
+	Line    int
+	Warning bool
+	Message string
+}
+
+var (
+	// workflowKeys, workflowJobKeys, and workflowStepKeys are the keys
+	// GitHub accepts at each level of a workflow
+	workflowKeys    = []string{"name", "run-name", "on", "permissions", "env", "defaults", "concurrency", "jobs"}
+	workflowJobKeys = []string{
+		"name", "permissions", "needs", "if", "runs-on", "environment", "concurrency", "outputs", "env",
+		"defaults", "steps", "timeout-minutes", "strategy", "continue-on-error", "container", "services",
+		"uses", "with", "secrets",
+	}
+	workflowStepKeys = []string{"id", "if", "name", "uses", "run", "shell", "with", "env", "continue-on-error", "timeout-minutes", "working-directory"}
+
+	// workflowEvents are the events a workflow can be triggered by
+	workflowEvents = []string{
+		"branch_protection_rule", "check_run", "check_suite", "create", "delete", "deployment",
+		"deployment_status", "discussion", "discussion_comment", "fork", "gollum", "issue_comment",
+		"issues", "label", "merge_group", "milestone", "page_build", "project", "project_card",
+		"project_column", "public", "pull_request", "pull_request_review", "pull_request_review_comment",
+		"pull_request_target", "push", "registry_package", "release", "repository_dispatch", "schedule",
+		"status", "watch", "workflow_call", "workflow_dispatch", "workflow_run",
+	}
+
+	// workflowJobID matches the job IDs GitHub accepts
+	workflowJobID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	// yamlErrorLine matches the line number in YAML parser errors
+	yamlErrorLine = regexp.MustCompile(`line (\d+): `)
+	// gitlabLintLocation matches the job or key a GitLab lint message is
+	// about, e.g. "jobs:build:script config ..." or "build job: ..."
+	gitlabLintLocation = regexp.MustCompile(`^(?:jobs:(\S+?)|(\S+) job):? `)
+)
+
+// parseLintYAML parses a CI file, reporting syntax errors with their line
+func parseLintYAML(file WorkflowFile) (*yaml.Node, []lintProblem) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(file.Content), &doc); err != nil {
+		var problems []lintProblem
+		// Unmarshal errors list one problem per line
+		for _, message := range strings.Split(strings.TrimPrefix(err.Error(), "yaml: "), "\n") {
+			message = strings.TrimSpace(message)
+			if message == "" || message == "unmarshal errors:" {
+				continue
+			}
+			problem := lintProblem{Path: file.Path, Message: message}
+			if match := yamlErrorLine.FindStringSubmatchIndex(message); match != nil {
+				problem.Line, _ = strconv.Atoi(message[match[2]:match[3]])
+				problem.Message = message[:match[0]] + message[match[1]:]
+			}
+			problems = append(problems, problem)
+		}
+		return nil, problems
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, []lintProblem{{Path: file.Path, Line: 1, Message: "file is not a YAML mapping"}}
+	}
+	return doc.Content[0], duplicateKeys(file.Path, doc.Content[0])
+}
+
+// duplicateKeys reports keys repeated in a mapping, which YAML parsers
+// resolve by silently keeping one of the values
+func duplicateKeys(path string, node *yaml.Node) []lintProblem {
+	var problems []lintProblem
+	if node.Kind == yaml.MappingNode {
+		seen := make(map[string]int)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if first, ok := seen[key.Value]; ok {
+				problems = append(problems, lintProblem{Path: path, Line: key.Line, Message: fmt.Sprintf("key %q is already defined on line %d", key.Value, first)})
+			} else {
+				seen[key.Value] = key.Line
+			}
+		}
+	}
+	for _, child := range node.Content {
+		problems = append(problems, duplicateKeys(path, child)...)
+	}
+	return problems
+}
+
+// mapKeyLine returns the line of key in a mapping node, or the line of the
+// mapping itself when key is missing
+func mapKeyLine(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i].Line
+		}
+	}
+	return node.Line
+}
+
+// lintGitHubWorkflow checks a GitHub Actions workflow against the workflow
+// syntax: known keys, triggers, job structure, needs, and expressions
+func lintGitHubWorkflow(file WorkflowFile) []lintProblem {
+	root, problems := parseLintYAML(file)
+	if root == nil {
+		return problems
+	}
+	add := func(line int, format string, args ...interface{}) {
+		problems = append(problems, lintProblem{Path: file.Path, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+	unknownKeys := func(node *yaml.Node, allowed []string, where string) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; !slices.Contains(allowed, key.Value) {
+				add(key.Line, "unknown key %q %s", key.Value, where)
+			}
+		}
+	}
+
+	unknownKeys(root, workflowKeys, "at the top level")
+	if on := mapGet(root, "on"); on == nil {
+		add(1, "missing the on key with the events that trigger the workflow")
+	} else {
+		lintWorkflowTriggers(on, add)
+	}
+
+	jobs := mapGet(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode || len(jobs.Content) == 0 {
+		add(mapKeyLine(root, "jobs"), "jobs must be a mapping with at least one job")
+		lintExpressions(root, add)
+		return problems
+	}
+
+	jobIDs := make(map[string]bool)
+	for _, entry := range mapEntries(jobs) {
+		jobIDs[entry.key] = true
+	}
+	for _, entry := range mapEntries(jobs) {
+		line := mapKeyLine(jobs, entry.key)
+		if !workflowJobID.MatchString(entry.key) {
+			add(line, "job ID %q must start with a letter or _ and contain only letters, digits, - and _", entry.key)
+		}
+		job := entry.value
+		if job.Kind != yaml.MappingNode {
+			add(line, "job %s must be a mapping", entry.key)
+			continue
+		}
+		unknownKeys(job, workflowJobKeys, "in job "+entry.key)
+
+		for _, need := range scalarValues(mapGet(job, "needs")) {
+			switch {
+			case need == entry.key:
+				add(mapKeyLine(job, "needs"), "job %s needs itself", entry.key)
+			case !jobIDs[need]:
+				add(mapKeyLine(job, "needs"), "job %s needs unknown job %q", entry.key, need)
+			}
+		}
+
+		// Reusable workflow calls run elsewhere and have no steps
+		if mapGet(job, "uses") != nil {
+			if mapGet(job, "steps") != nil || mapGet(job, "runs-on") != nil {
+				add(line, "job %s calls a reusable workflow and can't have runs-on or steps", entry.key)
+			}
+			continue
+		}
+		if mapGet(job, "runs-on") == nil {
+			add(line, "job %s is missing runs-on", entry.key)
+		}
+		lintWorkflowSteps(entry.key, job, add)
+	}
+
+	lintExpressions(root, add)
+	return problems
+}
+
+// lintWorkflowTriggers checks the events of a workflow's on key, which is
+// an event name, a list of them, or a mapping of events to filters
+func lintWorkflowTriggers(on *yaml.Node, add func(line int, format string, args ...interface{})) {
+	checkEvent := func(event string, line int) {
+		if !slices.Contains(workflowEvents, event) {
+			add(line, "unknown trigger event %q", event)
+		}
+	}
+	switch on.Kind {
+	case yaml.ScalarNode:
+		checkEvent(on.Value, on.Line)
+	case yaml.SequenceNode:
+		for _, item := range on.Content {
+			checkEvent(item.Value, item.Line)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(on.Content); i += 2 {
+			checkEvent(on.Content[i].Value, on.Content[i].Line)
+		}
+		schedule := mapGet(on, "schedule")
+		if schedule == nil {
+			return
+		}
+		if schedule.Kind != yaml.SequenceNode {
+			add(schedule.Line, "schedule must be a list of cron entries")
+			return
+		}
+		for _, entry := range schedule.Content {
+			cron := mapGet(entry, "cron")
+			if cron == nil {
+				add(entry.Line, "schedule entry is missing cron")
+			} else if len(strings.Fields(cron.Value)) != 5 {
+				add(cron.Line, "cron %q must have five fields", cron.Value)
+			}
+		}
+	}
+}
+
+// lintWorkflowSteps checks that a job has steps that each run a command or
+// use an action, with unique IDs
+func lintWorkflowSteps(jobID string, job *yaml.Node, add func(line int, format string, args ...interface{})) {
+	steps := mapGet(job, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode || len(steps.Content) == 0 {
+		add(mapKeyLine(job, "steps"), "job %s must have a list of steps", jobID)
+		return
+	}
+
+	ids := make(map[string]int)
+	for i, step := range steps.Content {
+		if step.Kind != yaml.MappingNode {
+			add(step.Line, "step %d of job %s must be a mapping", i+1, jobID)
+			continue
+		}
+		for k := 0; k+1 < len(step.Content); k += 2 {
+			if key := step.Content[k]; !slices.Contains(workflowStepKeys, key.Value) {
+				add(key.Line, "unknown key %q in step %d of job %s", key.Value, i+1, jobID)
+			}
+		}
+
+		uses, run := mapGet(step, "uses"), mapGet(step, "run")
+		switch {
+		case uses == nil && run == nil:
+			add(step.Line, "step %d of job %s needs uses or run", i+1, jobID)
+		case uses != nil && run != nil:
+			add(step.Line, "step %d of job %s can't have both uses and run", i+1, jobID)
+		}
+
+		if id := mapGet(step, "id"); id != nil {
+			if first, ok := ids[id.Value]; ok {
+				add(id.Line, "step ID %q in job %s is already used on line %d", id.Value, jobID, first)
+			} else {
+				ids[id.Value] = id.Line
+			}
+		}
+	}
+}
+
+// lintExpressions reports ${{ expressions that are never closed
+func lintExpressions(node *yaml.Node, add func(line int, format string, args ...interface{})) {
+	if node.Kind == yaml.ScalarNode {
+		value := node.Value
+		for {
+			start := strings.Index(value, "${{")
+			if start < 0 {
+				return
+			}
+			end := strings.Index(value[start:], "}}")
+			if end < 0 {
+				add(node.Line, "expression %q is missing its closing }}", truncate(value[start:], 40))
+				return
+			}
+			value = value[start+end+2:]
+		}
+	}
+	for _, child := range node.Content {
+		lintExpressions(child, add)
+	}
+}
+
+// gitlabLintLine finds the line a GitLab CI Lint message refers to by
+// walking the job and keys it names, stopping at the deepest one found
+func gitlabLintLine(root *yaml.Node, message string) int {
+	match := gitlabLintLocation.FindStringSubmatch(message)
+	if match == nil {
+		return 0
+	}
+	path := match[1]
+	if path == "" {
+		path = match[2]
+	}
+
+	line := 0
+	node := root
+	for _, key := range strings.Split(path, ":") {
+		value := mapGet(node, key)
+		if value == nil {
+			break
+		}
+		line = mapKeyLine(node, key)
+		node = value
+	}
+	return line
+}
+
+// lintGitLabCI checks the syntax of a .gitlab-ci.yml and, when project is
+// set, validates it with the project's CI Lint API
+func lintGitLabCI(ctx context.Context, file WorkflowFile, project *Project) ([]lintProblem, error) {
+	root, problems := parseLintYAML(file)
+	if root == nil || project == nil {
+		return problems, nil
+	}
+
+	client, err := NewGitLabClient(ctx, project.Name)
+	if err != nil {
+		return nil, err
+	}
+	errors, warnings, err := client.LintCIConfig(project.Name, file.Content)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range errors {
+		problems = append(problems, lintProblem{Path: file.Path, Line: gitlabLintLine(root, message), Message: message})
+	}
+	for _, message := range warnings {
+		problems = append(problems, lintProblem{Path: file.Path, Line: gitlabLintLine(root, message), Warning: true, Message: message})
+	}
+	return problems, nil
+}
+
+// findCIFiles returns the GitHub workflows and GitLab CI file in dir
+func findCIFiles(dir string) (workflows []string, gitlabCI string) {
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, ".github", "workflows", pattern))
+		workflows = append(workflows, matches...)
+	}
+	sort.Strings(workflows)
+	if _, err := os.Stat(filepath.Join(dir, ".gitlab-ci.yml")); err == nil {
+		gitlabCI = filepath.Join(dir, ".gitlab-ci.yml")
+	}
+	return workflows, gitlabCI
+}
+
+// readCIFile reads a CI file, naming it relative to dir in reports
+func readCIFile(dir, path string) (WorkflowFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return WorkflowFile{}, err
+	}
+	name, err := filepath.Rel(dir, path)
+	if err != nil {
+		name = path
+	}
+	// Paths are reported with forward slashes on Windows too, like the
+	// paths of files read from providers
+	return WorkflowFile{Path: filepath.ToSlash(name), Content: string(data)}, nil
+}
+
+// lintWorkflows checks the CI files of a local checkout before they are
+// pushed and exits with status 1 when any has errors
+func lintWorkflows(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "Only check .gitlab-ci.yml syntax, without the GitLab CI Lint API")
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	githubPaths, gitlabPath := findCIFiles(dir)
+	if len(githubPaths) == 0 && gitlabPath == "" {
+		fmt.Printf("%s No .github/workflows files or .gitlab-ci.yml found in %s\n", qc.Colorize("Info:", qc.ColorCyan), dir)
+		return
+	}
+
+	var problems []lintProblem
+	files := 0
+	for _, path := range githubPaths {
+		file, err := readCIFile(dir, path)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			continue
+		}
+		files++
+		problems = append(problems, lintGitHubWorkflow(file)...)
+	}
+	if gitlabPath != "" {
+		file, err := readCIFile(dir, gitlabPath)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		} else {
+			files++
+			var project *Project
+			if !*offline {
+				// The CI Lint API validates in the context of a project so
+				// includes resolve
+				if detected, err := detectProject(dir); err == nil && detected.Platform == "gitlab" {
+					project = detected
+				} else {
+					fmt.Printf("%s %s has no GitLab remote; only checking YAML syntax\n", qc.Colorize("Warning:", qc.ColorYellow), dir)
+				}
+			}
+			found, err := lintGitLabCI(ctx, file, project)
+			if err != nil {
+				fmt.Printf("%s CI Lint API failed, only checked YAML syntax: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+				found, _ = lintGitLabCI(ctx, file, nil)
+			}
+			problems = append(problems, found...)
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Line < problems[j].Line
+	})
+
+	errors := 0
+	for _, problem := range problems {
+		location := problem.Path
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d", problem.Path, problem.Line)
+		}
+		label := qc.Colorize("error  ", qc.ColorRed)
+		if problem.Warning {
+			label = qc.Colorize("warning", qc.ColorYellow)
+		} else {
+			errors++
+		}
+		fmt.Printf("%s %s %s\n", qc.ColorizeBold(location, qc.ColorWhite), label, problem.Message)
+	}
+
+	switch {
+	case len(problems) == 0:
+		fmt.Printf("%s No problems found in %d file(s)\n", qc.Colorize("Success:", qc.ColorGreen), files)
+	default:
+		fmt.Printf("\n%s %d error(s), %d warning(s) in %d file(s)\n", qc.Colorize("Summary:", qc.ColorBlue), errors, len(problems)-errors, files)
+	}
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
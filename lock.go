@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// lockTimeout is how long to wait for another process to release a lock
+	lockTimeout = 10 * time.Second
+	// lockStaleAfter is the age at which a lock is assumed abandoned by a
+	// crashed process and may be broken
+	lockStaleAfter = 30 * time.Second
+)
+
+// fileLock is an advisory lock held by creating path.lock next to a file.
+// O_EXCL creation is atomic on every platform, unlike flock.
+type fileLock struct {
+	path string
+}
+
+// acquireLock takes the advisory lock for path, waiting for other processes
+func acquireLock(path string) (*fileLock, error) {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.WriteString(strconv.Itoa(os.Getpid()))
+			f.Close()
+			return &fileLock{path: lockPath}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		// Break locks left behind by processes that died while holding them
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s (another quick_workflow may be running)", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// release drops the lock
+func (l *fileLock) release() {
+	os.Remove(l.path)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
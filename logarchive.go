@@ -0,0 +1,118 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// logFileName is the file a job's log is saved to, numbered like the
+// files in GitHub's log archives
+func logFileName(index int, job Job) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, job.Name)
+	return fmt.Sprintf("%d_%s.txt", index, name)
+}
+
+// runLogDir is the directory a run's logs are downloaded to, named after
+// its project and run
+func runLogDir(parent string, run WorkflowRun) string {
+	return filepath.Join(parent, strings.ReplaceAll(run.Project, "/", "-")+"-run-"+run.ID)
+}
+
+// extractLogArchive writes the files of a zip archive into dir, returning
+// how many were written. Paths leaving dir are refused.
+func extractLogArchive(data []byte, dir string) (int, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read log archive: %v", err)
+	}
+	written := 0
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !filepath.IsLocal(file.Name) {
+			return written, fmt.Errorf("log archive has an unsafe path: %s", file.Name)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, err
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return written, err
+		}
+		out, err := os.Create(path)
+		if err != nil {
+			rc.Close()
+			return written, err
+		}
+		_, err = io.Copy(out, rc)
+		rc.Close()
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// downloadRunLogs saves every log of a run into dir: GitHub's log archive
+// of the run, or each job's log on other platforms. It returns how many
+// files were written.
+func downloadRunLogs(ctx context.Context, run WorkflowRun, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	if run.Platform == "github" {
+		owner, repo, ok := strings.Cut(run.Project, "/")
+		if !ok {
+			return 0, fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+		}
+		client, err := NewGitHubClient(ctx, run.Project)
+		if err != nil {
+			return 0, err
+		}
+		data, err := client.GetRunLogArchive(owner, repo, run.ID)
+		if err != nil {
+			return 0, err
+		}
+		return extractLogArchive(data, dir)
+	}
+
+	jobs, err := getJobsForRun(ctx, run)
+	if err != nil {
+		return 0, err
+	}
+	written := 0
+	for i, job := range jobs {
+		// Skipped jobs never ran, so have no log
+		if job.Conclusion == "skipped" {
+			continue
+		}
+		log, err := fetchJobLog(ctx, run, job)
+		if err != nil {
+			return written, fmt.Errorf("failed to fetch the log of %s: %w", job.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, logFileName(i, job)), []byte(log), 0644); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// defaultLogLines is how many log lines of each failed step are shown
+const defaultLogLines = 20
+
+var (
+	// ansiEscape matches terminal escape sequences in job logs
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	// gitlabSection matches GitLab's collapsible section markers
+	gitlabSection = regexp.MustCompile(`section_(start|end):\d+:[^\r\n]*?\r`)
+	// errorLine matches log lines worth highlighting
+	errorLine = regexp.MustCompile(`(?i)\b(error|fail(ed|ure)?|fatal|panic|exception)\b|##\[error\]`)
+)
+
+// logLine is one line of a job log with its timestamp when the provider
+// records one
+type logLine struct {
+	At   time.Time
+	Text string
+	// Colored is Text with the colors the job printed
+	Colored string
+}
+
+// isFailedConclusion reports whether a job or step conclusion is a failure
+func isFailedConclusion(conclusion string) bool {
+	switch conclusion {
+	case "failure", "failed", "timed_out":
+		return true
+	}
+	return false
+}
+
+// fetchJobLog downloads the log of a job in a run
+func fetchJobLog(ctx context.Context, run WorkflowRun, job Job) (string, error) {
+	switch run.Platform {
+	case "github":
+		owner, repo, ok := strings.Cut(run.Project, "/")
+		if !ok {
+			return "", fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+		}
+		client, err := NewGitHubClient(ctx, run.Project)
+		if err != nil {
+			return "", err
+		}
+		return client.GetJobLog(owner, repo, job.ID)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, run.Project)
+		if err != nil {
+			return "", err
+		}
+		return client.GetJobLog(run.Project, job.ID)
+	case "drone", "woodpecker":
+		owner, repo, ok := strings.Cut(run.Project, "/")
+		if !ok {
+			return "", fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
+		}
+		client, err := NewDroneClient(ctx, run.Platform)
+		if err != nil {
+			return "", err
+		}
+		return client.GetStepLog(owner, repo, run.ID, job.ID)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return "", err
+		}
+		return client.GetJobLog(run.Project, run.ID, job.ID)
+	default:
+		client, err := NewPluginClient(ctx, run.Platform)
+		if err != nil {
+			return "", err
+		}
+		return client.JobLog(run, job.ID)
+	}
+}
+
+// parseLogLines splits a job log into lines, removing section markers and
+// escape codes, though Colored keeps the colors. GitHub prefixes each line
+// with an RFC 3339 timestamp, which is parsed so lines can be matched to
+// steps.
+func parseLogLines(log string) []logLine {
+	var lines []logLine
+	for _, text := range strings.Split(strings.ReplaceAll(log, "\r\n", "\n"), "\n") {
+		var line logLine
+		line.At, text = cutLogTimestamp(text)
+		line.Colored = colorLogText(gitlabSection.ReplaceAllString(text, ""))
+		line.Text = plainLogText(line.Colored)
+		lines = append(lines, line)
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1].Text == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// stepLines returns the lines logged while a step ran. Step times have
+// second precision, so the window is widened by a second on each side.
+func stepLines(lines []logLine, step Step) []logLine {
+	if step.StartedAt == nil || step.CompletedAt == nil {
+		return nil
+	}
+	start := step.StartedAt.Add(-time.Second)
+	end := step.CompletedAt.Add(time.Second)
+
+	var matched []logLine
+	for _, line := range lines {
+		if !line.At.IsZero() && !line.At.Before(start) && !line.At.After(end) {
+			matched = append(matched, line)
+		}
+	}
+	return matched
+}
+
+// tailLines returns the last n lines
+func tailLines(lines []logLine, n int) []logLine {
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// showFailedLogs prints the end of the log of each failed step, or of the
+// whole job when the failed step can't be located in the log
+func showFailedLogs(ctx context.Context, run WorkflowRun, jobs []Job, n int) {
+	for _, job := range jobs {
+		if !isFailedConclusion(job.Conclusion) {
+			continue
+		}
+
+		fmt.Printf("\n%s %s\n", qc.Colorize("Failed job:", qc.ColorRed), qc.ColorizeBold(job.Name, qc.ColorWhite))
+
+		log, err := fetchJobLog(ctx, run, job)
+		if err != nil {
+			fmt.Printf("%s Failed to fetch log: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+			continue
+		}
+		lines := parseLogLines(log)
+
+		shown := false
+		for _, step := range job.Steps {
+			// GitLab jobs have a single step named after the job
+			if !isFailedConclusion(step.Conclusion) || step.Name == job.Name {
+				continue
+			}
+			matched := stepLines(lines, step)
+			if len(matched) == 0 {
+				continue
+			}
+			fmt.Printf("%s %s\n", qc.Colorize("Failed step:", qc.ColorRed), step.Name)
+			printLogLines(tailLines(matched, n))
+			shown = true
+		}
+
+		if !shown {
+			fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Last %d log lines:", n), qc.ColorYellow))
+			printLogLines(tailLines(lines, n))
+		}
+	}
+}
+
+// printLogLines prints log lines indented, highlighting errors that the
+// job didn't color itself
+func printLogLines(lines []logLine) {
+	for _, line := range lines {
+		if qc.Enabled() && line.Colored != line.Text {
+			fmt.Printf("    %s%s\n", line.Colored, qc.ColorReset)
+		} else if errorLine.MatchString(line.Text) {
+			fmt.Printf("    %s\n", qc.Colorize(line.Text, qc.ColorRed))
+		} else {
+			fmt.Printf("    %s\n", line.Text)
+		}
+	}
+}
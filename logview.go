@@ -0,0 +1,816 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/term"
+)
+
+var (
+	// gitlabSectionStart and gitlabSectionEnd match GitLab's collapsible
+	// section markers, capturing the section name
+	gitlabSectionStart = regexp.MustCompile(`section_start:\d+:([^\[\r\n\x1b]+)(?:\[[^\]\r\n]*\])?\r?(?:\x1b\[0K)?`)
+	gitlabSectionEnd   = regexp.MustCompile(`section_end:\d+:([^\r\n\x1b]+)\r?(?:\x1b\[0K)?`)
+	// failureMarker matches what failing tools commonly print beyond the
+	// words errorLine looks for
+	failureMarker = regexp.MustCompile(`(?i)traceback \(most recent call last\)|npm ERR!|exit (code|status) [1-9]|^\s*(--- FAIL|FAIL\b|✖|✗)`)
+)
+
+// logGroup is a collapsible part of a job log: a GitHub ::group:: or a
+// GitLab section
+type logGroup struct {
+	Name string
+	// Parent is the enclosing group, -1 at the top level
+	Parent int
+	// Header and End are the indexes of the group's header line and of
+	// its last line
+	Header   int
+	End      int
+	HasError bool
+	Folded   bool
+}
+
+// viewLine is one line of a job log in the log viewer
+type viewLine struct {
+	Text string
+	// Colored is Text with the colors the job printed
+	Colored string
+	// At is when the line was logged, zero when the provider doesn't say
+	At time.Time
+	// Group is the innermost group the line is in, -1 at the top level.
+	// A header line is in its group's parent.
+	Group int
+	// Opens is the group a header line starts, -1 for other lines
+	Opens int
+	Depth int
+	Error bool
+}
+
+// isFailureLine reports whether a log line looks like it reports a failure
+func isFailureLine(text string) bool {
+	return errorLine.MatchString(text) || failureMarker.MatchString(text)
+}
+
+// cutLogTimestamp removes the RFC 3339 timestamp GitHub puts before each
+// log line, returning it
+func cutLogTimestamp(text string) (time.Time, string) {
+	if prefix, rest, ok := strings.Cut(text, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+			return t, rest
+		}
+	}
+	return time.Time{}, text
+}
+
+// colorLogText removes escape codes other than colors and keeps only what
+// a terminal would show after carriage returns
+func colorLogText(text string) string {
+	text = ansiEscape.ReplaceAllStringFunc(text, func(code string) string {
+		if strings.HasSuffix(code, "m") {
+			return code
+		}
+		return ""
+	})
+	if i := strings.LastIndex(text, "\r"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimRight(text, " \t")
+}
+
+// plainLogText removes the colors colorLogText keeps
+func plainLogText(colored string) string {
+	return strings.TrimRight(ansiEscape.ReplaceAllString(colored, ""), " \t")
+}
+
+// cleanLogText removes escape codes and keeps only what a terminal would
+// show after carriage returns
+func cleanLogText(text string) string {
+	return plainLogText(colorLogText(text))
+}
+
+// truncateColored shortens text with color codes to width visible runes,
+// like truncate, keeping the codes intact
+func truncateColored(text string, width int) string {
+	if utf8.RuneCountInString(plainLogText(text)) <= width {
+		return text
+	}
+	var b strings.Builder
+	shown := 0
+	for len(text) > 0 && shown < width-1 {
+		if loc := ansiEscape.FindStringIndex(text); loc != nil && loc[0] == 0 {
+			b.WriteString(text[:loc[1]])
+			text = text[loc[1]:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text)
+		b.WriteRune(r)
+		text = text[size:]
+		shown++
+	}
+	if width > 0 {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// logViewParser builds the lines and groups of a log
+type logViewParser struct {
+	lines  []viewLine
+	groups []logGroup
+	open   []int
+	// at is the timestamp of the raw line being added
+	at time.Time
+}
+
+func (p *logViewParser) current() int {
+	if len(p.open) == 0 {
+		return -1
+	}
+	return p.open[len(p.open)-1]
+}
+
+// emit adds a line of text with its colors
+func (p *logViewParser) emit(colored string) {
+	text := plainLogText(colored)
+	line := viewLine{Text: text, Colored: colored, At: p.at, Group: p.current(), Opens: -1, Depth: len(p.open), Error: isFailureLine(text)}
+	if line.Error {
+		for _, group := range p.open {
+			p.groups[group].HasError = true
+		}
+	}
+	p.lines = append(p.lines, line)
+}
+
+func (p *logViewParser) openGroup(name, header string) {
+	index := len(p.groups)
+	p.groups = append(p.groups, logGroup{Name: name, Parent: p.current(), Header: len(p.lines), End: len(p.lines)})
+	p.lines = append(p.lines, viewLine{Text: header, Colored: header, At: p.at, Group: p.current(), Opens: index, Depth: len(p.open)})
+	p.open = append(p.open, index)
+}
+
+// closeGroup ends the named group and any left open inside it, or the
+// innermost group when name is empty. Unknown names are ignored.
+func (p *logViewParser) closeGroup(name string) {
+	for i := len(p.open) - 1; i >= 0; i-- {
+		if name != "" && p.groups[p.open[i]].Name != name {
+			continue
+		}
+		for _, group := range p.open[i:] {
+			p.groups[group].End = len(p.lines) - 1
+		}
+		p.open = p.open[:i]
+		return
+	}
+}
+
+// addLine adds a raw log line, turning group and section markers into
+// group headers
+func (p *logViewParser) addLine(raw string) {
+	var text string
+	p.at, text = cutLogTimestamp(raw)
+
+	// GitLab puts section markers at the start of lines, sometimes an end
+	// and a start on the same line, with the section's header after them
+	marked := false
+	for {
+		start := gitlabSectionStart.FindStringSubmatchIndex(text)
+		end := gitlabSectionEnd.FindStringSubmatchIndex(text)
+		if start == nil && end == nil {
+			break
+		}
+		marked = true
+		if end != nil && (start == nil || end[0] < start[0]) {
+			if before := colorLogText(text[:end[0]]); plainLogText(before) != "" {
+				p.emit(before)
+			}
+			p.closeGroup(text[end[2]:end[3]])
+			text = text[end[1]:]
+			continue
+		}
+		if before := colorLogText(text[:start[0]]); plainLogText(before) != "" {
+			p.emit(before)
+		}
+		name := text[start[2]:start[3]]
+		text = text[start[1]:]
+		if next := gitlabSectionEnd.FindStringIndex(text); next != nil {
+			p.openGroup(name, firstNonEmpty(cleanLogText(text[:next[0]]), name))
+			text = text[next[0]:]
+			continue
+		}
+		p.openGroup(name, firstNonEmpty(cleanLogText(text), name))
+		text = ""
+	}
+
+	colored := colorLogText(text)
+	text = plainLogText(colored)
+	switch {
+	case strings.HasPrefix(text, "##[group]"):
+		name := strings.TrimPrefix(text, "##[group]")
+		p.openGroup(name, name)
+	case strings.HasPrefix(text, "::group::"):
+		name := strings.TrimPrefix(text, "::group::")
+		p.openGroup(name, name)
+	case text == "##[endgroup]" || text == "::endgroup::":
+		p.closeGroup("")
+	case text != "" || !marked:
+		p.emit(colored)
+	}
+}
+
+// parseLogView splits a job log into lines and collapsible groups. Groups
+// with a failure in them start unfolded and the rest folded, so the
+// interesting part of a long log shows first.
+func parseLogView(log string) ([]viewLine, []logGroup) {
+	p := &logViewParser{}
+	for _, raw := range strings.Split(strings.ReplaceAll(log, "\r\n", "\n"), "\n") {
+		p.addLine(raw)
+	}
+	for len(p.lines) > 0 && p.lines[len(p.lines)-1].Text == "" && p.lines[len(p.lines)-1].Opens < 0 {
+		p.lines = p.lines[:len(p.lines)-1]
+	}
+	// Groups left open run to the end of the log
+	for _, group := range p.open {
+		p.groups[group].End = len(p.lines) - 1
+	}
+	for i := range p.groups {
+		p.groups[i].End = min(p.groups[i].End, len(p.lines)-1)
+		p.groups[i].Folded = !p.groups[i].HasError
+	}
+	return p.lines, p.groups
+}
+
+// logViewOptions are how a log is shown
+type logViewOptions struct {
+	// Timestamps shows when each line was logged, where the provider says
+	Timestamps bool
+	// Fold prints groups without failures folded, as the viewer opens them
+	Fold bool
+	// Print prints the log even on a terminal, rather than opening the
+	// viewer
+	Print bool
+}
+
+// logViewer is a full screen pager for a job log: the arrow keys move, /
+// searches, n and e jump to the next match or failure, and Enter folds
+// groups
+type logViewer struct {
+	title   string
+	lines   []viewLine
+	groups  []logGroup
+	visible []int
+	cursor  int
+	offset  int
+	search  *regexp.Regexp
+	query   string
+	typing  bool
+	input   []rune
+	message string
+	// timestamps shows when lines were logged; timed is whether any were
+	timestamps bool
+	timed      bool
+}
+
+// newLogViewer prepares a viewer for a log, starting on its first failure
+func newLogViewer(title, log string, options logViewOptions) *logViewer {
+	lines, groups := parseLogView(log)
+	v := &logViewer{title: title, lines: lines, groups: groups}
+	for _, line := range lines {
+		if !line.At.IsZero() {
+			v.timed = true
+			break
+		}
+	}
+	v.timestamps = options.Timestamps && v.timed
+	v.refresh(0)
+	if len(lines) > 0 && !lines[0].Error {
+		v.find(v.isFailure, true)
+		v.message = ""
+	}
+	return v
+}
+
+// isFailure reports whether a line looks like it reports a failure
+func (v *logViewer) isFailure(i int) bool {
+	return v.lines[i].Error
+}
+
+// refresh recomputes the visible lines after folding changed, keeping the
+// cursor on line keep or the nearest visible line before it
+func (v *logViewer) refresh(keep int) {
+	v.visible = v.visible[:0]
+	for i := 0; i < len(v.lines); {
+		v.visible = append(v.visible, i)
+		if group := v.lines[i].Opens; group >= 0 && v.groups[group].Folded {
+			i = v.groups[group].End + 1
+			continue
+		}
+		i++
+	}
+	v.cursor = 0
+	for i, line := range v.visible {
+		if line <= keep {
+			v.cursor = i
+		}
+	}
+}
+
+// line returns the index of the line under the cursor
+func (v *logViewer) line() int {
+	if len(v.visible) == 0 {
+		return 0
+	}
+	return v.visible[v.cursor]
+}
+
+// reveal unfolds the groups around a line and moves the cursor to it
+func (v *logViewer) reveal(line int) {
+	group := v.lines[line].Group
+	for group >= 0 {
+		v.groups[group].Folded = false
+		group = v.groups[group].Parent
+	}
+	v.refresh(line)
+}
+
+// find moves to the next line after the cursor, or the previous one, that
+// matches, wrapping around the log and looking inside folded groups
+func (v *logViewer) find(match func(int) bool, forward bool) bool {
+	n := len(v.lines)
+	if n == 0 {
+		return false
+	}
+	start := v.line()
+	for step := 1; step <= n; step++ {
+		i := (start + step) % n
+		if !forward {
+			i = (start - step + n*2) % n
+		}
+		if match(i) {
+			v.message = ""
+			if (forward && i <= start) || (!forward && i >= start) {
+				v.message = "wrapped around"
+			}
+			v.reveal(i)
+			return true
+		}
+	}
+	return false
+}
+
+// toggle folds or unfolds the group of the line under the cursor
+func (v *logViewer) toggle() {
+	if len(v.lines) == 0 {
+		return
+	}
+	line := v.lines[v.line()]
+	group := line.Opens
+	if group < 0 {
+		group = line.Group
+	}
+	if group < 0 {
+		return
+	}
+	v.groups[group].Folded = !v.groups[group].Folded
+	v.refresh(v.groups[group].Header)
+}
+
+// foldAll folds or unfolds every group
+func (v *logViewer) foldAll(folded bool) {
+	for i := range v.groups {
+		v.groups[i].Folded = folded
+	}
+	v.refresh(v.line())
+}
+
+// move moves the cursor by delta visible lines
+func (v *logViewer) move(delta int) {
+	v.cursor = max(0, min(len(v.visible)-1, v.cursor+delta))
+}
+
+// scroll keeps the cursor within the rows shown
+func (v *logViewer) scroll(rows int) {
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+rows {
+		v.offset = v.cursor - rows + 1
+	}
+	v.offset = max(0, min(v.offset, len(v.visible)-rows))
+}
+
+// handleKeys applies the keys read in one go, reporting whether the user
+// quit
+func (v *logViewer) handleKeys(keys []byte, rows int) bool {
+	if v.typing {
+		return v.handleSearchKeys(keys, rows)
+	}
+	if len(keys) == 1 && keys[0] == 0x1b {
+		return true
+	}
+	for len(keys) > 0 {
+		if keys[0] == 0x1b {
+			end := 1
+			for end < len(keys) && (end < 2 || !(keys[end] >= 0x40 && keys[end] <= 0x7e)) {
+				end++
+			}
+			switch string(keys[:min(end+1, len(keys))]) {
+			case "\x1b[A", "\x1bOA":
+				v.move(-1)
+			case "\x1b[B", "\x1bOB":
+				v.move(1)
+			case "\x1b[5~":
+				v.move(-rows)
+			case "\x1b[6~":
+				v.move(rows)
+			case "\x1b[H", "\x1b[1~", "\x1bOH":
+				v.cursor = 0
+			case "\x1b[F", "\x1b[4~", "\x1bOF":
+				v.cursor = len(v.visible) - 1
+			}
+			keys = keys[min(end+1, len(keys)):]
+			continue
+		}
+
+		switch keys[0] {
+		case 'q', 3:
+			return true
+		case 'k', 0x10:
+			v.move(-1)
+		case 'j', 0x0e:
+			v.move(1)
+		case ' ', 0x06:
+			v.move(rows)
+		case 'b', 0x02:
+			v.move(-rows)
+		case 'g':
+			v.cursor = 0
+		case 'G':
+			v.cursor = len(v.visible) - 1
+		case '\r', '\n', '\t':
+			v.toggle()
+		case 't':
+			if !v.timed {
+				v.message = "the log has no timestamps"
+			} else {
+				v.timestamps = !v.timestamps
+			}
+		case 'o':
+			v.foldAll(false)
+		case 'c':
+			v.foldAll(true)
+		case '/':
+			v.typing = true
+			v.input = nil
+			v.message = ""
+			return v.handleSearchKeys(keys[1:], rows)
+		case 'n', 'N':
+			if v.search == nil {
+				v.message = "no search; press / to search"
+			} else if !v.find(v.matchesSearch, keys[0] == 'n') {
+				v.message = "no matches for " + v.query
+			}
+		case 'e', 'E':
+			if !v.find(v.isFailure, keys[0] == 'e') {
+				v.message = "no failures found"
+			}
+		}
+		keys = keys[1:]
+	}
+	return false
+}
+
+// handleSearchKeys edits the search being typed after /, searching on
+// Enter and cancelling on Esc
+func (v *logViewer) handleSearchKeys(keys []byte, rows int) bool {
+	for len(keys) > 0 {
+		switch {
+		case keys[0] == 0x1b || keys[0] == 3:
+			v.typing = false
+			return false
+		case keys[0] == '\r' || keys[0] == '\n':
+			v.typing = false
+			if len(v.input) == 0 {
+				return false
+			}
+			v.query = string(v.input)
+			// Not every pattern typed is meant as a regular expression
+			pattern, err := regexp.Compile("(?i)" + v.query)
+			if err != nil {
+				pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(v.query))
+			}
+			v.search = pattern
+			if !v.find(v.matchesSearch, true) {
+				v.message = "no matches for " + v.query
+			}
+			return v.handleKeys(keys[1:], rows)
+		case keys[0] == 0x7f || keys[0] == 8:
+			if len(v.input) > 0 {
+				v.input = v.input[:len(v.input)-1]
+			}
+		case keys[0] >= 0x20:
+			r, size := utf8.DecodeRune(keys)
+			if r != utf8.RuneError {
+				v.input = append(v.input, r)
+			}
+			keys = keys[size:]
+			continue
+		}
+		keys = keys[1:]
+	}
+	return false
+}
+
+// hasColors reports whether the job colored a line itself, and colors are
+// on
+func (v *logViewer) hasColors(i int) bool {
+	return qc.Enabled() && v.lines[i].Colored != v.lines[i].Text
+}
+
+// matchesSearch reports whether a line matches the last search
+func (v *logViewer) matchesSearch(i int) bool {
+	return v.search != nil && v.search.MatchString(v.lines[i].Text)
+}
+
+// formatLine renders a line for the viewer or for printing, indented by
+// its depth, with headers marked folded or unfolded. With colored, lines
+// keep the colors the job printed.
+func (v *logViewer) formatLine(i int, colored bool) string {
+	line := v.lines[i]
+	indent := strings.Repeat("  ", line.Depth)
+	if v.timestamps {
+		stamp := strings.Repeat(" ", len("2006-01-02 15:04:05"))
+		if !line.At.IsZero() {
+			stamp = formatAbsoluteTime(line.At)
+		}
+		indent = stamp + " " + indent
+	}
+	if line.Opens < 0 {
+		if colored && v.hasColors(i) {
+			return indent + "  " + line.Colored + qc.ColorReset
+		}
+		return indent + "  " + line.Text
+	}
+	group := v.groups[line.Opens]
+	if group.Folded {
+		return fmt.Sprintf("%s▸ %s (%d lines)", indent, line.Text, group.End-group.Header)
+	}
+	return indent + "▾ " + line.Text
+}
+
+// draw redraws the screen: the visible lines around the cursor and a
+// status line with the position, search, and keys
+func (v *logViewer) draw(width, rows int) {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for row := 0; row < rows; row++ {
+		b.WriteString("\x1b[2K")
+		index := v.offset + row
+		if index < len(v.visible) {
+			i := v.visible[index]
+			prefix := "  "
+			if index == v.cursor {
+				prefix = "> "
+			}
+			text := truncate(prefix+v.formatLine(i, false), width-1)
+			switch {
+			case index == v.cursor:
+				text = qc.ColorizeBold(text, qc.ColorCyan)
+			case v.matchesSearch(i):
+				text = qc.Colorize(text, qc.ColorYellow)
+			case v.hasColors(i):
+				text = truncateColored(prefix+v.formatLine(i, true), width-1) + qc.ColorReset
+			case v.lines[i].Error:
+				text = qc.Colorize(text, qc.ColorRed)
+			case v.lines[i].Opens >= 0:
+				text = qc.Colorize(text, qc.ColorBlue)
+			}
+			b.WriteString(text)
+		}
+		b.WriteString("\r\n")
+	}
+
+	b.WriteString("\x1b[2K")
+	if v.typing {
+		b.WriteString("/" + string(v.input))
+		fmt.Print(b.String())
+		return
+	}
+	status := fmt.Sprintf(" %s  line %d/%d", v.title, v.line()+1, len(v.lines))
+	if v.query != "" {
+		status += "  /" + v.query
+	}
+	if v.message != "" {
+		status += "  (" + v.message + ")"
+	}
+	status += "  ↑/↓ move, Space/b page, Enter fold, o/c open/close all, / search, n/N match, e/E failure, t times, q quit"
+	b.WriteString(qc.Colorize(truncate(status, width-1), qc.ColorBlue))
+	fmt.Print(b.String())
+}
+
+// terminalHeight returns the terminal's height, from LINES when set, or 24
+func terminalHeight() int {
+	if height, err := strconv.Atoi(os.Getenv("LINES")); err == nil && height > 0 {
+		return height
+	}
+	if _, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil && height > 0 {
+		return height
+	}
+	return 24
+}
+
+// viewLog shows a job log in the full screen viewer until the user quits
+func viewLog(title, log string, options logViewOptions) {
+	v := newLogViewer(title, log, options)
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		return
+	}
+	// Use the alternate screen, so quitting restores what was shown before
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		term.Restore(fd, state)
+	}()
+
+	buf := make([]byte, 256)
+	for {
+		width := terminalWidth()
+		if width <= 0 {
+			width = 80
+		}
+		rows := max(1, terminalHeight()-1)
+		v.scroll(rows)
+		v.draw(width, rows)
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		if v.handleKeys(buf[:n], rows) {
+			return
+		}
+	}
+}
+
+// printLogView prints a whole log with its groups indented, unfolded
+// unless options fold them, keeping the colors the job printed
+func printLogView(log string, options logViewOptions) {
+	v := newLogViewer("", log, options)
+	if !options.Fold {
+		v.foldAll(false)
+	}
+	for _, i := range v.visible {
+		line := v.lines[i]
+		text := v.formatLine(i, true)
+		switch {
+		case v.hasColors(i):
+			// The job's own colors win over highlighting
+		case line.Error:
+			text = qc.Colorize(text, qc.ColorRed)
+		case line.Opens >= 0:
+			text = qc.Colorize(text, qc.ColorBlue)
+		}
+		fmt.Println(text)
+	}
+}
+
+// isInteractiveTerminal reports whether both input and output are a
+// terminal, so full screen views can be used
+func isInteractiveTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// openJobLog fetches a job's log and shows it in the viewer on a
+// terminal, or prints it otherwise
+func openJobLog(ctx context.Context, run WorkflowRun, job Job, options logViewOptions) error {
+	log, err := fetchJobLog(ctx, run, job)
+	if err != nil {
+		return err
+	}
+	if options.Print || !isInteractiveTerminal() {
+		printLogView(log, options)
+		return nil
+	}
+	viewLog(fmt.Sprintf("%s #%s %s", run.Project, run.ID, job.Name), log, options)
+	return nil
+}
+
+// promptLogViewer offers to open the log of a job of the run shown in the
+// viewer, on a terminal
+func promptLogViewer(ctx context.Context, reader *bufio.Reader, run WorkflowRun, jobs []Job) {
+	if len(jobs) == 0 || !isInteractiveTerminal() {
+		return
+	}
+	for {
+		fmt.Printf("\n%s", qc.Colorize("View log (v<n> job log, Enter to continue): ", qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		input = strings.TrimSpace(input)
+		if input == "" || input == "q" {
+			return
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(input, "v")))
+		if err != nil || index < 1 || index > len(jobs) {
+			fmt.Printf("%s No job %s\n", qc.Colorize("Error:", qc.ColorRed), strings.TrimPrefix(input, "v"))
+			continue
+		}
+		if err := openJobLog(ctx, run, jobs[index-1], logViewOptions{}); err != nil {
+			fmt.Printf("%s Failed to fetch log: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		}
+	}
+}
+
+// findJob picks a job of a run by number or name, or by default the first
+// failed job, else the only job
+func findJob(jobs []Job, ref string) (*Job, error) {
+	if ref == "" {
+		for i := range jobs {
+			if isFailedConclusion(jobs[i].Conclusion) {
+				return &jobs[i], nil
+			}
+		}
+		if len(jobs) == 1 {
+			return &jobs[0], nil
+		}
+		names := make([]string, len(jobs))
+		for i, job := range jobs {
+			names[i] = fmt.Sprintf("%d. %s", i+1, job.Name)
+		}
+		return nil, fmt.Errorf("the run has %d jobs; pick one with --job: %s", len(jobs), strings.Join(names, ", "))
+	}
+	if index, err := strconv.Atoi(ref); err == nil && index >= 1 && index <= len(jobs) {
+		return &jobs[index-1], nil
+	}
+	for i := range jobs {
+		if strings.EqualFold(jobs[i].Name, ref) {
+			return &jobs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("job not found: %s", ref)
+}
+
+// handleLogs handles the logs command, which opens a job's log in the log
+// viewer, or prints it when output isn't a terminal
+func handleLogs(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("logs", flag.ExitOnError)
+	projectName := fs.String("project", "", "Project the run belongs to (default: found from local history)")
+	jobRef := fs.String("job", "", "Job number or name (default: the first failed job)")
+	var options logViewOptions
+	fs.BoolVar(&options.Print, "print", false, "Print the log instead of opening the viewer")
+	fs.BoolVar(&options.Fold, "fold", false, "Print groups without failures folded to their header")
+	fs.BoolVar(&options.Timestamps, "timestamps", false, "Show when each line was logged")
+	download := fs.Bool("download", false, "Save every log of the run into a directory named after it")
+	dir := fs.String("dir", ".", "Directory to download into, with --download")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Printf("%s Usage: quick_workflow logs [--project <name>] [--job <n|name>] [--print] [--fold] [--timestamps] [--download [--dir <path>]] <run-id|url>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project, runID, err := resolveRun(config, *projectName, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	run, err := getWorkflowRun(ctx, *project, runID)
+	if err != nil {
+		fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if *download {
+		path := runLogDir(*dir, *run)
+		written, err := downloadRunLogs(ctx, *run, path)
+		if err != nil {
+			fmt.Printf("%s Failed to download logs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Saved %d log file(s) of %s #%s to %s\n", qc.Colorize("Success:", qc.ColorGreen), written, run.Project, run.ID, path)
+		return
+	}
+	jobs, err := getJobsForRun(ctx, *run)
+	if err != nil {
+		fmt.Printf("%s Failed to get jobs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	job, err := findJob(jobs, *jobRef)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if err := openJobLog(ctx, *run, *job, options); err != nil {
+		fmt.Printf("%s Failed to fetch log: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+	}
+}
@@ -4,45 +4,85 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	qc "github.com/bevelwork/quick_color"
+	qc "github.com/bevelwork/quick_workflow/internal/color"
 	versionpkg "github.com/bevelwork/quick_workflow/version"
+	"golang.org/x/term"
 )
 
 // Project represents a tracked project with its repository information
 type Project struct {
-	Name        string `json:"name"`
-	Owner       string `json:"owner"`
-	Repo        string `json:"repo"`
-	Platform    string `json:"platform"` // "github" or "gitlab"
-	RemoteURL   string `json:"remote_url"`
-	AddedAt     string `json:"added_at"`
-	AccessToken string `json:"access_token,omitempty"` // Optional access token
+	Name        string `json:"name" yaml:"name"`
+	Owner       string `json:"owner" yaml:"owner"`
+	Repo        string `json:"repo" yaml:"repo"`
+	Platform    string `json:"platform" yaml:"platform"` // "github" or "gitlab"
+	RemoteURL   string `json:"remote_url" yaml:"remote_url"`
+	AddedAt     string `json:"added_at" yaml:"added_at"`
+	AccessToken string `json:"access_token,omitempty" yaml:"access_token,omitempty"` // Optional access token
+	// Account names the login the project uses when it isn't the default
+	// one of its platform; see Account
+	Account string `json:"account,omitempty" yaml:"account,omitempty"`
+	// CoverageArtifact and CoveragePattern are where GitHub runs report
+	// coverage; see the coverage command
+	CoverageArtifact string `json:"coverage_artifact,omitempty" yaml:"coverage_artifact,omitempty"`
+	CoveragePattern  string `json:"coverage_pattern,omitempty" yaml:"coverage_pattern,omitempty"`
+	// DefaultWorkflow and DefaultBranch are what start triggers without
+	// asking, and DefaultBranch is the branch status reports; see the
+	// defaults command
+	DefaultWorkflow string `json:"default_workflow,omitempty" yaml:"default_workflow,omitempty"`
+	DefaultBranch   string `json:"default_branch,omitempty" yaml:"default_branch,omitempty"`
+	// Archived hides the project from watch and list while keeping it
+	// tracked; see the archive command
+	Archived bool `json:"archived,omitempty" yaml:"archived,omitempty"`
 }
 
 // WorkflowRun represents a unified workflow run across platforms
 type WorkflowRun struct {
-	ID          string    `json:"id"`
-	Project     string    `json:"project"`
-	Workflow    string    `json:"workflow"`
-	Status      string    `json:"status"`
-	Conclusion  string    `json:"conclusion"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	URL         string    `json:"url"`
-	Platform    string    `json:"platform"`
-	Branch      string    `json:"branch"`
-	Commit      string    `json:"commit"`
+	ID         string    `json:"id"`
+	Project    string    `json:"project"`
+	Workflow   string    `json:"workflow"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	URL        string    `json:"url"`
+	Platform   string    `json:"platform"`
+	Branch     string    `json:"branch"`
+	Commit     string    `json:"commit"`
+	// CommitMessage is only filled in where the provider lists it with the
+	// run, or when messages are asked for; see fillCommitMessages
+	CommitMessage string `json:"commit_message,omitempty"`
+	// PullRequest is the number of the pull or merge request the run is
+	// for, 0 when there is none or the provider doesn't say
+	PullRequest int       `json:"pull_request,omitempty"`
 	TriggeredBy string    `json:"triggered_by"`
+	Attempt     int       `json:"attempt,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	// QueueTime is the longest a job of the run waited for a runner. It is
+	// only filled in when queue times are asked for.
+	QueueTime time.Duration `json:"queue_time,omitempty"`
+	// Cause is the probable cause of a failed run. It is only filled in
+	// when causes are asked for; see fillFailureCauses.
+	Cause *FailureCause `json:"cause,omitempty"`
+	// Superseded marks an unfinished run that a newer unfinished run of the
+	// same workflow and branch makes redundant; see markSuperseded
+	Superseded bool `json:"-"`
+	// StaleAt is when a run shown from the cache, because fetching its
+	// project failed, was fetched; see staleRuns
+	StaleAt time.Time `json:"-"`
 }
 
 // Job represents a job within a workflow run
@@ -52,10 +92,24 @@ type Job struct {
 	Name      string    `json:"name"`
 	Status    string    `json:"status"`
 	Conclusion string   `json:"conclusion"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
 	StartedAt *time.Time `json:"started_at,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Steps     []Step    `json:"steps"`
 	URL       string    `json:"url"`
+	Runner    *JobRunner `json:"runner,omitempty"`
+}
+
+// JobRunner is the runner a job ran on, or the labels it asked for while
+// it waits for one
+type JobRunner struct {
+	Name string `json:"name,omitempty"`
+	// Kind is github-hosted or self-hosted on GitHub and shared or
+	// project/group on GitLab
+	Kind string `json:"kind,omitempty"`
+	// Labels are the runs-on labels (GitHub) or tags (GitLab) of the job
+	Labels []string `json:"labels,omitempty"`
+	Group  string   `json:"group,omitempty"`
 }
 
 // Step represents a step within a job
@@ -68,10 +122,217 @@ type Step struct {
 	Logs        string     `json:"logs,omitempty"`
 }
 
+// Annotation is an error, warning, or notice attached to a job, usually with
+// the file and line it refers to
+type Annotation struct {
+	Level     string `json:"level"`
+	Path      string `json:"path,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Message   string `json:"message"`
+}
+
+// TestResult is the outcome of one test case in a run's test report
+type TestResult struct {
+	Suite    string        `json:"suite,omitempty"`
+	Name     string        `json:"name"`
+	Status   string        `json:"status"` // passed, failed, error, or skipped
+	Message  string        `json:"message,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// SecurityFinding is a vulnerability reported by a security scanner job of
+// a pipeline
+type SecurityFinding struct {
+	Scanner  string `json:"scanner"`  // sast, dependency_scanning, secret_detection, container_scanning
+	Severity string `json:"severity"` // Critical, High, Medium, Low, Info, Unknown
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	// Identifier is the finding's primary identifier such as a CVE or
+	// rule ID
+	Identifier string `json:"identifier,omitempty"`
+}
+
+// CommitCheck is a status reported on a commit by a system other than the
+// provider's own CI, such as a Jenkins commit status or a third-party check
+type CommitCheck struct {
+	Name        string    `json:"name"`
+	Source      string    `json:"source"` // "status" or "check"
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	Description string    `json:"description,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Required marks checks that must pass before the branch can be
+	// merged; see MergeStatus
+	Required bool `json:"required,omitempty"`
+}
+
+// MergeStatus is what the checks on a branch's head commit say about
+// merging it: which checks the target branch requires and how they went
+type MergeStatus struct {
+	Branch string `json:"branch"`
+	// Target is the branch whose protection applies: the base of the
+	// branch's open pull or merge request, or the branch itself
+	Target      string        `json:"target"`
+	PullRequest int           `json:"pull_request,omitempty"`
+	Checks      []CommitCheck `json:"checks"`
+	// Missing lists required checks not reported on the commit yet
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Deployment is what an environment is running: the last successful
+// deployment, or the latest attempt when none succeeded
+type Deployment struct {
+	Environment string    `json:"environment"`
+	URL         string    `json:"url,omitempty"`
+	Ref         string    `json:"ref,omitempty"`
+	SHA         string    `json:"sha,omitempty"`
+	Status      string    `json:"status"`
+	RunID       string    `json:"run_id,omitempty"`
+	Creator     string    `json:"creator,omitempty"`
+	DeployedAt  time.Time `json:"deployed_at"`
+}
+
+// Release is a tag of a project, with the release published for it when
+// there is one
+type Release struct {
+	Tag        string         `json:"tag"`
+	Name       string         `json:"name,omitempty"`
+	Commit     string         `json:"commit,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Draft      bool           `json:"draft,omitempty"`
+	Prerelease bool           `json:"prerelease,omitempty"`
+	URL        string         `json:"url,omitempty"`
+	Assets     []ReleaseAsset `json:"assets,omitempty"`
+}
+
+// ReleaseAsset is a file attached to a GitHub release
+type ReleaseAsset struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	Downloads int    `json:"downloads"`
+}
+
+// SelfHostedRunner is a runner registered to a GitHub repository or
+// organization, or a GitLab project or group
+type SelfHostedRunner struct {
+	Platform string   `json:"platform"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Scope    string   `json:"scope"` // e.g. "org acme", "repo acme/api", "group"
+	OS       string   `json:"os,omitempty"`
+	Status   string   `json:"status"`
+	Busy     bool     `json:"busy"`
+	Labels   []string `json:"labels,omitempty"`
+}
+
+// RunUsage is the runner time a run consumed, in minutes by runner OS on
+// GitHub (UBUNTU, MACOS, WINDOWS) or on shared runners on GitLab
+type RunUsage struct {
+	RunID    string             `json:"run_id"`
+	Workflow string             `json:"workflow"`
+	Minutes  map[string]float64 `json:"minutes"`
+}
+
+// BillingSummary is an account's CI minutes for the current billing period
+type BillingSummary struct {
+	Account         string         `json:"account"`
+	MinutesUsed     float64        `json:"minutes_used"`
+	PaidMinutesUsed float64        `json:"paid_minutes_used"`
+	IncludedMinutes float64        `json:"included_minutes"`
+	Breakdown       map[string]int `json:"breakdown,omitempty"`
+}
+
+// RateQuota is what remains of a provider API rate limit until it resets.
+// Limit is 0 when the provider reported no limit.
+type RateQuota struct {
+	Platform  string    `json:"platform"`
+	Resource  string    `json:"resource"` // e.g. core or search on GitHub
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// PendingDeployment is a deployment waiting on a protection rule approval
+type PendingDeployment struct {
+	Environment   string    `json:"environment"`
+	EnvironmentID int64     `json:"environment_id"`
+	DeploymentID  int64     `json:"deployment_id,omitempty"` // GitLab only
+	RunID         string    `json:"run_id"`
+	Ref           string    `json:"ref,omitempty"`
+	Reviewers     []string  `json:"reviewers,omitempty"`
+	CanApprove    bool      `json:"can_approve"`
+	WaitingSince  time.Time `json:"waiting_since"`
+}
+
+// DownstreamPipeline is a child or multi-project pipeline triggered by a
+// GitLab bridge job
+type DownstreamPipeline struct {
+	Bridge       string      `json:"bridge"`
+	MultiProject bool        `json:"multi_project"`
+	Run          WorkflowRun `json:"run"`
+}
+
+// RepoAccess is what the current token may do in a project
+type RepoAccess struct {
+	Role string
+	// CanTrigger covers starting, re-running, and cancelling runs
+	CanTrigger     bool
+	CanComment     bool
+	CanManageHooks bool
+	// Scopes of a classic GitHub token; empty for other tokens
+	Scopes []string
+}
+
+// CIVariable is a CI/CD variable or secret of a project. Secret values can't
+// be read back, so Value is empty for them.
+type CIVariable struct {
+	Name      string
+	Value     string
+	Masked    bool
+	Protected bool
+	// Environment is the GitLab environment scope, "*" for all environments
+	Environment string
+	UpdatedAt   time.Time
+}
+
+// WorkflowFile is a CI definition file fetched from a repository
+type WorkflowFile struct {
+	Path    string
+	Content string
+}
+
+// RunDefinition is the CI file a run executed, read at the commit the
+// definition was taken from
+type RunDefinition struct {
+	File   WorkflowFile
+	Commit string
+	// Reusable lists the reusable workflows the run called, as path@ref
+	// with the commit they resolved to (GitHub only)
+	Reusable []string
+}
+
+// TriggeredRun links a workflow started by this tool to the run it created
+type TriggeredRun struct {
+	Project     string `json:"project"`
+	Workflow    string `json:"workflow"`
+	Ref         string `json:"ref,omitempty"`
+	RunID       string `json:"run_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	TriggeredAt string `json:"triggered_at"`
+}
+
 // Config holds application configuration
 type Config struct {
 	StateFile string
+	// Workspace is the named workspace StateFile belongs to; empty when
+	// --state was given
+	Workspace string
 	Projects  []Project
+	Triggers  []TriggeredRun
+	Notes     []RunNote
+	Settings  Settings
 }
 
 // version is set at build time via ldflags
@@ -80,22 +341,62 @@ var version = ""
 func main() {
 	// Parse command line flags
 	showVersion := flag.Bool("version", false, "Show version information")
-	stateFile := flag.String("state", "", "Path to state file (default: ~/.config/quick_workflow/state.json)")
+	stateFile := flag.String("state", "", "Path to state file (default: <config dir>/quick_workflow/state.json)")
+	flag.StringVar(&githubTokenOverride, "github-token", "", "GitHub token for this invocation (overrides stored auth)")
+	flag.StringVar(&gitlabTokenOverride, "gitlab-token", "", "GitLab token for this invocation (overrides stored auth)")
+	absoluteTime := flag.Bool("absolute-time", false, "Show absolute timestamps instead of relative times")
+	profile := flag.String("profile", os.Getenv("QUICK_WORKFLOW_PROFILE"), "Profile to use for state, auth, and cache (env: QUICK_WORKFLOW_PROFILE)")
+	workspace := flag.String("workspace", os.Getenv(workspaceEnv), "Workspace of projects and layouts to use (env: "+workspaceEnv+")")
+	flag.DurationVar(&networkFlags.Timeout, "timeout", 0, "HTTP request timeout, e.g. 1m (default: setting http_timeout, else 30s)")
+	flag.DurationVar(&networkFlags.ConnectTimeout, "connect-timeout", 0, "Timeout for connecting and the TLS handshake (default: setting connect_timeout, else 10s)")
+	flag.StringVar(&networkFlags.Proxy, "proxy", "", "Proxy URL for API requests (default: setting proxy, else HTTPS_PROXY/HTTP_PROXY)")
+	flag.StringVar(&networkFlags.CABundle, "ca-bundle", "", "PEM file of extra trusted CA certificates, for TLS interception or private CAs")
+	flag.BoolVar(&networkFlags.InsecureSkipVerify, "insecure-skip-verify", false, "Don't verify TLS certificates (unsafe; prefer --ca-bundle)")
+	verbose := flag.Bool("verbose", false, "Log every API request with its status, duration, and remaining rate limit to stderr")
+	debug := flag.Bool("debug", false, "Like --verbose, also logging request and response headers (credentials redacted)")
+	demo := flag.Bool("demo", false, "Try the tool on made-up demo projects, without tokens or network access")
+	colorMode := flag.String("color", "auto", "Color output: auto (only on a terminal without NO_COLOR), always, or never")
+	offline := flag.Bool("offline", false, "Show runs from the local history and cache without any network requests")
 	flag.Parse()
 
+	if err := configureColor(*colorMode); err != nil {
+		log.Fatal(err)
+	}
+
 	// Handle version flag
 	if *showVersion {
 		fmt.Println(resolveVersion())
 		os.Exit(0)
 	}
 
-	// Set default state file if not provided
-	if *stateFile == "" {
-		homeDir, err := os.UserHomeDir()
+	configureAPILogging(*verbose, *debug)
+
+	if err := setProfile(*profile); err != nil {
+		log.Fatal(err)
+	}
+
+	// Set default state file if not provided, from the selected workspace
+	// or the one last switched to
+	if *demo {
+		*stateFile = filepath.Join(os.TempDir(), "quick_workflow-demo", "state.json")
+		*workspace = ""
+	} else if *stateFile == "" {
+		dir, err := configDir()
 		if err != nil {
-			log.Fatal("Failed to get user home directory:", err)
+			log.Fatal("Failed to get config directory:", err)
+		}
+		if *workspace == "" {
+			*workspace = currentWorkspace(dir)
+		}
+		if err := validateWorkspaceName(*workspace); err != nil {
+			log.Fatal(err)
 		}
-		*stateFile = filepath.Join(homeDir, ".config", "quick_workflow", "state.json")
+		if !workspaceExists(dir, *workspace) {
+			log.Fatalf("Workspace not found: %s (create it with 'quick_workflow workspace create %s')", *workspace, *workspace)
+		}
+		*stateFile = workspaceStateFile(dir, *workspace)
+	} else {
+		*workspace = ""
 	}
 
 	// Ensure state directory exists
@@ -106,13 +407,29 @@ func main() {
 
 	config := &Config{
 		StateFile: *stateFile,
+		Workspace: *workspace,
 	}
 
+	mockFixturesFile = filepath.Join(stateDir, "mock.json")
+
 	// Load existing projects
 	if err := loadProjects(config); err != nil {
 		log.Printf("Warning: Failed to load projects: %v", err)
 		config.Projects = []Project{}
 	}
+	if *demo {
+		if err := setupDemo(config); err != nil {
+			log.Fatal("Failed to set up demo projects:", err)
+		}
+	}
+
+	if err := applySettings(config.Settings, *absoluteTime); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if *offline {
+		offlineConfig = config
+		fmt.Fprintf(os.Stderr, "%s %s\n", qc.Colorize("Info:", qc.ColorCyan), offlineNotice(config))
+	}
 
 	// Parse command
 	args := flag.Args()
@@ -124,37 +441,150 @@ func main() {
 	command := args[0]
 	remainingArgs := args[1:]
 
-	ctx := context.Background()
+	// Ctrl+C cancels ctx, stopping API calls and refresh loops
+	ctx, done := signalContext()
+	defer done()
 
 	switch command {
 	case "add":
-		if len(remainingArgs) == 0 {
+		fs := flag.NewFlagSet("add", flag.ExitOnError)
+		platform := fs.String("platform", "", "CI platform when it isn't the git host: drone, woodpecker, mock, or a plugin's platform")
+		account := fs.String("account", "", "Login the project uses, added with 'login <platform> --account' (default: the account on the remote's host, if any)")
+		remotes := fs.String("remote", "", "Git remotes to add, comma separated, e.g. upstream or origin,upstream to track a fork and its upstream (default: origin, or pick from a list when there are several)")
+		fs.Parse(remainingArgs)
+		if *platform != "" && *platform != "drone" && *platform != "woodpecker" && *platform != "mock" {
+			if _, err := findPlugin(*platform); err != nil {
+				fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+				return
+			}
+		}
+		if fs.NArg() == 0 {
 			// Add current directory
-			addCurrentProject(ctx, config)
+			addCurrentProject(ctx, config, *platform, *account, splitList(*remotes))
 		} else {
 			// Add specific project
-			addProject(ctx, config, remainingArgs[0])
+			addProject(ctx, config, fs.Arg(0), *platform, *account, splitList(*remotes))
 		}
 	case "watch":
-		watchWorkflows(ctx, config)
+		watchWorkflows(ctx, config, remainingArgs)
 	case "start":
 		startWorkflow(ctx, config, remainingArgs)
 	case "list":
 		listWorkflows(ctx, config, remainingArgs)
+	case "ci":
+		showCurrentRepoRuns(ctx, config, remainingArgs)
+	case "status":
+		showStatus(ctx, config, remainingArgs)
+	case "check":
+		handleCheck(ctx, config, remainingArgs)
+	case "timeline":
+		showTimeline(ctx, config, remainingArgs)
+	case "details", "show":
+		showRunDetails(ctx, config, remainingArgs)
 	case "projects":
-		listProjects(config)
+		listProjects(ctx, config, remainingArgs)
 	case "remove":
 		if len(remainingArgs) == 0 {
 			fmt.Println("Usage: quick_workflow remove <project_name>")
 			return
 		}
 		removeProject(config, remainingArgs[0])
+	case "archive", "unarchive":
+		if len(remainingArgs) == 0 {
+			fmt.Printf("Usage: quick_workflow %s <project_name>\n", command)
+			return
+		}
+		setArchived(config, remainingArgs[0], command == "archive")
+	case "prune":
+		handlePrune(config, remainingArgs)
 	case "login":
-		handleLogin(remainingArgs)
+		handleLogin(ctx, remainingArgs)
 	case "logout":
 		handleLogout(remainingArgs)
 	case "auth":
-		showAuthStatus()
+		action := "status"
+		if len(remainingArgs) > 0 {
+			action = remainingArgs[0]
+		}
+		switch action {
+		// "auth status" reads as naturally as "auth"
+		case "status":
+			showAuthStatus(ctx)
+		case "encrypt", "decrypt":
+			handleAuthEncryption(action)
+		default:
+			fmt.Printf("%s Usage: quick_workflow auth [status|encrypt|decrypt]\n", qc.Colorize("Error:", qc.ColorRed))
+		}
+	case "state":
+		handleState(config, remainingArgs)
+	case "note":
+		handleNote(ctx, config, remainingArgs)
+	case "settings":
+		handleSettings(config, remainingArgs)
+	case "migrate":
+		handleMigrate(ctx, config, remainingArgs)
+	case "webhook":
+		handleWebhook(ctx, config, remainingArgs)
+	case "matrix":
+		showMatrixCoverage(ctx, config, remainingArgs)
+	case "trends":
+		showTrends(ctx, config, remainingArgs)
+	case "tests":
+		showTestResults(ctx, config, remainingArgs)
+	case "coverage":
+		handleCoverage(config, remainingArgs)
+	case "defaults":
+		handleDefaults(config, remainingArgs)
+	case "history":
+		showActionHistory(config, remainingArgs)
+	case "releases":
+		showReleases(ctx, config, remainingArgs)
+	case "environments":
+		showEnvironments(ctx, config, remainingArgs)
+	case "audit":
+		handleAudit(ctx, config, remainingArgs)
+	case "inventory":
+		handleInventory(ctx, config, remainingArgs)
+	case "approve":
+		handleApprove(ctx, config, remainingArgs)
+	case "workspace":
+		handleWorkspace(config, remainingArgs)
+	case "verify":
+		verifyProject(ctx, config, remainingArgs)
+	case "runners":
+		showRunners(ctx, config, remainingArgs)
+	case "usage":
+		showUsage(ctx, config, remainingArgs)
+	case "report":
+		generateReport(ctx, config, remainingArgs)
+	case "badge":
+		handleBadge(ctx, config, remainingArgs)
+	case "cancel":
+		handleCancel(ctx, config, remainingArgs)
+	case "rerun":
+		handleRerun(ctx, config, remainingArgs)
+	case "workflow":
+		handleWorkflow(ctx, config, remainingArgs)
+	case "workflows":
+		handleWorkflows(ctx, config, remainingArgs)
+	case "logs":
+		handleLogs(ctx, config, remainingArgs)
+	case "otel":
+		handleOTel(ctx, config, remainingArgs)
+	case "dispatch":
+		handleDispatch(ctx, config, remainingArgs)
+	case "schedules":
+		handleSchedules(ctx, config, remainingArgs)
+	case "lint":
+		lintWorkflows(ctx, remainingArgs)
+	case "secrets":
+		handleSecrets(ctx, config, remainingArgs)
+	case "variables":
+		handleVariables(ctx, config, remainingArgs)
+	case "plugins":
+		handlePlugins(ctx, remainingArgs)
+	case "ratelimit":
+		showRateLimits(ctx)
 	case "help":
 		showHelp()
 	default:
@@ -182,139 +612,341 @@ func showHelp() {
 	fmt.Println("  quick_workflow <command> [options]")
 	fmt.Println()
 	fmt.Printf("%s\n", qc.Colorize("Commands:", qc.ColorYellow))
-	fmt.Println("  add [path]     Add current directory or specified path as a project")
+	fmt.Println("  add [--platform drone|woodpecker|mock] [--remote name,...] [path]  Add current directory or specified path as a project")
 	fmt.Println("  watch          Watch running workflows across all projects")
-	fmt.Println("  start          Start a new workflow")
-	fmt.Println("  list           List historical workflow runs")
+	fmt.Println("  watch --run <id> [--project name]  Follow one run until it finishes; exit 1 unless it succeeded")
+	fmt.Println("  start [--project name] [--workflow name] [--ref branch] [--dry-run]  Start a new workflow")
+	fmt.Println("  list [--all] [--commit sha] [--grep re] [--interactive] [N]  List historical workflow runs (N per project, default 20)")
+	fmt.Println("  list --causes            Tag failed runs with their probable cause (infra or code) from their failed job logs")
+	fmt.Println("  ci             Show runs for the current repo and branch (no add needed)")
+	fmt.Println("  ci --upstream [--watch]  Also show the checks of the branch's pull request on the upstream of a fork")
+	fmt.Println("  status [--at <time>] [--checks]  Latest recorded run per project, optionally in the past")
+	fmt.Println("  status --oneline [--color ansi|tmux]  One-line pass/fail/running summary for status bars")
+	fmt.Println("  check [--project a,b] [--branch b] [--quiet]  Exit 0 if the latest runs passed, 2 if any failed, 3 if any is running")
+	fmt.Println("  timeline <run-id>        Show every recorded event for a run")
+	fmt.Println("  details|show <run-id|url>  Show a run's details, jobs, and failed step logs; takes pasted run URLs anywhere a run ID goes")
+	fmt.Println("  logs [--job n|name] <run-id|url>  Page through a job's log: / to search, e for the next failure, Enter to fold groups")
+	fmt.Println("  logs --print [--fold] [--timestamps] <run-id>  Print the log with the job's colors, folding groups without failures")
+	fmt.Println("  logs --download [--dir path] <run-id|url>  Save every log of a run into <project>-run-<id>, e.g. to keep failure evidence")
+	fmt.Println("  details --copy url|sha|log <run-id>  Copy the run URL, commit SHA, or failed job log to the clipboard")
+	fmt.Println("  report [--format md|html] [--out file]  Render recent runs as a Markdown table or HTML page")
+	fmt.Println("  badge [--workflow name] [--branch b] [--out file.svg] <project>  Render a status badge, or --serve them over HTTP")
+	fmt.Println("  cancel <run-id>... | --all|--superseded [filters]  Cancel runs, or every unfinished/superseded match")
+	fmt.Println("  rerun <run-id>... | --failed [filters]  Re-run runs, or every failed run matching filters")
+	fmt.Println("  history [--project name] [--action a] [--user u] [--since 24h]  Show triggers, cancels, re-runs, approvals, and secret changes made here")
+	fmt.Println("  workflow show <run-id>   Show the workflow file a run executed, at the run's commit")
+	fmt.Println("  workflows list [--disabled] [project...]  List GitHub workflows and which are disabled")
+	fmt.Println("  workflows enable|disable <project>[,<project>...] <workflow>  Turn a workflow on or off")
+	fmt.Println("  dispatch [--payload <json>] [--field KEY=VALUE] <project> <event-type>  Send a GitHub repository_dispatch event")
+	fmt.Println("  schedules list [project...]  List GitLab pipeline schedules")
+	fmt.Println("  schedules create|edit|run|delete <project>[,<project>...] [schedule]  Manage GitLab pipeline schedules")
+	fmt.Println("  otel export [--endpoint url] [--since 24h] [project...]  Send finished runs as OpenTelemetry traces over OTLP")
+	fmt.Println("  tests [--all] <run-id> | --history  Summarize a run's JUnit test reports, or failures per test so far")
 	fmt.Println("  projects       List tracked projects")
+	fmt.Println("  projects --status  List tracked projects with whether their default branch is passing")
+	fmt.Println("  verify <project>         Check which features work for a project with the current token")
+	fmt.Println("  note <project> <run-id> [text]  Add or show notes on a run")
 	fmt.Println("  remove <name>  Remove a project from tracking")
-	fmt.Println("  login <platform> [host]  Authenticate with GitHub or GitLab")
-	fmt.Println("  logout <platform>        Remove authentication")
-	fmt.Println("  auth           Show authentication status")
+	fmt.Println("  archive <name>  Hide a project from watch and list but keep tracking it (unarchive to undo)")
+	fmt.Println("  prune [--days N] [--runs N] [--dry-run]  Remove old runs from the history and cached data of untracked projects")
+	fmt.Println("  login <platform> [--account name] [host]  Authenticate with GitHub, GitLab, Drone, or Woodpecker")
+	fmt.Println("  plugins list|install [--sha256 hex] <path-or-url> [name]  Manage provider plugins for other CI systems")
+	fmt.Println("  logout <platform> | --account <name>  Remove authentication")
+	fmt.Println("  auth [status]  Show authentication status with each token's user, scopes, and expiry")
+	fmt.Println("  auth encrypt|decrypt     Encrypt stored tokens with a passphrase, or store them in plaintext again")
+	fmt.Println("  ratelimit      Show remaining GitHub and GitLab API quota and when it resets")
+	fmt.Println("  state export|import      Export or import tracked projects")
+	fmt.Println("  settings [set|unset]     Show or change settings (timezone, absolute_time, columns)")
+	fmt.Println("  settings signature [list|set|delete]  Manage the failure signatures list --causes matches")
+	fmt.Println("  migrate analyze <project>  Draft the project's CI config for the other platform")
+	fmt.Println("  webhook create|rotate|verify|delete|list  Manage CI event webhooks on projects")
+	fmt.Println("  matrix [--runs N] <project>  Report which matrix combinations recent runs exercised")
+	fmt.Println("  trends [--runs N] [--branch b] [--coverage] [project]  Chart workflow durations and flag slowdowns")
+	fmt.Println("  coverage <project> [--artifact name] [--pattern regex]  Show or set where GitHub runs report coverage")
+	fmt.Println("  defaults <project> [--workflow name] [--branch ref]  Show or set what start triggers and which branch status reports")
+	fmt.Println("  releases [--limit N] [--failed] [--assets] [project]  List recent releases with the runs that built them")
+	fmt.Println("  environments [project]   Show what is deployed where and deployments awaiting approval")
+	fmt.Println("  audit workflows [project]  Report deprecated runners, actions, and commands in CI files")
+	fmt.Println("  inventory actions [--unpinned] [project]  List external actions and includes with their pinning")
+	fmt.Println("  inventory verify [--fix] [--bump] [project]  Verify SHA pins and pin third-party actions")
+	fmt.Println("  approve [--comment text] [project]  Approve a pending deployment or play a manual job")
+	fmt.Println("  runners [--offline] [project]  List self-hosted runners with their status and labels")
+	fmt.Println("  usage [--month YYYY-MM] [--format csv|tsv] [project]  Report CI minutes per workflow and account billing")
+	fmt.Println("  lint [--offline] [path]  Check workflow files and .gitlab-ci.yml before pushing")
+	fmt.Println("  secrets list|set|delete <project> [name]  Manage Actions secrets or masked GitLab variables")
+	fmt.Println("  variables list|set|delete <project> [name] [value]  Manage Actions or GitLab CI/CD variables")
+	fmt.Println("  workspace list|create|use|delete [name]  Manage named sets of projects and layouts")
 	fmt.Println("  help           Show this help message")
 	fmt.Println()
 	fmt.Printf("%s\n", qc.Colorize("Examples:", qc.ColorYellow))
 	fmt.Println("  quick_workflow add .                    # Add current directory")
 	fmt.Println("  quick_workflow add /path/to/repo         # Add specific repository")
 	fmt.Println("  quick_workflow watch                     # Watch running workflows")
+	fmt.Println("  quick_workflow watch --layout oncall     # Watch with a saved layout")
 	fmt.Println("  quick_workflow start                     # Start a new workflow")
 	fmt.Println("  quick_workflow list                      # List recent workflow runs")
+	fmt.Println("  quick_workflow list --columns project,status,branch,duration")
+	fmt.Println("  quick_workflow list --format csv --fields id,project,conclusion,duration > runs.csv")
+	fmt.Println("  quick_workflow list --format template --template '{{.Project}} {{.Status}}'")
+	fmt.Println("  quick_workflow ci                        # Runs for this repo's current branch")
 	fmt.Println("  quick_workflow projects                  # List tracked projects")
+	fmt.Println("  quick_workflow status --at \"2025-01-05 14:00\"  # State at a past time")
 	fmt.Println("  quick_workflow login github              # Authenticate with GitHub")
 	fmt.Println("  quick_workflow login gitlab gitlab.com  # Authenticate with GitLab")
+	fmt.Println("  quick_workflow login github --account work ghe.example.com  # Add a GitHub Enterprise login")
 	fmt.Println("  quick_workflow auth                      # Show authentication status")
+	fmt.Println("  quick_workflow state export --out team.yaml --format yaml")
+	fmt.Println("  quick_workflow state import team.yaml    # Import projects from a file")
+	fmt.Println("  quick_workflow migrate analyze owner/repo --out .gitlab-ci.yml")
+	fmt.Println("  quick_workflow cancel --all --branch feature-x --dry-run")
+	fmt.Println("  quick_workflow --workspace platform watch  # Watch another workspace's projects")
+	fmt.Println("  quick_workflow --demo watch              # Try it on made-up projects, no login needed")
+	fmt.Println("  quick_workflow --offline list            # Runs recorded locally, without network access")
 	fmt.Println()
 	fmt.Printf("%s\n", qc.Colorize("Authentication:", qc.ColorYellow))
 	fmt.Println("  Use 'quick_workflow login <platform>' to authenticate via web browser")
 	fmt.Println("  No need to manually set GITHUB_TOKEN or GITLAB_TOKEN environment variables")
+	fmt.Println("  Use --github-token/--gitlab-token or GITLAB_TOKEN_<HOST> to override per invocation")
 }
 
 // addCurrentProject adds the current directory as a project
-func addCurrentProject(ctx context.Context, config *Config) {
+func addCurrentProject(ctx context.Context, config *Config, platformOverride, account string, remotes []string) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		log.Fatal("Failed to get current directory:", err)
 	}
 
 	// Check if we're in a git repository
-	if !isGitRepository(cwd) {
+	checkout, err := inspectGitCheckout(cwd)
+	if err != nil {
 		log.Fatal("Current directory is not a git repository")
 	}
+	addCheckout(config, checkout, platformOverride, account, remotes)
+}
 
-	// Get remote URL
-	remoteURL, err := getGitRemoteURL(cwd)
+// addProject adds a specific project
+func addProject(ctx context.Context, config *Config, path, platformOverride, account string, remotes []string) {
+	// Resolve absolute path
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		log.Fatal("Failed to get git remote URL:", err)
+		log.Fatal("Failed to resolve path:", err)
 	}
 
-	// Parse remote URL to determine platform and owner/repo
-	platform, owner, repo, err := parseRemoteURL(remoteURL)
+	// Check if it's a git repository
+	checkout, err := inspectGitCheckout(absPath)
 	if err != nil {
-		log.Fatal("Failed to parse remote URL:", err)
+		log.Fatal("Path is not a git repository:", absPath)
 	}
+	addCheckout(config, checkout, platformOverride, account, remotes)
+}
 
-	// Create project
-	project := Project{
-		Name:      fmt.Sprintf("%s/%s", owner, repo),
-		Owner:     owner,
-		Repo:      repo,
-		Platform:  platform,
-		RemoteURL: remoteURL,
-		AddedAt:   time.Now().Format(time.RFC3339),
+// addCheckout tracks the repositories of a checkout's remotes, each as its
+// own project, such as both a fork and its upstream
+func addCheckout(config *Config, checkout *gitCheckout, platformOverride, account string, remotes []string) {
+	if note := checkout.describe(); note != "" {
+		fmt.Printf("%s %s\n", qc.Colorize("Info:", qc.ColorCyan), note)
 	}
 
-	// Check if project already exists
-	for _, existing := range config.Projects {
-		if existing.Name == project.Name {
-			fmt.Printf("%s Project %s is already tracked\n", qc.Colorize("Info:", qc.ColorCyan), qc.ColorizeBold(project.Name, qc.ColorGreen))
-			return
+	remotes, err := chooseRemotes(checkout, remotes)
+	if err != nil {
+		log.Fatal("Failed to choose a git remote: ", err)
+	}
+	for _, remote := range remotes {
+		// Get remote URL
+		remoteURL, err := checkout.remoteURL(remote)
+		if err != nil {
+			log.Fatalf("Failed to get URL of git remote %s: %v", remote, err)
 		}
+		trackProject(config, projectFromRemote(remoteURL, platformOverride, account))
 	}
+}
 
-	// Add project
-	config.Projects = append(config.Projects, project)
-	if err := saveProjects(config); err != nil {
-		log.Fatal("Failed to save project:", err)
+// chooseRemotes returns the remotes to add projects from: those requested
+// with --remote, the only remote, or else those picked from a list. Without
+// a terminal to ask on, origin is used.
+func chooseRemotes(checkout *gitCheckout, requested []string) ([]string, error) {
+	if len(requested) > 0 {
+		return requested, nil
+	}
+	names, err := checkout.remoteNames()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(names) == 0:
+		return nil, fmt.Errorf("repository has no remotes")
+	case len(names) == 1:
+		return names, nil
 	}
 
-	fmt.Printf("%s Added project: %s (%s)\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(project.Name, qc.ColorGreen), platform)
-}
+	hasOrigin := slices.Contains(names, "origin")
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		if hasOrigin {
+			return []string{"origin"}, nil
+		}
+		return nil, fmt.Errorf("repository has several remotes and none is origin; choose with --remote %s", strings.Join(names, ","))
+	}
 
-// addProject adds a specific project
-func addProject(ctx context.Context, config *Config, path string) {
-	// Resolve absolute path
-	absPath, err := filepath.Abs(path)
+	if useFinder() {
+		// origin comes first, so Enter alone adds it
+		if hasOrigin {
+			ordered := []string{"origin"}
+			for _, name := range names {
+				if name != "origin" {
+					ordered = append(ordered, name)
+				}
+			}
+			names = ordered
+		}
+		items := make([]string, len(names))
+		for i, name := range names {
+			remoteURL, _ := checkout.remoteURL(name)
+			items[i] = fmt.Sprintf("%-12s %s", name, remoteURL)
+		}
+		indexes, ok := pickMany("Remotes to add", items)
+		if !ok {
+			return nil, fmt.Errorf("no remote selected")
+		}
+		chosen := make([]string, len(indexes))
+		for i, index := range indexes {
+			chosen[i] = names[index]
+		}
+		return chosen, nil
+	}
+
+	fmt.Printf("%s\n", qc.Colorize("Select remotes to add:", qc.ColorBlue))
+	for i, name := range names {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		remoteURL, _ := checkout.remoteURL(name)
+		fmt.Println(qc.Colorize(fmt.Sprintf("%3d. %-12s %s", i+1, name, remoteURL), rowColor))
+	}
+	prompt := "Select remotes (numbers separated by commas, 'a' for all): "
+	if hasOrigin {
+		prompt = "Select remotes (numbers separated by commas, 'a' for all, Enter for origin): "
+	}
+	fmt.Printf("%s", qc.Colorize(prompt, qc.ColorYellow))
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
 	if err != nil {
-		log.Fatal("Failed to resolve path:", err)
+		return nil, fmt.Errorf("no remote selected")
 	}
 
-	// Check if it's a git repository
-	if !isGitRepository(absPath) {
-		log.Fatal("Path is not a git repository:", absPath)
+	input = strings.TrimSpace(input)
+	switch {
+	case input == "" && hasOrigin:
+		return []string{"origin"}, nil
+	case strings.EqualFold(input, "a"):
+		return names, nil
 	}
+	var chosen []string
+	for _, field := range splitList(input) {
+		index, err := strconv.Atoi(field)
+		if err != nil || index < 1 || index > len(names) {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		if !slices.Contains(chosen, names[index-1]) {
+			chosen = append(chosen, names[index-1])
+		}
+	}
+	if len(chosen) == 0 {
+		return nil, fmt.Errorf("no remote selected")
+	}
+	return chosen, nil
+}
 
-	// Get remote URL
-	remoteURL, err := getGitRemoteURL(absPath)
+// projectFromRemote builds a project to track from its git remote URL. Its
+// account is the named one, or else the account on the remote's host, whose
+// platform the project is on.
+func projectFromRemote(remoteURL, platformOverride, account string) Project {
+	account, found, err := remoteAccount(account, remoteURL)
 	if err != nil {
-		log.Fatal("Failed to get git remote URL:", err)
+		log.Fatal("Failed to find account:", err)
+	}
+	if found != nil && platformOverride == "" {
+		platformOverride = found.Platform
 	}
 
-	// Parse remote URL
-	platform, owner, repo, err := parseRemoteURL(remoteURL)
+	// Parse remote URL to determine platform and owner/repo
+	platform, owner, repo, err := parseProjectRemote(remoteURL, platformOverride)
 	if err != nil {
 		log.Fatal("Failed to parse remote URL:", err)
 	}
 
-	// Create project
-	project := Project{
+	return Project{
 		Name:      fmt.Sprintf("%s/%s", owner, repo),
 		Owner:     owner,
 		Repo:      repo,
 		Platform:  platform,
 		RemoteURL: remoteURL,
 		AddedAt:   time.Now().Format(time.RFC3339),
+		Account:   account,
 	}
+}
 
-	// Check if project already exists
-	for _, existing := range config.Projects {
-		if existing.Name == project.Name {
-			fmt.Printf("%s Project %s is already tracked\n", qc.Colorize("Info:", qc.ColorCyan), qc.ColorizeBold(project.Name, qc.ColorGreen))
-			return
+// trackProject adds a project to the state file unless it is already tracked
+func trackProject(config *Config, project Project) {
+	alreadyTracked := false
+	err := updateProjects(config, func(config *Config) error {
+		for _, existing := range config.Projects {
+			if existing.Name == project.Name {
+				alreadyTracked = true
+				return nil
+			}
 		}
+		config.Projects = append(config.Projects, project)
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Failed to save project:", err)
 	}
 
-	// Add project
-	config.Projects = append(config.Projects, project)
-	if err := saveProjects(config); err != nil {
-		log.Fatal("Failed to save project:", err)
+	if alreadyTracked {
+		fmt.Printf("%s Project %s is already tracked\n", qc.Colorize("Info:", qc.ColorCyan), qc.ColorizeBold(project.Name, qc.ColorGreen))
+		return
 	}
 
-	fmt.Printf("%s Added project: %s (%s)\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(project.Name, qc.ColorGreen), platform)
+	fmt.Printf("%s Added project: %s (%s)\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(project.Name, qc.ColorGreen), project.Platform)
+	fmt.Printf("%s Run 'quick_workflow verify %s' to check which features work with your token\n", qc.Colorize("Info:", qc.ColorCyan), project.Name)
+}
+
+// findProject returns the tracked project with the given name
+func findProject(config *Config, name string) *Project {
+	for i := range config.Projects {
+		if config.Projects[i].Name == name {
+			return &config.Projects[i]
+		}
+	}
+	return nil
 }
 
+// listProjects shows tracked projects, with the state of their latest
+// default branch run for --status
+func listProjects(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("projects", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table or template")
+	templateText := fs.String("template", "", "Go template rendered per project with --format template, e.g. '{{.Name}} {{.Platform}}'")
+	idleMonths := fs.Int("idle-months", 6, "Suggest archiving projects without runs for this many months (0 to never)")
+	withStatus := fs.Bool("status", false, "Fetch the latest default branch run of each project and show whether it passed and how long ago")
+	fs.Parse(args)
+
+	if *format != "table" && *format != "template" {
+		fmt.Printf("%s Unsupported format: %s (expected table or template)\n", qc.Colorize("Error:", qc.ColorRed), *format)
+		return
+	}
+	if *format == "template" {
+		tmpl, err := parseOutputTemplate(*templateText)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		for _, project := range config.Projects {
+			if err := writeTemplate(os.Stdout, tmpl, project); err != nil {
+				fmt.Fprintf(os.Stderr, "%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+				return
+			}
+		}
+		return
+	}
 
-// listProjects shows tracked projects
-func listProjects(config *Config) {
 	if len(config.Projects) == 0 {
 		fmt.Printf("%s No projects tracked. Use 'quick_workflow add .' to add a project.\n", qc.Colorize("Info:", qc.ColorCyan))
 		return
@@ -323,6 +955,17 @@ func listProjects(config *Config) {
 	fmt.Printf("%s\n", qc.Colorize("Tracked Projects:", qc.ColorBlue))
 	fmt.Println()
 
+	var latest map[string]WorkflowRun
+	var failures []fetchFailure
+	if *withStatus {
+		latest, failures = fetchProjectHealth(ctx, config.Projects)
+	}
+	failed := make(map[string]bool)
+	for _, failure := range failures {
+		failed[failure.Project] = true
+	}
+
+	dead := deadProjects(config, time.Duration(*idleMonths)*30*24*time.Hour)
 	for i, project := range config.Projects {
 		// Alternate row colors
 		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
@@ -330,51 +973,205 @@ func listProjects(config *Config) {
 		// Color code the platform
 		platformColor := colorPlatform(project.Platform)
 		
+		platformLabel := project.Platform
+		if project.Account != "" {
+			platformLabel += ", " + project.Account
+		}
+		health := ""
+		if *withStatus && !project.Archived {
+			text, color := "? failed to load", qc.ColorYellow
+			if !failed[project.Name] {
+				run, ok := latest[project.Name]
+				text, color = projectHealth(run, ok)
+			}
+			health = qc.Colorize(fmt.Sprintf("%-22s", text), color) + " "
+		}
 		entry := fmt.Sprintf(
-			"%3d. %-30s %s [%s]",
-			i+1, project.Name, project.RemoteURL,
-			qc.Colorize(project.Platform, platformColor),
+			"%3d. %-30s %s%s [%s]",
+			i+1, project.Name, health, project.RemoteURL,
+			qc.Colorize(platformLabel, platformColor),
 		)
+		switch {
+		case project.Archived:
+			entry += " " + qc.Colorize("[archived]", qc.ColorPurple)
+		case dead[project.Name] != "":
+			entry += " " + qc.Colorize("("+dead[project.Name]+")", qc.ColorYellow)
+		}
 		fmt.Println(qc.Colorize(entry, rowColor))
 	}
+	printFetchFailures(os.Stdout, failures)
+	promptDeadProjects(config, dead)
 }
 
 // removeProject removes a project from tracking
 func removeProject(config *Config, name string) {
-	for i, project := range config.Projects {
-		if project.Name == name {
-			// Remove project
-			config.Projects = append(config.Projects[:i], config.Projects[i+1:]...)
-			if err := saveProjects(config); err != nil {
-				log.Fatal("Failed to save projects:", err)
+	removed := false
+	err := updateProjects(config, func(config *Config) error {
+		for i, project := range config.Projects {
+			if project.Name == name {
+				config.Projects = append(config.Projects[:i], config.Projects[i+1:]...)
+				removed = true
+				return nil
 			}
-			fmt.Printf("%s Removed project: %s\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(name, qc.ColorGreen))
-			return
 		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Failed to save projects:", err)
+	}
+
+	if !removed {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+		return
 	}
-	fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+	fmt.Printf("%s Removed project: %s\n", qc.Colorize("Success:", qc.ColorGreen), qc.ColorizeBold(name, qc.ColorGreen))
 }
 
 // Helper functions
 
-// isGitRepository checks if a directory is a git repository
-func isGitRepository(path string) bool {
-	gitDir := filepath.Join(path, ".git")
-	info, err := os.Stat(gitDir)
-	return err == nil && info.IsDir()
+// gitCheckout is the git work tree a path is in. A linked worktree has its
+// own git dir, while its remotes live in the primary repository's common
+// dir; a submodule has its own repository inside the superproject's.
+type gitCheckout struct {
+	TopLevel  string
+	CommonDir string
+	// Primary is the primary repository's work tree, set for linked
+	// worktrees
+	Primary string
+	// Superproject is the containing repository's work tree, set for
+	// submodules
+	Superproject string
 }
 
-// getGitRemoteURL gets the remote URL from git
-func getGitRemoteURL(path string) (string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
+// inspectGitCheckout asks git which work tree path is in and whether it is
+// a linked worktree or a submodule
+func inspectGitCheckout(path string) (*gitCheckout, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel", "--git-dir", "--git-common-dir", "--show-superproject-working-tree")
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("not a git repository: %s", path)
+	}
+	lines := strings.Split(strings.TrimRight(string(output), "\r\n"), "\n")
+	if len(lines) < 3 {
+		return nil, fmt.Errorf("unexpected git rev-parse output in %s", path)
+	}
+
+	// The git dirs may be relative to path
+	absolute := func(dir string) string {
+		dir = filepath.FromSlash(strings.TrimSpace(dir))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(path, dir)
+		}
+		return filepath.Clean(dir)
+	}
+	checkout := &gitCheckout{TopLevel: absolute(lines[0]), CommonDir: absolute(lines[2])}
+	if absolute(lines[1]) != checkout.CommonDir {
+		checkout.Primary = checkout.CommonDir
+		if filepath.Base(checkout.CommonDir) == ".git" {
+			checkout.Primary = filepath.Dir(checkout.CommonDir)
+		}
+	}
+	if len(lines) > 3 && strings.TrimSpace(lines[3]) != "" {
+		checkout.Superproject = absolute(lines[3])
+	}
+	return checkout, nil
+}
+
+// remoteURL gets the URL of a remote of the checkout's repository, read
+// from the common dir so a worktree resolves its primary repository's remote
+func (c *gitCheckout) remoteURL(remote string) (string, error) {
+	cmd := exec.Command("git", "--git-dir", c.CommonDir, "remote", "get-url", remote)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no remote named %s", remote)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
+// remoteNames lists the remotes of the checkout's repository
+func (c *gitCheckout) remoteNames() ([]string, error) {
+	cmd := exec.Command("git", "--git-dir", c.CommonDir, "remote")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// describe explains which repository a worktree or submodule adds, empty
+// for a primary work tree
+func (c *gitCheckout) describe() string {
+	switch {
+	case c.Primary != "":
+		return fmt.Sprintf("%s is a worktree of %s; adding that repository", c.TopLevel, c.Primary)
+	case c.Superproject != "":
+		return fmt.Sprintf("%s is a submodule of %s; adding the submodule's own repository", c.TopLevel, c.Superproject)
+	}
+	return ""
+}
+
+// getGitBranch gets the currently checked out branch
+func getGitBranch(path string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("detached HEAD has no branch")
+	}
+	return branch, nil
+}
+
+// detectProject builds an untracked project from the git repository at path
+func detectProject(path string) (*Project, error) {
+	return detectRemoteProject(path, "origin")
+}
+
+// detectRemoteProject builds an untracked project from a remote of the git
+// repository at path
+func detectRemoteProject(path, remote string) (*Project, error) {
+	checkout, err := inspectGitCheckout(path)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteURL, err := checkout.remoteURL(remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git remote URL: %v", err)
+	}
+
+	account, found, err := remoteAccount("", remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	platformOverride := ""
+	if found != nil {
+		platformOverride = found.Platform
+	}
+	platform, owner, repo, err := parseProjectRemote(remoteURL, platformOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{
+		Name:      fmt.Sprintf("%s/%s", owner, repo),
+		Owner:     owner,
+		Repo:      repo,
+		Platform:  platform,
+		RemoteURL: remoteURL,
+		Account:   account,
+	}
+	// A tracked project's account chosen by name is kept
+	if account != "" {
+		projectAccounts[project.Name] = account
+	}
+	return project, nil
+}
+
 // parseRemoteURL parses a git remote URL to extract platform, owner, and repo
 func parseRemoteURL(url string) (platform, owner, repo string, err error) {
 	// Handle different URL formats
@@ -479,64 +1276,186 @@ func parseRemoteURL(url string) (platform, owner, repo string, err error) {
 	return "", "", "", fmt.Errorf("unsupported remote URL format: %s", url)
 }
 
+// parseProjectRemote parses a git remote URL like parseRemoteURL. A
+// platform override is for Drone, Woodpecker, and plugin platforms, which
+// build repos hosted elsewhere, such as on Gitea, so any host is accepted
+// and only owner/repo is taken from the URL.
+func parseProjectRemote(url, platformOverride string) (platform, owner, repo string, err error) {
+	if platformOverride == "" {
+		return parseRemoteURL(url)
+	}
+
+	path := strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if _, rest, ok := strings.Cut(path, "://"); ok {
+		path = rest
+	} else if _, rest, ok := strings.Cut(path, ":"); ok {
+		// scp-like git@host:owner/repo
+		path = rest
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", "", fmt.Errorf("unsupported remote URL format: %s", url)
+	}
+	return platformOverride, parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
 // colorPlatform returns a color for the platform
 func colorPlatform(platform string) string {
 	switch platform {
 	case "github":
 		return qc.ColorPurple
-	case "gitlab":
+	case "gitlab", "drone", "woodpecker":
 		return qc.ColorPurple
+	case "mock":
+		return qc.ColorYellow
 	default:
 		return qc.ColorWhite
 	}
 }
 
 // handleLogin handles the login command
-func handleLogin(args []string) {
+func handleLogin(ctx context.Context, args []string) {
 	if len(args) == 0 {
-		fmt.Printf("%s Usage: quick_workflow login <platform> [host]\n", qc.Colorize("Error:", qc.ColorRed))
-		fmt.Println("  Platform: github, gitlab")
+		fmt.Printf("%s Usage: quick_workflow login <platform> [--account name] [host]\n", qc.Colorize("Error:", qc.ColorRed))
+		fmt.Println("  Platform: github, gitlab, drone, woodpecker")
 		fmt.Println("  Host: (optional) for GitLab, specify host like gitlab.com")
+		fmt.Println("        (required) for Drone and Woodpecker, the server like ci.example.com")
+		fmt.Println("  Account: (optional) for GitHub and GitLab, store a further login by name,")
+		fmt.Println("           e.g. --account work ghe.example.com, used by projects added with it")
 		return
 	}
 
 	platform := args[0]
-	host := ""
-	if len(args) > 1 {
-		host = args[1]
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	account := fs.String("account", "", "Name of a further GitHub or GitLab login, used by projects added with --account")
+	fs.Parse(args[1:])
+	host := fs.Arg(0)
+
+	if *account != "" && platform != "github" && platform != "gitlab" {
+		fmt.Printf("%s Accounts are only supported for github and gitlab\n", qc.Colorize("Error:", qc.ColorRed))
+		return
 	}
 
 	switch platform {
 	case "github":
-		if err := loginGitHub(); err != nil {
-			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		if err := loginGitHub(ctx, *account, host); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
 			return
 		}
 	case "gitlab":
-		if err := loginGitLab(host); err != nil {
-			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		if err := loginGitLab(ctx, *account, host); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	case "drone", "woodpecker":
+		if err := loginDrone(ctx, platform, host); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
 			return
 		}
 	default:
 		fmt.Printf("%s Invalid platform: %s\n", qc.Colorize("Error:", qc.ColorRed), platform)
-		fmt.Println("Supported platforms: github, gitlab")
+		fmt.Println("Supported platforms: github, gitlab, drone, woodpecker")
 	}
 }
 
 // handleLogout handles the logout command
 func handleLogout(args []string) {
-	if len(args) == 0 {
-		fmt.Printf("%s Usage: quick_workflow logout <platform>\n", qc.Colorize("Error:", qc.ColorRed))
-		fmt.Println("  Platform: github, gitlab, all")
+	fs := flag.NewFlagSet("logout", flag.ExitOnError)
+	account := fs.String("account", "", "Name of a further GitHub or GitLab login to remove")
+	fs.Parse(args)
+
+	if *account != "" {
+		if err := logoutAccount(*account); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Removed account %s\n", qc.Colorize("Success:", qc.ColorGreen), *account)
 		return
 	}
 
-	platform := args[0]
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow logout <platform> | --account <name>\n", qc.Colorize("Error:", qc.ColorRed))
+		fmt.Println("  Platform: github, gitlab, drone, woodpecker, all")
+		return
+	}
+
+	platform := fs.Arg(0)
 	if err := logout(platform); err != nil {
-		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
 		return
 	}
 
 	fmt.Printf("%s Logged out from %s\n", qc.Colorize("Success:", qc.ColorGreen), platform)
 }
 
+
+// handleState handles the state export and import commands
+func handleState(config *Config, args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow state <export|import> [options]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("state export", flag.ExitOnError)
+		format := fs.String("format", "json", "Output format: json or yaml")
+		out := fs.String("out", "", "Write to file instead of stdout")
+		includeSecrets := fs.Bool("include-secrets", false, "Include access tokens in the export")
+		fs.Parse(args[1:])
+
+		data, err := marshalStateExport(exportState(config, *includeSecrets), *format)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+
+		if *out == "" {
+			os.Stdout.Write(data)
+			return
+		}
+
+		// Exports may contain tokens, so keep them private
+		if err := os.WriteFile(*out, data, 0600); err != nil {
+			fmt.Printf("%s Failed to write export: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Exported %d projects to %s\n", qc.Colorize("Success:", qc.ColorGreen), len(config.Projects), *out)
+	case "import":
+		fs := flag.NewFlagSet("state import", flag.ExitOnError)
+		replace := fs.Bool("replace", false, "Replace tracked projects instead of merging")
+		fs.Parse(args[1:])
+
+		if fs.NArg() == 0 {
+			fmt.Printf("%s Usage: quick_workflow state import [--replace] <file|->\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+
+		var data []byte
+		var err error
+		if fs.Arg(0) == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(fs.Arg(0))
+		}
+		if err != nil {
+			fmt.Printf("%s Failed to read import: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+
+		export, err := unmarshalStateExport(data)
+		if err != nil {
+			fmt.Printf("%s Failed to parse import: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+
+		added, err := importState(config, export, *replace)
+		if err != nil {
+			fmt.Printf("%s Failed to import state: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Imported %d projects\n", qc.Colorize("Success:", qc.ColorGreen), added)
+	default:
+		fmt.Printf("%s Unknown state command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// githubMatrixJob matches GitHub's default matrix job names, "build (ubuntu-latest, 1.22)"
+	githubMatrixJob = regexp.MustCompile(`^(.*) \((.+)\)$`)
+	// gitlabMatrixJob matches GitLab parallel:matrix job names, "test: [amd64, 1.22]"
+	gitlabMatrixJob = regexp.MustCompile(`^(.*): \[(.+)\]$`)
+)
+
+// matrixStats counts the outcomes of one matrix combination
+type matrixStats struct {
+	Values  []string
+	Runs    int
+	Success int
+	Failure int
+	Skipped int
+}
+
+// matrixJob collects the combinations seen for one job of a workflow
+type matrixJob struct {
+	Name         string
+	Combinations map[string]*matrixStats
+}
+
+// parseMatrixJobName splits a matrix job name into the job name and its
+// matrix values
+func parseMatrixJobName(platform, name string) (string, []string, bool) {
+	pattern := githubMatrixJob
+	if platform == "gitlab" {
+		pattern = gitlabMatrixJob
+	}
+	match := pattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", nil, false
+	}
+	return match[1], strings.Split(match[2], ", "), true
+}
+
+// record adds a job's outcome to the combination's stats
+func (s *matrixStats) record(job Job) {
+	s.Runs++
+	switch result := job.Conclusion; {
+	case result == "success":
+		s.Success++
+	case isFailedConclusion(result):
+		s.Failure++
+	case result == "skipped":
+		s.Skipped++
+	}
+}
+
+// verdict summarizes a combination's history
+func (s *matrixStats) verdict() (string, string) {
+	switch {
+	case s.Skipped == s.Runs:
+		return "always skipped", qc.ColorYellow
+	case s.Failure == s.Runs:
+		return "always failing", qc.ColorRed
+	case s.Failure > 0:
+		return "sometimes failing", qc.ColorYellow
+	default:
+		return "ok", qc.ColorGreen
+	}
+}
+
+// configuredCombinations expands a GitHub strategy.matrix into the value
+// combinations it configures. Only scalar values are expanded; exclude
+// entries remove combinations and include entries that don't extend an
+// existing combination add new ones.
+func configuredCombinations(matrix *yaml.Node) [][]string {
+	var keys []string
+	values := make(map[string][]string)
+	for _, entry := range mapEntries(matrix) {
+		if entry.key == "include" || entry.key == "exclude" {
+			continue
+		}
+		if scalars := scalarValues(entry.value); len(scalars) > 0 && entry.value.Kind == yaml.SequenceNode {
+			keys = append(keys, entry.key)
+			values[entry.key] = scalars
+		}
+	}
+
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		var expanded []map[string]string
+		for _, combination := range combinations {
+			for _, value := range values[key] {
+				next := map[string]string{key: value}
+				for k, v := range combination {
+					next[k] = v
+				}
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+
+	// matchesEntry reports whether a combination has every scalar of an
+	// include or exclude entry that refers to an original matrix key
+	matchesEntry := func(combination map[string]string, entry *yaml.Node) bool {
+		for _, field := range mapEntries(entry) {
+			if _, original := values[field.key]; !original {
+				continue
+			}
+			if field.value.Kind != yaml.ScalarNode || combination[field.key] != field.value.Value {
+				return false
+			}
+		}
+		return true
+	}
+
+	var excludes []*yaml.Node
+	if exclude := mapGet(matrix, "exclude"); exclude != nil {
+		excludes = exclude.Content
+	}
+
+	var kept []map[string]string
+	for _, combination := range combinations {
+		excluded := false
+		for _, entry := range excludes {
+			if matchesEntry(combination, entry) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded && len(combination) > 0 {
+			kept = append(kept, combination)
+		}
+	}
+
+	var result [][]string
+	for _, combination := range kept {
+		var combo []string
+		for _, key := range keys {
+			combo = append(combo, combination[key])
+		}
+		result = append(result, combo)
+	}
+
+	if include := mapGet(matrix, "include"); include != nil {
+		for _, entry := range include.Content {
+			extends := false
+			for _, combination := range kept {
+				if matchesEntry(combination, entry) {
+					extends = true
+					break
+				}
+			}
+			if extends && len(kept) > 0 {
+				continue
+			}
+			var combo []string
+			for _, field := range mapEntries(entry) {
+				if field.value.Kind == yaml.ScalarNode {
+					combo = append(combo, field.value.Value)
+				}
+			}
+			if len(combo) > 0 {
+				result = append(result, combo)
+			}
+		}
+	}
+	return result
+}
+
+// combinationExercised reports whether an observed combination covers every
+// configured value. Job names may carry extra values added by include.
+func combinationExercised(configured []string, observed map[string]*matrixStats) bool {
+	for _, stats := range observed {
+		remaining := append([]string(nil), stats.Values...)
+		found := true
+		for _, value := range configured {
+			index := -1
+			for i, candidate := range remaining {
+				if candidate == value {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				found = false
+				break
+			}
+			remaining = append(remaining[:index], remaining[index+1:]...)
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// configuredMatrices reads the matrices configured in a GitHub project's
+// workflow files, keyed by workflow name and then job name
+func configuredMatrices(ctx context.Context, project Project) (map[string]map[string][][]string, error) {
+	client, err := NewGitHubClient(ctx, project.Name)
+	if err != nil {
+		return nil, err
+	}
+	files, err := client.GetWorkflowFiles(project.Owner, project.Repo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	matrices := make(map[string]map[string][][]string)
+	for _, file := range files {
+		root, err := parseWorkflowFile(file)
+		if err != nil {
+			continue
+		}
+		workflow := file.Path
+		if name := mapGet(root, "name"); name != nil {
+			workflow = name.Value
+		}
+
+		jobs := make(map[string][][]string)
+		for _, entry := range mapEntries(mapGet(root, "jobs")) {
+			matrix := mapGet(mapGet(entry.value, "strategy"), "matrix")
+			if matrix == nil || matrix.Kind != yaml.MappingNode {
+				continue
+			}
+			name := entry.key
+			if custom := mapGet(entry.value, "name"); custom != nil && !strings.Contains(custom.Value, "${{") {
+				name = custom.Value
+			}
+			jobs[name] = configuredCombinations(matrix)
+		}
+		if len(jobs) > 0 {
+			matrices[workflow] = jobs
+			matrices[filepath.Base(file.Path)] = jobs
+		}
+	}
+	return matrices, nil
+}
+
+// showMatrixCoverage reports which matrix combinations recent runs exercised
+// and how they fared, per workflow
+func showMatrixCoverage(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("matrix", flag.ExitOnError)
+	runs := fs.Int("runs", 20, "Number of recent runs to analyze")
+	workflowFilter := fs.String("workflow", "", "Only analyze this workflow")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow matrix [--runs N] [--workflow name] <project>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project := findProject(config, fs.Arg(0))
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+		return
+	}
+
+	recent, err := getWorkflowRunsForProject(ctx, *project, "", *runs)
+	if err != nil {
+		fmt.Printf("%s Failed to get runs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	// workflow -> job -> combinations
+	coverage := make(map[string]map[string]*matrixJob)
+	analyzed := 0
+	for _, run := range recent {
+		if *workflowFilter != "" && run.Workflow != *workflowFilter {
+			continue
+		}
+		if !isRunFinished(run.Status) {
+			continue
+		}
+		jobs, err := getJobsForRun(ctx, run)
+		if err != nil {
+			fmt.Printf("%s Failed to get jobs for run %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), run.ID, describeError(err))
+			continue
+		}
+		analyzed++
+
+		for _, job := range jobs {
+			name, values, ok := parseMatrixJobName(project.Platform, job.Name)
+			if !ok {
+				continue
+			}
+			if coverage[run.Workflow] == nil {
+				coverage[run.Workflow] = make(map[string]*matrixJob)
+			}
+			mj := coverage[run.Workflow][name]
+			if mj == nil {
+				mj = &matrixJob{Name: name, Combinations: make(map[string]*matrixStats)}
+				coverage[run.Workflow][name] = mj
+			}
+			key := strings.Join(values, ", ")
+			if mj.Combinations[key] == nil {
+				mj.Combinations[key] = &matrixStats{Values: values}
+			}
+			mj.Combinations[key].record(job)
+		}
+	}
+
+	var configured map[string]map[string][][]string
+	if project.Platform == "github" {
+		configured, err = configuredMatrices(ctx, *project)
+		if err != nil {
+			fmt.Printf("%s Failed to read workflow files, skipping configured matrix check: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		}
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Matrix coverage for %s (%d finished runs):", project.Name, analyzed), qc.ColorBlue))
+	if len(coverage) == 0 {
+		fmt.Printf("%s No matrix jobs found in recent runs\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	workflows := slices.Sorted(maps.Keys(coverage))
+
+	for _, workflow := range workflows {
+		fmt.Printf("\n%s\n", qc.ColorizeBold(workflow, qc.ColorGreen))
+
+		jobNames := slices.Sorted(maps.Keys(coverage[workflow]))
+
+		for _, name := range jobNames {
+			mj := coverage[workflow][name]
+			fmt.Printf("  %s\n", qc.ColorizeBold(name, qc.ColorWhite))
+
+			keys := make([]string, 0, len(mj.Combinations))
+			longest := 0
+			for key := range mj.Combinations {
+				keys = append(keys, key)
+				if len(key) > longest {
+					longest = len(key)
+				}
+			}
+			sort.Strings(keys)
+
+			for i, key := range keys {
+				stats := mj.Combinations[key]
+				verdict, color := stats.verdict()
+				entry := fmt.Sprintf("    %-*s %3d runs %3d ok %3d failed %3d skipped  ",
+					longest, key, stats.Runs, stats.Success, stats.Failure, stats.Skipped)
+				fmt.Println(qc.Colorize(entry, qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)) + qc.Colorize(verdict, color))
+			}
+
+			for _, combo := range configured[workflow][name] {
+				if !combinationExercised(combo, mj.Combinations) {
+					fmt.Printf("    %-*s %s\n", longest, strings.Join(combo, ", "), qc.Colorize("configured but never run", qc.ColorRed))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,886 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"gopkg.in/yaml.v3"
+)
+
+// migrationFinding is a construct that could not be translated directly and
+// needs a manual decision
+type migrationFinding struct {
+	Job       string
+	Construct string
+	Message   string
+}
+
+// migration collects the findings of a single conversion
+type migration struct {
+	findings []migrationFinding
+}
+
+// flag records a construct that needs manual attention
+func (m *migration) flag(job, construct, format string, args ...interface{}) {
+	m.findings = append(m.findings, migrationFinding{Job: job, Construct: construct, Message: fmt.Sprintf(format, args...)})
+}
+
+// yamlPair is one key/value entry of a YAML mapping
+type yamlPair struct {
+	key   string
+	value *yaml.Node
+}
+
+// mapEntries returns the entries of a mapping node in document order
+func mapEntries(node *yaml.Node) []yamlPair {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	entries := make([]yamlPair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		entries = append(entries, yamlPair{key: node.Content[i].Value, value: node.Content[i+1]})
+	}
+	return entries
+}
+
+// mapGet returns the value for key in a mapping node, or nil
+func mapGet(node *yaml.Node, key string) *yaml.Node {
+	for _, entry := range mapEntries(node) {
+		if entry.key == key {
+			return entry.value
+		}
+	}
+	return nil
+}
+
+// scalarValues returns the values of a scalar or a sequence of scalars
+func scalarValues(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}
+	case yaml.SequenceNode:
+		var values []string
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				values = append(values, item.Value)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// newMapping returns an empty mapping node
+func newMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode}
+}
+
+// addNode appends key: value to a mapping node
+func addNode(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, stringNode(key), value)
+}
+
+// stringNode returns a string scalar, using a block literal for multi-line text
+func stringNode(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if strings.Contains(value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	return node
+}
+
+// boolNode returns a boolean scalar
+func boolNode(value bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}
+}
+
+// sequenceNode returns a sequence of strings
+func sequenceNode(values ...string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, value := range values {
+		node.Content = append(node.Content, stringNode(value))
+	}
+	return node
+}
+
+// encodeYAML renders a document node with two space indentation
+func encodeYAML(doc *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parseWorkflowFile parses a CI file into its top level mapping
+func parseWorkflowFile(file WorkflowFile) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(file.Content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", file.Path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a YAML mapping", file.Path)
+	}
+	return doc.Content[0], nil
+}
+
+// convertGitHubToGitLab drafts a .gitlab-ci.yml from GitHub Actions
+// workflows. Jobs are prefixed with the workflow name when several
+// workflows are combined.
+func convertGitHubToGitLab(files []WorkflowFile) (string, []migrationFinding, error) {
+	m := &migration{}
+	jobs := newMapping()
+	variables := newMapping()
+	maxDepth := 0
+
+	for _, file := range files {
+		root, err := parseWorkflowFile(file)
+		if err != nil {
+			return "", nil, err
+		}
+
+		prefix := ""
+		if len(files) > 1 {
+			prefix = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file.Path), ".yml"), ".yaml") + "-"
+		}
+
+		m.convertGitHubTriggers(file.Path, mapGet(root, "on"))
+		for _, entry := range mapEntries(mapGet(root, "env")) {
+			addNode(variables, entry.key, entry.value)
+		}
+		for _, key := range []string{"permissions", "concurrency", "defaults"} {
+			if mapGet(root, key) != nil {
+				m.flag(file.Path, key, "workflow level %s has no direct equivalent; review job settings", key)
+			}
+		}
+
+		sourceJobs := mapGet(root, "jobs")
+		depths := githubJobDepths(sourceJobs)
+		for _, entry := range mapEntries(sourceJobs) {
+			depth := depths[entry.key]
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			addNode(jobs, prefix+entry.key, m.convertGitHubJob(prefix, entry.key, entry.value, depth))
+		}
+	}
+
+	root := newMapping()
+	stages := make([]string, maxDepth+1)
+	for i := range stages {
+		stages[i] = fmt.Sprintf("stage-%d", i+1)
+	}
+	addNode(root, "stages", sequenceNode(stages...))
+	if len(variables.Content) > 0 {
+		addNode(root, "variables", variables)
+	}
+	root.Content = append(root.Content, jobs.Content...)
+
+	out, err := encodeYAML(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}})
+	return out, m.findings, err
+}
+
+// convertGitHubTriggers flags workflow triggers, which GitLab configures
+// through rules and project settings rather than in each job
+func (m *migration) convertGitHubTriggers(path string, on *yaml.Node) {
+	var events []string
+	switch {
+	case on == nil:
+		return
+	case on.Kind == yaml.MappingNode:
+		for _, entry := range mapEntries(on) {
+			events = append(events, entry.key)
+		}
+	default:
+		events = scalarValues(on)
+	}
+
+	for _, event := range events {
+		switch event {
+		case "push", "pull_request":
+			// Pipelines run on pushes and merge requests by default
+		case "schedule":
+			m.flag(path, "on.schedule", "create a pipeline schedule in the GitLab project settings")
+		case "workflow_dispatch":
+			m.flag(path, "on.workflow_dispatch", "run pipelines manually from the UI or API; inputs become CI/CD variables")
+		default:
+			m.flag(path, "on."+event, "no direct equivalent for the %s trigger", event)
+		}
+	}
+}
+
+// githubJobDepths returns how many levels of needs each job has, which
+// becomes its stage
+func githubJobDepths(jobs *yaml.Node) map[string]int {
+	depths := make(map[string]int)
+	visiting := make(map[string]bool)
+
+	var depth func(name string) int
+	depth = func(name string) int {
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		d := 0
+		for _, need := range scalarValues(mapGet(mapGet(jobs, name), "needs")) {
+			if nd := depth(need) + 1; nd > d {
+				d = nd
+			}
+		}
+		depths[name] = d
+		return d
+	}
+
+	for _, entry := range mapEntries(jobs) {
+		depth(entry.key)
+	}
+	return depths
+}
+
+// convertGitHubJob drafts a GitLab job from a GitHub Actions job
+func (m *migration) convertGitHubJob(prefix, name string, job *yaml.Node, depth int) *yaml.Node {
+	out := newMapping()
+	jobName := prefix + name
+	addNode(out, "stage", stringNode(fmt.Sprintf("stage-%d", depth+1)))
+
+	if uses := mapGet(job, "uses"); uses != nil {
+		m.flag(jobName, "uses", "reusable workflow %s has no direct equivalent; consider include or a child pipeline", uses.Value)
+		addNode(out, "script", sequenceNode(fmt.Sprintf("echo \"TODO: replace reusable workflow %s\"", uses.Value)))
+		return out
+	}
+
+	if needs := scalarValues(mapGet(job, "needs")); len(needs) > 0 {
+		for i := range needs {
+			needs[i] = prefix + needs[i]
+		}
+		addNode(out, "needs", sequenceNode(needs...))
+	}
+
+	if container := mapGet(job, "container"); container != nil {
+		if container.Kind == yaml.MappingNode {
+			container = mapGet(container, "image")
+			m.flag(jobName, "container", "container options other than image were not converted")
+		}
+		if container != nil {
+			addNode(out, "image", stringNode(container.Value))
+		}
+	} else if runsOn := scalarValues(mapGet(job, "runs-on")); len(runsOn) > 0 {
+		if strings.HasPrefix(runsOn[0], "ubuntu") {
+			addNode(out, "image", stringNode("ubuntu:latest"))
+		} else {
+			addNode(out, "tags", sequenceNode(runsOn...))
+			m.flag(jobName, "runs-on", "runner labels %s were mapped to tags; match them to your GitLab runners", strings.Join(runsOn, ", "))
+		}
+	}
+
+	if services := mapGet(job, "services"); services != nil {
+		var images []string
+		for _, entry := range mapEntries(services) {
+			if image := mapGet(entry.value, "image"); image != nil {
+				images = append(images, image.Value)
+			} else if entry.value.Kind == yaml.ScalarNode {
+				images = append(images, entry.value.Value)
+			}
+		}
+		addNode(out, "services", sequenceNode(images...))
+		m.flag(jobName, "services", "service ports and options were not converted; services are reached by image name")
+	}
+
+	variables := newMapping()
+	for _, entry := range mapEntries(mapGet(job, "env")) {
+		addNode(variables, entry.key, entry.value)
+	}
+
+	if matrix := mapGet(mapGet(job, "strategy"), "matrix"); matrix != nil {
+		combination := newMapping()
+		for _, entry := range mapEntries(matrix) {
+			switch entry.key {
+			case "include", "exclude":
+				m.flag(jobName, "strategy.matrix."+entry.key, "parallel:matrix has no %s; list the combinations explicitly", entry.key)
+			default:
+				addNode(combination, entry.key, entry.value)
+			}
+		}
+		if len(combination.Content) > 0 {
+			parallel := newMapping()
+			addNode(parallel, "matrix", &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{combination}})
+			addNode(out, "parallel", parallel)
+			m.flag(jobName, "strategy.matrix", "matrix values are exposed as variables; replace ${{ matrix.* }} references")
+		}
+	}
+
+	script, artifacts, cache := m.convertGitHubSteps(jobName, mapGet(job, "steps"), variables)
+	if len(variables.Content) > 0 {
+		addNode(out, "variables", variables)
+	}
+	if len(script) == 0 {
+		script = []string{"echo \"TODO: add job commands\""}
+	}
+	addNode(out, "script", sequenceNode(script...))
+	if len(artifacts) > 0 {
+		artifactsNode := newMapping()
+		addNode(artifactsNode, "paths", sequenceNode(artifacts...))
+		addNode(out, "artifacts", artifactsNode)
+	}
+	if len(cache) > 0 {
+		cacheNode := newMapping()
+		addNode(cacheNode, "paths", sequenceNode(cache...))
+		addNode(out, "cache", cacheNode)
+	}
+
+	if timeout := mapGet(job, "timeout-minutes"); timeout != nil {
+		addNode(out, "timeout", stringNode(timeout.Value+" minutes"))
+	}
+	if continueOnError := mapGet(job, "continue-on-error"); continueOnError != nil {
+		addNode(out, "allow_failure", boolNode(continueOnError.Value == "true"))
+	}
+	if environment := mapGet(job, "environment"); environment != nil {
+		if environment.Kind == yaml.MappingNode {
+			environment = mapGet(environment, "name")
+		}
+		if environment != nil {
+			addNode(out, "environment", stringNode(environment.Value))
+		}
+	}
+	if condition := mapGet(job, "if"); condition != nil {
+		m.flag(jobName, "if", "rewrite the condition %q as rules", condition.Value)
+	}
+	if mapGet(job, "outputs") != nil {
+		m.flag(jobName, "outputs", "pass job outputs with an artifacts:reports:dotenv file")
+	}
+	for _, key := range []string{"permissions", "concurrency", "defaults"} {
+		if mapGet(job, key) != nil {
+			m.flag(jobName, key, "%s has no direct equivalent", key)
+		}
+	}
+
+	return out
+}
+
+// convertGitHubSteps converts steps to script lines, collecting artifact and
+// cache paths from well known actions. Step env is merged into variables.
+func (m *migration) convertGitHubSteps(jobName string, steps *yaml.Node, variables *yaml.Node) (script, artifacts, cache []string) {
+	if steps == nil {
+		return nil, nil, nil
+	}
+
+	for i, step := range steps.Content {
+		label := fmt.Sprintf("steps[%d]", i)
+		if name := mapGet(step, "name"); name != nil {
+			label = name.Value
+		}
+
+		for _, entry := range mapEntries(mapGet(step, "env")) {
+			addNode(variables, entry.key, entry.value)
+		}
+		if condition := mapGet(step, "if"); condition != nil {
+			m.flag(jobName, label, "step condition %q was dropped; split the step into its own job with rules", condition.Value)
+		}
+
+		if run := mapGet(step, "run"); run != nil {
+			command := strings.TrimRight(run.Value, "\n")
+			if dir := mapGet(step, "working-directory"); dir != nil {
+				command = fmt.Sprintf("cd %s\n%s\ncd \"$CI_PROJECT_DIR\"", dir.Value, command)
+			}
+			if mapGet(step, "shell") != nil {
+				m.flag(jobName, label, "shell selection was dropped; scripts run in the image's default shell")
+			}
+			script = append(script, command)
+			continue
+		}
+
+		uses := mapGet(step, "uses")
+		if uses == nil {
+			continue
+		}
+		action, _, _ := strings.Cut(uses.Value, "@")
+		with := mapGet(step, "with")
+		switch {
+		case action == "actions/checkout":
+			// GitLab checks out the repository before every job
+		case strings.HasPrefix(action, "actions/setup-"):
+			m.flag(jobName, label, "%s has no equivalent; choose an image that provides %s", action, strings.TrimPrefix(action, "actions/setup-"))
+		case action == "actions/upload-artifact":
+			if path := mapGet(with, "path"); path != nil {
+				artifacts = append(artifacts, nonEmptyLines(path.Value)...)
+			}
+		case action == "actions/download-artifact":
+			// Artifacts from earlier stages are downloaded automatically
+		case action == "actions/cache":
+			if path := mapGet(with, "path"); path != nil {
+				cache = append(cache, nonEmptyLines(path.Value)...)
+			}
+			m.flag(jobName, label, "cache key was not converted; set cache:key")
+		default:
+			m.flag(jobName, label, "action %s has no direct equivalent; reimplement it as script commands", uses.Value)
+			script = append(script, fmt.Sprintf("echo \"TODO: replace %s\"", uses.Value))
+		}
+	}
+	return script, artifacts, cache
+}
+
+// nonEmptyLines splits a multi-line value into trimmed, non-empty lines
+func nonEmptyLines(value string) []string {
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// gitlabReservedKeys are top level .gitlab-ci.yml keys that are not jobs
+var gitlabReservedKeys = map[string]bool{
+	"stages": true, "variables": true, "default": true, "include": true, "workflow": true,
+	"image": true, "services": true, "before_script": true, "after_script": true, "cache": true,
+}
+
+// convertGitLabToGitHub drafts a GitHub Actions workflow from .gitlab-ci.yml
+func convertGitLabToGitHub(file WorkflowFile) (string, []migrationFinding, error) {
+	m := &migration{}
+	root, err := parseWorkflowFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Defaults may be set at the top level or under default:
+	defaults := mapGet(root, "default")
+	if defaults == nil {
+		defaults = root
+	}
+
+	if mapGet(root, "include") != nil {
+		m.flag(file.Path, "include", "included configuration was not fetched; convert it separately")
+	}
+	if mapGet(root, "workflow") != nil {
+		m.flag(file.Path, "workflow", "workflow rules become the on: triggers; review them")
+	}
+	if mapGet(defaults, "cache") != nil {
+		m.flag(file.Path, "cache", "default cache was not converted; add actions/cache steps")
+	}
+
+	stages := scalarValues(mapGet(root, "stages"))
+	if len(stages) == 0 {
+		stages = []string{"build", "test", "deploy"}
+	}
+
+	type gitlabJob struct {
+		name  string
+		stage string
+		node  *yaml.Node
+	}
+	var jobs []gitlabJob
+	for _, entry := range mapEntries(root) {
+		if gitlabReservedKeys[entry.key] || entry.value.Kind != yaml.MappingNode {
+			continue
+		}
+		if strings.HasPrefix(entry.key, ".") {
+			m.flag(entry.key, "template", "hidden job templates have no equivalent; inline them into the jobs that extend them")
+			continue
+		}
+		stage := "test"
+		if s := mapGet(entry.value, "stage"); s != nil {
+			stage = s.Value
+		}
+		jobs = append(jobs, gitlabJob{name: entry.key, stage: stage, node: entry.value})
+	}
+
+	// Jobs without needs wait for every job of the previous stage
+	stageIndex := make(map[string]int)
+	for i, stage := range stages {
+		stageIndex[stage] = i
+	}
+	previousStageJobs := func(stage string) []string {
+		index, ok := stageIndex[stage]
+		for index > 0 && ok {
+			index--
+			var names []string
+			for _, job := range jobs {
+				if job.stage == stages[index] {
+					names = append(names, jobID(job.name))
+				}
+			}
+			if len(names) > 0 {
+				return names
+			}
+		}
+		return nil
+	}
+
+	outJobs := newMapping()
+	for _, job := range jobs {
+		var needs []string
+		if needsNode := mapGet(job.node, "needs"); needsNode != nil {
+			for _, item := range needsNode.Content {
+				if item.Kind == yaml.MappingNode {
+					item = mapGet(item, "job")
+				}
+				if item != nil {
+					needs = append(needs, jobID(item.Value))
+				}
+			}
+		} else {
+			needs = previousStageJobs(job.stage)
+		}
+		addNode(outJobs, jobID(job.name), m.convertGitLabJob(job.name, job.node, defaults, needs))
+	}
+
+	out := newMapping()
+	addNode(out, "name", stringNode("CI"))
+	on := newMapping()
+	addNode(on, "push", newMapping())
+	addNode(on, "pull_request", newMapping())
+	addNode(out, "on", on)
+	if variables := mapGet(root, "variables"); variables != nil {
+		addNode(out, "env", gitlabVariables(variables))
+	}
+	addNode(out, "jobs", outJobs)
+
+	yamlOut, err := encodeYAML(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{out}})
+	return yamlOut, m.findings, err
+}
+
+// jobID converts a GitLab job name to a valid GitHub Actions job id
+func jobID(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// gitlabVariables converts GitLab variables, which may be written as
+// mappings with a value and description, to plain env entries
+func gitlabVariables(variables *yaml.Node) *yaml.Node {
+	env := newMapping()
+	for _, entry := range mapEntries(variables) {
+		value := entry.value
+		if value.Kind == yaml.MappingNode {
+			value = mapGet(value, "value")
+			if value == nil {
+				value = stringNode("")
+			}
+		}
+		addNode(env, entry.key, value)
+	}
+	return env
+}
+
+// convertGitLabJob drafts a GitHub Actions job from a GitLab job
+func (m *migration) convertGitLabJob(name string, job, defaults *yaml.Node, needs []string) *yaml.Node {
+	out := newMapping()
+	if mapGet(job, "trigger") != nil {
+		m.flag(name, "trigger", "downstream pipelines have no direct equivalent; use workflow_call or repository_dispatch")
+	}
+	if mapGet(job, "extends") != nil {
+		m.flag(name, "extends", "inherited keys were not merged; copy them from the template")
+	}
+
+	if tags := scalarValues(mapGet(job, "tags")); len(tags) > 0 {
+		addNode(out, "runs-on", sequenceNode(append([]string{"self-hosted"}, tags...)...))
+		m.flag(name, "tags", "runner tags %s were mapped to self-hosted labels", strings.Join(tags, ", "))
+	} else {
+		addNode(out, "runs-on", stringNode("ubuntu-latest"))
+	}
+	if len(needs) > 0 {
+		addNode(out, "needs", sequenceNode(needs...))
+	}
+
+	image := mapGet(job, "image")
+	if image == nil {
+		image = mapGet(defaults, "image")
+	}
+	if image != nil {
+		if image.Kind == yaml.MappingNode {
+			image = mapGet(image, "name")
+		}
+		if image != nil {
+			addNode(out, "container", stringNode(image.Value))
+		}
+	}
+
+	services := mapGet(job, "services")
+	if services == nil {
+		services = mapGet(defaults, "services")
+	}
+	if services != nil {
+		servicesNode := newMapping()
+		for _, item := range services.Content {
+			if item.Kind == yaml.MappingNode {
+				item = mapGet(item, "name")
+			}
+			if item == nil {
+				continue
+			}
+			service := newMapping()
+			addNode(service, "image", stringNode(item.Value))
+			serviceName, _, _ := strings.Cut(filepath.Base(item.Value), ":")
+			addNode(servicesNode, serviceName, service)
+		}
+		addNode(out, "services", servicesNode)
+	}
+
+	if variables := mapGet(job, "variables"); variables != nil {
+		addNode(out, "env", gitlabVariables(variables))
+	}
+
+	if matrix := mapGet(mapGet(job, "parallel"), "matrix"); matrix != nil {
+		if len(matrix.Content) > 1 {
+			m.flag(name, "parallel.matrix", "only the first matrix entry was converted; add the rest with strategy.matrix.include")
+		}
+		if len(matrix.Content) > 0 {
+			strategy := newMapping()
+			addNode(strategy, "matrix", matrix.Content[0])
+			addNode(out, "strategy", strategy)
+			m.flag(name, "parallel.matrix", "matrix variables are no longer environment variables; reference them as ${{ matrix.* }}")
+		}
+	} else if parallel := mapGet(job, "parallel"); parallel != nil {
+		m.flag(name, "parallel", "parallel: %s has no direct equivalent; use a matrix", parallel.Value)
+	}
+
+	if allowFailure := mapGet(job, "allow_failure"); allowFailure != nil {
+		addNode(out, "continue-on-error", boolNode(allowFailure.Value == "true"))
+	}
+	if environment := mapGet(job, "environment"); environment != nil {
+		if environment.Kind == yaml.MappingNode {
+			environment = mapGet(environment, "name")
+		}
+		if environment != nil {
+			addNode(out, "environment", stringNode(environment.Value))
+		}
+	}
+	if timeout := mapGet(job, "timeout"); timeout != nil {
+		m.flag(name, "timeout", "set timeout-minutes for %q", timeout.Value)
+	}
+
+	for _, key := range []string{"rules", "only", "except"} {
+		if mapGet(job, key) != nil {
+			m.flag(name, key, "rewrite %s as an if: condition or workflow triggers", key)
+		}
+	}
+	condition := ""
+	if when := mapGet(job, "when"); when != nil {
+		switch when.Value {
+		case "manual":
+			m.flag(name, "when", "manual jobs have no direct equivalent; use an environment with required reviewers or workflow_dispatch")
+		case "always":
+			condition = "always()"
+		case "on_failure":
+			condition = "failure()"
+		case "delayed":
+			m.flag(name, "when", "delayed jobs have no direct equivalent")
+		}
+	}
+	if condition != "" {
+		addNode(out, "if", stringNode("${{ "+condition+" }}"))
+	}
+	if mapGet(job, "retry") != nil {
+		m.flag(name, "retry", "automatic retries have no direct equivalent")
+	}
+	if mapGet(job, "cache") != nil {
+		m.flag(name, "cache", "cache was not converted; add an actions/cache step")
+	}
+
+	steps := &yaml.Node{Kind: yaml.SequenceNode}
+	addStep := func(fields ...*yaml.Node) {
+		step := newMapping()
+		step.Content = fields
+		steps.Content = append(steps.Content, step)
+	}
+	addStep(stringNode("uses"), stringNode("actions/checkout@v4"))
+
+	commands := func(key string) string {
+		lines := scalarValues(mapGet(job, key))
+		if lines == nil {
+			lines = scalarValues(mapGet(defaults, key))
+		}
+		return strings.Join(lines, "\n")
+	}
+	if script := commands("before_script"); script != "" {
+		addStep(stringNode("name"), stringNode("before_script"), stringNode("run"), stringNode(script))
+	}
+	if script := commands("script"); script != "" {
+		addStep(stringNode("run"), stringNode(script))
+	}
+	if script := commands("after_script"); script != "" {
+		addStep(stringNode("name"), stringNode("after_script"), stringNode("if"), stringNode("${{ always() }}"), stringNode("run"), stringNode(script))
+	}
+
+	if artifacts := mapGet(job, "artifacts"); artifacts != nil {
+		if paths := scalarValues(mapGet(artifacts, "paths")); len(paths) > 0 {
+			with := newMapping()
+			addNode(with, "name", stringNode(jobID(name)))
+			addNode(with, "path", stringNode(strings.Join(paths, "\n")))
+			addStep(stringNode("uses"), stringNode("actions/upload-artifact@v4"), stringNode("with"), with)
+		}
+		if mapGet(artifacts, "reports") != nil {
+			m.flag(name, "artifacts.reports", "reports have no direct equivalent; dotenv reports become job outputs")
+		}
+		if len(needs) > 0 {
+			m.flag(name, "artifacts", "later jobs must download artifacts with actions/download-artifact")
+		}
+	}
+	addNode(out, "steps", steps)
+
+	return out
+}
+
+// loadMigrationSource fetches the CI files of a project at ref
+func loadMigrationSource(ctx context.Context, project Project, ref string) ([]WorkflowFile, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetWorkflowFiles(project.Owner, project.Repo, ref)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := client.GetCIConfigFile(project.Name, ref)
+		if err != nil {
+			return nil, err
+		}
+		return []WorkflowFile{*file}, nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// handleMigrate handles the migrate command
+func handleMigrate(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 || args[0] != "analyze" {
+		fmt.Printf("%s Usage: quick_workflow migrate analyze [options] <project>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	fs := flag.NewFlagSet("migrate analyze", flag.ExitOnError)
+	ref := fs.String("ref", "", "Branch, tag, or commit to read CI files from (default: default branch)")
+	workflow := fs.String("workflow", "", "Only convert this GitHub workflow file")
+	file := fs.String("file", "", "Convert a local CI file instead of fetching one")
+	from := fs.String("from", "", "Platform of --file: github or gitlab (default: guessed from the file name)")
+	out := fs.String("out", "", "Write the draft to a file instead of stdout")
+	fs.Parse(args[1:])
+
+	var platform string
+	var files []WorkflowFile
+	switch {
+	case *file != "":
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		platform = *from
+		if platform == "" {
+			platform = "github"
+			if strings.HasSuffix(*file, ".gitlab-ci.yml") {
+				platform = "gitlab"
+			}
+		}
+		files = []WorkflowFile{{Path: *file, Content: string(data)}}
+	case fs.NArg() > 0:
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		platform = project.Platform
+
+		var err error
+		files, err = loadMigrationSource(ctx, *project, *ref)
+		if err != nil {
+			fmt.Printf("%s Failed to fetch CI configuration: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	default:
+		fmt.Printf("%s Usage: quick_workflow migrate analyze [options] <project>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	if *workflow != "" {
+		var selected []WorkflowFile
+		for _, f := range files {
+			if f.Path == *workflow || filepath.Base(f.Path) == *workflow {
+				selected = append(selected, f)
+			}
+		}
+		files = selected
+	}
+	if len(files) == 0 {
+		fmt.Printf("%s No CI files found to convert\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	var draft, target string
+	var findings []migrationFinding
+	var err error
+	switch platform {
+	case "github":
+		target = ".gitlab-ci.yml"
+		draft, findings, err = convertGitHubToGitLab(files)
+	case "gitlab":
+		target = ".github/workflows/ci.yml"
+		draft, findings, err = convertGitLabToGitHub(files[0])
+	default:
+		err = fmt.Errorf("unsupported platform: %s", platform)
+	}
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	if *out != "" {
+		if err := os.WriteFile(*out, []byte(draft), 0644); err != nil {
+			fmt.Printf("%s Failed to write draft: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Wrote draft %s to %s\n", qc.Colorize("Success:", qc.ColorGreen), target, *out)
+	} else {
+		fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("# Draft %s", target), qc.ColorBlue))
+		fmt.Print(draft)
+	}
+
+	fmt.Println()
+	if len(findings) == 0 {
+		fmt.Printf("%s No constructs need manual review\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Job < findings[j].Job
+	})
+	fmt.Printf("%s %d constructs need manual review:\n", qc.Colorize("Warning:", qc.ColorYellow), len(findings))
+	for _, finding := range findings {
+		fmt.Printf("  %s %s: %s\n",
+			qc.Colorize(finding.Job, qc.ColorCyan),
+			qc.Colorize(finding.Construct, qc.ColorYellow),
+			finding.Message)
+	}
+}
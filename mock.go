@@ -0,0 +1,669 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mockFixturesEnv names the fixture file the mock provider serves, for
+// end-to-end tests against canned runs
+const mockFixturesEnv = "QUICK_WORKFLOW_MOCK_FIXTURES"
+
+// mockFixturesFile is the fixture file of the mock provider. It defaults to
+// mock.json next to the state file and is created from the demo fixtures
+// when missing.
+var mockFixturesFile string
+
+// mockFixtures are the canned projects the mock provider serves, by project
+// name
+type mockFixtures struct {
+	Projects map[string]*mockProject `json:"projects"`
+}
+
+// mockProject is the canned CI history of one project
+type mockProject struct {
+	DefaultBranch string    `json:"default_branch,omitempty"`
+	Workflows     []string  `json:"workflows"`
+	Runs          []mockRun `json:"runs"`
+	// DisabledWorkflows are the workflows turned off with workflows disable
+	DisabledWorkflows []string `json:"disabled_workflows,omitempty"`
+}
+
+// mockRun is a canned run. Its status isn't stored: the jobs run one after
+// another from CreatedAt for their durations, so a run that is in progress
+// when the fixtures are written finishes on its own while being watched.
+type mockRun struct {
+	ID          string     `json:"id"`
+	Workflow    string     `json:"workflow"`
+	Branch      string     `json:"branch"`
+	Commit      string     `json:"commit"`
+	Message     string     `json:"message,omitempty"`
+	PullRequest int        `json:"pull_request,omitempty"`
+	TriggeredBy string     `json:"triggered_by"`
+	Attempt     int        `json:"attempt,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	Jobs        []mockJob  `json:"jobs"`
+}
+
+// mockJob is a canned job. Result is the conclusion it ends with, success
+// unless set; a failure skips the jobs after it.
+type mockJob struct {
+	Name     string   `json:"name"`
+	Duration int      `json:"duration"` // seconds
+	Result   string   `json:"result,omitempty"`
+	Steps    []string `json:"steps,omitempty"`
+	// Log replaces the generated log of the job when set
+	Log string `json:"log,omitempty"`
+}
+
+// MockClient serves runs and jobs from fixtures instead of a CI server
+type MockClient struct {
+	path string
+}
+
+// NewMockClient creates a client for the mock fixture file
+func NewMockClient() (*MockClient, error) {
+	path := mockFixturesFile
+	if env := os.Getenv(mockFixturesEnv); env != "" {
+		path = env
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no mock fixtures configured (set %s)", mockFixturesEnv)
+	}
+	return &MockClient{path: path}, nil
+}
+
+// load reads the fixtures, writing the demo fixtures first when the file
+// doesn't exist yet
+func (m *MockClient) load() (*mockFixtures, error) {
+	data, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		fixtures := demoFixtures(time.Now())
+		return fixtures, m.save(fixtures)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fixtures mockFixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid mock fixtures %s: %w", m.path, err)
+	}
+	return &fixtures, nil
+}
+
+// save writes the fixtures back
+func (m *MockClient) save(fixtures *mockFixtures) error {
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(m.path, data, 0644)
+}
+
+// update locks the fixture file, applies fn to the fixtures, and saves them
+func (m *MockClient) update(fn func(fixtures *mockFixtures) error) error {
+	lock, err := acquireLock(m.path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	fixtures, err := m.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(fixtures); err != nil {
+		return err
+	}
+	return m.save(fixtures)
+}
+
+// project returns the fixtures of a project
+func (f *mockFixtures) project(name string) (*mockProject, error) {
+	project, ok := f.Projects[name]
+	if !ok {
+		return nil, fmt.Errorf("no mock fixtures for project %s", name)
+	}
+	return project, nil
+}
+
+// run returns a run of a project's fixtures
+func (p *mockProject) run(runID string) (*mockRun, error) {
+	for i := range p.Runs {
+		if p.Runs[i].ID == runID {
+			return &p.Runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("run not found: %s", runID)
+}
+
+// mockURL returns the made-up web URL of a run, or of a job when jobID is set
+func mockURL(project, runID, jobID string) string {
+	url := "https://ci.example.com/" + project + "/runs/" + runID
+	if jobID != "" {
+		url += "/jobs/" + jobID
+	}
+	return url
+}
+
+// jobs works out the state of a run's jobs at a time: each starts when the
+// one before it ends, and a failure skips the rest
+func (r mockRun) jobs(project string, at time.Time) []Job {
+	if r.CancelledAt != nil && r.CancelledAt.Before(at) {
+		at = *r.CancelledAt
+	}
+
+	var jobs []Job
+	start := r.CreatedAt
+	failed := false
+	for i, fixture := range r.Jobs {
+		started := start
+		end := started.Add(time.Duration(fixture.Duration) * time.Second)
+		job := Job{
+			ID:        strconv.Itoa(i + 1),
+			RunID:     r.ID,
+			Name:      fixture.Name,
+			CreatedAt: &r.CreatedAt,
+			URL:       mockURL(project, r.ID, strconv.Itoa(i+1)),
+		}
+		switch {
+		case failed:
+			job.Status, job.Conclusion = "completed", "skipped"
+		case at.Before(started):
+			job.Status = "queued"
+		case at.Before(end):
+			job.Status = "in_progress"
+			job.StartedAt = &started
+		default:
+			job.Status, job.Conclusion = "completed", fixture.result()
+			job.StartedAt = &started
+			job.CompletedAt = &end
+			failed = job.Conclusion == "failure"
+		}
+		if r.CancelledAt != nil && job.Status != "completed" {
+			job.Status, job.Conclusion = "completed", "cancelled"
+			job.CompletedAt = r.CancelledAt
+		}
+		job.Steps = fixture.steps(job, started, end, at)
+		jobs = append(jobs, job)
+		start = end
+	}
+	return jobs
+}
+
+// result returns the conclusion a job fixture ends with
+func (j mockJob) result() string {
+	if j.Result == "" {
+		return "success"
+	}
+	return j.Result
+}
+
+// steps splits a job's time evenly over its steps; a failed job fails in
+// its last step
+func (j mockJob) steps(job Job, started, end, at time.Time) []Step {
+	if len(j.Steps) == 0 {
+		return nil
+	}
+	slice := end.Sub(started) / time.Duration(len(j.Steps))
+	steps := make([]Step, len(j.Steps))
+	for i, name := range j.Steps {
+		stepStart := started.Add(slice * time.Duration(i))
+		stepEnd := stepStart.Add(slice)
+		step := Step{Name: name, Status: "completed"}
+		switch {
+		case job.StartedAt == nil || at.Before(stepStart):
+			step.Status = "queued"
+			if job.Status == "completed" {
+				step.Status, step.Conclusion = "completed", job.Conclusion
+			}
+		case at.Before(stepEnd):
+			step.Status = "in_progress"
+			step.StartedAt = &stepStart
+			if job.Status == "completed" {
+				step.Status, step.Conclusion = "completed", job.Conclusion
+				step.CompletedAt = job.CompletedAt
+			}
+		default:
+			step.Conclusion = "success"
+			if i == len(j.Steps)-1 && job.Conclusion == "failure" {
+				step.Conclusion = "failure"
+			}
+			step.StartedAt = &stepStart
+			step.CompletedAt = &stepEnd
+		}
+		steps[i] = step
+	}
+	return steps
+}
+
+// workflowRun works out the state of a run at a time from its jobs
+func (r mockRun) workflowRun(project string, at time.Time) WorkflowRun {
+	run := WorkflowRun{
+		ID:            r.ID,
+		Project:       project,
+		Workflow:      r.Workflow,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.CreatedAt,
+		URL:           mockURL(project, r.ID, ""),
+		Platform:      "mock",
+		Branch:        r.Branch,
+		Commit:        r.Commit,
+		CommitMessage: r.Message,
+		PullRequest:   r.PullRequest,
+		TriggeredBy:   r.TriggeredBy,
+		Attempt:       r.Attempt,
+		StartedAt:     r.CreatedAt,
+		Status:        "completed",
+		Conclusion:    "success",
+	}
+
+	started := false
+	for _, job := range r.jobs(project, at) {
+		if job.StartedAt != nil {
+			started = true
+		}
+		if job.CompletedAt != nil && job.CompletedAt.After(run.UpdatedAt) {
+			run.UpdatedAt = *job.CompletedAt
+		}
+		switch {
+		case job.Status != "completed":
+			run.Status, run.Conclusion = "queued", ""
+		case run.Status != "completed":
+		case job.Conclusion == "failure":
+			run.Conclusion = "failure"
+		case job.Conclusion == "cancelled" && run.Conclusion != "failure":
+			run.Conclusion = "cancelled"
+		}
+	}
+	if run.Status == "queued" && started {
+		run.Status = "in_progress"
+	}
+	return run
+}
+
+// GetRuns retrieves a project's runs, newest first, optionally on one branch
+func (m *MockClient) GetRuns(project, branch string, limit int) ([]WorkflowRun, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var runs []WorkflowRun
+	for _, fixture := range p.Runs {
+		if branch != "" && fixture.Branch != branch {
+			continue
+		}
+		runs = append(runs, fixture.workflowRun(project, now))
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// GetRun retrieves a single run
+func (m *MockClient) GetRun(project, runID string) (*WorkflowRun, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return nil, err
+	}
+	fixture, err := p.run(runID)
+	if err != nil {
+		return nil, err
+	}
+	run := fixture.workflowRun(project, time.Now())
+	return &run, nil
+}
+
+// GetRunJobs retrieves the jobs of a run
+func (m *MockClient) GetRunJobs(project, runID string) ([]Job, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return nil, err
+	}
+	fixture, err := p.run(runID)
+	if err != nil {
+		return nil, err
+	}
+	return fixture.jobs(project, time.Now()), nil
+}
+
+// GetWorkflows lists the workflows of a project
+func (m *MockClient) GetWorkflows(project string) ([]string, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return nil, err
+	}
+	return p.Workflows, nil
+}
+
+// GetWorkflowStates lists the workflows of a project and whether each is
+// enabled
+func (m *MockClient) GetWorkflowStates(project string) ([]WorkflowState, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]WorkflowState, len(p.Workflows))
+	for i, name := range p.Workflows {
+		states[i] = WorkflowState{Name: name, State: "active"}
+		if slices.Contains(p.DisabledWorkflows, name) {
+			states[i].State = "disabled_manually"
+		}
+	}
+	return states, nil
+}
+
+// SetWorkflowEnabled enables or disables a workflow of a project
+func (m *MockClient) SetWorkflowEnabled(project, workflow string, enabled bool) error {
+	return m.update(func(fixtures *mockFixtures) error {
+		p, err := fixtures.project(project)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(p.Workflows, workflow) {
+			return fmt.Errorf("workflow not found: %s", workflow)
+		}
+		var disabled []string
+		for _, name := range p.DisabledWorkflows {
+			if name != workflow {
+				disabled = append(disabled, name)
+			}
+		}
+		if !enabled {
+			disabled = append(disabled, workflow)
+		}
+		p.DisabledWorkflows = disabled
+		return nil
+	})
+}
+
+// GetDefaultBranch returns the default branch of a project, main unless
+// the fixtures say otherwise
+func (m *MockClient) GetDefaultBranch(project string) (string, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return "", err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return "", err
+	}
+	return firstNonEmpty(p.DefaultBranch, "main"), nil
+}
+
+// GetJobLog returns the fixture log of a job, or one made up from its steps
+// with GitHub-style timestamps
+func (m *MockClient) GetJobLog(project, runID, jobID string) (string, error) {
+	fixtures, err := m.load()
+	if err != nil {
+		return "", err
+	}
+	p, err := fixtures.project(project)
+	if err != nil {
+		return "", err
+	}
+	fixture, err := p.run(runID)
+	if err != nil {
+		return "", err
+	}
+	index, err := strconv.Atoi(jobID)
+	if err != nil || index < 1 || index > len(fixture.Jobs) {
+		return "", fmt.Errorf("job not found: %s", jobID)
+	}
+	if log := fixture.Jobs[index-1].Log; log != "" {
+		return log, nil
+	}
+
+	job := fixture.jobs(project, time.Now())[index-1]
+	var b strings.Builder
+	for _, step := range job.Steps {
+		if step.StartedAt == nil {
+			continue
+		}
+		timestamp := step.StartedAt.UTC().Format(time.RFC3339Nano)
+		fmt.Fprintf(&b, "%s ##[group]Run %s\n", timestamp, step.Name)
+		fmt.Fprintf(&b, "%s %s\n", timestamp, step.Name)
+		fmt.Fprintf(&b, "%s ##[endgroup]\n", timestamp)
+		if step.Conclusion == "failure" {
+			fmt.Fprintf(&b, "%s ##[error]Process completed with exit code 1.\n", step.CompletedAt.UTC().Format(time.RFC3339Nano))
+		}
+	}
+	return b.String(), nil
+}
+
+// TriggerRun starts a new run of a workflow on the project's default
+// branch, with the jobs of the workflow's latest run
+func (m *MockClient) TriggerRun(project, workflow string) (*WorkflowRun, error) {
+	var run WorkflowRun
+	err := m.update(func(fixtures *mockFixtures) error {
+		p, err := fixtures.project(project)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(p.Workflows, workflow) {
+			return fmt.Errorf("workflow not found: %s", workflow)
+		}
+
+		branch := p.DefaultBranch
+		if branch == "" {
+			branch = "main"
+		}
+		fixture := mockRun{
+			ID:          strconv.Itoa(nextMockRunID(p)),
+			Workflow:    workflow,
+			Branch:      branch,
+			TriggeredBy: "you",
+			Attempt:     1,
+			CreatedAt:   time.Now().UTC(),
+			Jobs:        []mockJob{{Name: "build", Duration: 30, Steps: []string{"Checkout", "Build"}}},
+		}
+		var latest *mockRun
+		for i := range p.Runs {
+			if p.Runs[i].Workflow == workflow && (latest == nil || p.Runs[i].CreatedAt.After(latest.CreatedAt)) {
+				latest = &p.Runs[i]
+			}
+		}
+		if latest != nil {
+			fixture.Commit = latest.Commit
+			fixture.Jobs = append([]mockJob(nil), latest.Jobs...)
+		}
+		p.Runs = append(p.Runs, fixture)
+		run = fixture.workflowRun(project, time.Now())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// nextMockRunID returns an ID above every run of a project
+func nextMockRunID(p *mockProject) int {
+	next := 1
+	for _, run := range p.Runs {
+		if id, err := strconv.Atoi(run.ID); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+// CancelRun stops an unfinished run
+func (m *MockClient) CancelRun(project, runID string) error {
+	return m.update(func(fixtures *mockFixtures) error {
+		p, err := fixtures.project(project)
+		if err != nil {
+			return err
+		}
+		fixture, err := p.run(runID)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if isRunFinished(fixture.workflowRun(project, now).Status) {
+			return fmt.Errorf("run %s has already finished", runID)
+		}
+		fixture.CancelledAt = &now
+		return nil
+	})
+}
+
+// RerunRun starts a finished run again as its next attempt. With failedOnly
+// the jobs that passed are reused and finish at once.
+func (m *MockClient) RerunRun(project, runID string, failedOnly bool) error {
+	return m.update(func(fixtures *mockFixtures) error {
+		p, err := fixtures.project(project)
+		if err != nil {
+			return err
+		}
+		fixture, err := p.run(runID)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if !isRunFinished(fixture.workflowRun(project, now).Status) {
+			return fmt.Errorf("run %s is still running", runID)
+		}
+
+		if failedOnly {
+			for i, job := range fixture.jobs(project, now) {
+				if job.Conclusion == "success" {
+					fixture.Jobs[i].Duration = 0
+				}
+			}
+		}
+		fixture.Attempt = max(fixture.Attempt, 1) + 1
+		fixture.CreatedAt = now
+		fixture.CancelledAt = nil
+		return nil
+	})
+}
+
+// demoRefreshAfter is how long demo fixtures go unchanged before they are
+// written afresh, so the demo always has runs in progress
+const demoRefreshAfter = time.Hour
+
+// setupDemo tracks the demo projects served by the mock provider on first
+// use and refreshes stale demo fixtures
+func setupDemo(config *Config) error {
+	if info, err := os.Stat(mockFixturesFile); err == nil && time.Since(info.ModTime()) > demoRefreshAfter {
+		client := &MockClient{path: mockFixturesFile}
+		if err := client.save(demoFixtures(time.Now())); err != nil {
+			return err
+		}
+	}
+	if len(config.Projects) > 0 {
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(demoFixtures(time.Now()).Projects))
+
+	return updateProjects(config, func(config *Config) error {
+		for _, name := range names {
+			owner, repo, _ := strings.Cut(name, "/")
+			config.Projects = append(config.Projects, Project{
+				Name:      name,
+				Owner:     owner,
+				Repo:      repo,
+				Platform:  "mock",
+				RemoteURL: "https://ci.example.com/" + name + ".git",
+				AddedAt:   time.Now().Format(time.RFC3339),
+			})
+		}
+		return nil
+	})
+}
+
+// demoFixtures are the runs of the demo projects, placed relative to now so
+// that some are still running
+func demoFixtures(now time.Time) *mockFixtures {
+	now = now.UTC().Truncate(time.Second)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	ci := []mockJob{
+		{Name: "lint", Duration: 45, Steps: []string{"Checkout", "Set up Go", "golangci-lint"}},
+		{Name: "test", Duration: 180, Steps: []string{"Checkout", "Set up Go", "go test ./..."}},
+		{Name: "build", Duration: 90, Steps: []string{"Checkout", "Set up Go", "go build", "Upload artifact"}},
+	}
+	failingCI := append([]mockJob(nil), ci...)
+	failingCI[1].Result = "failure"
+	deploy := []mockJob{
+		{Name: "build image", Duration: 120, Steps: []string{"Checkout", "docker build", "docker push"}},
+		{Name: "deploy staging", Duration: 240, Steps: []string{"helm upgrade", "Smoke tests"}},
+	}
+	web := []mockJob{
+		{Name: "install", Duration: 60, Steps: []string{"Checkout", "npm ci"}},
+		{Name: "test", Duration: 150, Steps: []string{"npm test"}},
+		{Name: "e2e", Duration: 420, Steps: []string{"Start server", "playwright test"}},
+	}
+	terraform := []mockJob{
+		{Name: "fmt", Duration: 15, Steps: []string{"terraform fmt -check"}},
+		{Name: "plan", Duration: 200, Steps: []string{"terraform init", "terraform plan"}},
+	}
+
+	return &mockFixtures{Projects: map[string]*mockProject{
+		"acme/api": {
+			DefaultBranch: "main",
+			Workflows:     []string{"CI", "Deploy"},
+			Runs: []mockRun{
+				{ID: "1001", Workflow: "CI", Branch: "main", Commit: "4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39", Message: "Release 1.4.0", TriggeredBy: "alice", Attempt: 1, CreatedAt: ago(26 * time.Hour), Jobs: ci},
+				{ID: "1002", Workflow: "Deploy", Branch: "main", Commit: "4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39", Message: "Release 1.4.0", TriggeredBy: "alice", Attempt: 1, CreatedAt: ago(25 * time.Hour), Jobs: deploy},
+				{ID: "1003", Workflow: "CI", Branch: "fix-timeouts", Commit: "9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c", Message: "Raise upstream timeouts to 30s", PullRequest: 42, TriggeredBy: "bob", Attempt: 1, CreatedAt: ago(3 * time.Hour), Jobs: failingCI},
+				{ID: "1004", Workflow: "CI", Branch: "main", Commit: "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b", Message: "Add request ID to access logs", TriggeredBy: "carol", Attempt: 1, CreatedAt: ago(2 * time.Minute), Jobs: ci},
+			},
+		},
+		"acme/web": {
+			DefaultBranch: "main",
+			Workflows:     []string{"Test"},
+			Runs: []mockRun{
+				{ID: "2001", Workflow: "Test", Branch: "main", Commit: "c0ffee1234567890abcdef1234567890abcdef12", Message: "Update dependencies", TriggeredBy: "dave", Attempt: 1, CreatedAt: ago(5 * time.Hour), Jobs: web},
+				{ID: "2002", Workflow: "Test", Branch: "redesign", Commit: "deadbeef4567890abcdef1234567890abcdef123", Message: "Redesign landing page", PullRequest: 17, TriggeredBy: "erin", Attempt: 1, CreatedAt: ago(30 * time.Second), Jobs: web},
+			},
+		},
+		"acme/infra": {
+			DefaultBranch: "main",
+			Workflows:     []string{"Plan"},
+			Runs: []mockRun{
+				{ID: "3001", Workflow: "Plan", Branch: "main", Commit: "0badc0de567890abcdef1234567890abcdef1234", Message: "Scale workers to 3 replicas", TriggeredBy: "frank", Attempt: 1, CreatedAt: ago(50 * time.Minute), Jobs: terraform},
+			},
+		},
+	}}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout    = 30 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+)
+
+// networkOptions configures the HTTP clients used for provider APIs. Zero
+// values leave the setting or default in place.
+type networkOptions struct {
+	Timeout        time.Duration
+	ConnectTimeout time.Duration
+	// Proxy is used for every request instead of HTTPS_PROXY and HTTP_PROXY
+	Proxy string
+	// CABundle is a PEM file of certificates trusted in addition to the
+	// system roots, e.g. a corporate TLS interception CA
+	CABundle           string
+	InsecureSkipVerify bool
+}
+
+// Network options resolved from settings and flags at startup
+var (
+	httpTimeout    = defaultHTTPTimeout
+	connectTimeout = defaultConnectTimeout
+	proxyURL       *url.URL
+	tlsConfig      *tls.Config
+	// networkFlags keeps the network flags for settings reapplied after
+	// switching workspaces
+	networkFlags networkOptions
+)
+
+// applyNetworkSettings resolves the HTTP client options from settings, with
+// the network flags taking precedence
+func applyNetworkSettings(settings Settings) error {
+	options := networkOptions{
+		Proxy:              settings.Proxy,
+		CABundle:           settings.CABundle,
+		InsecureSkipVerify: settings.InsecureSkipVerify || networkFlags.InsecureSkipVerify,
+	}
+	var err error
+	if options.Timeout, err = parseTimeoutSetting("http_timeout", settings.HTTPTimeout); err != nil {
+		return err
+	}
+	if options.ConnectTimeout, err = parseTimeoutSetting("connect_timeout", settings.ConnectTimeout); err != nil {
+		return err
+	}
+	if networkFlags.Timeout != 0 {
+		options.Timeout = networkFlags.Timeout
+	}
+	if networkFlags.ConnectTimeout != 0 {
+		options.ConnectTimeout = networkFlags.ConnectTimeout
+	}
+	if networkFlags.Proxy != "" {
+		options.Proxy = networkFlags.Proxy
+	}
+	if networkFlags.CABundle != "" {
+		options.CABundle = networkFlags.CABundle
+	}
+
+	httpTimeout = defaultHTTPTimeout
+	if options.Timeout > 0 {
+		httpTimeout = options.Timeout
+	}
+	connectTimeout = defaultConnectTimeout
+	if options.ConnectTimeout > 0 {
+		connectTimeout = options.ConnectTimeout
+	}
+
+	proxyURL = nil
+	if options.Proxy != "" {
+		if proxyURL, err = parseProxyURL(options.Proxy); err != nil {
+			return err
+		}
+	}
+
+	tlsConfig = nil
+	if options.CABundle != "" || options.InsecureSkipVerify {
+		tlsConfig = &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify}
+		if options.CABundle != "" {
+			if tlsConfig.RootCAs, err = loadCABundle(options.CABundle); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseTimeoutSetting parses a stored timeout, empty for the default
+func parseTimeoutSetting(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration like 30s or 2m, got %q", key, value)
+	}
+	return d, nil
+}
+
+// parseProxyURL validates a proxy URL; a bare host:port means an HTTP proxy
+func parseProxyURL(value string) (*url.URL, error) {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		u, err = url.Parse("http://" + value)
+	}
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: %s", value)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https, or socks5)", u.Scheme)
+	}
+	return u, nil
+}
+
+// loadCABundle returns the system roots plus the certificates in a PEM file
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// newHTTPClient returns an HTTP client with the configured timeouts, proxy,
+// and TLS options. Without a configured proxy, HTTPS_PROXY, HTTP_PROXY, and
+// NO_PROXY apply.
+func newHTTPClient() *http.Client {
+	if offlineConfig != nil {
+		return &http.Client{Transport: offlineTransport{}}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
+	}
+	if apiLog.Enabled(context.Background(), slog.LevelInfo) {
+		return &http.Client{Timeout: httpTimeout, Transport: &loggingTransport{base: transport}}
+	}
+	return &http.Client{Timeout: httpTimeout, Transport: transport}
+}
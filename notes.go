@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// RunNote is a triage note attached to a workflow run
+type RunNote struct {
+	Project   string `json:"project" yaml:"project"`
+	RunID     string `json:"run_id" yaml:"run_id"`
+	Text      string `json:"text" yaml:"text"`
+	CreatedAt string `json:"created_at" yaml:"created_at"`
+	PostedTo  string `json:"posted_to,omitempty" yaml:"posted_to,omitempty"`
+}
+
+// handleNote adds a note to a run, or lists a run's notes when no text is given
+func handleNote(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("note", flag.ExitOnError)
+	localOnly := fs.Bool("local", false, "Keep the note locally without posting it to the provider")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Printf("%s Usage: quick_workflow note [--local] <project> <run-id> [text]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	project := findProject(config, fs.Arg(0))
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+		return
+	}
+	runID := fs.Arg(1)
+	text := strings.TrimSpace(strings.Join(fs.Args()[2:], " "))
+
+	if text == "" {
+		displayRunNotes(runNotes(config, project.Name, runID))
+		return
+	}
+
+	note := RunNote{
+		Project:   project.Name,
+		RunID:     runID,
+		Text:      text,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+
+	if !*localOnly {
+		postedTo, err := postRunNote(ctx, *project, runID, text)
+		if err != nil {
+			fmt.Printf("%s Failed to post note to %s: %v (saved locally)\n", qc.Colorize("Warning:", qc.ColorYellow), project.Platform, describeError(err))
+		} else {
+			note.PostedTo = postedTo
+		}
+	}
+
+	err := updateProjects(config, func(config *Config) error {
+		config.Notes = append(config.Notes, note)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s Failed to save note: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	fmt.Printf("%s Added note to run %s of %s\n", qc.Colorize("Success:", qc.ColorGreen), runID, project.Name)
+	if note.PostedTo != "" {
+		fmt.Printf("Posted: %s\n", note.PostedTo)
+	}
+}
+
+// postRunNote posts a note to the provider's PR/MR or commit for the run
+func postRunNote(ctx context.Context, project Project, runID, text string) (string, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return "", err
+		}
+		return client.CommentOnRun(project.Owner, project.Repo, runID, text)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return "", err
+		}
+		return client.CommentOnPipeline(project.Name, runID, text)
+	default:
+		return "", fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// runNotes returns the notes recorded for a run, oldest first
+func runNotes(config *Config, project, runID string) []RunNote {
+	var notes []RunNote
+	for _, note := range config.Notes {
+		if note.Project == project && note.RunID == runID {
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
+
+// displayRunNotes prints a list of run notes
+func displayRunNotes(notes []RunNote) {
+	if len(notes) == 0 {
+		fmt.Printf("%s No notes for this run\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	fmt.Printf("%s\n", qc.Colorize("Notes:", qc.ColorBlue))
+	for _, note := range notes {
+		createdAt := note.CreatedAt
+		if t, err := time.Parse(time.RFC3339, note.CreatedAt); err == nil {
+			createdAt = t.In(displayLocation).Format("2006-01-02 15:04")
+		}
+		fmt.Printf("  %s %s\n", qc.Colorize(createdAt, qc.ColorCyan), note.Text)
+		if note.PostedTo != "" {
+			fmt.Printf("    %s\n", qc.Colorize("posted: "+note.PostedTo, qc.ColorWhite))
+		}
+	}
+}
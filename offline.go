@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// offlineConfig is the configuration whose local history and run cache
+// stand in for the providers with --offline; nil when online
+var offlineConfig *Config
+
+// offlineTransport fails every request, so nothing reaches the network
+// with --offline, even where local data can't stand in
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errOffline(platformForHost(req.URL.String()))
+}
+
+// errOffline is the error for anything that needs the network with
+// --offline
+func errOffline(platform string) error {
+	return &ProviderError{
+		Platform: platform,
+		Kind:     ErrOffline,
+		Message:  "not available offline",
+		Hint:     "run without --offline to fetch it",
+	}
+}
+
+// localRuns returns a project's runs on branch, or on every branch when
+// empty, from the local history, newest first, with the latest state
+// recorded of each. StaleAt marks when that state was last confirmed: the
+// project's last fetch, or when the state was recorded if that is later.
+// Projects never fetched fall back to the run cache, and fail when it has
+// nothing either.
+func localRuns(config *Config, project Project, branch string, limit int) ([]WorkflowRun, error) {
+	records, err := queryHistory(config, HistoryFilter{Platform: project.Platform, Project: project.Name})
+	if err != nil {
+		return nil, err
+	}
+	cached, fetched := readRunCache(config)[project.Name]
+
+	// Records are oldest first, so later observations replace earlier ones
+	index := make(map[string]int)
+	var runs []WorkflowRun
+	for _, record := range records {
+		run := record.WorkflowRun
+		run.StaleAt = record.ObservedAt
+		if i, ok := index[run.ID]; ok {
+			runs[i] = run
+			continue
+		}
+		index[run.ID] = len(runs)
+		runs = append(runs, run)
+	}
+	if len(runs) == 0 {
+		if !fetched || cached.FetchedAt.IsZero() {
+			return nil, fmt.Errorf("no runs of %s recorded yet; list it once online", project.Name)
+		}
+		runs = append(runs, cached.Runs...)
+	}
+
+	var matching []WorkflowRun
+	for _, run := range runs {
+		if branch != "" && run.Branch != branch {
+			continue
+		}
+		if cached.FetchedAt.After(run.StaleAt) {
+			run.StaleAt = cached.FetchedAt
+		}
+		matching = append(matching, run)
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+	if limit > 0 && len(matching) > limit {
+		matching = matching[:limit]
+	}
+	return matching, nil
+}
+
+// lastFetched returns when runs of any project were last fetched, or the
+// zero time if never
+func lastFetched(config *Config) time.Time {
+	var latest time.Time
+	for _, cached := range readRunCache(config) {
+		if cached.FetchedAt.After(latest) {
+			latest = cached.FetchedAt
+		}
+	}
+	return latest
+}
+
+// offlineNotice describes where offline data comes from and how old it is
+func offlineNotice(config *Config) string {
+	at := lastFetched(config)
+	if at.IsZero() {
+		return "Offline: nothing has been fetched yet, so only recorded history is shown"
+	}
+	return fmt.Sprintf("Offline: showing runs recorded locally, last fetched %s (%s)", formatAgo(time.Since(at)), formatAbsoluteTime(at))
+}
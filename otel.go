@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// defaultOTLPEndpoint is where a local OpenTelemetry collector receives
+// OTLP over HTTP
+const defaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// OTLP span kinds and status codes, as numbered in the protocol
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusOK         = 1
+	otlpStatusError      = 2
+)
+
+// otlpTraces is an OTLP/HTTP JSON export request for traces. Only the
+// fields filled in here are declared.
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID      string          `json:"traceId"`
+	SpanID       string          `json:"spanId"`
+	ParentSpanID string          `json:"parentSpanId,omitempty"`
+	Name         string          `json:"name"`
+	Kind         int             `json:"kind"`
+	Start        string          `json:"startTimeUnixNano"`
+	End          string          `json:"endTimeUnixNano"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+	Status       *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	// IntValue is a decimal string, as OTLP JSON encodes 64-bit integers
+	IntValue *string `json:"intValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpString returns a string attribute, or false when value is empty
+func otlpString(key, value string) (otlpAttribute, bool) {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}, value != ""
+}
+
+// otlpAttributes builds attributes from key, value pairs, leaving out
+// empty values
+func otlpAttributes(pairs ...string) []otlpAttribute {
+	var attributes []otlpAttribute
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if attribute, ok := otlpString(pairs[i], pairs[i+1]); ok {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// otlpInt returns an integer attribute
+func otlpInt(key string, value int) otlpAttribute {
+	text := strconv.Itoa(value)
+	return otlpAttribute{Key: key, Value: otlpValue{IntValue: &text}}
+}
+
+// otlpTime formats a time as OTLP's unix nanoseconds string
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// traceIDs derives stable trace and span IDs from a run, so exporting a run
+// twice produces the same trace instead of a duplicate
+type traceIDs struct {
+	run string
+}
+
+func newTraceIDs(run WorkflowRun) traceIDs {
+	return traceIDs{run: fmt.Sprintf("%s:%d", historyKey(run), run.Attempt)}
+}
+
+// trace returns the 16 byte trace ID of the run
+func (t traceIDs) trace() string {
+	sum := sha256.Sum256([]byte(t.run))
+	return hex.EncodeToString(sum[:16])
+}
+
+// span returns the 8 byte ID of a span within the run, by path
+func (t traceIDs) span(path ...string) string {
+	sum := sha256.Sum256([]byte(t.run + "\x00" + strings.Join(path, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// otlpRunStatus maps a conclusion to a span status: errors for failures,
+// ok for successes, and none for the rest, like cancelled or skipped
+func otlpRunStatus(conclusion string) *otlpStatus {
+	switch {
+	case isFailedConclusion(conclusion):
+		return &otlpStatus{Code: otlpStatusError, Message: conclusion}
+	case conclusion == "success":
+		return &otlpStatus{Code: otlpStatusOK}
+	}
+	return nil
+}
+
+// runResult returns how a finished run or job ended, from its conclusion
+// or, where the provider puts it there, its status
+func runResult(status, conclusion string) string {
+	if conclusion != "" {
+		return conclusion
+	}
+	return status
+}
+
+// runSpans converts a finished run into a trace: the run is the root span,
+// its jobs are children, and their steps are grandchildren. Jobs and steps
+// without both timings are left out.
+func runSpans(run WorkflowRun, jobs []Job) []otlpSpan {
+	ids := newTraceIDs(run)
+	traceID := ids.trace()
+	rootID := ids.span()
+
+	start := run.StartedAt
+	if start.IsZero() {
+		start = run.CreatedAt
+	}
+	root := otlpSpan{
+		TraceID: traceID,
+		SpanID:  rootID,
+		Name:    run.Workflow,
+		Kind:    otlpSpanKindInternal,
+		Start:   otlpTime(start),
+		End:     otlpTime(run.UpdatedAt),
+		Attributes: otlpAttributes(
+			"cicd.pipeline.name", run.Workflow,
+			"cicd.pipeline.run.id", run.ID,
+			"cicd.pipeline.run.url.full", run.URL,
+			"cicd.pipeline.result", runResult(run.Status, run.Conclusion),
+			"vcs.ref.head.name", run.Branch,
+			"vcs.ref.head.revision", run.Commit,
+			"enduser.id", run.TriggeredBy,
+		),
+		Status: otlpRunStatus(runResult(run.Status, run.Conclusion)),
+	}
+	if run.Attempt > 0 {
+		root.Attributes = append(root.Attributes, otlpInt("cicd.pipeline.run.attempt", run.Attempt))
+	}
+	if !run.StartedAt.IsZero() && run.StartedAt.After(run.CreatedAt) {
+		root.Attributes = append(root.Attributes, otlpInt("cicd.pipeline.run.queue_ms", int(run.StartedAt.Sub(run.CreatedAt).Milliseconds())))
+	}
+	spans := []otlpSpan{root}
+
+	for _, job := range jobs {
+		if job.StartedAt == nil || job.CompletedAt == nil {
+			continue
+		}
+		jobID := ids.span("job", job.ID)
+		span := otlpSpan{
+			TraceID:      traceID,
+			SpanID:       jobID,
+			ParentSpanID: rootID,
+			Name:         job.Name,
+			Kind:         otlpSpanKindInternal,
+			Start:        otlpTime(*job.StartedAt),
+			End:          otlpTime(*job.CompletedAt),
+			Attributes: otlpAttributes(
+				"cicd.pipeline.task.name", job.Name,
+				"cicd.pipeline.task.run.id", job.ID,
+				"cicd.pipeline.task.run.url.full", job.URL,
+				"cicd.pipeline.task.run.result", runResult(job.Status, job.Conclusion),
+			),
+			Status: otlpRunStatus(runResult(job.Status, job.Conclusion)),
+		}
+		if job.Runner != nil {
+			span.Attributes = append(span.Attributes, otlpAttributes("cicd.worker.name", job.Runner.Name)...)
+		}
+		if job.CreatedAt != nil && job.StartedAt.After(*job.CreatedAt) {
+			span.Attributes = append(span.Attributes, otlpInt("cicd.pipeline.task.queue_ms", int(job.StartedAt.Sub(*job.CreatedAt).Milliseconds())))
+		}
+		spans = append(spans, span)
+
+		for i, step := range job.Steps {
+			if step.StartedAt == nil || step.CompletedAt == nil {
+				continue
+			}
+			spans = append(spans, otlpSpan{
+				TraceID:      traceID,
+				SpanID:       ids.span("job", job.ID, "step", strconv.Itoa(i)),
+				ParentSpanID: jobID,
+				Name:         step.Name,
+				Kind:         otlpSpanKindInternal,
+				Start:        otlpTime(*step.StartedAt),
+				End:          otlpTime(*step.CompletedAt),
+				Attributes:   otlpAttributes("cicd.pipeline.task.step.result", runResult(step.Status, step.Conclusion)),
+				Status:       otlpRunStatus(runResult(step.Status, step.Conclusion)),
+			})
+		}
+	}
+	return spans
+}
+
+// otlpProjectSpans wraps a project's spans with the project as the
+// resource, so each project shows up as its own service
+func otlpProjectSpans(project Project, spans []otlpSpan) otlpResourceSpans {
+	return otlpResourceSpans{
+		Resource: otlpResource{Attributes: otlpAttributes(
+			"service.name", project.Name,
+			"vcs.provider.name", project.Platform,
+			"vcs.repository.url.full", project.RemoteURL,
+		)},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "quick_workflow", Version: resolveVersion()},
+			Spans: spans,
+		}},
+	}
+}
+
+// otlpEndpoint returns where traces are sent: the flag, else the standard
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT
+// variables, else a local collector
+func otlpEndpoint(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return strings.TrimRight(endpoint, "/") + "/v1/traces"
+	}
+	return defaultOTLPEndpoint
+}
+
+// otlpHeaders returns the headers to send: OTEL_EXPORTER_OTLP_HEADERS, as
+// comma separated key=value pairs, overridden by --header flags. Headers
+// usually carry an API key, so they aren't stored.
+func otlpHeaders(flags map[string]string) (map[string]string, error) {
+	headers := make(map[string]string)
+	if env := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); env != "" {
+		for _, pair := range strings.Split(env, ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: expected key=value pairs")
+			}
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	for key, value := range flags {
+		headers[key] = value
+	}
+	return headers, nil
+}
+
+// sendOTLP posts an export request to an OTLP/HTTP endpoint
+func sendOTLP(ctx context.Context, endpoint string, headers map[string]string, traces otlpTraces) error {
+	body, err := json.Marshal(traces)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s rejected the traces: %s %s", endpoint, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// otelExportedFile returns the path of the runs already exported, kept so
+// repeated exports, e.g. from cron, only send new runs
+func otelExportedFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "otel-exported.json")
+}
+
+// readOTelExported reads the keys of the runs already exported. A missing
+// or unreadable file is empty.
+func readOTelExported(config *Config) map[string]time.Time {
+	exported := make(map[string]time.Time)
+	if data, err := os.ReadFile(otelExportedFile(config)); err == nil {
+		_ = json.Unmarshal(data, &exported)
+	}
+	return exported
+}
+
+// markOTelExported adds runs to the exported ones, dropping runs exported
+// over 90 days ago, which recent listings no longer reach
+func markOTelExported(config *Config, runs []WorkflowRun, at time.Time) error {
+	path := otelExportedFile(config)
+	lock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	exported := readOTelExported(config)
+	for _, run := range runs {
+		exported[newTraceIDs(run).run] = at
+	}
+	for key, when := range exported {
+		if at.Sub(when) > 90*24*time.Hour {
+			delete(exported, key)
+		}
+	}
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// handleOTel handles the otel command, which exports finished runs as
+// OpenTelemetry traces
+func handleOTel(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Printf("%s Usage: quick_workflow otel export [--endpoint url] [--header KEY=VALUE]... [--runs N] [--since 24h] [--all] [--out file] [project...]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	fs := flag.NewFlagSet("otel export", flag.ExitOnError)
+	endpointFlag := fs.String("endpoint", "", "OTLP/HTTP traces endpoint (default: OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, OTEL_EXPORTER_OTLP_ENDPOINT, else "+defaultOTLPEndpoint+")")
+	headerFlags := variableFlags{}
+	fs.Var(headerFlags, "header", "Header to send as KEY=VALUE, e.g. an API key; repeatable (also OTEL_EXPORTER_OTLP_HEADERS)")
+	limit := fs.Int("runs", 20, "Number of recent runs per project to consider")
+	since := fs.Duration("since", 0, "Only export runs that finished within this long, e.g. 24h")
+	all := fs.Bool("all", false, "Export runs again even if they were exported before")
+	out := fs.String("out", "", "Write the OTLP JSON to this file, or - for stdout, instead of sending it")
+	fs.Parse(args[1:])
+
+	endpoint := otlpEndpoint(*endpointFlag)
+	headers, err := otlpHeaders(headerFlags)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	projects := activeProjects(config.Projects)
+	if fs.NArg() > 0 {
+		projects = nil
+		for _, name := range fs.Args() {
+			project := findProject(config, name)
+			if project == nil {
+				fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+				return
+			}
+			projects = append(projects, *project)
+		}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	// Progress goes to stderr so --out - stays valid JSON
+	status := os.Stdout
+	if *out == "-" {
+		status = os.Stderr
+	}
+
+	exported := readOTelExported(config)
+	var traces otlpTraces
+	var sent []WorkflowRun
+	var failures []fetchFailure
+	spanCount := 0
+	for _, project := range projects {
+		runs, err := getWorkflowRunsForProject(ctx, project, "", *limit)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			failures = append(failures, fetchFailure{Project: project.Name, Err: err})
+			continue
+		}
+
+		var spans []otlpSpan
+		for _, run := range runs {
+			if !isRunFinished(run.Status) || (*since > 0 && time.Since(run.UpdatedAt) > *since) {
+				continue
+			}
+			if _, ok := exported[newTraceIDs(run).run]; ok && !*all {
+				continue
+			}
+			jobs, err := getJobsForRun(ctx, run)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				fmt.Fprintf(status, "%s Exporting run %s of %s without jobs: %v\n", qc.Colorize("Warning:", qc.ColorYellow), run.ID, project.Name, describeError(err))
+			}
+			spans = append(spans, runSpans(run, jobs)...)
+			sent = append(sent, run)
+		}
+		if len(spans) > 0 {
+			traces.ResourceSpans = append(traces.ResourceSpans, otlpProjectSpans(project, spans))
+			spanCount += len(spans)
+		}
+	}
+	printFetchFailures(status, failures)
+
+	if len(sent) == 0 {
+		fmt.Fprintf(status, "%s No finished runs to export\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	if *out != "" {
+		data, err := json.MarshalIndent(traces, "", "  ")
+		if err != nil {
+			fmt.Fprintf(status, "%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		if *out == "-" {
+			os.Stdout.Write(append(data, '\n'))
+		} else if err := os.WriteFile(*out, data, 0644); err != nil {
+			fmt.Printf("%s Failed to write %s: %v\n", qc.Colorize("Error:", qc.ColorRed), *out, describeError(err))
+			return
+		}
+		fmt.Fprintf(status, "%s Wrote %d run(s) as %d span(s) to %s\n", qc.Colorize("Success:", qc.ColorGreen), len(sent), spanCount, *out)
+		return
+	}
+
+	if err := sendOTLP(ctx, endpoint, headers, traces); err != nil {
+		fmt.Printf("%s Failed to export traces: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if err := markOTelExported(config, sent, time.Now()); err != nil {
+		fmt.Printf("%s Failed to remember exported runs: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	fmt.Printf("%s Exported %d run(s) as %d span(s) to %s\n", qc.Colorize("Success:", qc.ColorGreen), len(sent), spanCount, endpoint)
+}
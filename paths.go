@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appDirName is the directory name used under the platform config directory
+const appDirName = "quick_workflow"
+
+// activeProfile namespaces state, auth, and cache files. It is set from
+// --profile or QUICK_WORKFLOW_PROFILE; empty means the default profile.
+var activeProfile string
+
+// setProfile validates and activates a profile name
+func setProfile(name string) error {
+	if name == "" || name == "default" {
+		activeProfile = ""
+		return nil
+	}
+	if strings.ContainsAny(name, `/\:`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid profile name: %s", name)
+	}
+	activeProfile = name
+	return nil
+}
+
+// profileDir places dir under profiles/<name> for non-default profiles
+func profileDir(dir string) string {
+	if activeProfile == "" {
+		return dir
+	}
+	return filepath.Join(dir, "profiles", activeProfile)
+}
+
+// configDir returns the directory holding state and auth files for the
+// active profile. It follows
+// the platform convention ($XDG_CONFIG_HOME or ~/.config on Linux,
+// %AppData% on Windows, ~/Library/Application Support on macOS) but keeps
+// using ~/.config/quick_workflow when that already exists and the platform
+// directory does not, so existing installs keep their projects and tokens.
+func configDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacyDir := filepath.Join(homeDir, ".config", appDirName)
+
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return profileDir(legacyDir), nil
+	}
+	dir := filepath.Join(userConfigDir, appDirName)
+
+	if dir != legacyDir && !dirExists(dir) && dirExists(legacyDir) {
+		return profileDir(legacyDir), nil
+	}
+	return profileDir(dir), nil
+}
+
+// cacheDir returns the directory for cached API data for the active profile
+func cacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return profileDir(filepath.Join(userCacheDir, appDirName)), nil
+}
+
+// dirExists reports whether path exists and is a directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// firstPartyOwners publish actions maintained by GitHub itself
+var firstPartyOwners = []string{"actions", "github"}
+
+// pinCheck is the verification result for one action reference
+type pinCheck struct {
+	Use    actionUse
+	Status string // verified, mismatch, unverified, unpinned, error
+	Detail string
+	// SHA and Tag are what the reference should be pinned to; empty when
+	// it should be left alone
+	SHA string
+	Tag string
+}
+
+// actionRepo returns the owner and repository of an action or reusable
+// workflow reference such as owner/repo/path
+func actionRepo(name string) (string, string, bool) {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// parseVersion splits a release tag like v4.1.2 into its numbers. Tags with
+// pre-release suffixes are not versions for bumping purposes.
+func parseVersion(tag string) ([]int, bool) {
+	var numbers []int
+	for _, part := range strings.Split(strings.TrimPrefix(tag, "v"), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
+
+// newestInMajor returns the highest tag sharing current's major version, or
+// current when none is newer. Between equal versions the more specific tag
+// wins, so v4 moves to v4.2.0.
+func newestInMajor(tags []string, current string) string {
+	currentVersion, ok := parseVersion(current)
+	if !ok {
+		return current
+	}
+	best, bestVersion := current, currentVersion
+	for _, tag := range tags {
+		version, ok := parseVersion(tag)
+		if !ok || version[0] != currentVersion[0] {
+			continue
+		}
+		cmp := compareVersions(version, bestVersion)
+		if cmp > 0 || (cmp == 0 && len(version) > len(bestVersion)) {
+			best, bestVersion = tag, version
+		}
+	}
+	return best
+}
+
+// compareVersions compares versions part by part, treating missing parts
+// as zero
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := 0, 0
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// pinResolver resolves refs of action repositories, caching lookups since
+// the same actions appear across many projects
+type pinResolver struct {
+	client  *GitHubClient
+	commits map[string]string
+	tags    map[string][]string
+}
+
+func (r *pinResolver) commit(owner, repo, ref string) (string, error) {
+	key := owner + "/" + repo + "@" + ref
+	if sha, ok := r.commits[key]; ok {
+		return sha, nil
+	}
+	sha, err := r.client.ResolveCommit(owner, repo, ref)
+	if err != nil {
+		return "", err
+	}
+	r.commits[key] = sha
+	return sha, nil
+}
+
+func (r *pinResolver) releaseTags(owner, repo string) ([]string, error) {
+	key := owner + "/" + repo
+	if tags, ok := r.tags[key]; ok {
+		return tags, nil
+	}
+	tags, err := r.client.GetTags(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	r.tags[key] = tags
+	return tags, nil
+}
+
+// checkPin verifies one reference. SHA pins are checked against the tag in
+// their comment; tag and branch references to third-party actions are
+// flagged. With bump, the target moves to the newest tag of the same major.
+func (r *pinResolver) checkPin(use actionUse, bump bool) pinCheck {
+	check := pinCheck{Use: use}
+	owner, repo, ok := actionRepo(use.Name)
+	if !ok {
+		return check
+	}
+	thirdParty := !slices.Contains(firstPartyOwners, strings.ToLower(owner))
+
+	switch {
+	case use.Pin == "sha" && use.Claimed == "":
+		check.Status, check.Detail = "unverified", "pinned to a SHA without a tag comment"
+	case use.Pin == "sha":
+		sha, err := r.commit(owner, repo, use.Claimed)
+		if err != nil {
+			check.Status, check.Detail = "error", fmt.Sprintf("could not resolve %s: %s", use.Claimed, describeError(err))
+			return check
+		}
+		if sha == use.Ref {
+			check.Status, check.Detail = "verified", "matches "+use.Claimed
+		} else {
+			check.Status = "mismatch"
+			check.Detail = fmt.Sprintf("%s points to %s, not %s", use.Claimed, shortSHA(sha), shortSHA(use.Ref))
+			check.SHA, check.Tag = sha, use.Claimed
+		}
+	case thirdParty:
+		sha, err := r.commit(owner, repo, use.Ref)
+		if err != nil {
+			check.Status, check.Detail = "error", fmt.Sprintf("could not resolve %s: %s", use.Ref, describeError(err))
+			return check
+		}
+		check.Status, check.Detail = "unpinned", fmt.Sprintf("third-party action pinned to %s %s", use.Pin, use.Ref)
+		check.SHA, check.Tag = sha, use.Ref
+	default:
+		return check
+	}
+
+	tag := check.Tag
+	if tag == "" && use.Pin == "sha" {
+		tag = use.Claimed
+	}
+	if bump && tag != "" {
+		tags, err := r.releaseTags(owner, repo)
+		if err != nil {
+			return check
+		}
+		if newest := newestInMajor(tags, tag); newest != tag {
+			sha, err := r.commit(owner, repo, newest)
+			if err != nil {
+				return check
+			}
+			check.SHA, check.Tag = sha, newest
+			check.Detail += fmt.Sprintf("; %s is available", newest)
+		}
+	}
+	return check
+}
+
+// rewriteUse pins the reference on a workflow line to sha, replacing any
+// trailing comment with the tag it corresponds to
+func rewriteUse(line string, use actionUse, sha, tag string) string {
+	old := use.Name
+	if use.Ref != "" {
+		old += "@" + use.Ref
+	}
+	i := strings.Index(line, old)
+	if i < 0 {
+		return line
+	}
+	rest := line[i+len(old):]
+	if comment := strings.Index(rest, " #"); comment >= 0 {
+		rest = rest[:comment]
+	}
+	return line[:i] + use.Name + "@" + sha + strings.TrimRight(rest, " ") + " # " + tag
+}
+
+// applyPins rewrites the files of a project for every check with a target,
+// returning the changed files
+func applyPins(files []WorkflowFile, checks []pinCheck) []WorkflowFile {
+	var changed []WorkflowFile
+	for _, file := range files {
+		lines := strings.Split(file.Content, "\n")
+		modified := false
+		for _, check := range checks {
+			if check.Use.Path != file.Path || check.SHA == "" || check.Use.Line > len(lines) {
+				continue
+			}
+			i := check.Use.Line - 1
+			if updated := rewriteUse(lines[i], check.Use, check.SHA, check.Tag); updated != lines[i] {
+				lines[i] = updated
+				modified = true
+			}
+		}
+		if modified {
+			changed = append(changed, WorkflowFile{Path: file.Path, Content: strings.Join(lines, "\n")})
+		}
+	}
+	return changed
+}
+
+// colorPinStatus colors a verification status padded to a fixed width
+func colorPinStatus(status string) string {
+	padded := fmt.Sprintf("%-10s", status)
+	switch status {
+	case "verified":
+		return qc.Colorize(padded, qc.ColorGreen)
+	case "mismatch", "error":
+		return qc.Colorize(padded, qc.ColorRed)
+	default:
+		return qc.Colorize(padded, qc.ColorYellow)
+	}
+}
+
+// verifyPins checks the action pins of tracked GitHub projects and, with
+// --fix, opens a pull request per project that pins or bumps them
+func verifyPins(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("inventory verify", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "Open pull requests that pin flagged actions to commit SHAs")
+	bump := fs.Bool("bump", false, "Also move pins to the newest tag of the same major version")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+
+	client, err := NewGitHubClient(ctx, "")
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	resolver := &pinResolver{client: client, commits: make(map[string]string), tags: make(map[string][]string)}
+	reader := bufio.NewReader(os.Stdin)
+
+	checked := 0
+	for _, project := range projects {
+		if project.Platform != "github" {
+			continue
+		}
+		checked++
+		files, err := client.GetWorkflowFiles(project.Owner, project.Repo, "")
+		if err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+
+		var checks []pinCheck
+		for _, file := range files {
+			for _, use := range scanWorkflowUses(project.Name, file) {
+				if use.Kind == "docker" {
+					continue
+				}
+				if check := resolver.checkPin(use, *bump); check.Status != "" {
+					checks = append(checks, check)
+				}
+			}
+		}
+
+		fmt.Printf("\n%s\n", qc.ColorizeBold(project.Name, qc.ColorWhite))
+		if len(checks) == 0 {
+			fmt.Printf("  %s\n", qc.Colorize("No SHA pins or unpinned third-party actions", qc.ColorCyan))
+			continue
+		}
+		sort.SliceStable(checks, func(i, j int) bool {
+			if checks[i].Use.Path != checks[j].Use.Path {
+				return checks[i].Use.Path < checks[j].Use.Path
+			}
+			return checks[i].Use.Line < checks[j].Use.Line
+		})
+		for _, check := range checks {
+			fmt.Printf("  %s %s:%d %s\n", colorPinStatus(check.Status), check.Use.Path, check.Use.Line, check.Use.Name)
+			fmt.Printf("             %s\n", check.Detail)
+		}
+
+		if !*fix {
+			continue
+		}
+		changed := applyPins(files, checks)
+		if len(changed) == 0 {
+			continue
+		}
+		if !confirm(reader, fmt.Sprintf("Open a pull request in %s updating %d workflow file(s)?", project.Name, len(changed))) {
+			continue
+		}
+
+		var body strings.Builder
+		body.WriteString("Pins GitHub Actions to full commit SHAs, with the tag each SHA corresponds to in a comment.\n\n")
+		for _, check := range checks {
+			if check.SHA != "" {
+				fmt.Fprintf(&body, "- `%s`: %s -> `%s` (%s)\n", check.Use.Name, check.Use.Ref, shortSHA(check.SHA), check.Tag)
+			}
+		}
+		branch := fmt.Sprintf("quick-workflow/pin-actions-%d", time.Now().Unix())
+		url, err := client.OpenPullRequest(project.Owner, project.Repo, branch, "Pin GitHub Actions to commit SHAs", body.String(), changed)
+		if err != nil {
+			fmt.Printf("%s Failed to open pull request: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			continue
+		}
+		fmt.Printf("%s Opened %s\n", qc.Colorize("Success:", qc.ColorGreen), url)
+	}
+
+	if checked == 0 {
+		fmt.Printf("%s No GitHub projects to verify\n", qc.Colorize("Info:", qc.ColorCyan))
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// pluginPrefix starts the executable name of provider plugins; the rest is
+// the platform name projects use, e.g. quick_workflow-provider-teamcity
+const pluginPrefix = "quick_workflow-provider-"
+
+// pluginTimeout bounds a single plugin call
+const pluginTimeout = 2 * time.Minute
+
+// pluginName matches the platform names plugins can be installed as, so the
+// executable stays inside the plugins directory
+var pluginName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// PluginInfo is a plugin's answer to the info method
+type PluginInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Version     string `json:"version"`
+	// Methods lists the methods the plugin implements besides info
+	Methods []string `json:"methods"`
+}
+
+// PluginRequest is the JSON a plugin reads from stdin. Fields that don't
+// apply to a method are omitted.
+type PluginRequest struct {
+	Project   string            `json:"project"`
+	Owner     string            `json:"owner"`
+	Repo      string            `json:"repo"`
+	RemoteURL string            `json:"remote_url,omitempty"`
+	Branch    string            `json:"branch,omitempty"`
+	Limit     int               `json:"limit,omitempty"`
+	RunID     string            `json:"run_id,omitempty"`
+	JobID     string            `json:"job_id,omitempty"`
+	Workflow  string            `json:"workflow,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// PluginClient runs provider methods through an external executable. Each
+// call runs "<plugin> <method>" with a PluginRequest on stdin and reads the
+// result as JSON from stdout. A plugin reports failure by exiting non-zero,
+// with {"error": "..."} on stdout or a message on stderr.
+type PluginClient struct {
+	platform string
+	path     string
+	ctx      context.Context
+}
+
+// pluginsDir returns the directory plugins are installed to
+func pluginsDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugins"), nil
+}
+
+// findPlugin returns the executable of a platform's plugin, looking in the
+// plugins directory before PATH
+func findPlugin(platform string) (string, error) {
+	name := pluginPrefix + platform
+	if dir, err := pluginsDir(); err == nil {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("unsupported platform: %s (no %s plugin found; see 'quick_workflow plugins list')", platform, name)
+	}
+	return path, nil
+}
+
+// discoverPlugins finds the plugins in the plugins directory and on PATH by
+// platform; installed plugins shadow ones on PATH
+func discoverPlugins() map[string]string {
+	found := make(map[string]string)
+	dirs := filepath.SplitList(os.Getenv("PATH"))
+	if dir, err := pluginsDir(); err == nil {
+		dirs = append([]string{dir}, dirs...)
+	}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			platform, ok := strings.CutPrefix(entry.Name(), pluginPrefix)
+			if !ok || platform == "" || entry.IsDir() {
+				continue
+			}
+			if _, seen := found[platform]; !seen {
+				found[platform] = filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	return found
+}
+
+// NewPluginClient creates a client for a platform served by a plugin; ctx
+// cancels calls in progress
+func NewPluginClient(ctx context.Context, platform string) (*PluginClient, error) {
+	// Plugins talk to their providers themselves
+	if offlineConfig != nil {
+		return nil, errOffline(platform)
+	}
+	path, err := findPlugin(platform)
+	if err != nil {
+		return nil, err
+	}
+	return &PluginClient{platform: platform, path: path, ctx: ctx}, nil
+}
+
+// call runs a plugin method and decodes its result into out when set
+func (p *PluginClient) call(method string, request PluginRequest, out any) error {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, pluginTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path, method)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err = cmd.Run()
+	apiLog.Info("plugin call", "platform", p.platform, "method", method, "duration", time.Since(start).Round(time.Millisecond), "error", err)
+	apiLog.Debug("plugin output", "platform", p.platform, "method", method, "stdout_bytes", stdout.Len(), "stderr", strings.TrimSpace(stderr.String()))
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s plugin: %s timed out after %s", p.platform, method, pluginTimeout)
+	}
+	if err != nil {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(stderr.String())
+		if json.Unmarshal(stdout.Bytes(), &failure) == nil && failure.Error != "" {
+			message = failure.Error
+		}
+		if message == "" {
+			message = err.Error()
+		}
+		return fmt.Errorf("%s plugin: %s", p.platform, message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("%s plugin: invalid %s response: %w", p.platform, method, err)
+	}
+	return nil
+}
+
+// projectRequest returns the request fields identifying a project
+func (p *PluginClient) projectRequest(project Project) PluginRequest {
+	return PluginRequest{Project: project.Name, Owner: project.Owner, Repo: project.Repo, RemoteURL: project.RemoteURL}
+}
+
+// runRequest returns the request fields identifying a run
+func (p *PluginClient) runRequest(run WorkflowRun) PluginRequest {
+	owner, repo, _ := strings.Cut(run.Project, "/")
+	return PluginRequest{Project: run.Project, Owner: owner, Repo: repo, RunID: run.ID}
+}
+
+// withPlatform stamps runs with the plugin's platform so later calls for
+// them come back to it
+func (p *PluginClient) withPlatform(runs []WorkflowRun, project string) []WorkflowRun {
+	for i := range runs {
+		runs[i].Platform = p.platform
+		if runs[i].Project == "" {
+			runs[i].Project = project
+		}
+	}
+	return runs
+}
+
+// Info asks the plugin to describe itself
+func (p *PluginClient) Info() (*PluginInfo, error) {
+	var info PluginInfo
+	if err := p.call("info", PluginRequest{}, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListRuns retrieves recent runs of a project, optionally on one branch
+func (p *PluginClient) ListRuns(project Project, branch string, limit int) ([]WorkflowRun, error) {
+	request := p.projectRequest(project)
+	request.Branch = branch
+	request.Limit = limit
+	var runs []WorkflowRun
+	if err := p.call("list_runs", request, &runs); err != nil {
+		return nil, err
+	}
+	return p.withPlatform(runs, project.Name), nil
+}
+
+// GetRun retrieves a single run
+func (p *PluginClient) GetRun(project Project, runID string) (*WorkflowRun, error) {
+	request := p.projectRequest(project)
+	request.RunID = runID
+	var run WorkflowRun
+	if err := p.call("get_run", request, &run); err != nil {
+		return nil, err
+	}
+	return &p.withPlatform([]WorkflowRun{run}, project.Name)[0], nil
+}
+
+// ListWorkflows lists what can be started for a project
+func (p *PluginClient) ListWorkflows(project Project) ([]string, error) {
+	var workflows []string
+	if err := p.call("list_workflows", p.projectRequest(project), &workflows); err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
+// Trigger starts a workflow and returns the run, or nil when the plugin
+// can't tell which run it started
+func (p *PluginClient) Trigger(project Project, workflow string, variables map[string]string) (*WorkflowRun, error) {
+	request := p.projectRequest(project)
+	request.Workflow = workflow
+	request.Variables = variables
+	var run *WorkflowRun
+	if err := p.call("trigger", request, &run); err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, nil
+	}
+	return &p.withPlatform([]WorkflowRun{*run}, project.Name)[0], nil
+}
+
+// ListJobs retrieves the jobs of a run
+func (p *PluginClient) ListJobs(run WorkflowRun) ([]Job, error) {
+	var jobs []Job
+	if err := p.call("list_jobs", p.runRequest(run), &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// JobLog retrieves the log of a job as plain text
+func (p *PluginClient) JobLog(run WorkflowRun, jobID string) (string, error) {
+	request := p.runRequest(run)
+	request.JobID = jobID
+	var log string
+	if err := p.call("job_log", request, &log); err != nil {
+		return "", err
+	}
+	return log, nil
+}
+
+// Cancel stops a running run
+func (p *PluginClient) Cancel(run WorkflowRun) error {
+	return p.call("cancel", p.runRequest(run), nil)
+}
+
+// Rerun starts a finished run again
+func (p *PluginClient) Rerun(run WorkflowRun, failedOnly bool) error {
+	method := "rerun"
+	if failedOnly {
+		method = "rerun_failed"
+	}
+	return p.call(method, p.runRequest(run), nil)
+}
+
+// handlePlugins handles the plugins command
+func handlePlugins(ctx context.Context, args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow plugins list|install [--sha256 <hex>] <path-or-url> [name]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		listPlugins(ctx)
+	case "install":
+		fs := flag.NewFlagSet("plugins install", flag.ExitOnError)
+		digest := fs.String("sha256", "", "Expected SHA-256 digest of the executable, in hex; required for http:// URLs")
+		fs.Parse(args[1:])
+		if fs.NArg() < 1 {
+			fmt.Printf("%s Usage: quick_workflow plugins install [--sha256 <hex>] <path-or-url> [name]\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		if err := installPlugin(ctx, fs.Arg(0), fs.Arg(1), *digest); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		}
+	default:
+		fmt.Printf("%s Unknown plugins command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+	}
+}
+
+// listPlugins shows discovered plugins with what they report about
+// themselves
+func listPlugins(ctx context.Context) {
+	plugins := discoverPlugins()
+	if len(plugins) == 0 {
+		dir, _ := pluginsDir()
+		fmt.Printf("%s No provider plugins found in %s or on PATH\n", qc.Colorize("Info:", qc.ColorCyan), dir)
+		return
+	}
+
+	platforms := slices.Sorted(maps.Keys(plugins))
+
+	fmt.Printf("%s\n", qc.Colorize("Provider plugins:", qc.ColorBlue))
+	fmt.Println()
+	for i, platform := range platforms {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		client := &PluginClient{platform: platform, path: plugins[platform], ctx: ctx}
+		info, err := client.Info()
+		if err != nil {
+			entry := fmt.Sprintf("%3d. %-15s %s", i+1, platform, plugins[platform])
+			fmt.Printf("%s %s\n", qc.Colorize(entry, rowColor), qc.Colorize("("+describeError(err)+")", qc.ColorRed))
+			continue
+		}
+		entry := fmt.Sprintf("%3d. %-15s %-20s %-10s %s", i+1, platform, info.DisplayName, info.Version, strings.Join(info.Methods, ","))
+		fmt.Println(qc.Colorize(entry, rowColor))
+		fmt.Printf("     %s\n", qc.Dim(plugins[platform]))
+	}
+}
+
+// installPlugin copies a plugin executable from a path or downloads it from
+// a URL into the plugins directory. The platform name defaults to the part
+// of the file name after the plugin prefix. When digest is given, the
+// executable must have that SHA-256 digest; plain http:// downloads need
+// one.
+func installPlugin(ctx context.Context, source, name, digest string) error {
+	base := filepath.Base(source)
+	if name == "" {
+		var ok bool
+		name, ok = strings.CutPrefix(base, pluginPrefix)
+		if !ok || name == "" {
+			return fmt.Errorf("can't tell the platform from %q; name it, e.g. 'quick_workflow plugins install %s teamcity'", base, source)
+		}
+	}
+	if !pluginName.MatchString(name) {
+		return fmt.Errorf("invalid platform name %q (expected lowercase letters, digits, _ and -)", name)
+	}
+	if strings.HasPrefix(source, "http://") && digest == "" {
+		return fmt.Errorf("refusing to install an executable over plain http:// without --sha256; use https:// or give its digest")
+	}
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		data, err = downloadPlugin(ctx, source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return err
+	}
+	if digest != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, strings.TrimSpace(digest)) {
+			return fmt.Errorf("SHA-256 of %s is %s, not %s; not installing it", source, actual, digest)
+		}
+	}
+
+	dir, err := pluginsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, pluginPrefix+name)
+	if err := writeFileAtomic(path, data, 0755); err != nil {
+		return err
+	}
+
+	// Check the plugin speaks the protocol before suggesting it
+	info, err := (&PluginClient{platform: name, path: path, ctx: ctx}).Info()
+	if err != nil {
+		fmt.Printf("%s Installed %s, but it didn't answer info: %v\n", qc.Colorize("Warning:", qc.ColorYellow), path, describeError(err))
+		return nil
+	}
+	fmt.Printf("%s Installed %s plugin %s to %s\n", qc.Colorize("Success:", qc.ColorGreen), info.DisplayName, info.Version, path)
+	fmt.Printf("%s Track projects with 'quick_workflow add --platform %s <path>'\n", qc.Colorize("Info:", qc.ColorCyan), name)
+	return nil
+}
+
+// downloadPlugin fetches a plugin executable over HTTP
+func downloadPlugin(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Plugins can be large; allow at least five minutes
+	client := newHTTPClient()
+	client.Timeout = max(client.Timeout, 5*time.Minute)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
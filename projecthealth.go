@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// resolveDefaultBranch returns the branch a project's health is judged on:
+// its configured default branch, else the repository's default branch
+// where the provider reports it, else "" for runs of any branch
+func resolveDefaultBranch(ctx context.Context, project Project) (string, error) {
+	if project.DefaultBranch != "" {
+		return project.DefaultBranch, nil
+	}
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return "", err
+		}
+		return client.GetDefaultBranch(project.Owner, project.Repo)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return "", err
+		}
+		return client.GetDefaultBranch(project.Name)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return "", err
+		}
+		return client.GetDefaultBranch(project.Name)
+	}
+	return "", nil
+}
+
+// fetchProjectHealth fetches the latest default branch run of each
+// project, by project name, with the projects that failed to load
+func fetchProjectHealth(ctx context.Context, projects []Project) (map[string]WorkflowRun, []fetchFailure) {
+	latest := make(map[string]WorkflowRun)
+	var failures []fetchFailure
+	for _, project := range projects {
+		if project.Archived {
+			continue
+		}
+		branch, err := resolveDefaultBranch(ctx, project)
+		var runs []WorkflowRun
+		if err == nil {
+			runs, err = getWorkflowRunsForProject(ctx, project, branch, 1)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			failures = append(failures, fetchFailure{Project: project.Name, Err: err})
+			continue
+		}
+		if len(runs) > 0 {
+			latest[project.Name] = runs[0]
+		}
+	}
+	return latest, failures
+}
+
+// projectHealth describes a project's latest default branch run as a
+// pass, fail, or running mark with its age, and the color to show it in
+func projectHealth(run WorkflowRun, ok bool) (string, string) {
+	if !ok {
+		return "- no runs", qc.ColorWhite
+	}
+	age := formatAgo(time.Since(run.CreatedAt))
+	switch runCheckStatus(run) {
+	case checkFailing:
+		return "✖ failing, " + age, qc.ColorRed
+	case checkRunning:
+		return "● running, " + age, qc.ColorBlue
+	}
+	if run.Conclusion != "" && run.Conclusion != "success" {
+		return "● " + run.Conclusion + ", " + age, qc.ColorYellow
+	}
+	return "✔ passing, " + age, qc.ColorGreen
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// defaultRetentionRuns is how many runs of each project the history keeps
+// when the retention_runs setting is unset
+const defaultRetentionRuns = 1000
+
+// autoPruneInterval is how often recording runs also prunes the history
+const autoPruneInterval = 24 * time.Hour
+
+// RetentionPolicy decides which runs the history keeps; zero fields keep
+// everything
+type RetentionPolicy struct {
+	// MaxAge drops runs created longer ago than this
+	MaxAge time.Duration
+	// MaxRuns keeps only the newest runs of each project
+	MaxRuns int
+}
+
+// retentionPolicy returns the policy from the retention settings
+func retentionPolicy(settings Settings) RetentionPolicy {
+	policy := RetentionPolicy{MaxAge: time.Duration(settings.RetentionDays) * 24 * time.Hour, MaxRuns: settings.RetentionRuns}
+	if policy.MaxRuns == 0 {
+		policy.MaxRuns = defaultRetentionRuns
+	}
+	return policy
+}
+
+// describe summarizes the policy for output
+func (p RetentionPolicy) describe() string {
+	var parts []string
+	if p.MaxAge > 0 {
+		parts = append(parts, fmt.Sprintf("runs from the last %d days", int(p.MaxAge.Hours()/24)))
+	}
+	if p.MaxRuns > 0 {
+		parts = append(parts, fmt.Sprintf("the newest %d runs of each project", p.MaxRuns))
+	}
+	if len(parts) == 0 {
+		return "every run"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// prunedRuns returns the runs the policy drops from the latest observation
+// of each run in the history, newest first per project
+func prunedRuns(latest []WorkflowRun, policy RetentionPolicy, now time.Time) []WorkflowRun {
+	byProject := make(map[string][]WorkflowRun)
+	for _, run := range latest {
+		key := run.Platform + ":" + run.Project
+		byProject[key] = append(byProject[key], run)
+	}
+
+	var pruned []WorkflowRun
+	for _, runs := range byProject {
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].CreatedAt.After(runs[j].CreatedAt) })
+		for i, run := range runs {
+			tooOld := policy.MaxAge > 0 && !run.CreatedAt.IsZero() && now.Sub(run.CreatedAt) > policy.MaxAge
+			tooMany := policy.MaxRuns > 0 && i >= policy.MaxRuns
+			if tooOld || tooMany {
+				pruned = append(pruned, run)
+			}
+		}
+	}
+	return pruned
+}
+
+// Prune rewrites the file without the runs the policy drops
+func (s *jsonlHistoryStore) Prune(policy RetentionPolicy, now time.Time, dryRun bool) ([]WorkflowRun, error) {
+	lock, err := acquireLock(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	records, err := s.Query(HistoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]WorkflowRun)
+	for _, record := range records {
+		latest[historyKey(record.WorkflowRun)] = record.WorkflowRun
+	}
+	runs := make([]WorkflowRun, 0, len(latest))
+	for _, run := range latest {
+		runs = append(runs, run)
+	}
+	pruned := prunedRuns(runs, policy, now)
+	if len(pruned) == 0 || dryRun {
+		return pruned, nil
+	}
+
+	drop := make(map[string]bool, len(pruned))
+	for _, run := range pruned {
+		drop[historyKey(run)] = true
+	}
+	var data []byte
+	for _, record := range records {
+		if drop[historyKey(record.WorkflowRun)] {
+			continue
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return pruned, writeFileAtomic(s.path, data, 0644)
+}
+
+// Prune deletes every observation of the runs the policy drops in a single
+// transaction, then compacts SQLite databases
+func (s *sqlHistoryStore) Prune(policy RetentionPolicy, now time.Time, dryRun bool) ([]WorkflowRun, error) {
+	rows, err := s.db.Query(`SELECT platform, project, run_id, MAX(created_at) FROM run_history
+		GROUP BY platform, project, run_id`)
+	if err != nil {
+		return nil, err
+	}
+	var runs []WorkflowRun
+	for rows.Next() {
+		var run WorkflowRun
+		var createdAt int64
+		if err := rows.Scan(&run.Platform, &run.Project, &run.ID, &createdAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		run.CreatedAt = fromUnixNano(createdAt)
+		runs = append(runs, run)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pruned := prunedRuns(runs, policy, now)
+	if len(pruned) == 0 || dryRun {
+		return pruned, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(s.rebind(`DELETE FROM run_history WHERE platform = ? AND project = ? AND run_id = ?`))
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	for _, run := range pruned {
+		if _, err := stmt.Exec(run.Platform, run.Project, run.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if s.driver == "sqlite" {
+		// Deleted rows only free pages for reuse; VACUUM shrinks the file
+		if _, err := s.db.Exec("VACUUM"); err != nil {
+			return pruned, fmt.Errorf("pruned the history but failed to compact it: %v", err)
+		}
+	}
+	return pruned, nil
+}
+
+// pruneStampFile returns the path whose modification time is when the
+// history was last pruned automatically
+func pruneStampFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "last-prune")
+}
+
+// autoPrune prunes a local history by the retention settings at most once
+// per autoPruneInterval. A shared postgres history is left to 'prune' so
+// one user's settings don't decide what the team keeps.
+func autoPrune(config *Config, store HistoryStore) error {
+	if config.Settings.HistoryBackend == "postgres" {
+		return nil
+	}
+	stamp := pruneStampFile(config)
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < autoPruneInterval {
+		return nil
+	}
+	// Stamp first so a failing prune isn't retried on every refresh
+	if err := os.WriteFile(stamp, nil, 0644); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := os.Chtimes(stamp, now, now); err != nil {
+		return err
+	}
+
+	pruned, err := store.Prune(retentionPolicy(config.Settings), now, false)
+	if err != nil {
+		return err
+	}
+	_, err = pruneSideFiles(config, pruned, false)
+	return err
+}
+
+// pruneSideFiles drops the cached runs, coverage, failure causes, and test
+// results of pruned runs and of projects no longer tracked, returning how
+// many entries were dropped
+func pruneSideFiles(config *Config, pruned []WorkflowRun, dryRun bool) (int, error) {
+	tracked := make(map[string]bool, len(config.Projects))
+	for _, project := range config.Projects {
+		tracked[project.Name] = true
+	}
+	droppedRuns := make(map[string]bool, len(pruned))
+	for _, run := range pruned {
+		droppedRuns[run.Project+"#"+run.ID] = true
+	}
+	keepRun := func(key string) bool {
+		project, _, _ := strings.Cut(key, "#")
+		return tracked[project] && !droppedRuns[key]
+	}
+
+	dropped := 0
+	runCache := make(map[string]cachedRuns)
+	n, err := pruneJSONFile(runCacheFile(config), 0600, dryRun, &runCache, func() int {
+		count := 0
+		for name := range runCache {
+			if !tracked[name] {
+				delete(runCache, name)
+				count++
+			}
+		}
+		return count
+	})
+	dropped += n
+	if err != nil {
+		return dropped, err
+	}
+
+	coverage := make(map[string]*float64)
+	n, err = pruneJSONFile(coverageFile(config), 0644, dryRun, &coverage, func() int {
+		count := 0
+		for key := range coverage {
+			if !keepRun(key) {
+				delete(coverage, key)
+				count++
+			}
+		}
+		return count
+	})
+	dropped += n
+	if err != nil {
+		return dropped, err
+	}
+
+	var causes causeCache
+	n, err = pruneJSONFile(causeCacheFile(config), 0644, dryRun, &causes, func() int {
+		count := 0
+		for key := range causes.Causes {
+			if !keepRun(key) {
+				delete(causes.Causes, key)
+				count++
+			}
+		}
+		return count
+	})
+	dropped += n
+	if err != nil {
+		return dropped, err
+	}
+
+	var history testHistory
+	n, err = pruneJSONFile(testHistoryFile(config), 0644, dryRun, &history, func() int {
+		count := 0
+		var recorded []string
+		for _, key := range history.Recorded {
+			if keepRun(key) {
+				recorded = append(recorded, key)
+			} else {
+				count++
+			}
+		}
+		history.Recorded = recorded
+		// Failure counts of tracked projects outlive the runs they came from
+		var tests []TestRecord
+		for _, test := range history.Tests {
+			if tracked[test.Project] {
+				tests = append(tests, test)
+			} else {
+				count++
+			}
+		}
+		history.Tests = tests
+		return count
+	})
+	dropped += n
+	return dropped, err
+}
+
+// pruneJSONFile loads a JSON side file into value under its lock, lets
+// prune drop entries from it, and writes it back if any were dropped. A
+// missing file has nothing to prune.
+func pruneJSONFile(path string, perm os.FileMode, dryRun bool, value interface{}, prune func() int) (int, error) {
+	lock, err := acquireLock(path)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.release()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	dropped := prune()
+	if dropped == 0 || dryRun {
+		return dropped, nil
+	}
+	if data, err = json.MarshalIndent(value, "", "  "); err != nil {
+		return dropped, err
+	}
+	return dropped, writeFileAtomic(path, data, perm)
+}
+
+// handlePrune removes runs from the history beyond the retention settings
+// or flags, along with cached data of pruned runs and untracked projects
+func handlePrune(config *Config, args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	days := fs.Int("days", config.Settings.RetentionDays, "Keep runs created in the last N days; 0 keeps any age")
+	runs := fs.Int("runs", retentionPolicy(config.Settings).MaxRuns, "Keep the newest N runs of each project; 0 keeps any number")
+	dryRun := fs.Bool("dry-run", false, "Show what would be removed without removing it")
+	fs.Parse(args)
+
+	if *days < 0 || *runs < 0 || fs.NArg() > 0 {
+		fmt.Printf("%s Usage: quick_workflow prune [--days N] [--runs N] [--dry-run]\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	policy := RetentionPolicy{MaxAge: time.Duration(*days) * 24 * time.Hour, MaxRuns: *runs}
+
+	store, err := openHistoryStore(config)
+	if err != nil {
+		fmt.Printf("%s Failed to open the run history: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	defer store.Close()
+
+	pruned, err := store.Prune(policy, time.Now(), *dryRun)
+	if err != nil {
+		fmt.Printf("%s Failed to prune the run history: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	dropped, err := pruneSideFiles(config, pruned, *dryRun)
+	if err != nil {
+		fmt.Printf("%s Failed to prune cached data: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s Keeping %s\n", qc.Colorize("Info:", qc.ColorCyan), policy.describe())
+	if len(pruned) == 0 && dropped == 0 {
+		fmt.Printf("%s Nothing to prune\n", qc.Colorize("Success:", qc.ColorGreen))
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, run := range pruned {
+		counts[run.Project]++
+	}
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Printf("  %-30s %d run(s)\n", name, counts[name])
+	}
+	fmt.Printf("%s %s %d run(s) from the history and %d cached entries\n", qc.Colorize("Success:", qc.ColorGreen), verb, len(pruned), dropped)
+	if *dryRun {
+		fmt.Printf("%s Dry run; nothing was removed\n", qc.Colorize("Info:", qc.ColorCyan))
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// isWaitingStatus reports whether a GitHub or GitLab status means the run
+// or job hasn't been picked up by a runner yet
+func isWaitingStatus(status string) bool {
+	switch status {
+	case "queued", "pending", "waiting", "requested", "created", "waiting_for_resource", "preparing":
+		return true
+	default:
+		return false
+	}
+}
+
+// jobQueueTime returns how long a job waited for a runner, or has been
+// waiting so far
+func jobQueueTime(job Job) (time.Duration, bool) {
+	if job.CreatedAt == nil {
+		return 0, false
+	}
+	if job.StartedAt != nil {
+		return job.StartedAt.Sub(*job.CreatedAt), true
+	}
+	if isWaitingStatus(job.Status) {
+		return time.Since(*job.CreatedAt), true
+	}
+	return 0, false
+}
+
+// fillQueueTimes sets each run's queue time to the longest wait of its
+// jobs, since that is what runner saturation delays. This fetches the jobs
+// of every run, so it is only done when queue times are asked for.
+func fillQueueTimes(ctx context.Context, runs []WorkflowRun) {
+	for i := range runs {
+		run := &runs[i]
+		if isWaitingStatus(run.Status) {
+			run.QueueTime = time.Since(run.CreatedAt)
+			continue
+		}
+
+		jobs, err := getJobsForRun(ctx, *run)
+		if err != nil {
+			// Pipelines start when their first job does
+			if !run.StartedAt.IsZero() && run.Platform == "gitlab" {
+				run.QueueTime = run.StartedAt.Sub(run.CreatedAt)
+			}
+			continue
+		}
+		for _, job := range jobs {
+			if wait, ok := jobQueueTime(job); ok && wait > run.QueueTime {
+				run.QueueTime = wait
+			}
+		}
+	}
+}
+
+// needsQueueTimes reports whether queue times have to be fetched for the
+// columns shown or a queue threshold
+func needsQueueTimes(columns []string, threshold time.Duration) bool {
+	return threshold > 0 || slices.Contains(columns, "queued")
+}
+
+// filterQueuedOver keeps the runs that waited longer than threshold
+func filterQueuedOver(runs []WorkflowRun, threshold time.Duration) []WorkflowRun {
+	if threshold <= 0 {
+		return runs
+	}
+	var filtered []WorkflowRun
+	for _, run := range runs {
+		if run.QueueTime > threshold {
+			filtered = append(filtered, run)
+		}
+	}
+	return filtered
+}
+
+// queueAlerter lists runs waiting longer than threshold while watching,
+// ringing the terminal bell when a run first goes over so it is noticed
+type queueAlerter struct {
+	threshold time.Duration
+	alerted   map[string]bool
+}
+
+func newQueueAlerter(threshold time.Duration) *queueAlerter {
+	return &queueAlerter{threshold: threshold, alerted: make(map[string]bool)}
+}
+
+// check prints an alert for every unfinished run over the threshold;
+// finished runs no longer say anything about current capacity
+func (a *queueAlerter) check(runs []WorkflowRun) {
+	if a.threshold <= 0 {
+		return
+	}
+	for _, run := range runs {
+		if run.QueueTime <= a.threshold || isRunFinished(run.Status) {
+			continue
+		}
+		bell := ""
+		if key := run.Project + "#" + run.ID; !a.alerted[key] {
+			a.alerted[key] = true
+			bell = "\a"
+		}
+		fmt.Printf("%s%s %s %s (run %s) queued for %s, over %s\n",
+			bell, qc.Colorize("Queue alert:", qc.ColorRed),
+			run.Project, run.Workflow, run.ID, formatDuration(run.QueueTime), formatDuration(a.threshold))
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// collectRateQuotas returns the rate limits of the platforms with stored
+// credentials. Platforms that aren't logged in are skipped quietly; other
+// failures are returned as warnings.
+func collectRateQuotas(ctx context.Context) ([]RateQuota, []string) {
+	var quotas []RateQuota
+	var warnings []string
+
+	if client, err := NewGitHubClient(ctx, ""); err == nil {
+		found, err := client.GetRateLimits()
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("GitHub: %s", describeError(err)))
+		}
+		quotas = append(quotas, found...)
+	}
+	if client, err := NewGitLabClient(ctx, ""); err == nil {
+		quota, err := client.GetRateLimit()
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("GitLab: %s", describeError(err)))
+		} else {
+			quotas = append(quotas, quota)
+		}
+	}
+	return quotas, warnings
+}
+
+// colorRemaining colors the remaining requests of a quota padded to a fixed
+// width: red under 10% of the limit, yellow under 25%
+func colorRemaining(quota RateQuota) string {
+	text := fmt.Sprintf("%-14s", fmt.Sprintf("%d/%d", quota.Remaining, quota.Limit))
+	switch {
+	case quota.Limit == 0:
+		return qc.Colorize(fmt.Sprintf("%-14s", "unlimited"), qc.ColorGreen)
+	case quota.Remaining*10 < quota.Limit:
+		return qc.Colorize(text, qc.ColorRed)
+	case quota.Remaining*4 < quota.Limit:
+		return qc.Colorize(text, qc.ColorYellow)
+	default:
+		return qc.Colorize(text, qc.ColorGreen)
+	}
+}
+
+// showRateLimits shows the remaining API quota of each logged in platform
+// and when it resets
+func showRateLimits(ctx context.Context) {
+	quotas, warnings := collectRateQuotas(ctx)
+	for _, warning := range warnings {
+		fmt.Printf("%s %s\n", qc.Colorize("Warning:", qc.ColorYellow), warning)
+	}
+	if len(quotas) == 0 {
+		if len(warnings) == 0 {
+			fmt.Printf("%s Not logged in to GitHub or GitLab. Use 'quick_workflow login <platform>' first.\n", qc.Colorize("Info:", qc.ColorCyan))
+		}
+		return
+	}
+
+	fmt.Printf("%s\n", qc.Colorize("API rate limits:", qc.ColorBlue))
+	for i, quota := range quotas {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		entry := fmt.Sprintf("%-6s  %-8s", quota.Platform, quota.Resource)
+		reset := "-"
+		if !quota.Reset.IsZero() {
+			reset = fmt.Sprintf("resets in %s (%s)", formatDuration(time.Until(quota.Reset)), formatAbsoluteTime(quota.Reset))
+		}
+		fmt.Printf("%s  %s %s\n", qc.Colorize(entry, rowColor), colorRemaining(quota), reset)
+	}
+}
+
+// warnRateLimit warns on w when fetching for projects would need more
+// requests than the GitHub core or GitLab quota has left, perProject being
+// the requests a fetch makes for each project. Checking is best effort:
+// platforms that can't report their limits aren't warned about.
+func warnRateLimit(ctx context.Context, w io.Writer, projects []Project, perProject int) {
+	if offlineConfig != nil {
+		return
+	}
+	needed := make(map[string]int)
+	for _, project := range projects {
+		if project.Platform == "github" || project.Platform == "gitlab" {
+			needed[project.Platform] += perProject
+		}
+	}
+
+	var quotas []RateQuota
+	if needed["github"] > 0 {
+		if client, err := NewGitHubClient(ctx, ""); err == nil {
+			quotas, _ = client.GetRateLimits()
+		}
+	}
+	if needed["gitlab"] > 0 {
+		if client, err := NewGitLabClient(ctx, ""); err == nil {
+			if quota, err := client.GetRateLimit(); err == nil {
+				quotas = append(quotas, quota)
+			}
+		}
+	}
+
+	for _, quota := range quotas {
+		if quota.Resource == "search" || quota.Resource == "graphql" || quota.Limit == 0 {
+			continue
+		}
+		if quota.Remaining >= needed[quota.Platform] {
+			continue
+		}
+		fmt.Fprintf(w, "%s %s has %d API request(s) left but this fetch needs about %d; results may be incomplete until the limit resets in %s\n",
+			qc.Colorize("Warning:", qc.ColorYellow), platformName(quota.Platform), quota.Remaining, needed[quota.Platform], formatDuration(time.Until(quota.Reset)))
+	}
+}
+
+// fetchRequests estimates the requests fetching limit runs of a project
+// makes: the pages of runs, plus the jobs of every run for queue times
+func fetchRequests(queueTimes bool, limit int) int {
+	pages := max(1, (limit+maxPageSize-1)/maxPageSize)
+	if queueTimes {
+		return pages + limit
+	}
+	return pages
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// getReleases retrieves up to limit of a project's newest releases or tags
+func getReleases(ctx context.Context, project Project, limit int) ([]Release, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetReleases(project.Owner, project.Repo, limit)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetReleases(project.Name, limit)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// releaseBuild is the outcome of the runs triggered by a release's tag
+type releaseBuild struct {
+	// Runs is the latest run of each workflow, by workflow name
+	Runs []WorkflowRun
+}
+
+// newReleaseBuild keeps the latest run of each workflow among runs
+func newReleaseBuild(runs []WorkflowRun) releaseBuild {
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+	var build releaseBuild
+	seen := make(map[string]bool)
+	for _, run := range runs {
+		if !seen[run.Workflow] {
+			seen[run.Workflow] = true
+			build.Runs = append(build.Runs, run)
+		}
+	}
+	sort.Slice(build.Runs, func(i, j int) bool {
+		return build.Runs[i].Workflow < build.Runs[j].Workflow
+	})
+	return build
+}
+
+// failed reports whether any workflow of the build failed
+func (b releaseBuild) failed() bool {
+	for _, run := range b.Runs {
+		if isRunFinished(run.Status) && isFailedConclusion(firstNonEmpty(run.Conclusion, run.Status)) {
+			return true
+		}
+	}
+	return false
+}
+
+// summary lists each workflow with a marker for how its run ended
+func (b releaseBuild) summary() string {
+	if len(b.Runs) == 0 {
+		return qc.Colorize("no runs", qc.ColorYellow)
+	}
+	parts := make([]string, 0, len(b.Runs))
+	for _, run := range b.Runs {
+		marker := stepMarker(Step{Status: run.Status, Conclusion: run.Conclusion})
+		parts = append(parts, qc.Colorize(marker+" "+run.Workflow, colorWorkflowStatus(run.Status, run.Conclusion)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatSize formats a byte count with a binary unit
+func formatSize(bytes int) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size, exp := float64(bytes)/unit, 0
+	for size >= unit && exp < 3 {
+		size /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", size, "KMGT"[exp])
+}
+
+// showReleases lists the newest releases of the given project, or of every
+// tracked project, with the runs their tags triggered and, on GitHub, their
+// assets. Releases whose build failed are highlighted.
+func showReleases(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("releases", flag.ExitOnError)
+	limit := fs.Int("limit", 5, "Number of releases per project")
+	failed := fs.Bool("failed", false, "Only show releases whose build failed")
+	assets := fs.Bool("assets", false, "List each release's assets with their size and downloads")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	failures := 0
+	for _, project := range projects {
+		releases, err := getReleases(ctx, project, *limit)
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("\n%s (%s)\n", qc.ColorizeBold(project.Name, qc.ColorWhite), qc.Colorize(project.Platform, colorPlatform(project.Platform)))
+		if err != nil {
+			fmt.Printf("  %s Failed to get releases: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			continue
+		}
+		if len(releases) == 0 {
+			fmt.Printf("  %s No releases or tags found\n", qc.Colorize("Info:", qc.ColorCyan))
+			continue
+		}
+
+		width := 0
+		for _, release := range releases {
+			width = max(width, len(release.Tag))
+		}
+		shown := 0
+		for _, release := range releases {
+			// Tag pushes run with the tag as their branch
+			runs, err := getWorkflowRunsForProject(ctx, project, release.Tag, 20)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				fmt.Printf("  %s Failed to get runs for %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), release.Tag, describeError(err))
+			}
+			build := newReleaseBuild(runs)
+			broken := build.failed()
+			if broken {
+				failures++
+			} else if *failed {
+				continue
+			}
+
+			rowColor := qc.AlternatingColor(shown, qc.ColorWhite, qc.ColorCyan)
+			if broken {
+				rowColor = qc.ColorRed
+			}
+			shown++
+
+			var details []string
+			if release.Name != "" && release.Name != release.Tag {
+				details = append(details, release.Name)
+			}
+			if release.Draft {
+				details = append(details, "draft")
+			}
+			if release.Prerelease {
+				details = append(details, "pre-release")
+			}
+			if !release.CreatedAt.IsZero() {
+				details = append(details, formatRunTime(release.CreatedAt))
+			}
+			if len(release.Assets) > 0 {
+				details = append(details, fmt.Sprintf("%d asset(s)", len(release.Assets)))
+			}
+			entry := fmt.Sprintf("  %-*s", width, release.Tag)
+			fmt.Printf("%s  %s  %s\n", qc.Colorize(entry, rowColor), build.summary(), strings.Join(details, ", "))
+
+			if *assets {
+				for _, asset := range release.Assets {
+					fmt.Printf("  %-*s    %s (%s, %d downloads)\n", width, "", asset.Name, formatSize(asset.Size), asset.Downloads)
+				}
+			}
+		}
+		if *failed && shown == 0 {
+			fmt.Printf("  %s No failed release builds\n", qc.Colorize("Info:", qc.ColorCyan))
+		}
+	}
+
+	if failures > 0 {
+		fmt.Printf("\n%s %d release build(s) failed\n", qc.Colorize("Warning:", qc.ColorYellow), failures)
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// runBadge is how a run's outcome is shown in reports
+type runBadge struct {
+	Label string
+	// Class is the CSS class of HTML badges: success, failure, running,
+	// cancelled, or neutral
+	Class string
+	Emoji string
+}
+
+// badgeForRun classifies a run by its conclusion once finished, or its
+// status while it runs
+func badgeForRun(run WorkflowRun) runBadge {
+	label := run.Status
+	if run.Status == "completed" && run.Conclusion != "" {
+		label = run.Conclusion
+	}
+	switch {
+	case label == "success":
+		return runBadge{label, "success", "✅"}
+	case isFailedConclusion(label):
+		return runBadge{label, "failure", "❌"}
+	case label == "cancelled" || label == "canceled":
+		return runBadge{label, "cancelled", "⛔"}
+	case !isRunFinished(run.Status):
+		return runBadge{label, "running", "🟡"}
+	default:
+		return runBadge{label, "neutral", "⚪"}
+	}
+}
+
+// reportSummary counts runs by badge class
+type reportSummary struct {
+	Total     int
+	Success   int
+	Failure   int
+	Running   int
+	Cancelled int
+}
+
+// summarizeReport counts the runs of a report by outcome
+func summarizeReport(runs []WorkflowRun) reportSummary {
+	summary := reportSummary{Total: len(runs)}
+	for _, run := range runs {
+		switch badgeForRun(run).Class {
+		case "success":
+			summary.Success++
+		case "failure":
+			summary.Failure++
+		case "running":
+			summary.Running++
+		case "cancelled":
+			summary.Cancelled++
+		}
+	}
+	return summary
+}
+
+// reportHeader returns the table header of a run column
+func reportHeader(column string) string {
+	switch column {
+	case "id":
+		return "ID"
+	case "url":
+		return "URL"
+	default:
+		return strings.ToUpper(column[:1]) + column[1:]
+	}
+}
+
+// escapeMarkdownCell keeps a value from breaking a Markdown table row
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// renderMarkdownReport renders runs as a Markdown table with a summary
+func renderMarkdownReport(title string, generated time.Time, runs []WorkflowRun, columns []string) string {
+	var b strings.Builder
+	summary := summarizeReport(runs)
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "Generated %s. %d run(s): %d succeeded, %d failed, %d running, %d cancelled.\n\n",
+		formatAbsoluteTime(generated), summary.Total, summary.Success, summary.Failure, summary.Running, summary.Cancelled)
+
+	headers := make([]string, len(columns))
+	rule := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = reportHeader(column)
+		rule[i] = "---"
+	}
+	fmt.Fprintf(&b, "| %s |\n| %s |\n", strings.Join(headers, " | "), strings.Join(rule, " | "))
+
+	for _, run := range runs {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			switch column {
+			case "status":
+				badge := badgeForRun(run)
+				cells[i] = fmt.Sprintf("%s %s", badge.Emoji, badge.Label)
+				if run.URL != "" {
+					cells[i] = fmt.Sprintf("[%s %s](%s)", badge.Emoji, badge.Label, run.URL)
+				}
+			case "url":
+				if run.URL != "" {
+					cells[i] = fmt.Sprintf("<%s>", run.URL)
+				}
+			default:
+				cells[i] = escapeMarkdownCell(runColumns[column].value(run))
+			}
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+	}
+	return b.String()
+}
+
+// reportTemplate is a standalone HTML page with inline styles so it can be
+// attached or opened without other files
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 6px 10px; border-bottom: 1px solid #d0d7de; white-space: nowrap; }
+th { background: #f6f8fa; }
+tr:nth-child(even) td { background: #fafbfc; }
+.summary { color: #59636e; }
+.badge { display: inline-block; padding: 2px 8px; border-radius: 10px; font-size: 0.85em; color: #fff; text-decoration: none; }
+.success { background: #1a7f37; }
+.failure { background: #cf222e; }
+.running { background: #9a6700; }
+.cancelled { background: #6e7781; }
+.neutral { background: #8c959f; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="summary">Generated {{.Generated}}. {{.Summary.Total}} run(s): {{.Summary.Success}} succeeded, {{.Summary.Failure}} failed, {{.Summary.Running}} running, {{.Summary.Cancelled}} cancelled.</p>
+<table>
+<thead><tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{if .Badge}}{{if .URL}}<a class="badge {{.Badge.Class}}" href="{{.URL}}">{{.Badge.Label}}</a>{{else}}<span class="badge {{.Badge.Class}}">{{.Badge.Label}}</span>{{end}}{{else if .URL}}<a href="{{.URL}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// reportCell is one cell of the HTML report
+type reportCell struct {
+	Text  string
+	URL   string
+	Badge *runBadge
+}
+
+// renderHTMLReport renders runs as a standalone HTML page with a summary
+func renderHTMLReport(title string, generated time.Time, runs []WorkflowRun, columns []string) (string, error) {
+	data := struct {
+		Title     string
+		Generated string
+		Summary   reportSummary
+		Headers   []string
+		Rows      [][]reportCell
+	}{
+		Title:     title,
+		Generated: formatAbsoluteTime(generated),
+		Summary:   summarizeReport(runs),
+	}
+	for _, column := range columns {
+		data.Headers = append(data.Headers, reportHeader(column))
+	}
+	for _, run := range runs {
+		row := make([]reportCell, len(columns))
+		for i, column := range columns {
+			switch column {
+			case "status":
+				badge := badgeForRun(run)
+				row[i] = reportCell{URL: run.URL, Badge: &badge}
+			case "url":
+				row[i] = reportCell{Text: run.URL, URL: run.URL}
+			default:
+				row[i] = reportCell{Text: runColumns[column].value(run)}
+			}
+		}
+		data.Rows = append(data.Rows, row)
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// generateReport renders a snapshot of recent runs, filtered like a watch
+// layout, as Markdown or a standalone HTML page
+func generateReport(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "", "Output format: md or html (default: from --out, else md)")
+	out := fs.String("out", "", "Write the report to a file instead of stdout")
+	title := fs.String("title", "CI report", "Report title")
+	layoutName := fs.String("layout", "", "Use the filters and columns of a named layout")
+	projectList := fs.String("projects", "", "Comma separated projects to include")
+	branchList := fs.String("branches", "", "Comma separated branches to include")
+	statusList := fs.String("statuses", "", "Comma separated statuses or conclusions to include")
+	columnSpec := fs.String("columns", "", "Comma separated columns: "+strings.Join(columnNames(), ","))
+	since := fs.Duration("since", 0, "Only runs created within this long, e.g. 24h")
+	limit := fs.Int("limit", 20, "Number of recent runs to fetch per project")
+	fs.Parse(args)
+
+	if *format == "" {
+		*format = "md"
+		if ext := strings.ToLower(filepath.Ext(*out)); ext == ".html" || ext == ".htm" {
+			*format = "html"
+		}
+	}
+	if *format != "md" && *format != "html" {
+		fmt.Printf("%s Unsupported format: %s (expected md or html)\n", qc.Colorize("Error:", qc.ColorRed), *format)
+		return
+	}
+
+	layout := WatchLayout{}
+	if *layoutName != "" {
+		l, ok := config.Settings.Layouts[*layoutName]
+		if !ok {
+			fmt.Printf("%s Layout not found: %s\n", qc.Colorize("Error:", qc.ColorRed), *layoutName)
+			return
+		}
+		layout = l
+	}
+	if *projectList != "" {
+		layout.Projects = splitList(*projectList)
+	}
+	if *branchList != "" {
+		layout.Branches = splitList(*branchList)
+	}
+	if *statusList != "" {
+		layout.Statuses = splitList(*statusList)
+	}
+	if *columnSpec != "" {
+		layout.Columns = *columnSpec
+	}
+	columns, err := parseColumns(layout.Columns, config.Settings)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	projects := layout.selectProjects(config.Projects)
+	if len(projects) == 0 {
+		fmt.Printf("%s No tracked projects to report on\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	runs, failures := collectRuns(ctx, config, projects, *limit)
+	printFetchFailures(os.Stdout, failures)
+	runs = layout.filterRuns(runs)
+	if *since > 0 {
+		var recent []WorkflowRun
+		for _, run := range runs {
+			if time.Since(run.CreatedAt) <= *since {
+				recent = append(recent, run)
+			}
+		}
+		runs = recent
+	}
+	if needsQueueTimes(columns, 0) {
+		fillQueueTimes(ctx, runs)
+	}
+	layout.sortRuns(runs)
+
+	var report string
+	generated := time.Now()
+	if *format == "html" {
+		report, err = renderHTMLReport(*title, generated, runs, columns)
+		if err != nil {
+			fmt.Printf("%s Failed to render report: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	} else {
+		report = renderMarkdownReport(*title, generated, runs, columns)
+	}
+
+	if *out == "" {
+		fmt.Print(report)
+		return
+	}
+	if err := writeFileAtomic(*out, []byte(report), 0644); err != nil {
+		fmt.Printf("%s Failed to write report: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Wrote a report of %d run(s) to %s\n", qc.Colorize("Success:", qc.ColorGreen), len(runs), *out)
+}
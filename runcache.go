@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// staleCacheRuns is how many recent runs of each project are cached to
+// show in place of fresh ones while fetching them fails
+const staleCacheRuns = 20
+
+// fetchFailure is a project whose runs couldn't be fetched, and why
+type fetchFailure struct {
+	Project string
+	Err     error
+	// StaleAt is when the cached runs shown instead were fetched, zero
+	// when there were none
+	StaleAt time.Time
+}
+
+// cachedRuns are the last runs fetched of a project, and how fetching
+// them has failed since
+type cachedRuns struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Runs      []WorkflowRun `json:"runs"`
+	// Failures counts fetches failing in a row since FailingSince, the
+	// last one for FailureKind
+	Failures     int        `json:"failures,omitempty"`
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	FailureKind  ErrorKind  `json:"failure_kind,omitempty"`
+}
+
+// runCacheFile returns the path of the cache of each project's last
+// fetched runs, kept next to the state file
+func runCacheFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "runs-cache.json")
+}
+
+// readRunCache reads the cached runs by project name. A missing or
+// unreadable cache is empty.
+func readRunCache(config *Config) map[string]cachedRuns {
+	cache := make(map[string]cachedRuns)
+	if data, err := os.ReadFile(runCacheFile(config)); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// cacheRuns stores the newest runs fetched of each project, by project
+// name, and counts the failures of the projects that couldn't be fetched
+func cacheRuns(config *Config, fetched map[string][]WorkflowRun, failures []fetchFailure, at time.Time) error {
+	if len(fetched) == 0 && len(failures) == 0 {
+		return nil
+	}
+	cache := readRunCache(config)
+	for name, runs := range fetched {
+		if len(runs) > staleCacheRuns {
+			runs = runs[:staleCacheRuns]
+		}
+		cache[name] = cachedRuns{FetchedAt: at, Runs: runs}
+	}
+	for _, failure := range failures {
+		cached := cache[failure.Project]
+		if cached.FailingSince == nil {
+			cached.FailingSince = &at
+		}
+		cached.Failures++
+		cached.FailureKind = ""
+		if providerErr := classifyError(failure.Err); providerErr != nil {
+			cached.FailureKind = providerErr.Kind
+		}
+		cache[failure.Project] = cached
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(runCacheFile(config), data, 0600)
+}
+
+// staleRuns returns the cached runs of the projects that failed, marked
+// stale, and notes on each failure when its cached runs are from
+func staleRuns(config *Config, failures []fetchFailure) []WorkflowRun {
+	if len(failures) == 0 {
+		return nil
+	}
+	cache := readRunCache(config)
+	var runs []WorkflowRun
+	for i, failure := range failures {
+		cached, ok := cache[failure.Project]
+		if !ok || len(cached.Runs) == 0 {
+			continue
+		}
+		failures[i].StaleAt = cached.FetchedAt
+		for _, run := range cached.Runs {
+			run.StaleAt = cached.FetchedAt
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+// staleMarker describes how old a stale run is, e.g. "stale (2m old)"
+func staleMarker(at time.Time) string {
+	return fmt.Sprintf("stale (%s old)", formatDuration(time.Since(at)))
+}
+
+// printFetchFailures lists the projects whose runs couldn't be fetched and
+// why, noting the ones shown from the cache
+func printFetchFailures(w io.Writer, failures []fetchFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", qc.Colorize(fmt.Sprintf("Failed to load %d project(s):", len(failures)), qc.ColorRed))
+	for _, failure := range failures {
+		line := fmt.Sprintf("  %s: %s", failure.Project, describeError(failure.Err))
+		if !failure.StaleAt.IsZero() {
+			line += qc.Colorize(fmt.Sprintf(" (showing runs from %s ago)", formatDuration(time.Since(failure.StaleAt))), qc.ColorYellow)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// collectRunners lists the self-hosted runners of the given projects,
+// including each GitHub owner's organization runners once. Runners seen
+// through several projects, like GitLab group runners, are listed once.
+func collectRunners(ctx context.Context, projects []Project) ([]SelfHostedRunner, []string) {
+	var runners []SelfHostedRunner
+	var warnings []string
+	seen := make(map[string]bool)
+	add := func(account string, found []SelfHostedRunner) {
+		for _, runner := range found {
+			key := account + "/" + runner.Platform + "/" + runner.ID
+			if !seen[key] {
+				seen[key] = true
+				runners = append(runners, runner)
+			}
+		}
+	}
+
+	// Projects of the same account share a client, and an account that
+	// can't log in is skipped after one warning
+	githubClients := make(map[string]*GitHubClient)
+	gitlabClients := make(map[string]*GitLabClient)
+	failed := make(map[string]bool)
+	orgs := make(map[string]bool)
+	for _, project := range projects {
+		account := projectAccounts[project.Name]
+		skipping := "Skipping GitHub projects"
+		if account != "" {
+			skipping = fmt.Sprintf("Skipping projects of account %s", account)
+		}
+		switch project.Platform {
+		case "github":
+			client := githubClients[account]
+			if client == nil {
+				if failed["github/"+account] {
+					continue
+				}
+				var err error
+				if client, err = NewGitHubClient(ctx, project.Name); err != nil {
+					failed["github/"+account] = true
+					warnings = append(warnings, fmt.Sprintf("%s: %s", skipping, describeError(err)))
+					continue
+				}
+				githubClients[account] = client
+			}
+			found, err := client.GetRunners(project.Owner, project.Repo)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Skipping %s: %s", project.Name, describeError(err)))
+			}
+			add(account, found)
+
+			if !orgs[account+"/"+project.Owner] {
+				orgs[account+"/"+project.Owner] = true
+				// User accounts have no organization runners, and listing
+				// them needs an organization admin, so failures are quiet
+				if found, err := client.GetOrganizationRunners(project.Owner); err == nil {
+					add(account, found)
+				}
+			}
+		case "gitlab":
+			if account == "" {
+				skipping = "Skipping GitLab projects"
+			}
+			client := gitlabClients[account]
+			if client == nil {
+				if failed["gitlab/"+account] {
+					continue
+				}
+				var err error
+				if client, err = NewGitLabClient(ctx, project.Name); err != nil {
+					failed["gitlab/"+account] = true
+					warnings = append(warnings, fmt.Sprintf("%s: %s", skipping, describeError(err)))
+					continue
+				}
+				gitlabClients[account] = client
+			}
+			found, err := client.GetProjectRunners(project.Name)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Skipping %s: %s", project.Name, describeError(err)))
+			}
+			add(account, found)
+		}
+	}
+	return runners, warnings
+}
+
+// runnerOnline reports whether a runner is connected and accepting jobs
+func runnerOnline(runner SelfHostedRunner) bool {
+	return runner.Status == "online"
+}
+
+// colorRunnerState colors a runner's state padded to a fixed width: busy,
+// idle, or its status when it isn't online
+func colorRunnerState(runner SelfHostedRunner) string {
+	switch {
+	case !runnerOnline(runner):
+		return qc.Colorize(fmt.Sprintf("%-15s", runner.Status), qc.ColorRed)
+	case runner.Busy:
+		return qc.Colorize(fmt.Sprintf("%-15s", "busy"), qc.ColorYellow)
+	default:
+		return qc.Colorize(fmt.Sprintf("%-15s", "idle"), qc.ColorGreen)
+	}
+}
+
+// showRunners lists self-hosted runners across tracked projects with their
+// status, labels, and whether they are running a job
+func showRunners(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("runners", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "Only show runners that are not online")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	runners, warnings := collectRunners(ctx, projects)
+	for _, warning := range warnings {
+		fmt.Printf("%s %s\n", qc.Colorize("Warning:", qc.ColorYellow), warning)
+	}
+
+	online, busy := 0, 0
+	for _, runner := range runners {
+		if runnerOnline(runner) {
+			online++
+			if runner.Busy {
+				busy++
+			}
+		}
+	}
+
+	shown := runners
+	if *offline {
+		shown = nil
+		for _, runner := range runners {
+			if !runnerOnline(runner) {
+				shown = append(shown, runner)
+			}
+		}
+	}
+	if len(shown) == 0 {
+		if *offline && len(runners) > 0 {
+			fmt.Printf("%s All %d runner(s) are online\n", qc.Colorize("Info:", qc.ColorCyan), len(runners))
+		} else {
+			fmt.Printf("%s No self-hosted runners found\n", qc.Colorize("Info:", qc.ColorCyan))
+		}
+		return
+	}
+
+	// Offline runners first, then by scope and name
+	sort.SliceStable(shown, func(i, j int) bool {
+		if a, b := runnerOnline(shown[i]), runnerOnline(shown[j]); a != b {
+			return !a
+		}
+		if shown[i].Scope != shown[j].Scope {
+			return shown[i].Scope < shown[j].Scope
+		}
+		return shown[i].Name < shown[j].Name
+	})
+
+	nameWidth, scopeWidth := 4, 5
+	for _, runner := range shown {
+		nameWidth = max(nameWidth, len(runner.Name))
+		scopeWidth = max(scopeWidth, len(runner.Scope))
+	}
+
+	fmt.Printf("%s\n", qc.Colorize("Self-hosted runners:", qc.ColorBlue))
+	for i, runner := range shown {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		entry := fmt.Sprintf("%3d. %-6s  %-*s  %-*s  %-7s", i+1, runner.Platform, nameWidth, runner.Name, scopeWidth, runner.Scope, runner.OS)
+		fmt.Printf("%s  %s %s\n", qc.Colorize(entry, rowColor), colorRunnerState(runner), strings.Join(runner.Labels, ", "))
+	}
+
+	fmt.Printf("\n%s %d runner(s): %d online (%d busy), %d not online\n",
+		qc.Colorize("Summary:", qc.ColorBlue), len(runners), online, busy, len(runners)-online)
+}
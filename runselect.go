@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// parseRunNumbers parses run numbers typed at a prompt, such as 1,3,5-8,
+// into indexes of the n runs listed, in the order given without repeats
+func parseRunNumbers(input string, n int) ([]int, error) {
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, field := range splitList(input) {
+		first, last := field, field
+		if from, to, ok := strings.Cut(field, "-"); ok {
+			first, last = strings.TrimSpace(from), strings.TrimSpace(to)
+		}
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run number: %s", field)
+		}
+		end, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run number: %s", field)
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("no runs %s (runs are numbered 1-%d)", field, n)
+		}
+		for number := start; number <= end; number++ {
+			if !seen[number] {
+				seen[number] = true
+				indexes = append(indexes, number-1)
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("no runs selected")
+	}
+	return indexes, nil
+}
+
+// runPromptAction is an action offered next to the runs when picking them:
+// typed as its key at the numbered prompt, or chosen by its label in the
+// finder
+type runPromptAction struct {
+	key   string
+	label string
+}
+
+// pickRuns asks the user to select one or more of the listed runs, with
+// the finder or by number, returning their indexes, or the key of an
+// action chosen instead. ok is false when the user quits.
+func pickRuns(reader *bufio.Reader, runs []WorkflowRun, columns []string, actions []runPromptAction) (indexes []int, action string, ok bool) {
+	if useFinder() {
+		widths := layoutColumns(runs, columns, terminalWidth())
+		items := make([]string, len(runs), len(runs)+len(actions))
+		for i, run := range runs {
+			items[i] = formatRunEntry(run, columns, widths)
+		}
+		// Actions follow the runs, found by typing their name like a run
+		for _, action := range actions {
+			items = append(items, "» "+action.label)
+		}
+		fmt.Println()
+		chosen, ok := pickMany("Runs", items)
+		if !ok {
+			return nil, "", false
+		}
+		for _, index := range chosen {
+			if index < len(runs) {
+				indexes = append(indexes, index)
+			}
+		}
+		// An action counts when it is all that was chosen
+		if len(indexes) == 0 {
+			return nil, actions[chosen[0]-len(runs)].key, true
+		}
+		return indexes, "", true
+	}
+
+	options := []string{"numbers like 1,3,5-8"}
+	for _, action := range actions {
+		options = append(options, fmt.Sprintf("'%s' to %s", action.key, action.label))
+	}
+	fmt.Printf("%s", qc.Colorize(fmt.Sprintf("Select workflow runs for details (%s, or 'q' to quit): ", strings.Join(options, ", ")), qc.ColorYellow))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		log.Fatal(err)
+	}
+	input = strings.TrimSpace(input)
+	if input == "q" || input == "" {
+		return nil, "", false
+	}
+	for _, action := range actions {
+		if input == action.key {
+			return nil, action.key, true
+		}
+	}
+
+	indexes, err = parseRunNumbers(input, len(runs))
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return nil, "", false
+	}
+	return indexes, "", true
+}
+
+// showSelectedRuns opens the details of one selected run, with the prompts
+// to act on it, or asks what to do with several: show their details,
+// cancel or re-run them, or export them to a file
+func showSelectedRuns(ctx context.Context, config *Config, reader *bufio.Reader, runs []WorkflowRun, columns []string, logLines int) {
+	if len(runs) == 1 {
+		showSelectedRun(ctx, config, reader, runs[0], logLines)
+		return
+	}
+
+	fmt.Printf("\n%s", qc.Colorize(fmt.Sprintf("Action for %d runs (d details, c cancel, r re-run, e export, Enter to quit): ", len(runs)), qc.ColorYellow))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+	switch strings.TrimSpace(input) {
+	case "", "q":
+	case "d":
+		for i, run := range runs {
+			fmt.Printf("\n%s\n", qc.ColorizeBold(fmt.Sprintf("Run %d of %d", i+1, len(runs)), qc.ColorWhite))
+			showWorkflowDetails(ctx, config, completeRun(ctx, config, run), logLines)
+		}
+	case "c":
+		applySelected(ctx, config, reader, cancelOperation, runs)
+	case "r":
+		applySelected(ctx, config, reader, rerunOperation(false), runs)
+	case "e":
+		exportSelected(reader, runs, columns)
+	default:
+		fmt.Printf("%s Enter d, c, r, or e\n", qc.Colorize("Error:", qc.ColorRed))
+	}
+}
+
+// applySelected cancels or re-runs the selected runs it applies to,
+// skipping the rest
+func applySelected(ctx context.Context, config *Config, reader *bufio.Reader, op runOperation, runs []WorkflowRun) {
+	var eligible []WorkflowRun
+	for _, run := range runs {
+		if ok, reason := op.eligible(run); !ok {
+			fmt.Printf("%s Skipping %s run %s: %s\n", qc.Colorize("Warning:", qc.ColorYellow), run.Project, run.ID, reason)
+			continue
+		}
+		eligible = append(eligible, run)
+	}
+	if len(eligible) == 0 {
+		fmt.Printf("%s No runs to %s\n", qc.Colorize("Info:", qc.ColorCyan), strings.ToLower(op.verb))
+		return
+	}
+	fmt.Println()
+	confirmAndApply(ctx, reader, config, op, eligible, false, false)
+}
+
+// exportSelected writes the selected runs to a CSV file, or TSV for a .tsv
+// file, with the listed columns
+func exportSelected(reader *bufio.Reader, runs []WorkflowRun, columns []string) {
+	fmt.Printf("%s", qc.Colorize("Export to (.csv or .tsv file, Enter for runs.csv): ", qc.ColorYellow))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+	path := firstNonEmpty(strings.TrimSpace(input), "runs.csv")
+	format := "csv"
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		format = "tsv"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("%s Failed to export runs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	err = writeRunsDelimited(f, format, runs, columns)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("%s Failed to export runs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Exported %d runs to %s\n", qc.Colorize("Success:", qc.ColorGreen), len(runs), path)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// parseRunURL reads the project and run ID from a pasted run URL: a GitHub
+// run (owner/repo/actions/runs/123, also a job or attempt of it) or a GitLab
+// pipeline (group/project/-/pipelines/456), on any host. Other URLs match
+// tracked projects' runs at <project>/runs/<id>, as the mock provider
+// links them.
+func parseRunURL(config *Config, raw string) (*Project, string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, "", false
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	host := u.Scheme + "://" + u.Host
+
+	// GitLab paths mark where the project ends with a "-" segment
+	for i, segment := range segments {
+		if segment == "-" && i >= 2 && i+2 < len(segments) && segments[i+1] == "pipelines" {
+			name := strings.Join(segments[:i], "/")
+			return &Project{
+				Name:      name,
+				Owner:     segments[0],
+				Repo:      segments[i-1],
+				Platform:  "gitlab",
+				RemoteURL: host + "/" + name + ".git",
+			}, segments[i+2], true
+		}
+	}
+
+	if len(segments) >= 5 && segments[2] == "actions" && segments[3] == "runs" {
+		return &Project{
+			Name:      segments[0] + "/" + segments[1],
+			Owner:     segments[0],
+			Repo:      segments[1],
+			Platform:  "github",
+			RemoteURL: host + "/" + segments[0] + "/" + segments[1] + ".git",
+		}, segments[4], true
+	}
+
+	for i, segment := range segments {
+		if segment == "runs" && i > 0 && i+1 < len(segments) {
+			if project := findProject(config, strings.Join(segments[:i], "/")); project != nil {
+				return project, segments[i+1], true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// resolveRun finds the project and ID of a run given by ID, or by a pasted
+// run URL. A URL of an untracked project is used as is, without adding the
+// project.
+func resolveRun(config *Config, projectName, ref string) (*Project, string, error) {
+	project, runID, ok := parseRunURL(config, ref)
+	if !ok {
+		if strings.Contains(ref, "://") {
+			return nil, "", fmt.Errorf("not a GitHub run or GitLab pipeline URL: %s", ref)
+		}
+		project, err := resolveRunProject(config, projectName, ref)
+		return project, ref, err
+	}
+
+	if tracked := findProject(config, project.Name); tracked != nil && tracked.Platform == project.Platform {
+		return tracked, runID, nil
+	}
+	account, _, err := remoteAccount("", project.RemoteURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if host := remoteHost(project.RemoteURL); account == "" && project.Platform == "github" && host != "github.com" {
+		return nil, "", fmt.Errorf("no account for %s. Run 'quick_workflow login github --account <name> %s' to add one", host, host)
+	}
+	project.Account = account
+	registerProjectAccounts([]Project{*project})
+	fmt.Fprintf(os.Stderr, "%s %s isn't tracked; run 'quick_workflow add' in its repository to track it\n", qc.Colorize("Info:", qc.ColorCyan), project.Name)
+	return project, runID, nil
+}
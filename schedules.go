@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// PipelineSchedule is a GitLab pipeline schedule: a cron expression that
+// starts pipelines on a ref with variables
+type PipelineSchedule struct {
+	ID          int
+	Description string
+	Ref         string
+	Cron        string
+	Timezone    string
+	Active      bool
+	NextRunAt   *time.Time
+	Owner       string
+	// Variables are only filled in for a single schedule
+	Variables map[string]string
+}
+
+// scheduleChanges are the fields of a schedule to create or edit; nil
+// fields are left as they are
+type scheduleChanges struct {
+	Description *string
+	Ref         *string
+	Cron        *string
+	Timezone    *string
+	Active      *bool
+	Variables   map[string]string
+	Unset       []string
+}
+
+// empty reports whether there is nothing to change
+func (c scheduleChanges) empty() bool {
+	return c.Description == nil && c.Ref == nil && c.Cron == nil && c.Timezone == nil && c.Active == nil &&
+		len(c.Variables) == 0 && len(c.Unset) == 0
+}
+
+// validateCron checks that a cron expression has the five fields GitLab
+// expects
+func validateCron(cron string) error {
+	if len(strings.Fields(cron)) != 5 {
+		return fmt.Errorf("cron %q must have five fields: minute, hour, day of month, month, and day of week", cron)
+	}
+	return nil
+}
+
+// scheduleProjects resolves comma separated project names to GitLab
+// projects
+func scheduleProjects(config *Config, names string) ([]Project, error) {
+	var projects []Project
+	for _, name := range splitList(names) {
+		project := findProject(config, name)
+		if project == nil {
+			return nil, fmt.Errorf("project not found: %s", name)
+		}
+		if project.Platform != "gitlab" {
+			return nil, fmt.Errorf("%s is on %s; pipeline schedules are only supported on GitLab", project.Name, platformName(project.Platform))
+		}
+		projects = append(projects, *project)
+	}
+	return projects, nil
+}
+
+// findSchedule finds a schedule of a project by ID or exact description,
+// so one name can pick the same schedule across projects
+func findSchedule(client *GitLabClient, project Project, ref string) (*PipelineSchedule, error) {
+	schedules, err := client.GetPipelineSchedules(project.Name)
+	if err != nil {
+		return nil, err
+	}
+	id, idErr := strconv.Atoi(ref)
+	var matches []PipelineSchedule
+	for _, schedule := range schedules {
+		if (idErr == nil && schedule.ID == id) || schedule.Description == ref {
+			matches = append(matches, schedule)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no schedule %q in %s", ref, project.Name)
+	case 1:
+		return client.GetPipelineSchedule(project.Name, matches[0].ID)
+	default:
+		return nil, fmt.Errorf("%d schedules of %s are described %q; use the ID", len(matches), project.Name, ref)
+	}
+}
+
+// handleSchedules handles the schedules command for GitLab pipeline
+// schedules: list, create, edit, run, and delete, each across one or more
+// projects
+func handleSchedules(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow schedules list|create|edit|run|delete ...\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	fs := flag.NewFlagSet("schedules "+args[0], flag.ExitOnError)
+	cron := fs.String("cron", "", "Cron expression, e.g. \"0 2 * * *\" for 2am daily")
+	timezone := fs.String("timezone", "", "Time zone of the cron expression, e.g. Europe/Berlin (default: UTC)")
+	ref := fs.String("ref", "", "Branch or tag to run on (default: the project's default branch)")
+	description := fs.String("description", "", "What the schedule is for, e.g. Nightly build")
+	active := fs.Bool("active", true, "Whether the schedule starts pipelines")
+	variables := variableFlags{}
+	fs.Var(variables, "var", "Pipeline variable as KEY=VALUE; repeatable")
+	unset := fs.String("unset-var", "", "Comma separated variables to remove (edit)")
+	fs.Parse(args[1:])
+
+	// Only flags that were given change a schedule
+	changes := scheduleChanges{Variables: variables, Unset: splitList(*unset)}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cron":
+			changes.Cron = cron
+		case "timezone":
+			changes.Timezone = timezone
+		case "ref":
+			changes.Ref = ref
+		case "description":
+			changes.Description = description
+		case "active":
+			changes.Active = active
+		}
+	})
+	if changes.Cron != nil {
+		if err := validateCron(*changes.Cron); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		listSchedules(ctx, config, fs.Args())
+	case "create":
+		if fs.NArg() != 1 || changes.Cron == nil || changes.Description == nil {
+			fmt.Printf("%s Usage: quick_workflow schedules create --cron <expr> --description <text> [--ref <branch>] [--timezone <zone>] [--var KEY=VALUE]... [--active=false] <project>[,<project>...]\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		projects, err := scheduleProjects(config, fs.Arg(0))
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+		for _, project := range projects {
+			createSchedule(ctx, config, project, changes)
+		}
+	case "edit", "run", "delete":
+		if fs.NArg() != 2 {
+			fmt.Printf("%s Usage: quick_workflow schedules %s <project>[,<project>...] <schedule-id-or-description>\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+			return
+		}
+		if args[0] == "edit" && changes.empty() {
+			fmt.Printf("%s Nothing to change; pass --cron, --ref, --description, --timezone, --active, --var, or --unset-var\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		projects, err := scheduleProjects(config, fs.Arg(0))
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+		if args[0] == "delete" && !confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("Delete schedule %q from %d project(s)?", fs.Arg(1), len(projects))) {
+			fmt.Printf("%s Cancelled\n", qc.Colorize("Info:", qc.ColorCyan))
+			return
+		}
+		for _, project := range projects {
+			changeSchedule(ctx, config, project, args[0], fs.Arg(1), changes)
+		}
+	default:
+		fmt.Printf("%s Unknown schedules command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+		fmt.Println("  Commands: list, create, edit, run, delete")
+	}
+}
+
+// createSchedule creates a schedule in a project, on its default branch
+// unless a ref was given
+func createSchedule(ctx context.Context, config *Config, project Project, changes scheduleChanges) {
+	if changes.Ref == nil {
+		branch, err := resolveDefaultBranch(ctx, project)
+		if err != nil {
+			fmt.Printf("%s Failed to find the default branch of %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, describeError(err))
+			return
+		}
+		changes.Ref = &branch
+	}
+
+	client, err := NewGitLabClient(ctx, project.Name)
+	var schedule *PipelineSchedule
+	if err == nil {
+		schedule, err = client.CreatePipelineSchedule(project.Name, changes)
+	}
+	result := ""
+	if schedule != nil {
+		result = fmt.Sprintf("created schedule %d", schedule.ID)
+	}
+	recordAction(config, "schedule create", project.Name, scheduleParameters(changes), result, err)
+	if err != nil {
+		fmt.Printf("%s Failed to create the schedule in %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, describeError(err))
+		return
+	}
+	fmt.Printf("%s Created schedule %d '%s' in %s (%s on %s, next run %s)\n", qc.Colorize("Success:", qc.ColorGreen),
+		schedule.ID, schedule.Description, project.Name, schedule.Cron, schedule.Ref, formatNextRun(schedule))
+}
+
+// changeSchedule edits, runs, or deletes a schedule of a project, found by
+// ID or description
+func changeSchedule(ctx context.Context, config *Config, project Project, action, ref string, changes scheduleChanges) {
+	client, err := NewGitLabClient(ctx, project.Name)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	schedule, err := findSchedule(client, project, ref)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	parameters := map[string]string{"schedule": strconv.Itoa(schedule.ID)}
+	var done string
+	switch action {
+	case "edit":
+		for key, value := range scheduleParameters(changes) {
+			parameters[key] = value
+		}
+		err = client.EditPipelineSchedule(project.Name, schedule.ID, changes, schedule.Variables)
+		done = "Updated"
+	case "run":
+		err = client.RunPipelineSchedule(project.Name, schedule.ID)
+		done = "Started a pipeline of"
+	case "delete":
+		err = client.DeletePipelineSchedule(project.Name, schedule.ID)
+		done = "Deleted"
+	}
+	recordAction(config, "schedule "+action, project.Name, parameters, strings.ToLower(done)+" schedule "+strconv.Itoa(schedule.ID), err)
+	if err != nil {
+		fmt.Printf("%s Failed to %s schedule %d in %s: %v\n", qc.Colorize("Error:", qc.ColorRed), action, schedule.ID, project.Name, describeError(err))
+		return
+	}
+	fmt.Printf("%s %s schedule %d '%s' in %s\n", qc.Colorize("Success:", qc.ColorGreen), done, schedule.ID, schedule.Description, project.Name)
+}
+
+// scheduleParameters describes schedule changes for the action log.
+// Variable values are left out; they may hold credentials.
+func scheduleParameters(changes scheduleChanges) map[string]string {
+	parameters := make(map[string]string)
+	for name, value := range map[string]*string{"description": changes.Description, "ref": changes.Ref, "cron": changes.Cron, "timezone": changes.Timezone} {
+		if value != nil {
+			parameters[name] = *value
+		}
+	}
+	if changes.Active != nil {
+		parameters["active"] = strconv.FormatBool(*changes.Active)
+	}
+	if len(changes.Variables) > 0 {
+		parameters["variables"] = strings.Join(slices.Sorted(maps.Keys(changes.Variables)), ",")
+	}
+	if len(changes.Unset) > 0 {
+		parameters["unset"] = strings.Join(changes.Unset, ",")
+	}
+	return parameters
+}
+
+// formatNextRun describes when a schedule starts its next pipeline
+func formatNextRun(schedule *PipelineSchedule) string {
+	if !schedule.Active {
+		return "never, inactive"
+	}
+	if schedule.NextRunAt == nil {
+		return "unknown"
+	}
+	return schedule.NextRunAt.In(displayLocation).Format("2006-01-02 15:04")
+}
+
+// listSchedules lists the pipeline schedules of the named projects, or of
+// every tracked GitLab project
+func listSchedules(ctx context.Context, config *Config, names []string) {
+	var projects []Project
+	if len(names) > 0 {
+		var err error
+		if projects, err = scheduleProjects(config, strings.Join(names, ",")); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+	} else {
+		for _, project := range activeProjects(config.Projects) {
+			if project.Platform == "gitlab" {
+				projects = append(projects, project)
+			}
+		}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No tracked GitLab projects\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	var failures []fetchFailure
+	for _, project := range projects {
+		client, err := NewGitLabClient(ctx, project.Name)
+		var schedules []PipelineSchedule
+		if err == nil {
+			schedules, err = client.GetPipelineSchedules(project.Name)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			failures = append(failures, fetchFailure{Project: project.Name, Err: err})
+			continue
+		}
+		if len(schedules) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s\n", qc.Colorize(project.Name+":", qc.ColorBlue))
+		for _, schedule := range schedules {
+			cron := schedule.Cron
+			if schedule.Timezone != "" && schedule.Timezone != "UTC" {
+				cron += " " + schedule.Timezone
+			}
+			state := qc.Colorize("next "+formatNextRun(&schedule), qc.ColorGreen)
+			if !schedule.Active {
+				state = qc.Colorize("inactive", qc.ColorYellow)
+			}
+			fmt.Printf("  %6d  %-30s %-20s %-15s %s\n", schedule.ID, truncate(schedule.Description, 30), cron, truncate(schedule.Ref, 15), state)
+		}
+	}
+	printFetchFailures(os.Stdout, failures)
+}
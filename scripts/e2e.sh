@@ -0,0 +1,103 @@
+#!/bin/bash
+
+# End-to-end checks of quick_workflow against the mock provider. Runs the
+# commands on canned fixtures in a scratch directory, so no tokens or
+# network access are needed.
+
+set -e
+
+# Colors for output
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+NC='\033[0m' # No Color
+
+ROOT=$(cd "$(dirname "$0")/.." && pwd)
+WORK=$(mktemp -d)
+trap 'rm -rf "$WORK"' EXIT
+
+go build -o "$WORK/quick_workflow" "$ROOT"
+cp "$ROOT/scripts/e2e/state.json" "$WORK/state.json"
+cp "$ROOT/scripts/e2e/fixtures.json" "$WORK/fixtures.json"
+export QUICK_WORKFLOW_MOCK_FIXTURES="$WORK/fixtures.json"
+export XDG_CONFIG_HOME="$WORK/config"
+export HOME="$WORK"
+
+failures=0
+
+# qw runs quick_workflow on the scratch state
+qw() {
+    "$WORK/quick_workflow" -state "$WORK/state.json" "$@"
+}
+
+# check runs a command and expects its output to contain a string
+check() {
+    local name=$1
+    local want=$2
+    shift 2
+    local output
+    output=$("$@" 2>&1 | sed 's/\x1b\[[0-9;]*m//g') || true
+    if [[ "$output" == *"$want"* ]]; then
+        echo -e "${GREEN}ok${NC}   $name"
+    else
+        echo -e "${RED}FAIL${NC} $name: output lacks \"$want\""
+        echo "$output" | sed 's/^/     /'
+        failures=$((failures + 1))
+    fi
+}
+
+check "projects lists fixtures" "acme/web" qw projects
+check "list shows the failed run" "fix-timeouts" qw list
+check "list starts with a summary" "3 runs: 0 running, 0 queued, 1 failed, 2 succeeded" qw list
+check "list exports csv" "102,acme/api,CI,completed,failure" qw list --format csv --fields id,project,workflow,status,conclusion
+check "list --causes tags the failed run" "code: test-failure" qw list --causes
+check "list renders templates" "201 Test main" qw list --format template --template '{{.ID}} {{.Workflow}} {{.Branch}}'
+check "list takes flags after the limit" "201 Test main" qw list 50 --format template --template '{{.ID}} {{.Workflow}} {{.Branch}}'
+check "list finds runs by commit" "101 main" qw list --commit 4f2a9c1 --format template --template '{{.ID}} {{.Branch}}'
+check "list greps commit messages" "102 fix-timeouts" qw list --grep 'upstream timeouts' --format template --template '{{.ID}} {{.Branch}}'
+check "check exits 2 when a workflow fails" "exit 2" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' check --quiet; echo exit \$?"
+check "check exits 0 when a branch is green" "exit 0" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' check --quiet --branch main; echo exit \$?"
+check "details shows jobs" "golangci-lint" qw details --project acme/api 101
+check "details shows the failed step log" "FAIL: TestTimeout" qw details --project acme/api 102
+check "logs prints the failed job log" "FAIL: TestTimeout" qw logs --project acme/api 102
+check "logs --timestamps prints line times" "2025-03-03 10:02:35" env TZ=UTC "$WORK/quick_workflow" -state "$WORK/state.json" logs --project acme/api --timestamps 102
+check "logs --download saves every job log" "acme-api-run-102" qw logs --project acme/api --download --dir "$WORK/logs" 102
+check "show takes a run URL" "FAIL: TestTimeout" qw show https://ci.example.com/acme/api/runs/102
+check "details skips jobs after a failure" "build=skipped" qw details --project acme/api --format template --template '{{range .Jobs}}{{.Name}}={{.Conclusion}} {{end}}' 102
+check "watch --run exits 0 for a passed run" "exit 0" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' watch --project acme/api --run 101; echo exit \$?"
+check "watch --run exits 1 for a failed run" "exit 1" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' watch --project acme/api --run 102; echo exit \$?"
+check "badge renders" "failing" qw badge --branch fix-timeouts acme/api
+check "start triggers a run" "Triggered workflow 'Deploy'" bash -c "(echo 1; sleep 0.2; echo 2) | '$WORK/quick_workflow' -state '$WORK/state.json' start"
+check "triggered run is listed" "Deploy" qw list --format template --template '{{.ID}} {{.Workflow}} {{.Status}}'
+check "cancel stops the triggered run" "Canceled acme/api run 103" bash -c "echo y | '$WORK/quick_workflow' -state '$WORK/state.json' cancel --project acme/api 103"
+check "rerun starts a new attempt" "Re-ran acme/api run 102" bash -c "echo y | '$WORK/quick_workflow' -state '$WORK/state.json' rerun --project acme/api 102"
+check "rerun shows the attempt" "102 2" qw list --format template --template '{{.ID}} {{.Attempt}}'
+check "start --dry-run prints the call" "mock start Deploy in acme/api" bash -c "(echo 1; sleep 0.2; echo 2) | '$WORK/quick_workflow' -state '$WORK/state.json' start --dry-run"
+check "cancel --dry-run prints the calls" "mock cancel run" qw cancel --all --dry-run
+check "history records triggers" "trigger" qw history --project acme/api --action trigger
+check "defaults are saved" "Updated defaults of acme/api" qw defaults acme/api --workflow Deploy
+check "start uses the project's defaults" "Triggered workflow 'Deploy' for acme/api" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' start --project acme/api < /dev/null"
+mkdir -p "$WORK/bin"
+printf '#!/bin/sh\ncat > "%s/clipboard"\n' "$WORK" > "$WORK/bin/xclip"
+chmod +x "$WORK/bin/xclip"
+check "details --copy copies the commit" "Copied the commit SHA" env PATH="$WORK/bin:$PATH" WAYLAND_DISPLAY= "$WORK/quick_workflow" -state "$WORK/state.json" details --project acme/api --copy sha 102
+check "the clipboard holds the commit" "9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c" cat "$WORK/clipboard"
+check "list --interactive exports selected runs" "Exported 2 runs" bash -c "printf '1,2\ne\n$WORK/runs.csv\n' | '$WORK/quick_workflow' -state '$WORK/state.json' list --interactive"
+check "workflows disable turns a workflow off" "Disabled workflow 'Deploy' in acme/api" qw workflows disable acme/api Deploy
+check "workflows list shows disabled workflows" "Deploy  disabled manually" qw workflows list --disabled
+qw workflows enable acme/api Deploy > /dev/null
+check "projects --status shows default branch health" "passing" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' projects --status < /dev/null"
+check "projects suggests archiving idle projects" "last run" bash -c "'$WORK/quick_workflow' -state '$WORK/state.json' projects < /dev/null"
+qw archive acme/web > /dev/null
+check "archive hides a project from list" "projects: acme/api." bash -c "echo projects: \$('$WORK/quick_workflow' -state '$WORK/state.json' list --format csv --fields project | tail -n +2 | sort -u | paste -sd, -)."
+qw unarchive acme/web > /dev/null
+echo "not json" > "$WORK/broken.json"
+check "list shows cached runs of projects that fail" "stale (" env QUICK_WORKFLOW_MOCK_FIXTURES="$WORK/broken.json" "$WORK/quick_workflow" -state "$WORK/state.json" list
+check "prune keeps the newest runs of each project" "Would remove" qw prune --runs 1 --dry-run
+check "--offline lists recorded runs without fetching" "stale (" bash -c "QUICK_WORKFLOW_MOCK_FIXTURES=\"$WORK/broken.json\" \"$WORK/quick_workflow\" -state \"$WORK/state.json\" --offline list 2>&1"
+check "otel export writes runs as OTLP traces" "\"parentSpanId\"" qw otel export --out -
+
+if [ $failures -gt 0 ]; then
+    echo -e "${RED}$failures check(s) failed${NC}"
+    exit 1
+fi
+echo -e "${GREEN}All checks passed${NC}"
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// runSearch narrows listed runs to a commit or to commit messages matching
+// a pattern. The zero value matches every run.
+type runSearch struct {
+	// Commit is a full or abbreviated SHA
+	Commit string
+	Grep   *regexp.Regexp
+}
+
+// newRunSearch validates the --commit and --grep flags. The pattern is
+// matched case-insensitively against the whole commit message.
+func newRunSearch(commit, grep string) (runSearch, error) {
+	search := runSearch{Commit: strings.ToLower(strings.TrimSpace(commit))}
+	if search.Commit != "" && !isHexString(search.Commit) {
+		return runSearch{}, fmt.Errorf("invalid commit SHA: %s", commit)
+	}
+	if grep != "" {
+		pattern, err := regexp.Compile("(?i)" + grep)
+		if err != nil {
+			return runSearch{}, fmt.Errorf("invalid --grep pattern: %v", err)
+		}
+		search.Grep = pattern
+	}
+	return search, nil
+}
+
+// isHexString reports whether s is made of hex digits only
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// collect fetches up to limit runs of each project matching the search,
+// reporting warnings to w, and returns them with the projects that failed.
+// Without a commit it fetches the latest runs and filters them, so --grep
+// only looks as far back as limit.
+func (s runSearch) collect(ctx context.Context, w io.Writer, config *Config, projects []Project, limit int) ([]WorkflowRun, []fetchFailure) {
+	if s.Commit == "" {
+		runs, failures := collectRunsTo(ctx, w, config, projects, limit)
+		return s.filter(ctx, runs), failures
+	}
+
+	var allRuns []WorkflowRun
+	var failures []fetchFailure
+	for _, project := range projects {
+		runs, err := getWorkflowRunsForCommit(ctx, project, s.Commit, limit)
+		if ctx.Err() != nil {
+			return allRuns, failures
+		}
+		if err != nil {
+			failures = append(failures, fetchFailure{Project: project.Name, Err: err})
+			continue
+		}
+		allRuns = append(allRuns, runs...)
+	}
+	if err := recordRuns(config, allRuns); err != nil {
+		fmt.Fprintf(w, "%s Failed to record run history: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	return s.filter(ctx, allRuns), failures
+}
+
+// filter keeps the runs whose commit message matches the pattern, fetching
+// messages the provider didn't list with the runs
+func (s runSearch) filter(ctx context.Context, runs []WorkflowRun) []WorkflowRun {
+	if s.Grep == nil {
+		return runs
+	}
+	fillCommitMessages(ctx, runs)
+	var matched []WorkflowRun
+	for _, run := range runs {
+		if s.Grep.MatchString(run.CommitMessage) {
+			matched = append(matched, run)
+		}
+	}
+	return matched
+}
+
+// commitMessages caches fetched commit messages by project@sha, as they
+// never change and watch asks again on every refresh
+var commitMessages = make(map[string]string)
+
+// fillCommitMessages fetches the commit message of runs listed without one,
+// once per commit. Only GitLab lists runs without their message; runs whose
+// message can't be fetched keep an empty one.
+func fillCommitMessages(ctx context.Context, runs []WorkflowRun) {
+	// Clients are shared by the projects of an account, nil for accounts
+	// that can't log in
+	clients := make(map[string]*GitLabClient)
+	for i := range runs {
+		run := &runs[i]
+		if run.CommitMessage != "" || run.Commit == "" || run.Platform != "gitlab" {
+			continue
+		}
+		key := run.Project + "@" + run.Commit
+		if _, ok := commitMessages[key]; !ok {
+			account := projectAccounts[run.Project]
+			client, created := clients[account]
+			if !created {
+				client, _ = NewGitLabClient(ctx, run.Project)
+				clients[account] = client
+			}
+			if client == nil {
+				continue
+			}
+			message, err := client.GetCommitMessage(run.Project, run.Commit)
+			if err != nil {
+				continue
+			}
+			commitMessages[key] = message
+		}
+		run.CommitMessage = commitMessages[key]
+	}
+}
+
+// getWorkflowRunsForCommit retrieves up to limit runs of a project for a
+// full or abbreviated commit SHA. Providers that can't filter by commit
+// have their recent runs searched instead.
+func getWorkflowRunsForCommit(ctx context.Context, project Project, sha string, limit int) ([]WorkflowRun, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetWorkflowRunsForCommit(project.Owner, project.Repo, sha, limit)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPipelineRunsForCommit(project.Name, sha, limit)
+	}
+
+	recent, err := getWorkflowRunsForProject(ctx, project, "", max(limit, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+	var runs []WorkflowRun
+	for _, run := range recent {
+		if strings.HasPrefix(strings.ToLower(run.Commit), sha) {
+			runs = append(runs, run)
+			if len(runs) == limit {
+				break
+			}
+		}
+	}
+	return runs, nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/term"
+)
+
+// ciVariableName matches names both platforms accept for secrets and
+// variables
+var ciVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateCIVariableName rejects names the platform would refuse, before
+// asking for a value
+func validateCIVariableName(platform, name string) error {
+	if !ciVariableName.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use letters, digits, and underscores, not starting with a digit", name)
+	}
+	if platform == "github" && strings.HasPrefix(strings.ToUpper(name), "GITHUB_") {
+		return fmt.Errorf("invalid name %q: the GITHUB_ prefix is reserved", name)
+	}
+	return nil
+}
+
+// readSecretValue reads a value without echoing it when stdin is a
+// terminal, or all of stdin otherwise so values can be piped in. One
+// trailing newline is dropped.
+func readSecretValue(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Printf("%s", qc.Colorize(prompt, qc.ColorYellow))
+		value, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", err
+		}
+		return string(value), nil
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	trimmed := strings.TrimSuffix(string(value), "\n")
+	return strings.TrimSuffix(trimmed, "\r"), nil
+}
+
+// listCIVariables retrieves a project's secrets, or its variables. GitLab
+// has only CI/CD variables, so its masked variables are treated as secrets.
+func listCIVariables(ctx context.Context, project Project, secrets bool) ([]CIVariable, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		if secrets {
+			return client.GetSecrets(project.Owner, project.Repo)
+		}
+		return client.GetVariables(project.Owner, project.Repo)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		variables, err := client.GetProjectVariables(project.Name)
+		if err != nil {
+			return nil, err
+		}
+		var result []CIVariable
+		for _, variable := range variables {
+			if variable.Masked == secrets {
+				if secrets {
+					variable.Value = ""
+				}
+				result = append(result, variable)
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// setCIVariable creates or updates a secret or variable. Secrets are always
+// masked on GitLab; masked and protected don't apply to GitHub.
+func setCIVariable(ctx context.Context, project Project, name, value string, secret, masked, protected bool) error {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return err
+		}
+		if secret {
+			return client.SetSecret(project.Owner, project.Repo, name, value)
+		}
+		return client.SetVariable(project.Owner, project.Repo, name, value)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return err
+		}
+		return client.SetProjectVariable(project.Name, name, value, secret || masked, protected)
+	default:
+		return fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// deleteCIVariable removes a secret or variable
+func deleteCIVariable(ctx context.Context, project Project, name string, secret bool) error {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return err
+		}
+		if secret {
+			return client.DeleteSecret(project.Owner, project.Repo, name)
+		}
+		return client.DeleteVariable(project.Owner, project.Repo, name)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return err
+		}
+		return client.DeleteProjectVariable(project.Name, name)
+	default:
+		return fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// handleSecrets handles the secrets command
+func handleSecrets(ctx context.Context, config *Config, args []string) {
+	handleCIVariables(ctx, config, "secrets", args)
+}
+
+// handleVariables handles the variables command
+func handleVariables(ctx context.Context, config *Config, args []string) {
+	handleCIVariables(ctx, config, "variables", args)
+}
+
+// handleCIVariables handles the list, set, and delete subcommands shared by
+// the secrets and variables commands
+func handleCIVariables(ctx context.Context, config *Config, command string, args []string) {
+	secret := command == "secrets"
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow %s list|set|delete <project> [name]\n", qc.Colorize("Error:", qc.ColorRed), command)
+		return
+	}
+
+	fs := flag.NewFlagSet(command+" "+args[0], flag.ExitOnError)
+	protected := fs.Bool("protected", false, "GitLab: only expose to protected branches and tags")
+	// GitLab secrets are always masked
+	masked := new(bool)
+	if !secret {
+		masked = fs.Bool("masked", false, "GitLab: hide the value in job logs")
+	}
+	fs.Parse(args[1:])
+
+	var project *Project
+	if fs.NArg() > 0 {
+		project = findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		if project == nil {
+			fmt.Printf("%s Usage: quick_workflow %s list <project>\n", qc.Colorize("Error:", qc.ColorRed), command)
+			return
+		}
+		showCIVariables(ctx, *project, secret)
+	case "set":
+		if project == nil || fs.NArg() < 2 || (secret && fs.NArg() > 2) {
+			usage := fmt.Sprintf("quick_workflow %s set [--protected] [--masked] <project> <name> [value]", command)
+			if secret {
+				// Secret values are never taken as arguments, which would
+				// leave them in shell history
+				usage = fmt.Sprintf("quick_workflow %s set [--protected] <project> <name>", command)
+			}
+			fmt.Printf("%s Usage: %s\n", qc.Colorize("Error:", qc.ColorRed), usage)
+			return
+		}
+		setCIVariableCommand(ctx, config, *project, fs.Arg(1), fs.Arg(2), fs.NArg() > 2, secret, *masked, *protected)
+	case "delete":
+		if project == nil || fs.NArg() < 2 {
+			fmt.Printf("%s Usage: quick_workflow %s delete <project> <name>\n", qc.Colorize("Error:", qc.ColorRed), command)
+			return
+		}
+		name := fs.Arg(1)
+		kind := strings.TrimSuffix(command, "s")
+		if !confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("Delete %s %s from %s?", kind, name, project.Name)) {
+			fmt.Printf("%s Cancelled\n", qc.Colorize("Info:", qc.ColorCyan))
+			return
+		}
+		err := deleteCIVariable(ctx, *project, name, secret)
+		recordAction(config, kind+" delete", project.Name, map[string]string{"name": name}, "deleted "+kind+" "+name, err)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Deleted %s %s from %s\n", qc.Colorize("Success:", qc.ColorGreen), kind, name, project.Name)
+	default:
+		fmt.Printf("%s Unknown %s command: %s\n", qc.Colorize("Error:", qc.ColorRed), command, args[0])
+		fmt.Println("  Commands: list, set, delete")
+	}
+}
+
+// setCIVariableCommand validates and stores one secret or variable, reading
+// the value when it wasn't given as an argument
+func setCIVariableCommand(ctx context.Context, config *Config, project Project, name, value string, hasValue, secret, masked, protected bool) {
+	if err := validateCIVariableName(project.Platform, name); err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		return
+	}
+	if project.Platform == "github" && (masked || protected) {
+		fmt.Printf("%s --masked and --protected only apply to GitLab and are ignored\n", qc.Colorize("Warning:", qc.ColorYellow))
+	}
+
+	if !hasValue {
+		var err error
+		value, err = readSecretValue(fmt.Sprintf("Value for %s: ", name))
+		if err != nil {
+			fmt.Printf("%s Failed to read value: %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+	}
+	if value == "" && secret {
+		fmt.Printf("%s Secret value is empty\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	kind := "variable"
+	if secret {
+		kind = "secret"
+	}
+	err := setCIVariable(ctx, project, name, value, secret, masked, protected)
+	// Values are left out; variables may hold credentials too
+	parameters := map[string]string{"name": name}
+	if project.Platform == "gitlab" {
+		parameters["masked"] = strconv.FormatBool(masked || secret)
+		parameters["protected"] = strconv.FormatBool(protected)
+	}
+	recordAction(config, kind+" set", project.Name, parameters, "set "+kind+" "+name, err)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Set %s %s on %s\n", qc.Colorize("Success:", qc.ColorGreen), kind, name, project.Name)
+}
+
+// showCIVariables lists a project's secrets by name, or its variables with
+// their values
+func showCIVariables(ctx context.Context, project Project, secret bool) {
+	variables, err := listCIVariables(ctx, project, secret)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	kind := "Variables"
+	if secret {
+		kind = "Secrets"
+	}
+	if len(variables) == 0 {
+		fmt.Printf("%s No %s in %s\n", qc.Colorize("Info:", qc.ColorCyan), strings.ToLower(kind), project.Name)
+		return
+	}
+
+	nameWidth := 4
+	for _, variable := range variables {
+		nameWidth = max(nameWidth, len(variable.Name))
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("%s of %s:", kind, project.Name), qc.ColorBlue))
+	for i, variable := range variables {
+		var details []string
+		if !secret {
+			details = append(details, truncate(strings.ReplaceAll(variable.Value, "\n", " "), 50))
+		}
+		if project.Platform == "github" {
+			details = append(details, "updated "+formatRunTime(variable.UpdatedAt))
+		}
+		if variable.Protected {
+			details = append(details, "protected")
+		}
+		if variable.Environment != "" && variable.Environment != "*" {
+			details = append(details, "environment "+variable.Environment)
+		}
+		entry := fmt.Sprintf("%3d. %-*s  %s", i+1, nameWidth, variable.Name, strings.Join(details, "  "))
+		fmt.Println(qc.Colorize(entry, qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)))
+	}
+}
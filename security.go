@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// maxListedFindings caps the new findings listed in the details view
+const maxListedFindings = 20
+
+// securitySeverities are GitLab's finding severities, most severe first
+var securitySeverities = []string{"Critical", "High", "Medium", "Low", "Info", "Unknown"}
+
+// securityReport is the part of a GitLab security report JSON file that is
+// summarized
+type securityReport struct {
+	Vulnerabilities []struct {
+		Name     string `json:"name"`
+		Message  string `json:"message"`
+		Severity string `json:"severity"`
+		Location struct {
+			File       string `json:"file"`
+			StartLine  int    `json:"start_line"`
+			Image      string `json:"image"`
+			Dependency struct {
+				Package struct {
+					Name string `json:"name"`
+				} `json:"package"`
+				Version string `json:"version"`
+			} `json:"dependency"`
+		} `json:"location"`
+		Identifiers []struct {
+			Value string `json:"value"`
+			Name  string `json:"name"`
+		} `json:"identifiers"`
+	} `json:"vulnerabilities"`
+}
+
+// parseSecurityReport reads the findings of a GitLab SAST, dependency
+// scanning, secret detection, or container scanning report
+func parseSecurityReport(r io.Reader, scanner string) ([]SecurityFinding, error) {
+	var report securityReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	findings := make([]SecurityFinding, 0, len(report.Vulnerabilities))
+	for _, v := range report.Vulnerabilities {
+		finding := SecurityFinding{
+			Scanner:  scanner,
+			Severity: "Unknown",
+			Name:     firstNonEmpty(v.Name, v.Message),
+		}
+		if rank := severityRank(v.Severity); rank < len(securitySeverities) {
+			finding.Severity = securitySeverities[rank]
+		}
+		switch {
+		case v.Location.Dependency.Package.Name != "":
+			finding.Location = v.Location.Dependency.Package.Name + "@" + v.Location.Dependency.Version
+			if v.Location.Image != "" {
+				finding.Location = v.Location.Image + " " + finding.Location
+			}
+		case v.Location.StartLine > 0:
+			finding.Location = fmt.Sprintf("%s:%d", v.Location.File, v.Location.StartLine)
+		default:
+			finding.Location = v.Location.File
+		}
+		if len(v.Identifiers) > 0 {
+			finding.Identifier = firstNonEmpty(v.Identifiers[0].Name, v.Identifiers[0].Value)
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// findingKey identifies a finding across pipelines. Line numbers are left
+// out, as unrelated edits move them.
+func findingKey(finding SecurityFinding) string {
+	location, _, _ := strings.Cut(finding.Location, ":")
+	return strings.Join([]string{finding.Scanner, finding.Identifier, finding.Name, location}, "\x00")
+}
+
+// severityRank orders severities most severe first, unknown ones last
+func severityRank(severity string) int {
+	for i, s := range securitySeverities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return len(securitySeverities)
+}
+
+// colorSeverity colors text red for critical and high severity, yellow
+// for medium
+func colorSeverity(severity, text string) string {
+	switch severityRank(severity) {
+	case 0, 1:
+		return qc.Colorize(text, qc.ColorRed)
+	case 2:
+		return qc.Colorize(text, qc.ColorYellow)
+	default:
+		return qc.Colorize(text, qc.ColorCyan)
+	}
+}
+
+// getNewSecurityFindings retrieves a GitLab pipeline's security findings
+// and those among them that the latest earlier pipeline of the default
+// branch didn't report. baseline is that pipeline's ID, empty when there
+// is none and every finding counts as new.
+func getNewSecurityFindings(ctx context.Context, project Project, run WorkflowRun) (findings, added []SecurityFinding, baseline string, err error) {
+	client, err := NewGitLabClient(ctx, project.Name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	findings, err = client.GetPipelineSecurityFindings(project.Name, run.ID)
+	if len(findings) == 0 {
+		return nil, nil, "", err
+	}
+	// Missing reports are passed on as a warning once the findings that
+	// were read are compared
+	reportErr := err
+
+	branch, err := client.GetDefaultBranch(project.Name)
+	if err != nil {
+		return findings, findings, "", err
+	}
+	recent, err := client.GetPipelineRuns(project.Name, branch, 10)
+	if err != nil {
+		return findings, findings, "", err
+	}
+	seen := make(map[string]bool)
+	for _, candidate := range recent {
+		if candidate.ID == run.ID || !isRunFinished(candidate.Status) || !candidate.CreatedAt.Before(run.CreatedAt) {
+			continue
+		}
+		previous, err := client.GetPipelineSecurityFindings(project.Name, candidate.ID)
+		if err != nil && len(previous) == 0 {
+			continue
+		}
+		baseline = candidate.ID
+		for _, finding := range previous {
+			seen[findingKey(finding)] = true
+		}
+		break
+	}
+
+	for _, finding := range findings {
+		if !seen[findingKey(finding)] {
+			added = append(added, finding)
+		}
+	}
+	return findings, added, baseline, reportErr
+}
+
+// showSecurityFindings summarizes a GitLab pipeline's new security findings
+// by severity and lists them, most severe first. Pipelines without scanner
+// reports show nothing.
+func showSecurityFindings(ctx context.Context, project Project, run WorkflowRun) {
+	findings, added, baseline, err := getNewSecurityFindings(ctx, project, run)
+	if err != nil {
+		fmt.Printf("%s Security reports: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	if len(findings) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, finding := range added {
+		counts[finding.Severity]++
+	}
+	var parts []string
+	for _, severity := range securitySeverities {
+		if counts[severity] > 0 {
+			parts = append(parts, colorSeverity(severity, fmt.Sprintf("%d %s", counts[severity], severity)))
+		}
+	}
+	compared := fmt.Sprintf("not in pipeline %s", baseline)
+	if baseline == "" {
+		compared = "no earlier default branch pipeline to compare with"
+	}
+
+	fmt.Printf("\n%s %d new of %d (%s)\n", qc.Colorize("Security findings:", qc.ColorBlue), len(added), len(findings), compared)
+	if len(added) == 0 {
+		return
+	}
+	fmt.Printf("  %s\n", strings.Join(parts, ", "))
+
+	sort.SliceStable(added, func(i, j int) bool {
+		return severityRank(added[i].Severity) < severityRank(added[j].Severity)
+	})
+	for i, finding := range added {
+		if i == maxListedFindings {
+			fmt.Printf("  ... and %d more\n", len(added)-maxListedFindings)
+			break
+		}
+		severity := colorSeverity(finding.Severity, fmt.Sprintf("%-8s", finding.Severity))
+		fmt.Printf("  %s %s %s %s\n", severity, qc.Colorize(finding.Scanner, qc.ColorCyan), finding.Name, finding.Location)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/term"
+)
+
+// Settings holds user preferences stored in the state file
+type Settings struct {
+	Timezone     string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	AbsoluteTime bool   `json:"absolute_time,omitempty" yaml:"absolute_time,omitempty"`
+	Columns      string `json:"columns,omitempty" yaml:"columns,omitempty"`
+	// HistoryBackend selects the run history store: sqlite (default),
+	// postgres, or jsonl
+	HistoryBackend string                 `json:"history_backend,omitempty" yaml:"history_backend,omitempty"`
+	Layouts        map[string]WatchLayout `json:"layouts,omitempty" yaml:"layouts,omitempty"`
+	// Network options for provider API requests; see network.go
+	HTTPTimeout        string `json:"http_timeout,omitempty" yaml:"http_timeout,omitempty"`
+	ConnectTimeout     string `json:"connect_timeout,omitempty" yaml:"connect_timeout,omitempty"`
+	Proxy              string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	CABundle           string `json:"ca_bundle,omitempty" yaml:"ca_bundle,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+	// GitHubFetch is how runs of GitHub projects are listed: rest, the
+	// default when empty, or graphql to batch them
+	GitHubFetch string `json:"github_fetch,omitempty" yaml:"github_fetch,omitempty"`
+	// Theme remaps output colors by name, e.g. red to purple and green to
+	// blue for red-green color blindness
+	Theme map[string]string `json:"theme,omitempty" yaml:"theme,omitempty"`
+	// NumberedPrompts keeps numbered selection prompts instead of the
+	// fuzzy finder, e.g. for screen readers
+	NumberedPrompts bool `json:"numbered_prompts,omitempty" yaml:"numbered_prompts,omitempty"`
+	// RetentionDays and RetentionRuns limit the run history to runs from
+	// the last N days and the newest N runs of each project; see prune.go
+	RetentionDays int `json:"retention_days,omitempty" yaml:"retention_days,omitempty"`
+	RetentionRuns int `json:"retention_runs,omitempty" yaml:"retention_runs,omitempty"`
+	// FailureSignatures are patterns naming the causes of failed runs,
+	// matched before the built-in ones; see failurecause.go
+	FailureSignatures map[string]FailureSignature `json:"failure_signatures,omitempty" yaml:"failure_signatures,omitempty"`
+}
+
+// Display options resolved from settings and flags at startup
+var (
+	displayLocation = time.Local
+	absoluteTimes   bool
+	// absoluteTimeFlag keeps --absolute-time for settings reapplied after
+	// switching workspaces
+	absoluteTimeFlag bool
+)
+
+// applySettings resolves display options from settings. The --absolute-time
+// flag is passed as absoluteFlag and enables absolute times regardless of
+// the stored preference.
+func applySettings(settings Settings, absoluteFlag bool) error {
+	absoluteTimeFlag = absoluteFlag
+	absoluteTimes = settings.AbsoluteTime || absoluteFlag
+	numberedPrompts = settings.NumberedPrompts
+
+	displayLocation = time.Local
+	if settings.Timezone != "" {
+		loc, err := time.LoadLocation(settings.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone setting %q: %v", settings.Timezone, err)
+		}
+		displayLocation = loc
+	}
+	if err := applyNetworkSettings(settings); err != nil {
+		return err
+	}
+	if err := qc.SetTheme(settings.Theme); err != nil {
+		return fmt.Errorf("invalid theme setting: %v", err)
+	}
+	return nil
+}
+
+// configureColor turns ANSI colors on or off for the --color flag: always,
+// never, or auto, which colors output to a terminal unless NO_COLOR is set
+func configureColor(mode string) error {
+	switch mode {
+	case "always":
+		enableANSI()
+		qc.SetEnabled(true)
+	case "never":
+		qc.SetEnabled(false)
+	case "auto":
+		qc.SetEnabled(os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd())) && enableANSI())
+	default:
+		return fmt.Errorf("invalid --color %q (expected auto, always, or never)", mode)
+	}
+	return nil
+}
+
+// parseThemeSetting parses a theme given as from=to pairs separated by
+// commas, e.g. red=purple,green=blue
+func parseThemeSetting(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	theme := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("theme must be color=replacement pairs, e.g. red=purple,green=blue")
+		}
+		theme[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	if err := qc.ParseTheme(theme); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// formatThemeSetting formats a theme the way parseThemeSetting reads it
+func formatThemeSetting(theme map[string]string) string {
+	pairs := make([]string, 0, len(theme))
+	for from, to := range theme {
+		pairs = append(pairs, from+"="+to)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// setSetting updates a single setting by key
+func setSetting(settings *Settings, key, value string) error {
+	switch key {
+	case "timezone":
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("invalid timezone: %s", value)
+			}
+		}
+		settings.Timezone = value
+	case "absolute_time":
+		if value == "" {
+			settings.AbsoluteTime = false
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("absolute_time must be true or false")
+		}
+		settings.AbsoluteTime = b
+	case "columns":
+		if _, err := parseColumns(value, Settings{}); err != nil {
+			return err
+		}
+		settings.Columns = value
+	case "history_backend":
+		switch value {
+		case "", "sqlite", "postgres", "jsonl":
+		default:
+			return fmt.Errorf("history_backend must be sqlite, postgres, or jsonl")
+		}
+		settings.HistoryBackend = value
+	case "http_timeout", "connect_timeout":
+		if _, err := parseTimeoutSetting(key, value); err != nil {
+			return err
+		}
+		if key == "http_timeout" {
+			settings.HTTPTimeout = value
+		} else {
+			settings.ConnectTimeout = value
+		}
+	case "proxy":
+		if value != "" {
+			if _, err := parseProxyURL(value); err != nil {
+				return err
+			}
+		}
+		settings.Proxy = value
+	case "ca_bundle":
+		if value != "" {
+			if _, err := loadCABundle(value); err != nil {
+				return err
+			}
+		}
+		settings.CABundle = value
+	case "insecure_skip_verify":
+		if value == "" {
+			settings.InsecureSkipVerify = false
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("insecure_skip_verify must be true or false")
+		}
+		settings.InsecureSkipVerify = b
+	case "github_fetch":
+		switch value {
+		case "", "rest", "graphql":
+		default:
+			return fmt.Errorf("github_fetch must be rest or graphql")
+		}
+		settings.GitHubFetch = value
+	case "theme":
+		theme, err := parseThemeSetting(value)
+		if err != nil {
+			return err
+		}
+		settings.Theme = theme
+	case "numbered_prompts":
+		if value == "" {
+			settings.NumberedPrompts = false
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("numbered_prompts must be true or false")
+		}
+		settings.NumberedPrompts = b
+	case "retention_days", "retention_runs":
+		n := 0
+		if value != "" {
+			var err error
+			n, err = strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("%s must be a positive number", key)
+			}
+		}
+		if key == "retention_days" {
+			settings.RetentionDays = n
+		} else {
+			settings.RetentionRuns = n
+		}
+	default:
+		return fmt.Errorf("unknown setting: %s", key)
+	}
+	return nil
+}
+
+// handleSettings handles the settings command
+func handleSettings(config *Config, args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		fmt.Printf("%s\n", qc.Colorize("Settings:", qc.ColorBlue))
+		fmt.Printf("  timezone      = %s\n", config.Settings.Timezone)
+		fmt.Printf("  absolute_time = %t\n", config.Settings.AbsoluteTime)
+		fmt.Printf("  columns       = %s\n", config.Settings.Columns)
+		fmt.Printf("  history_backend = %s\n", config.Settings.HistoryBackend)
+		fmt.Printf("  http_timeout  = %s\n", config.Settings.HTTPTimeout)
+		fmt.Printf("  connect_timeout = %s\n", config.Settings.ConnectTimeout)
+		fmt.Printf("  proxy         = %s\n", config.Settings.Proxy)
+		fmt.Printf("  ca_bundle     = %s\n", config.Settings.CABundle)
+		fmt.Printf("  insecure_skip_verify = %t\n", config.Settings.InsecureSkipVerify)
+		fmt.Printf("  github_fetch  = %s\n", config.Settings.GitHubFetch)
+		fmt.Printf("  theme         = %s\n", formatThemeSetting(config.Settings.Theme))
+		fmt.Printf("  numbered_prompts = %t\n", config.Settings.NumberedPrompts)
+		fmt.Printf("  retention_days = %s\n", formatRetention(config.Settings.RetentionDays, "keep any age"))
+		fmt.Printf("  retention_runs = %s\n", formatRetention(config.Settings.RetentionRuns, fmt.Sprintf("%d (default)", defaultRetentionRuns)))
+		fmt.Printf("  layouts       = %d (see 'settings layout list')\n", len(config.Settings.Layouts))
+		fmt.Printf("  signatures    = %d configured (see 'settings signature list')\n", len(config.Settings.FailureSignatures))
+		return
+	}
+
+	if args[0] == "layout" {
+		handleLayoutSettings(config, args[1:])
+		return
+	}
+	if args[0] == "signature" {
+		handleSignatureSettings(config, args[1:])
+		return
+	}
+
+	var key, value string
+	switch {
+	case args[0] == "set" && len(args) == 3:
+		key, value = args[1], args[2]
+	case args[0] == "unset" && len(args) == 2:
+		key = args[1]
+	default:
+		fmt.Printf("%s Usage: quick_workflow settings [list | set <key> <value> | unset <key> | layout ... | signature ...]\n", qc.Colorize("Error:", qc.ColorRed))
+		fmt.Println("  Keys: timezone, absolute_time, columns, history_backend, http_timeout, connect_timeout, proxy, ca_bundle, insecure_skip_verify, github_fetch, theme, numbered_prompts, retention_days, retention_runs")
+		return
+	}
+
+	err := updateProjects(config, func(config *Config) error {
+		return setSetting(&config.Settings, key, value)
+	})
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Updated %s\n", qc.Colorize("Success:", qc.ColorGreen), key)
+}
+
+// formatRetention formats a retention setting, or describes what unset
+// means
+func formatRetention(n int, unset string) string {
+	if n == 0 {
+		return unset
+	}
+	return strconv.Itoa(n)
+}
+
+// formatRunTime formats a timestamp for run tables, as "3m ago" by default
+// or as an absolute time in the configured timezone
+func formatRunTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if absoluteTimes {
+		return t.In(displayLocation).Format("2006-01-02 15:04")
+	}
+	return formatAgo(time.Since(t))
+}
+
+// formatAbsoluteTime formats a timestamp with seconds in the configured timezone
+func formatAbsoluteTime(t time.Time) string {
+	return t.In(displayLocation).Format("2006-01-02 15:04:05")
+}
+
+// formatAgo formats an elapsed time as a coarse relative time
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	default:
+		return fmt.Sprintf("%dmo ago", int(d.Hours()/(24*30)))
+	}
+}
+
+// formatDuration formats a duration compactly, e.g. 4m12s or 1h05m
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
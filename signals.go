@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// shutdownGrace is how long a command gets to stop on its own after Ctrl+C
+// before the process exits anyway
+const shutdownGrace = 2 * time.Second
+
+// interruptedExitCode is the exit status after Ctrl+C, as shells report it
+const interruptedExitCode = 130
+
+// signalContext returns a context cancelled on Ctrl+C or SIGTERM. Commands
+// stop their API calls, refresh loops, and servers through it; one that is
+// stuck elsewhere, such as on a prompt, is ended after shutdownGrace, or
+// at once by a second Ctrl+C. The returned done function must be deferred:
+// it exits with interruptedExitCode after an interrupt, restoring the
+// terminal either way.
+func signalContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	restore := saveTerminal()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	interrupted := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		close(interrupted)
+		cancel()
+		select {
+		case <-signals:
+		case <-time.After(shutdownGrace):
+		}
+		restore()
+		os.Exit(interruptedExitCode)
+	}()
+
+	done := func() {
+		select {
+		case <-interrupted:
+			restore()
+			os.Exit(interruptedExitCode)
+		default:
+		}
+		signal.Stop(signals)
+		cancel()
+	}
+	return ctx, done
+}
+
+// saveTerminal records the state of the terminal on stdin and returns a
+// function that brings it back, along with the cursor and default colors
+// on stdout, for when a prompt or redraw is cut short
+func saveTerminal() func() {
+	stdin := int(os.Stdin.Fd())
+	state, _ := term.GetState(stdin)
+	return func() {
+		if state != nil {
+			_ = term.Restore(stdin, state)
+		}
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			fmt.Print("\033[0m\033[?25h\n")
+		}
+	}
+}
+
+// sleepContext waits for d, returning early with ctx's error once ctx is
+// cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
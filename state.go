@@ -2,14 +2,24 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// maxTriggeredRuns bounds how many triggered run links are kept in state
+const maxTriggeredRuns = 100
+
 // State represents the application state
 type State struct {
-	Projects []Project `json:"projects"`
-	Version  string    `json:"version"`
+	Projects []Project      `json:"projects"`
+	Triggers []TriggeredRun `json:"triggers,omitempty"`
+	Notes    []RunNote      `json:"notes,omitempty"`
+	Settings Settings       `json:"settings"`
+	Version  string         `json:"version"`
 }
 
 // loadProjects loads projects from the state file
@@ -33,13 +43,21 @@ func loadProjects(config *Config) error {
 	}
 
 	config.Projects = state.Projects
+	registerProjectAccounts(config.Projects)
+	config.Triggers = state.Triggers
+	config.Notes = state.Notes
+	config.Settings = state.Settings
 	return nil
 }
 
-// saveProjects saves projects to the state file
+// saveProjects saves projects to the state file. Callers must hold the state
+// lock; use updateProjects to modify tracked projects.
 func saveProjects(config *Config) error {
 	state := State{
 		Projects: config.Projects,
+		Triggers: config.Triggers,
+		Notes:    config.Notes,
+		Settings: config.Settings,
 		Version:  "1.0",
 	}
 
@@ -54,5 +72,146 @@ func saveProjects(config *Config) error {
 		return err
 	}
 
-	return os.WriteFile(config.StateFile, data, 0644)
+	return writeFileAtomic(config.StateFile, data, 0644)
+}
+
+// updateProjects locks the state file, reloads it, applies fn, and saves the
+// result, so concurrent invocations don't overwrite each other's changes
+func updateProjects(config *Config, fn func(config *Config) error) error {
+	lock, err := acquireLock(config.StateFile)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	if err := loadProjects(config); err != nil {
+		return err
+	}
+	if err := fn(config); err != nil {
+		return err
+	}
+	return saveProjects(config)
+}
+
+// recordTriggeredRun stores the link between a dispatch and its run,
+// keeping only the most recent entries
+func recordTriggeredRun(config *Config, triggered TriggeredRun) error {
+	return updateProjects(config, func(config *Config) error {
+		config.Triggers = append(config.Triggers, triggered)
+		if len(config.Triggers) > maxTriggeredRuns {
+			config.Triggers = config.Triggers[len(config.Triggers)-maxTriggeredRuns:]
+		}
+		return nil
+	})
+}
+
+// StateExport is the portable document produced by 'state export'
+type StateExport struct {
+	Version    string      `json:"version" yaml:"version"`
+	ExportedAt string      `json:"exported_at" yaml:"exported_at"`
+	Projects   []Project   `json:"projects" yaml:"projects"`
+	Settings   *Settings   `json:"settings,omitempty" yaml:"settings,omitempty"`
+	Auth       *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+
+// exportState builds an export document from the current configuration.
+// Tokens are only included when includeSecrets is set.
+func exportState(config *Config, includeSecrets bool) StateExport {
+	projects := make([]Project, len(config.Projects))
+	copy(projects, config.Projects)
+
+	export := StateExport{
+		Version:    "1.0",
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Projects:   projects,
+		Settings:   &config.Settings,
+	}
+
+	if includeSecrets {
+		if auth, err := loadAuthConfig(); err == nil {
+			export.Auth = auth
+		}
+	} else {
+		for i := range export.Projects {
+			export.Projects[i].AccessToken = ""
+		}
+	}
+
+	return export
+}
+
+// marshalStateExport encodes an export document as json or yaml
+func marshalStateExport(export StateExport, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "yaml":
+		return yaml.Marshal(export)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s (expected json or yaml)", format)
+	}
+}
+
+// unmarshalStateExport decodes an export document. JSON is a subset of YAML,
+// so a single YAML decoder handles both formats.
+func unmarshalStateExport(data []byte) (*StateExport, error) {
+	var export StateExport
+	if err := yaml.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// importState merges an export document into the configuration. Projects that
+// are already tracked are skipped unless replace is set, in which case the
+// tracked project list is replaced entirely. Settings in the document replace
+// the current settings. It returns the number of projects added.
+func importState(config *Config, export *StateExport, replace bool) (int, error) {
+	for _, project := range export.Projects {
+		if project.Name == "" || project.Platform == "" {
+			return 0, fmt.Errorf("invalid project entry: name and platform are required")
+		}
+	}
+
+	added := 0
+	err := updateProjects(config, func(config *Config) error {
+		if replace {
+			config.Projects = []Project{}
+		}
+		if export.Settings != nil {
+			config.Settings = *export.Settings
+		}
+
+		for _, project := range export.Projects {
+			exists := false
+			for _, existing := range config.Projects {
+				if existing.Name == project.Name {
+					exists = true
+					break
+				}
+			}
+			if exists {
+				continue
+			}
+
+			config.Projects = append(config.Projects, project)
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if export.Auth != nil {
+		if err := saveAuthConfig(*export.Auth); err != nil {
+			return added, fmt.Errorf("failed to save authentication: %v", err)
+		}
+	}
+
+	return added, nil
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// statusCounts summarizes the tracked projects for the one-line status
+type statusCounts struct {
+	// Passing and Failing count projects by their latest finished run
+	Passing int `json:"passing"`
+	Failing int `json:"failing"`
+	// Running counts unfinished runs across all projects
+	Running int `json:"running"`
+	// Unknown counts projects whose runs couldn't be fetched
+	Unknown   int       `json:"unknown"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// statusLineFile returns the path of the cached one-line status, kept next
+// to the state file
+func statusLineFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "status-line.json")
+}
+
+// countStatuses fetches the recent runs of every tracked project, on its
+// default branch when it has one, and counts passing and failing projects
+// and running runs
+func countStatuses(ctx context.Context, config *Config) statusCounts {
+	counts := statusCounts{FetchedAt: time.Now()}
+	for _, project := range config.Projects {
+		runs, err := getWorkflowRunsForProject(ctx, project, project.DefaultBranch, 10)
+		if err != nil {
+			counts.Unknown++
+			continue
+		}
+
+		var latest *WorkflowRun
+		for i, run := range runs {
+			if !isRunFinished(run.Status) {
+				counts.Running++
+				continue
+			}
+			if latest == nil || run.CreatedAt.After(latest.CreatedAt) {
+				latest = &runs[i]
+			}
+		}
+		switch {
+		case latest == nil:
+		case isFailedConclusion(latest.Conclusion):
+			counts.Failing++
+		default:
+			counts.Passing++
+		}
+	}
+	return counts
+}
+
+// cachedStatusCounts returns the counts cached within maxAge, fetching and
+// caching fresh ones otherwise
+func cachedStatusCounts(ctx context.Context, config *Config, maxAge time.Duration) statusCounts {
+	path := statusLineFile(config)
+	var cached statusCounts
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil {
+		if time.Since(cached.FetchedAt) < maxAge {
+			return cached
+		}
+	}
+
+	counts := countStatuses(ctx, config)
+	// Keep showing the last good counts while offline
+	if counts.Unknown == len(config.Projects) && !cached.FetchedAt.IsZero() {
+		return cached
+	}
+	if data, err := json.Marshal(counts); err == nil {
+		// A failed write only costs a refetch next time
+		_ = writeFileAtomic(path, data, 0600)
+	}
+	return counts
+}
+
+// formatStatusLine renders counts as "✔ 12 ✖ 1 ● 3", colored for a terminal
+// with "ansi" or for a tmux status bar with "tmux"
+func formatStatusLine(counts statusCounts, color string) string {
+	parts := []struct {
+		symbol string
+		count  int
+		ansi   string
+		tmux   string
+		// optional parts are left out when zero
+		optional bool
+	}{
+		{"✔", counts.Passing, qc.ColorGreen, "green", false},
+		{"✖", counts.Failing, qc.ColorRed, "red", false},
+		{"●", counts.Running, qc.ColorYellow, "yellow", false},
+		{"?", counts.Unknown, qc.ColorWhite, "colour244", true},
+	}
+
+	var segments []string
+	for _, part := range parts {
+		if part.optional && part.count == 0 {
+			continue
+		}
+		segment := fmt.Sprintf("%s %d", part.symbol, part.count)
+		switch color {
+		case "ansi":
+			segment = qc.Colorize(segment, part.ansi)
+		case "tmux":
+			segment = "#[fg=" + part.tmux + "]" + segment + "#[default]"
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, " ")
+}
+
+// printStatusLine prints the one-line status for tmux status bars and shell
+// prompts. Errors print nothing so a prompt never fills with messages.
+func printStatusLine(ctx context.Context, config *Config, maxAge time.Duration, color string) {
+	if len(config.Projects) == 0 {
+		return
+	}
+	counts := cachedStatusCounts(ctx, config, maxAge)
+	fmt.Println(formatStatusLine(counts, color))
+}
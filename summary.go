@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// runSummary counts the runs a list or watch shows by state, and the
+// projects whose runs couldn't be fetched
+type runSummary struct {
+	Total       int
+	Running     int
+	Queued      int
+	Failed      int
+	Succeeded   int
+	Other       int
+	FetchErrors int
+	// InfraFailures and CodeFailures count the failed runs by the kind of
+	// their cause, when causes were found
+	InfraFailures int
+	CodeFailures  int
+}
+
+// isQueuedStatus reports whether an unfinished run is still waiting to
+// start
+func isQueuedStatus(status string) bool {
+	switch status {
+	case "queued", "pending", "waiting", "requested", "created", "scheduled", "preparing", "waiting_for_resource":
+		return true
+	}
+	return false
+}
+
+// summarizeRuns counts runs by state. Cancelled and skipped runs count as
+// other.
+func summarizeRuns(runs []WorkflowRun, fetchErrors int) runSummary {
+	summary := runSummary{Total: len(runs), FetchErrors: fetchErrors}
+	for _, run := range runs {
+		switch {
+		case isQueuedStatus(run.Status):
+			summary.Queued++
+		case !isRunFinished(run.Status):
+			summary.Running++
+		case run.Status == "failed" || isFailedConclusion(run.Conclusion):
+			summary.Failed++
+			if run.Cause != nil {
+				switch run.Cause.Kind {
+				case causeInfra:
+					summary.InfraFailures++
+				case causeCode:
+					summary.CodeFailures++
+				}
+			}
+		case run.Status == "success" || run.Conclusion == "success":
+			summary.Succeeded++
+		default:
+			summary.Other++
+		}
+	}
+	return summary
+}
+
+// String renders the summary as one line, e.g. "12 runs: 2 running,
+// 1 queued, 3 failed (2 infra, 1 code), 6 succeeded; 1 project failed to
+// load"
+func (s runSummary) String() string {
+	failed := fmt.Sprintf("%d failed", s.Failed)
+	if s.InfraFailures+s.CodeFailures > 0 {
+		failed += fmt.Sprintf(" (%d infra, %d code)", s.InfraFailures, s.CodeFailures)
+	}
+	counts := []string{
+		qc.Colorize(fmt.Sprintf("%d running", s.Running), qc.ColorBlue),
+		qc.Colorize(fmt.Sprintf("%d queued", s.Queued), qc.ColorYellow),
+		qc.Colorize(failed, qc.ColorRed),
+		qc.Colorize(fmt.Sprintf("%d succeeded", s.Succeeded), qc.ColorGreen),
+	}
+	if s.Other > 0 {
+		counts = append(counts, fmt.Sprintf("%d other", s.Other))
+	}
+	line := fmt.Sprintf("%d runs: %s", s.Total, strings.Join(counts, ", "))
+	if s.FetchErrors > 0 {
+		line += "; " + qc.Colorize(fmt.Sprintf("%d project(s) failed to load", s.FetchErrors), qc.ColorRed)
+	}
+	return line
+}
@@ -0,0 +1,45 @@
+package main
+
+import "sort"
+
+// markSuperseded flags every unfinished run that has a newer unfinished run
+// of the same workflow on the same branch, like a concurrency group with
+// cancel-in-progress would cancel it. It returns how many were flagged.
+func markSuperseded(runs []WorkflowRun) int {
+	kept := make(map[string]bool)
+	order := make([]int, 0, len(runs))
+	for i := range runs {
+		runs[i].Superseded = false
+		// Stale runs may have finished since they were cached
+		if !isRunFinished(runs[i].Status) && runs[i].Branch != "" && runs[i].StaleAt.IsZero() {
+			order = append(order, i)
+		}
+	}
+	// Newest first, so the first run seen of each group is the one to keep
+	sort.SliceStable(order, func(a, b int) bool {
+		return runs[order[a]].CreatedAt.After(runs[order[b]].CreatedAt)
+	})
+
+	count := 0
+	for _, i := range order {
+		key := runs[i].Project + "\x00" + runs[i].Workflow + "\x00" + runs[i].Branch
+		if kept[key] {
+			runs[i].Superseded = true
+			count++
+			continue
+		}
+		kept[key] = true
+	}
+	return count
+}
+
+// supersededRuns returns the runs flagged by markSuperseded
+func supersededRuns(runs []WorkflowRun) []WorkflowRun {
+	var superseded []WorkflowRun
+	for _, run := range runs {
+		if run.Superseded {
+			superseded = append(superseded, run)
+		}
+	}
+	return superseded
+}
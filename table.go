@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+	"golang.org/x/term"
+)
+
+// defaultRunColumns is the column layout used when none is configured
+var defaultRunColumns = []string{"project", "workflow", "created", "duration", "status", "branch", "pr", "subject", "attempt"}
+
+// runColumn describes one selectable column of a run table
+type runColumn struct {
+	// minWidth is the narrowest the column may be truncated to when the
+	// table does not fit the terminal; 0 means never truncate
+	minWidth int
+	// maxWidth caps the column even when the terminal is wide; 0 means no cap
+	maxWidth int
+	value    func(run WorkflowRun) string
+	// color returns the color for a cell; nil uses the row color
+	color func(run WorkflowRun) string
+	// bracket wraps the cell in [ ] like the status column has always been
+	bracket bool
+}
+
+// runColumns are the columns available to --columns
+var runColumns = map[string]runColumn{
+	"id":       {value: func(run WorkflowRun) string { return run.ID }},
+	"project":  {minWidth: 12, value: func(run WorkflowRun) string { return run.Project }},
+	"workflow": {minWidth: 10, maxWidth: 30, value: func(run WorkflowRun) string { return run.Workflow }},
+	"status": {
+		value: func(run WorkflowRun) string {
+			switch {
+			case run.Superseded:
+				return run.Status + ", superseded"
+			case !run.StaleAt.IsZero():
+				return run.Status + ", " + staleMarker(run.StaleAt)
+			}
+			return run.Status
+		},
+		color: func(run WorkflowRun) string {
+			// Offline every run is stale, so keep showing how it went
+			if run.Superseded || (!run.StaleAt.IsZero() && offlineConfig == nil) {
+				return qc.ColorYellow
+			}
+			return colorWorkflowStatus(run.Status, run.Conclusion)
+		},
+		bracket: true,
+	},
+	"conclusion": {value: func(run WorkflowRun) string { return run.Conclusion }},
+	"branch":     {minWidth: 10, maxWidth: 40, value: func(run WorkflowRun) string { return run.Branch }},
+	"created":    {value: func(run WorkflowRun) string { return formatRunTime(run.CreatedAt) }},
+	"duration":   {value: runDuration},
+	"actor":      {minWidth: 8, maxWidth: 20, value: func(run WorkflowRun) string { return run.TriggeredBy }},
+	"commit":     {value: func(run WorkflowRun) string { return shortSHA(run.Commit) }},
+	"subject":    {minWidth: 12, maxWidth: 50, value: func(run WorkflowRun) string { return commitSubject(run.CommitMessage) }},
+	"pr":         {value: pullRequestRef},
+	"url":        {minWidth: 20, value: func(run WorkflowRun) string { return run.URL }},
+	"queued": {value: func(run WorkflowRun) string {
+		if run.QueueTime > 0 {
+			return formatDuration(run.QueueTime)
+		}
+		return ""
+	}},
+	// Only failed runs have a cause
+	"cause": {minWidth: 10, maxWidth: 30, value: func(run WorkflowRun) string {
+		if run.Cause == nil {
+			return ""
+		}
+		return run.Cause.String()
+	}, color: causeColor},
+	// Only re-runs show an attempt, so the column stays empty otherwise
+	"attempt": {value: func(run WorkflowRun) string {
+		if run.Attempt > 1 {
+			return fmt.Sprintf("attempt %d", run.Attempt)
+		}
+		return ""
+	}},
+}
+
+// parseColumns validates a comma separated column list, falling back to the
+// configured or default layout when empty
+func parseColumns(spec string, settings Settings) ([]string, error) {
+	if spec == "" {
+		spec = settings.Columns
+	}
+	if spec == "" {
+		return defaultRunColumns, nil
+	}
+
+	var columns []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := runColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(columnNames(), ", "))
+		}
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return defaultRunColumns, nil
+	}
+	return columns, nil
+}
+
+// columnNames lists the available columns in a stable order
+func columnNames() []string {
+	return []string{"id", "project", "workflow", "status", "conclusion", "branch", "created", "duration", "actor", "commit", "subject", "pr", "url", "attempt", "queued", "cause"}
+}
+
+// terminalWidth returns the width of stdout, or 0 when it is not a terminal
+func terminalWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		return width
+	}
+	return 0
+}
+
+// layoutColumns computes column widths for the given runs, shrinking the
+// widest truncatable columns until the row fits the terminal
+func layoutColumns(runs []WorkflowRun, columns []string, width int) []int {
+	widths := make([]int, len(columns))
+	for i, name := range columns {
+		column := runColumns[name]
+		for _, run := range runs {
+			if n := utf8.RuneCountInString(column.value(run)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		if column.bracket {
+			widths[i] += 2
+		}
+		if column.maxWidth > 0 && widths[i] > column.maxWidth {
+			widths[i] = column.maxWidth
+		}
+	}
+
+	if width <= 0 {
+		return widths
+	}
+
+	// Row number prefix "%3d. " plus a space between columns
+	total := 5 + len(columns) - 1
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > width {
+		widest := -1
+		for i, name := range columns {
+			minWidth := runColumns[name].minWidth
+			if minWidth == 0 || widths[i] <= minWidth {
+				continue
+			}
+			if widest == -1 || widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string([]rune(s)[:width])
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// formatRunRow renders one run using the computed column widths. Padding is
+// computed from the visible text so escape codes don't disturb alignment.
+func formatRunRow(index int, run WorkflowRun, columns []string, widths []int, rowColor string) string {
+	cells := make([]string, len(columns))
+	for i, name := range columns {
+		column := runColumns[name]
+
+		cellWidth := widths[i]
+		if column.bracket {
+			cellWidth -= 2
+		}
+		text := truncate(column.value(run), max(cellWidth, 0))
+
+		padding := ""
+		if i < len(columns)-1 {
+			padding = strings.Repeat(" ", max(cellWidth-utf8.RuneCountInString(text), 0))
+		}
+		if column.color != nil {
+			// Restore the row color after the cell's own color resets it
+			text = qc.Colorize(text, column.color(run)) + rowColor
+		}
+		if column.bracket {
+			text = "[" + text + "]"
+		}
+		cells[i] = text + padding
+	}
+	return qc.Colorize(fmt.Sprintf("%3d. %s", index, strings.Join(cells, " ")), rowColor)
+}
+
+// formatRunEntry renders a run like formatRunRow, without the row number
+// or colors, for the fuzzy finder to match against
+func formatRunEntry(run WorkflowRun, columns []string, widths []int) string {
+	cells := make([]string, len(columns))
+	for i, name := range columns {
+		column := runColumns[name]
+		cellWidth := widths[i]
+		if column.bracket {
+			cellWidth -= 2
+		}
+		text := truncate(column.value(run), max(cellWidth, 0))
+		if i < len(columns)-1 {
+			text += strings.Repeat(" ", max(cellWidth-utf8.RuneCountInString(text), 0))
+		}
+		if column.bracket {
+			text = "[" + text + "]"
+		}
+		cells[i] = text
+	}
+	return strings.TrimRight(strings.Join(cells, " "), " ")
+}
+
+// shortSHA abbreviates a commit SHA
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// commitSubject returns the first line of a commit message
+func commitSubject(message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(subject)
+}
+
+// pullRequestRef formats the pull request of a run the way its platform
+// refers to it: #12 for a GitHub pull request, !12 for a GitLab merge request
+func pullRequestRef(run WorkflowRun) string {
+	switch {
+	case run.PullRequest == 0:
+		return ""
+	case run.Platform == "gitlab":
+		return fmt.Sprintf("!%d", run.PullRequest)
+	default:
+		return fmt.Sprintf("#%d", run.PullRequest)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// templateFuncs are the helpers available to --template on top of the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"short": shortSHA,
+	"time":  formatAbsoluteTime,
+	"ago": func(t time.Time) string {
+		return formatAgo(time.Since(t))
+	},
+	"duration": formatDuration,
+}
+
+// parseOutputTemplate parses the --template of a command run with
+// --format template
+func parseOutputTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, fmt.Errorf("--format template needs a --template, e.g. '{{.Project}} {{.Status}}'")
+	}
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate renders the template for one item followed by a newline,
+// so list commands print one line per item like docker --format
+func writeTemplate(w io.Writer, tmpl *template.Template, item any) error {
+	if err := tmpl.Execute(w, item); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// runDetails is the data --template sees for the details command: the run's
+// fields plus its jobs and notes
+type runDetails struct {
+	WorkflowRun
+	Jobs  []Job
+	Notes []RunNote
+}
+
+// showRunDetails shows a run's details, jobs, and failed step logs like
+// selecting it in watch, without the interactive prompts
+func showRunDetails(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("details", flag.ExitOnError)
+	projectName := fs.String("project", "", "Project the run belongs to (default: found from local history)")
+	logLines := fs.Int("log-lines", defaultLogLines, "Log lines to show for each failed step (0 to hide)")
+	format := fs.String("format", "text", "Output format: text or template")
+	templateText := fs.String("template", "", "Go template rendered with --format template, e.g. '{{.Status}} {{len .Jobs}}'")
+	copyWhat := fs.String("copy", "", "Copy the run's url, commit sha, or failed job log to the clipboard")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow details [--project <name>] [--copy url|sha|log] [--format template --template <template>] <run-id|url>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	if *format != "text" && *format != "template" {
+		fmt.Printf("%s Unsupported format: %s (expected text or template)\n", qc.Colorize("Error:", qc.ColorRed), *format)
+		return
+	}
+	if *copyWhat != "" && *copyWhat != "url" && *copyWhat != "sha" && *copyWhat != "log" {
+		fmt.Printf("%s Unsupported --copy: %s (expected url, sha, or log)\n", qc.Colorize("Error:", qc.ColorRed), *copyWhat)
+		return
+	}
+	var tmpl *template.Template
+	if *format == "template" {
+		var err error
+		if tmpl, err = parseOutputTemplate(*templateText); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	}
+	project, runID, err := resolveRun(config, *projectName, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	run, err := getWorkflowRun(ctx, *project, runID)
+	if err != nil {
+		fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	if tmpl == nil {
+		jobs, _ := showWorkflowDetails(ctx, config, *run, *logLines)
+		if *copyWhat != "" {
+			fmt.Println()
+			copyRunText(ctx, *run, jobs, *copyWhat, *logLines)
+		}
+		return
+	}
+
+	jobs, err := getJobsForRun(ctx, *run)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s Failed to get jobs: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	details := runDetails{WorkflowRun: *run, Jobs: jobs, Notes: runNotes(config, run.Project, run.ID)}
+	if err := writeTemplate(os.Stdout, tmpl, details); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// TestRecord counts how often a test failed across the runs whose test
+// reports were read
+type TestRecord struct {
+	Project    string    `json:"project"`
+	Suite      string    `json:"suite,omitempty"`
+	Name       string    `json:"name"`
+	Runs       int       `json:"runs"`
+	Failures   int       `json:"failures"`
+	LastFailed time.Time `json:"last_failed,omitempty"`
+	LastRunID  string    `json:"last_run_id,omitempty"`
+}
+
+// testHistory is the test failure counts kept next to the state file
+type testHistory struct {
+	// Recorded lists project#run of the runs already counted, so reading a
+	// report twice doesn't count it twice
+	Recorded []string     `json:"recorded"`
+	Tests    []TestRecord `json:"tests"`
+}
+
+// testHistoryFile returns the path of the test failure counts
+func testHistoryFile(config *Config) string {
+	return filepath.Join(filepath.Dir(config.StateFile), "tests.json")
+}
+
+// recordTestResults adds a run's test results to the failure counts
+func recordTestResults(config *Config, run WorkflowRun, results []TestResult) error {
+	path := testHistoryFile(config)
+	lock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	var history testHistory
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &history); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	key := run.Project + "#" + run.ID
+	if slices.Contains(history.Recorded, key) {
+		return nil
+	}
+	history.Recorded = append(history.Recorded, key)
+
+	index := make(map[string]int, len(history.Tests))
+	for i, record := range history.Tests {
+		index[record.Project+"\x00"+record.Suite+"\x00"+record.Name] = i
+	}
+	for _, result := range results {
+		if result.Status == "skipped" {
+			continue
+		}
+		id := run.Project + "\x00" + result.Suite + "\x00" + result.Name
+		i, ok := index[id]
+		if !ok {
+			history.Tests = append(history.Tests, TestRecord{Project: run.Project, Suite: result.Suite, Name: result.Name})
+			i = len(history.Tests) - 1
+			index[id] = i
+		}
+		record := &history.Tests[i]
+		record.Runs++
+		if isFailedTest(result) {
+			record.Failures++
+			record.LastFailed = run.CreatedAt
+			record.LastRunID = run.ID
+		}
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// loadTestHistory reads the test failure counts, empty when none were kept
+func loadTestHistory(config *Config) (testHistory, error) {
+	var history testHistory
+	data, err := os.ReadFile(testHistoryFile(config))
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return history, err
+	}
+	err = json.Unmarshal(data, &history)
+	return history, err
+}
+
+// getTestResults retrieves the test results of a run: parsed from JUnit
+// artifacts on GitHub, from the pipeline test report on GitLab
+func getTestResults(ctx context.Context, project Project, runID, artifact string) ([]TestResult, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetTestReports(project.Owner, project.Repo, runID, artifact)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPipelineTestReport(project.Name, runID)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// isFailedTest reports whether a test result is a failure or error
+func isFailedTest(result TestResult) bool {
+	return result.Status == "failed" || result.Status == "error"
+}
+
+// showTestResults summarizes a run's test report with its failed tests and
+// counts them towards each test's failure history, or with --history shows
+// that history
+func showTestResults(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("tests", flag.ExitOnError)
+	projectName := fs.String("project", "", "Project the run belongs to (default: found from local history)")
+	artifact := fs.String("artifact", "", "GitHub artifact holding the JUnit reports (default: artifacts named like test reports)")
+	all := fs.Bool("all", false, "List every test, not just failures")
+	history := fs.Bool("history", false, "Show how often each test failed across the runs read so far")
+	fs.Parse(args)
+
+	if *history {
+		showTestHistory(config, *projectName)
+		return
+	}
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow tests [--project <name>] [--artifact name] [--all] <run-id> | --history\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project, runID, err := resolveRun(config, *projectName, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	run, err := getWorkflowRun(ctx, *project, runID)
+	if err != nil {
+		fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	results, err := getTestResults(ctx, *project, runID, *artifact)
+	if err != nil {
+		fmt.Printf("%s Failed to get test reports: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if len(results) == 0 {
+		fmt.Printf("%s No JUnit test reports found for run %s\n", qc.Colorize("Info:", qc.ColorCyan), runID)
+		return
+	}
+
+	// Only finished runs have complete reports worth counting
+	if isRunFinished(run.Status) {
+		if err := recordTestResults(config, *run, results); err != nil {
+			fmt.Printf("%s Failed to record test history: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Tests for %s run %s:", project.Name, runID), qc.ColorBlue))
+	sort.SliceStable(results, func(i, j int) bool {
+		return isFailedTest(results[i]) && !isFailedTest(results[j])
+	})
+	for _, result := range results {
+		if !*all && !isFailedTest(result) {
+			continue
+		}
+		marker := qc.Colorize("✓", qc.ColorGreen)
+		switch result.Status {
+		case "failed", "error":
+			marker = qc.Colorize("✗", qc.ColorRed)
+		case "skipped":
+			marker = qc.Colorize("○", qc.ColorYellow)
+		}
+		fmt.Printf("  %s %s %s\n", marker, qc.Colorize(result.Suite, qc.ColorCyan), result.Name)
+		if isFailedTest(result) && result.Message != "" {
+			fmt.Printf("      %s\n", qc.Colorize(truncate(result.Message, 200), qc.ColorRed))
+		}
+	}
+
+	fmt.Printf("\n%s %d tests: %d passed, %d failed, %d errors, %d skipped\n",
+		qc.Colorize("Summary:", qc.ColorBlue), len(results), counts["passed"], counts["failed"], counts["error"], counts["skipped"])
+}
+
+// showTestHistory lists the tests that failed in the runs read so far,
+// most failures first. Tests that both passed and failed are likely flaky.
+func showTestHistory(config *Config, projectName string) {
+	history, err := loadTestHistory(config)
+	if err != nil {
+		fmt.Printf("%s Failed to read test history: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	var failing []TestRecord
+	for _, record := range history.Tests {
+		if record.Failures > 0 && (projectName == "" || record.Project == projectName) {
+			failing = append(failing, record)
+		}
+	}
+	if len(failing) == 0 {
+		fmt.Printf("%s No test failures recorded. Use 'quick_workflow tests <run-id>' to read a run's test reports.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+	sort.Slice(failing, func(i, j int) bool {
+		if failing[i].Failures != failing[j].Failures {
+			return failing[i].Failures > failing[j].Failures
+		}
+		return failing[i].LastFailed.After(failing[j].LastFailed)
+	})
+
+	fmt.Printf("%s\n", qc.Colorize("Test failures:", qc.ColorBlue))
+	for i, record := range failing {
+		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+		entry := fmt.Sprintf("%3d. %-20s %3d/%-3d failed  last %s (run %s)", i+1, record.Project, record.Failures, record.Runs, formatRunTime(record.LastFailed), record.LastRunID)
+		verdict := ""
+		if record.Failures < record.Runs {
+			verdict = qc.Colorize(" flaky?", qc.ColorYellow)
+		}
+		fmt.Printf("%s  %s %s%s\n", qc.Colorize(entry, rowColor), record.Suite, record.Name, verdict)
+	}
+}
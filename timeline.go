@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// timelineEvent is a single entry in a run's timeline
+type timelineEvent struct {
+	At     time.Time
+	Source string // "provider" or "local"
+	Text   string
+	Color  string
+}
+
+// showTimeline lists every known event for a run in order, combining
+// provider data with locally recorded history, triggers, and notes
+func showTimeline(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("timeline", flag.ExitOnError)
+	projectName := fs.String("project", "", "Project the run belongs to (default: found from local history)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%s Usage: quick_workflow timeline [--project <name>] <run-id>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project, runID, err := resolveRun(config, *projectName, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	run, err := getWorkflowRun(ctx, *project, runID)
+	if err != nil {
+		fmt.Printf("%s Failed to get run: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	events := providerRunEvents(*run)
+
+	jobs, err := getJobsForRun(ctx, *run)
+	if err != nil {
+		fmt.Printf("%s Failed to get jobs: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	events = append(events, jobEvents(jobs)...)
+
+	local, err := localRunEvents(config, *run)
+	if err != nil {
+		fmt.Printf("%s Failed to read history: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	events = append(events, local...)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Timeline for %s run %s (%s):", run.Project, run.ID, run.Workflow), qc.ColorBlue))
+	fmt.Println()
+	for _, event := range events {
+		fmt.Printf(
+			"  %s  %-8s %s\n",
+			formatAbsoluteTime(event.At),
+			qc.Colorize(event.Source, qc.ColorCyan),
+			qc.Colorize(event.Text, event.Color),
+		)
+	}
+}
+
+// resolveRunProject finds the project a run belongs to, from the explicit
+// name, the local history, or the only tracked project
+func resolveRunProject(config *Config, name, runID string) (*Project, error) {
+	if name != "" {
+		project := findProject(config, name)
+		if project == nil {
+			return nil, fmt.Errorf("project not found: %s", name)
+		}
+		return project, nil
+	}
+
+	records, err := queryHistory(config, HistoryFilter{RunID: runID})
+	if err != nil {
+		return nil, err
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].ID == runID {
+			if project := findProject(config, records[i].Project); project != nil {
+				return project, nil
+			}
+		}
+	}
+	for _, triggered := range config.Triggers {
+		if triggered.RunID == runID {
+			if project := findProject(config, triggered.Project); project != nil {
+				return project, nil
+			}
+		}
+	}
+
+	if len(config.Projects) == 1 {
+		return &config.Projects[0], nil
+	}
+	return nil, fmt.Errorf("run %s is not in local history; specify --project", runID)
+}
+
+// providerRunEvents derives run-level events from the provider's run data
+func providerRunEvents(run WorkflowRun) []timelineEvent {
+	events := []timelineEvent{{
+		At:     run.CreatedAt,
+		Source: "provider",
+		Text:   fmt.Sprintf("Run created on %s by %s", run.Branch, run.TriggeredBy),
+		Color:  qc.ColorWhite,
+	}}
+
+	if run.Attempt > 1 && !run.StartedAt.IsZero() {
+		events = append(events, timelineEvent{
+			At:     run.StartedAt,
+			Source: "provider",
+			Text:   fmt.Sprintf("Re-run attempt %d started", run.Attempt),
+			Color:  qc.ColorYellow,
+		})
+	}
+
+	if isRunFinished(run.Status) {
+		conclusion := run.Conclusion
+		if conclusion == "" {
+			conclusion = run.Status
+		}
+		text := fmt.Sprintf("Run finished: %s", conclusion)
+		if conclusion == "cancelled" || conclusion == "canceled" {
+			text = "Run cancelled"
+		}
+		events = append(events, timelineEvent{
+			At:     run.UpdatedAt,
+			Source: "provider",
+			Text:   text,
+			Color:  colorWorkflowStatus(run.Status, run.Conclusion),
+		})
+	}
+	return events
+}
+
+// jobEvents derives queued, started, and finished events for each job
+func jobEvents(jobs []Job) []timelineEvent {
+	var events []timelineEvent
+	for _, job := range jobs {
+		if job.CreatedAt != nil {
+			events = append(events, timelineEvent{
+				At:     *job.CreatedAt,
+				Source: "provider",
+				Text:   fmt.Sprintf("Job queued: %s", job.Name),
+				Color:  qc.ColorYellow,
+			})
+		}
+		if job.StartedAt != nil {
+			events = append(events, timelineEvent{
+				At:     *job.StartedAt,
+				Source: "provider",
+				Text:   fmt.Sprintf("Job started: %s", job.Name),
+				Color:  qc.ColorBlue,
+			})
+		}
+		if job.CompletedAt != nil {
+			conclusion := job.Conclusion
+			if conclusion == "" {
+				conclusion = job.Status
+			}
+			events = append(events, timelineEvent{
+				At:     *job.CompletedAt,
+				Source: "provider",
+				Text:   fmt.Sprintf("Job finished: %s (%s)", job.Name, conclusion),
+				Color:  colorJobStatus(job.Status, job.Conclusion),
+			})
+		}
+	}
+	return events
+}
+
+// localRunEvents collects events recorded by this tool for a run
+func localRunEvents(config *Config, run WorkflowRun) ([]timelineEvent, error) {
+	var events []timelineEvent
+
+	for _, triggered := range config.Triggers {
+		if triggered.Project != run.Project || triggered.RunID != run.ID {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, triggered.TriggeredAt); err == nil {
+			events = append(events, timelineEvent{
+				At:     t,
+				Source: "local",
+				Text:   fmt.Sprintf("Triggered '%s' with quick_workflow", triggered.Workflow),
+				Color:  qc.ColorGreen,
+			})
+		}
+	}
+
+	for _, note := range runNotes(config, run.Project, run.ID) {
+		if t, err := time.Parse(time.RFC3339, note.CreatedAt); err == nil {
+			text := "Note: " + note.Text
+			if note.PostedTo != "" {
+				text += " (posted to " + note.PostedTo + ")"
+			}
+			events = append(events, timelineEvent{
+				At:     t,
+				Source: "local",
+				Text:   text,
+				Color:  qc.ColorWhite,
+			})
+		}
+	}
+
+	records, err := queryHistory(config, HistoryFilter{Platform: run.Platform, Project: run.Project, RunID: run.ID})
+	if err != nil {
+		return events, err
+	}
+	for _, record := range records {
+		events = append(events, timelineEvent{
+			At:     record.ObservedAt,
+			Source: "local",
+			Text:   fmt.Sprintf("Observed status %s", record.Status),
+			Color:  colorWorkflowStatus(record.Status, record.Conclusion),
+		})
+	}
+
+	return events, nil
+}
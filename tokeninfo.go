@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// tokenExpiryWarning is how long before a token expires auth starts warning
+const tokenExpiryWarning = 7 * 24 * time.Hour
+
+// TokenInfo is what a provider reports about a token
+type TokenInfo struct {
+	User string
+	// Scopes is nil when the provider doesn't list them, as for GitHub
+	// fine-grained tokens, whose permissions are set per repository
+	Scopes      []string
+	FineGrained bool
+	// ExpiresAt is zero for tokens that don't expire
+	ExpiresAt time.Time
+}
+
+// scopeNeed is a feature and the token scopes, any of which it needs
+type scopeNeed struct {
+	feature string
+	scopes  []string
+}
+
+// githubScopeNeeds are the classic token scopes features need. A scope's
+// broader variants are listed with it, as GitHub reports only what was
+// granted.
+var githubScopeNeeds = []scopeNeed{
+	{"private repositories, workflow dispatch, re-runs, and secrets", []string{"repo"}},
+	{"organization information", []string{"read:org", "write:org", "admin:org"}},
+	{"organization runners", []string{"admin:org", "manage_runners:org"}},
+}
+
+// gitlabScopeNeeds are the token scopes features need
+var gitlabScopeNeeds = []scopeNeed{
+	{"reading pipelines", []string{"read_api", "api"}},
+	{"triggering, cancelling, and retrying pipelines, and CI/CD variables", []string{"api"}},
+}
+
+// missingFeatures returns the features among needs the token's scopes
+// don't cover, nil when its scopes aren't known
+func (i *TokenInfo) missingFeatures(needs []scopeNeed) []string {
+	if i.Scopes == nil {
+		return nil
+	}
+	var missing []string
+	for _, need := range needs {
+		granted := false
+		for _, scope := range need.scopes {
+			granted = granted || slices.Contains(i.Scopes, scope)
+		}
+		if !granted {
+			missing = append(missing, fmt.Sprintf("%s (needs %s)", need.feature, need.scopes[0]))
+		}
+	}
+	return missing
+}
+
+// getGitHubTokenInfo asks GitHub who a token belongs to. Classic and OAuth
+// tokens list their scopes in the X-OAuth-Scopes header, and tokens with an
+// expiry date report it in GitHub-Authentication-Token-Expiration.
+func getGitHubTokenInfo(ctx context.Context, host, token string) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", githubAPIURL(host)+"user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError("github", resp)
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+
+	info := &TokenInfo{User: user.Login, FineGrained: strings.HasPrefix(token, "github_pat_")}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok && !info.FineGrained {
+		info.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	if expiry := resp.Header.Get("GitHub-Authentication-Token-Expiration"); expiry != "" {
+		for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+			if t, err := time.Parse(layout, expiry); err == nil {
+				info.ExpiresAt = t
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+// getGitLabTokenInfo asks GitLab who a token belongs to and, for personal,
+// project, and group access tokens, its scopes and expiry date. OAuth
+// tokens, as glab may use, only report the user.
+func getGitLabTokenInfo(ctx context.Context, host, token string) (*TokenInfo, error) {
+	get := func(path string, v any) (int, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://%s/api/v4/%s", host, path), nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := newHTTPClient().Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, responseError("gitlab", resp)
+		}
+		return resp.StatusCode, json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	if _, err := get("user", &user); err != nil {
+		return nil, err
+	}
+	info := &TokenInfo{User: user.Username}
+
+	var self struct {
+		Scopes    []string `json:"scopes"`
+		ExpiresAt string   `json:"expires_at"`
+	}
+	if _, err := get("personal_access_tokens/self", &self); err == nil {
+		info.Scopes = self.Scopes
+		if info.Scopes == nil {
+			info.Scopes = []string{}
+		}
+		if t, err := time.ParseInLocation("2006-01-02", self.ExpiresAt, time.UTC); err == nil {
+			// Tokens stop working at the start of their expiry date
+			info.ExpiresAt = t
+		}
+	}
+	return info, nil
+}
+
+// showTokenInfo prints the user, scopes, and expiry of an authenticated
+// token beneath its status line, warning about features its scopes leave
+// out and about expiry within tokenExpiryWarning
+func showTokenInfo(ctx context.Context, platform, host, token string) {
+	var info *TokenInfo
+	var err error
+	needs := githubScopeNeeds
+	if platform == "gitlab" {
+		info, err = getGitLabTokenInfo(ctx, host, token)
+		needs = gitlabScopeNeeds
+	} else {
+		info, err = getGitHubTokenInfo(ctx, host, token)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("  %s Could not check the token: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		}
+		return
+	}
+
+	fmt.Printf("  User: %s\n", qc.ColorizeBold(info.User, qc.ColorWhite))
+	switch {
+	case info.FineGrained:
+		fmt.Printf("  Scopes: fine-grained, permissions are set per repository; use 'quick_workflow verify <project>' to check them\n")
+	case info.Scopes == nil:
+		fmt.Printf("  Scopes: not reported for this kind of token\n")
+	case len(info.Scopes) == 0:
+		fmt.Printf("  Scopes: none\n")
+	default:
+		fmt.Printf("  Scopes: %s\n", strings.Join(info.Scopes, ", "))
+	}
+
+	if !info.ExpiresAt.IsZero() {
+		left := time.Until(info.ExpiresAt)
+		expires := fmt.Sprintf("%s (in %d days)", info.ExpiresAt.In(displayLocation).Format("2006-01-02"), int(left.Hours()/24))
+		switch {
+		case left <= 0:
+			fmt.Printf("  Expires: %s\n", qc.Colorize(info.ExpiresAt.In(displayLocation).Format("2006-01-02")+" (expired)", qc.ColorRed))
+		case left < tokenExpiryWarning:
+			fmt.Printf("  Expires: %s\n", qc.Colorize(expires, qc.ColorYellow))
+		default:
+			fmt.Printf("  Expires: %s\n", expires)
+		}
+	} else if info.Scopes != nil || info.FineGrained {
+		fmt.Printf("  Expires: never\n")
+	}
+
+	for _, feature := range info.missingFeatures(needs) {
+		fmt.Printf("  %s Scopes don't allow %s\n", qc.Colorize("Warning:", qc.ColorYellow), feature)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// sparkBlocks are the bar heights of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// minTrendRuns is the fewest runs a workflow needs before its latest run is
+// judged against the median
+const minTrendRuns = 5
+
+// workflowTrend is the durations of a workflow's successful runs, oldest
+// first
+type workflowTrend struct {
+	Workflow  string
+	Durations []time.Duration
+}
+
+// latest returns the duration of the newest run
+func (t workflowTrend) latest() time.Duration {
+	return t.Durations[len(t.Durations)-1]
+}
+
+// baseline returns the median duration of the runs before the newest
+func (t workflowTrend) baseline() time.Duration {
+	return medianDuration(t.Durations[:len(t.Durations)-1])
+}
+
+// regression returns how much slower the newest run was than the baseline
+// in percent, and whether there are enough runs to tell
+func (t workflowTrend) regression() (float64, bool) {
+	if len(t.Durations) < minTrendRuns {
+		return 0, false
+	}
+	baseline := t.baseline()
+	if baseline <= 0 {
+		return 0, false
+	}
+	return (float64(t.latest())/float64(baseline) - 1) * 100, true
+}
+
+// medianDuration returns the median of durations, 0 when there are none
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// sparkline draws values as bars scaled between the lowest and highest
+func sparkline[T time.Duration | float64](values []T) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lowest, highest := values[0], values[0]
+	for _, v := range values {
+		lowest = min(lowest, v)
+		highest = max(highest, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := len(sparkBlocks) / 2
+		if highest > lowest {
+			level = int(float64(v-lowest) / float64(highest-lowest) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// workflowTrends groups the successful finished runs by workflow, keeping
+// the newest limit of each, oldest first. Failed runs stop early, so their
+// durations would hide slowdowns.
+func workflowTrends(runs []WorkflowRun, limit int) []workflowTrend {
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+
+	byWorkflow := make(map[string]*workflowTrend)
+	var order []string
+	for _, run := range runs {
+		if !isRunFinished(run.Status) || run.Conclusion != "success" || run.CreatedAt.IsZero() {
+			continue
+		}
+		trend := byWorkflow[run.Workflow]
+		if trend == nil {
+			trend = &workflowTrend{Workflow: run.Workflow}
+			byWorkflow[run.Workflow] = trend
+			order = append(order, run.Workflow)
+		}
+		if len(trend.Durations) < limit {
+			trend.Durations = append(trend.Durations, run.UpdatedAt.Sub(run.CreatedAt))
+		}
+	}
+
+	sort.Strings(order)
+	trends := make([]workflowTrend, 0, len(order))
+	for _, workflow := range order {
+		trend := *byWorkflow[workflow]
+		for i, j := 0, len(trend.Durations)-1; i < j; i, j = i+1, j-1 {
+			trend.Durations[i], trend.Durations[j] = trend.Durations[j], trend.Durations[i]
+		}
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// showTrends draws each workflow's recent run durations as a sparkline and
+// flags workflows whose latest run was much slower than their median. With
+// --coverage it also charts each project's coverage.
+func showTrends(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("trends", flag.ExitOnError)
+	runs := fs.Int("runs", 30, "Number of successful runs per workflow to chart")
+	branch := fs.String("branch", "", "Only chart runs on this branch")
+	threshold := fs.Float64("threshold", 20, "Flag workflows whose latest run was this many percent slower than the median")
+	coverage := fs.Bool("coverage", false, "Also chart the coverage of each project's successful runs")
+	fs.Parse(args)
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	// Runs of all workflows come mixed, and failures are skipped, so fetch
+	// a few times more than are charted
+	fetch := min(max(*runs*3, maxPageSize), maxListRuns)
+	regressions, drops := 0, 0
+	for _, project := range projects {
+		recent, err := getWorkflowRunsForProject(ctx, project, *branch, fetch)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Printf("%s Failed to get runs for %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, describeError(err))
+			continue
+		}
+
+		trends := workflowTrends(recent, *runs)
+		fmt.Printf("\n%s\n", qc.ColorizeBold(project.Name, qc.ColorGreen))
+		if len(trends) == 0 {
+			fmt.Printf("  %s No successful runs found\n", qc.Colorize("Info:", qc.ColorCyan))
+			continue
+		}
+		if *coverage && showCoverageTrend(ctx, config, project, recent, *runs) {
+			drops++
+		}
+
+		width, sparkWidth := 8, 0
+		for _, trend := range trends {
+			width = max(width, len(trend.Workflow))
+			sparkWidth = max(sparkWidth, len(trend.Durations))
+		}
+		for i, trend := range trends {
+			rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
+			entry := fmt.Sprintf("  %-*s %-*s", width, trend.Workflow, sparkWidth, sparkline(trend.Durations))
+			summary := fmt.Sprintf("latest %s", formatDuration(trend.latest()))
+
+			verdict := ""
+			if change, ok := trend.regression(); ok {
+				summary += fmt.Sprintf(", median %s, %+.0f%%", formatDuration(trend.baseline()), change)
+				if change > *threshold {
+					verdict = qc.Colorize(" slower", qc.ColorRed)
+					regressions++
+				}
+			}
+			fmt.Printf("%s %s%s\n", qc.Colorize(entry, rowColor), summary, verdict)
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Printf("\n%s %d workflow(s) more than %.0f%% slower than their median\n", qc.Colorize("Warning:", qc.ColorYellow), regressions, *threshold)
+	}
+	if drops > 0 {
+		fmt.Printf("\n%s Coverage dropped in %d project(s)\n", qc.Colorize("Warning:", qc.ColorYellow), drops)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// upstreamProject finds the repository a fork's pull requests go to: the
+// repository of the named remote or, on GitHub when there is no such
+// remote, the repository the fork was made from
+func upstreamProject(ctx context.Context, path string, fork Project, remote string) (*Project, error) {
+	upstream, err := detectRemoteProject(path, remote)
+	if err == nil {
+		if upstream.Name == fork.Name {
+			return nil, fmt.Errorf("remote %s is the same repository as origin", remote)
+		}
+		if upstream.Platform != fork.Platform {
+			return nil, fmt.Errorf("remote %s is on %s, but origin is on %s", remote, upstream.Platform, fork.Platform)
+		}
+		return upstream, nil
+	}
+	if fork.Platform != "github" {
+		return nil, err
+	}
+
+	client, clientErr := NewGitHubClient(ctx, fork.Name)
+	if clientErr != nil {
+		return nil, clientErr
+	}
+	owner, repo, parentErr := client.GetForkParent(fork.Owner, fork.Repo)
+	if parentErr != nil {
+		return nil, fmt.Errorf("%v, and %v", err, parentErr)
+	}
+	upstream = &Project{
+		Name:     owner + "/" + repo,
+		Owner:    owner,
+		Repo:     repo,
+		Platform: fork.Platform,
+		Account:  fork.Account,
+	}
+	// The parent is on the fork's host, so it uses the fork's account
+	registerProjectAccounts([]Project{*upstream})
+	return upstream, nil
+}
+
+// getUpstreamStatus retrieves the checks of the open pull or merge request
+// from the fork's branch into upstream
+func getUpstreamStatus(ctx context.Context, upstream, fork Project, branch string) (*MergeStatus, error) {
+	switch upstream.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, upstream.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPullStatus(upstream.Owner, upstream.Repo, fork.Owner+":"+branch)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, upstream.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetForkMergeStatus(upstream.Name, fork.Name, branch)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", upstream.Platform)
+	}
+}
+
+// showUpstreamStatus shows the checks of the fork branch's pull request on
+// upstream. With watch, it polls every interval, printing the checks'
+// transitions, until all reported checks finish. It returns the exit
+// status the checks stand for: 1 when merging is blocked or watching was
+// cut short, else 0.
+func showUpstreamStatus(ctx context.Context, upstream, fork Project, branch string, watch bool, interval time.Duration) int {
+	status, err := getUpstreamStatus(ctx, upstream, fork, branch)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("\n%s Failed to get the pull request on %s: %v\n", qc.Colorize("Error:", qc.ColorRed), upstream.Name, describeError(err))
+		}
+		return 1
+	}
+
+	if watch {
+		fmt.Printf("\n%s %s on %s (polling every %s, Ctrl+C to stop)\n",
+			qc.Colorize("Following", qc.ColorBlue), pullRequestRef(WorkflowRun{Platform: upstream.Platform, PullRequest: status.PullRequest}),
+			qc.ColorizeBold(upstream.Name, qc.ColorGreen), interval)
+		states := make(map[string]string)
+		for {
+			for _, check := range status.Checks {
+				state := jobState(Job{Status: check.Status, Conclusion: check.Conclusion})
+				if previous, seen := states[check.Name]; !seen || previous != state {
+					printTransition("  "+check.Name, previous, state, colorWorkflowStatus(check.Status, check.Conclusion))
+					states[check.Name] = state
+				}
+			}
+			if len(status.Checks) > 0 && upstreamChecksFinished(status) {
+				break
+			}
+			if sleepContext(ctx, interval) != nil {
+				return 1
+			}
+			latest, err := getUpstreamStatus(ctx, upstream, fork, branch)
+			if ctx.Err() != nil {
+				return 1
+			}
+			if err != nil {
+				fmt.Printf("%s Failed to get checks: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+				continue
+			}
+			status = latest
+		}
+	}
+
+	fmt.Printf("\n%s %s\n", qc.Colorize("Upstream:", qc.ColorBlue), qc.ColorizeBold(upstream.Name, qc.ColorGreen))
+	displayMergeStatus(upstream.Platform, status)
+	if verdict, _ := status.verdict(); verdict == "blocked" {
+		return 1
+	}
+	return 0
+}
+
+// upstreamChecksFinished reports whether every reported check finished.
+// Manual GitLab jobs wait for someone to start them, so they count too.
+func upstreamChecksFinished(status *MergeStatus) bool {
+	for _, check := range status.Checks {
+		if !isRunFinished(check.Status) && check.Status != "manual" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// runnerMultipliers are GitHub's per-minute rates of hosted runners
+// relative to Linux
+var runnerMultipliers = map[string]float64{
+	"UBUNTU":  1,
+	"WINDOWS": 2,
+	"MACOS":   10,
+}
+
+// workflowUsage totals the usage of one workflow (GitHub) or ref (GitLab)
+type workflowUsage struct {
+	Name    string
+	Runs    int
+	Minutes float64
+	// Billed weighs GitHub minutes by runner OS; GitLab minutes as-is
+	Billed float64
+}
+
+// usageFields are the fields available to usage --fields, in export order
+var usageFields = []string{"month", "project", "platform", "workflow", "runs", "minutes", "billed"}
+
+// getRunUsage retrieves the runner time of a project's runs in a month
+func getRunUsage(ctx context.Context, project Project, month time.Time) ([]RunUsage, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetRunUsage(project.Owner, project.Repo, month)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPipelineUsage(project.Name, month)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// getBillingSummary retrieves the current billing period's minutes of the
+// account owning a project
+func getBillingSummary(ctx context.Context, project Project) (*BillingSummary, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetActionsBilling(project.Owner)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetComputeQuota(project.Name)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// summarizeUsage totals run usage per workflow, most billed first
+func summarizeUsage(runs []RunUsage) []workflowUsage {
+	byName := make(map[string]*workflowUsage)
+	for _, run := range runs {
+		usage, ok := byName[run.Workflow]
+		if !ok {
+			usage = &workflowUsage{Name: run.Workflow}
+			byName[run.Workflow] = usage
+		}
+		usage.Runs++
+		for machine, minutes := range run.Minutes {
+			usage.Minutes += minutes
+			multiplier, ok := runnerMultipliers[machine]
+			if !ok {
+				multiplier = 1
+			}
+			usage.Billed += minutes * multiplier
+		}
+	}
+
+	summary := make([]workflowUsage, 0, len(byName))
+	for _, usage := range byName {
+		summary = append(summary, *usage)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Billed != summary[j].Billed {
+			return summary[i].Billed > summary[j].Billed
+		}
+		return summary[i].Name < summary[j].Name
+	})
+	return summary
+}
+
+// exportUsage writes per workflow usage of the projects in a month to stdout
+// as CSV or TSV. Minutes keep one decimal; projects that fail go to stderr.
+func exportUsage(ctx context.Context, projects []Project, month time.Time, format string, fields []string) {
+	var rows [][]string
+	for _, project := range projects {
+		runs, err := getRunUsage(ctx, project, month)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+		for _, usage := range summarizeUsage(runs) {
+			values := map[string]string{
+				"month":    month.Format("2006-01"),
+				"project":  project.Name,
+				"platform": project.Platform,
+				"workflow": usage.Name,
+				"runs":     strconv.Itoa(usage.Runs),
+				"minutes":  strconv.FormatFloat(usage.Minutes, 'f', 1, 64),
+				"billed":   strconv.FormatFloat(usage.Billed, 'f', 1, 64),
+			}
+			row := make([]string, len(fields))
+			for i, field := range fields {
+				row[i] = values[field]
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if err := writeDelimited(os.Stdout, format, fields, rows); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Failed to write usage: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+	}
+}
+
+// showUsage reports the CI minutes tracked projects used in a month per
+// workflow, followed by each account's minutes for its billing period
+func showUsage(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	monthSpec := fs.String("month", "", "Month to report as YYYY-MM (default: current month)")
+	format := fs.String("format", "table", "Output format: table, csv, or tsv")
+	fieldSpec := fs.String("fields", "", "Comma separated fields to export with csv or tsv: "+strings.Join(usageFields, ","))
+	fs.Parse(args)
+
+	if *format != "table" && !isExportFormat(*format) {
+		fmt.Printf("%s Unsupported format: %s (expected table, csv, or tsv)\n", qc.Colorize("Error:", qc.ColorRed), *format)
+		return
+	}
+	fields, err := parseFields(*fieldSpec, usageFields, usageFields)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	now := time.Now().In(displayLocation)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, displayLocation)
+	if *monthSpec != "" {
+		parsed, err := time.ParseInLocation("2006-01", *monthSpec, displayLocation)
+		if err != nil {
+			fmt.Printf("%s Invalid month %q, expected YYYY-MM\n", qc.Colorize("Error:", qc.ColorRed), *monthSpec)
+			return
+		}
+		month = parsed
+	}
+
+	projects := config.Projects
+	if fs.NArg() > 0 {
+		project := findProject(config, fs.Arg(0))
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), fs.Arg(0))
+			return
+		}
+		projects = []Project{*project}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No projects tracked. Use 'quick_workflow add' to add projects.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	if isExportFormat(*format) {
+		exportUsage(ctx, projects, month, *format, fields)
+		return
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("CI usage for %s:", month.Format("January 2006")), qc.ColorBlue))
+	var total float64
+	for _, project := range projects {
+		runs, err := getRunUsage(ctx, project, month)
+		if err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", qc.Colorize("Warning:", qc.ColorYellow), project.Name, describeError(err))
+			continue
+		}
+
+		fmt.Printf("\n%s (%s)\n", qc.ColorizeBold(project.Name, qc.ColorWhite), qc.Colorize(project.Platform, colorPlatform(project.Platform)))
+		summary := summarizeUsage(runs)
+		if len(summary) == 0 {
+			fmt.Printf("  %s\n", qc.Colorize("No runs this month", qc.ColorCyan))
+			continue
+		}
+
+		nameWidth := len("Workflow")
+		for _, usage := range summary {
+			nameWidth = max(nameWidth, min(len(usage.Name), 40))
+		}
+		fmt.Printf("  %-*s %6s %9s %9s\n", nameWidth, "Workflow", "Runs", "Minutes", "Billed")
+		var projectTotal float64
+		for i, usage := range summary {
+			entry := fmt.Sprintf("  %-*s %6d %9.0f %9.0f", nameWidth, truncate(usage.Name, nameWidth), usage.Runs, usage.Minutes, usage.Billed)
+			fmt.Println(qc.Colorize(entry, qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)))
+			projectTotal += usage.Billed
+		}
+		fmt.Printf("  %-*s %6s %9s %9.0f\n", nameWidth, "Total", "", "", projectTotal)
+		if len(runs) >= maxUsageRuns {
+			fmt.Printf("  %s only the latest %d runs were counted\n", qc.Colorize("Note:", qc.ColorYellow), maxUsageRuns)
+		}
+		total += projectTotal
+	}
+	if len(projects) > 1 {
+		fmt.Printf("\n%s %.0f billed minutes across %d projects\n", qc.Colorize("Total:", qc.ColorBlue), total, len(projects))
+	}
+
+	// Account totals are only available for the current billing period
+	fmt.Printf("\n%s\n", qc.Colorize("Current billing period:", qc.ColorBlue))
+	seen := make(map[string]bool)
+	shown := 0
+	for _, project := range projects {
+		summary, err := getBillingSummary(ctx, project)
+		if err != nil || seen[project.Platform+"/"+summary.Account] {
+			continue
+		}
+		seen[project.Platform+"/"+summary.Account] = true
+		shown++
+
+		line := fmt.Sprintf("  %s %s: ", qc.Colorize(fmt.Sprintf("%-6s", project.Platform), colorPlatform(project.Platform)), summary.Account)
+		switch {
+		case project.Platform == "github":
+			line += fmt.Sprintf("%.0f of %.0f included minutes used, %.0f paid", summary.MinutesUsed, summary.IncludedMinutes, summary.PaidMinutesUsed)
+			var machines []string
+			for machine, minutes := range summary.Breakdown {
+				machines = append(machines, fmt.Sprintf("%s %d", strings.ToLower(machine), minutes))
+			}
+			sort.Strings(machines)
+			if len(machines) > 0 {
+				line += " (" + strings.Join(machines, ", ") + ")"
+			}
+		case summary.IncludedMinutes > 0:
+			line += fmt.Sprintf("quota of %.0f compute minutes per month", summary.IncludedMinutes)
+		default:
+			line += "no compute minute quota visible"
+		}
+		fmt.Println(line)
+	}
+	if shown == 0 {
+		fmt.Printf("  %s\n", qc.Colorize("Not available; reading billing needs an account owner or billing manager token", qc.ColorCyan))
+	}
+}
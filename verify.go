@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// capabilityCheck is the outcome of one API call made by verify
+type capabilityCheck struct {
+	Name   string
+	Detail string
+	Err    error
+	// Skipped checks depend on data an earlier check didn't find
+	Skipped bool
+}
+
+// getRepoAccess reports what the current token may do in a project
+func getRepoAccess(ctx context.Context, project Project) (RepoAccess, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return RepoAccess{}, err
+		}
+		return client.GetRepoAccess(project.Owner, project.Repo)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return RepoAccess{}, err
+		}
+		return client.GetProjectAccess(project.Name)
+	default:
+		return RepoAccess{}, fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+}
+
+// printCheck prints one check result aligned with the others
+func printCheck(name string, ok bool, detail string) {
+	marker, color := "✓", qc.ColorGreen
+	if !ok {
+		marker, color = "✗", qc.ColorRed
+	}
+	fmt.Printf("  %s %-22s %s\n", qc.Colorize(marker, color), name, detail)
+}
+
+// verifyProject exercises every API a project's features rely on without
+// changing anything, then reports which features work with the current
+// token
+func verifyProject(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow verify <project>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	project := findProject(config, args[0])
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+		return
+	}
+
+	fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Verifying %s (%s)...", project.Name, project.Platform), qc.ColorBlue))
+	var checks []capabilityCheck
+
+	runs, err := getWorkflowRunsForProject(ctx, *project, "", 5)
+	runsCheck := capabilityCheck{Name: "List runs", Err: err, Detail: fmt.Sprintf("%d recent run(s)", len(runs))}
+	checks = append(checks, runsCheck)
+
+	workflows, err := getAvailableWorkflows(ctx, *project)
+	what := "workflow(s)"
+	if project.Platform == "gitlab" {
+		what = "branch(es) to run pipelines on"
+	}
+	checks = append(checks, capabilityCheck{Name: "List workflows", Err: err, Detail: fmt.Sprintf("%d %s", len(workflows), what)})
+
+	jobsCheck := capabilityCheck{Name: "Fetch jobs", Skipped: true, Detail: "no runs to fetch jobs from"}
+	logsCheck := capabilityCheck{Name: "Fetch logs", Skipped: true, Detail: "no jobs to fetch a log from"}
+	if len(runs) > 0 {
+		run := runs[0]
+		jobs, err := getJobsForRun(ctx, run)
+		jobsCheck = capabilityCheck{Name: "Fetch jobs", Err: err, Detail: fmt.Sprintf("%d job(s) in run %s", len(jobs), run.ID)}
+		for _, job := range jobs {
+			// Skipped jobs never ran, so they have no log
+			if !isRunFinished(job.Status) || job.Status == "skipped" || job.Conclusion == "skipped" {
+				continue
+			}
+			log, err := fetchJobLog(ctx, run, job)
+			logsCheck = capabilityCheck{Name: "Fetch logs", Err: err, Detail: fmt.Sprintf("%d line(s) from %s", strings.Count(log, "\n"), job.Name)}
+			break
+		}
+	}
+	checks = append(checks, jobsCheck, logsCheck)
+
+	access, err := getRepoAccess(ctx, *project)
+	detail := "role: " + access.Role
+	if len(access.Scopes) > 0 {
+		detail += ", token scopes: " + strings.Join(access.Scopes, ", ")
+	}
+	accessCheck := capabilityCheck{Name: "Check permissions", Err: err, Detail: detail}
+	checks = append(checks, accessCheck)
+
+	failed := false
+	for _, check := range checks {
+		switch {
+		case check.Err != nil:
+			failed = true
+			printCheck(check.Name, false, describeError(check.Err))
+		case check.Skipped:
+			fmt.Printf("  %s %-22s %s\n", qc.Colorize("-", qc.ColorYellow), check.Name, check.Detail)
+		default:
+			printCheck(check.Name, true, check.Detail)
+		}
+	}
+
+	// Features with the check they depend on. A permission the token lacks
+	// is only reported when the permissions check itself worked.
+	roleNeeded := func(github, gitlab string) string {
+		if project.Platform == "gitlab" {
+			return "needs the " + gitlab + " role or higher"
+		}
+		return "needs " + github + " access"
+	}
+	features := []struct {
+		name    string
+		check   capabilityCheck
+		allowed bool
+		reason  string
+	}{
+		{"watch, list, status", runsCheck, true, ""},
+		{"run details", jobsCheck, true, ""},
+		{"failed step logs", logsCheck, true, ""},
+		{"start", accessCheck, access.CanTrigger && len(workflows) > 0, roleNeeded("write", "developer")},
+		{"re-run and cancel", accessCheck, access.CanTrigger, roleNeeded("write", "developer")},
+		{"notes on the platform", accessCheck, access.CanComment, roleNeeded("write", "reporter")},
+		{"webhooks", accessCheck, access.CanManageHooks, roleNeeded("admin", "maintainer")},
+	}
+	if access.CanTrigger && len(workflows) == 0 {
+		features[3].reason = "no workflows found"
+	}
+
+	fmt.Printf("\n%s\n", qc.Colorize("Features:", qc.ColorBlue))
+	for _, feature := range features {
+		switch {
+		case feature.check.Err != nil:
+			printCheck(feature.name, false, strings.ToLower(feature.check.Name)+" failed")
+		case feature.check.Skipped:
+			fmt.Printf("  %s %-22s %s\n", qc.Colorize("-", qc.ColorYellow), feature.name, "untested: "+feature.check.Detail)
+		case !feature.allowed:
+			printCheck(feature.name, false, feature.reason)
+		default:
+			printCheck(feature.name, true, "")
+		}
+	}
+
+	if failed {
+		fmt.Printf("\n%s Some checks failed; run 'quick_workflow auth' to check the token in use\n", qc.Colorize("Warning:", qc.ColorYellow))
+	}
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// WebhookConfig is a webhook created on a project by quick_workflow. The
+// secret signs deliveries so a receiver can verify them.
+type WebhookConfig struct {
+	Platform  string    `json:"platform"`
+	HookID    int64     `json:"hook_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+	RotatedAt time.Time `json:"rotated_at,omitempty"`
+}
+
+// WebhookDelivery is one attempt by a provider to deliver a webhook event
+type WebhookDelivery struct {
+	At         time.Time
+	Event      string
+	StatusCode int
+	Status     string
+}
+
+// webhooksFile returns the path of the webhook secrets, kept with auth.json
+// rather than in the state file so secrets are never exported
+func webhooksFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "webhooks.json"), nil
+}
+
+// loadWebhooks reads the stored webhooks keyed by project name
+func loadWebhooks() (map[string]WebhookConfig, error) {
+	path, err := webhooksFile()
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make(map[string]WebhookConfig)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return webhooks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// updateWebhooks applies fn to the stored webhooks under the file lock and
+// saves the result
+func updateWebhooks(fn func(webhooks map[string]WebhookConfig) error) error {
+	path, err := webhooksFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	lock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		return err
+	}
+	if err := fn(webhooks); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(webhooks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// generateWebhookSecret returns a random 256-bit hex secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// handleWebhook handles the webhook command
+func handleWebhook(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		listWebhooks()
+		return
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("webhook create", flag.ExitOnError)
+		url := fs.String("url", "", "URL of the webhook receiver")
+		fs.Parse(args[1:])
+		if *url == "" || fs.NArg() == 0 {
+			fmt.Printf("%s Usage: quick_workflow webhook create --url <receiver-url> <project>\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		createWebhook(ctx, config, fs.Arg(0), *url)
+	case "rotate", "verify", "delete":
+		if len(args) < 2 {
+			fmt.Printf("%s Usage: quick_workflow webhook %s <project>\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+			return
+		}
+		project := findProject(config, args[1])
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), args[1])
+			return
+		}
+		switch args[0] {
+		case "rotate":
+			rotateWebhook(ctx, *project)
+		case "verify":
+			verifyWebhook(ctx, *project)
+		case "delete":
+			deleteWebhook(ctx, *project)
+		}
+	default:
+		fmt.Printf("%s Unknown webhook command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+		fmt.Println("  Commands: list, create, rotate, verify, delete")
+	}
+}
+
+// listWebhooks shows stored webhooks without their secrets
+func listWebhooks() {
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if len(webhooks) == 0 {
+		fmt.Printf("%s No webhooks. Use 'quick_workflow webhook create --url <url> <project>'.\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	names := slices.Sorted(maps.Keys(webhooks))
+
+	fmt.Printf("%s\n", qc.Colorize("Webhooks:", qc.ColorBlue))
+	for i, name := range names {
+		webhook := webhooks[name]
+		rotated := "never rotated"
+		if !webhook.RotatedAt.IsZero() {
+			rotated = "rotated " + formatAgo(time.Since(webhook.RotatedAt))
+		}
+		entry := fmt.Sprintf("%3d. %-30s %-8s %s (%s)", i+1, name, webhook.Platform, webhook.URL, rotated)
+		fmt.Println(qc.Colorize(entry, qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)))
+	}
+}
+
+// createWebhook creates a webhook on the project and stores its secret
+func createWebhook(ctx context.Context, config *Config, name, url string) {
+	project := findProject(config, name)
+	if project == nil {
+		fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+		return
+	}
+
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if _, ok := webhooks[project.Name]; ok {
+		fmt.Printf("%s %s already has a webhook. Use 'quick_workflow webhook rotate %s' to change its secret.\n", qc.Colorize("Error:", qc.ColorRed), project.Name, project.Name)
+		return
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	var hookID int64
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err == nil {
+			hookID, err = client.CreateWebhook(project.Owner, project.Repo, url, secret)
+		}
+		if err != nil {
+			fmt.Printf("%s Failed to create webhook: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		var id int
+		if err == nil {
+			id, err = client.CreateProjectWebhook(project.Name, url, secret)
+		}
+		if err != nil {
+			fmt.Printf("%s Failed to create webhook: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		hookID = int64(id)
+	default:
+		fmt.Printf("%s Unsupported platform: %s\n", qc.Colorize("Error:", qc.ColorRed), project.Platform)
+		return
+	}
+
+	err = updateWebhooks(func(webhooks map[string]WebhookConfig) error {
+		webhooks[project.Name] = WebhookConfig{
+			Platform:  project.Platform,
+			HookID:    hookID,
+			URL:       url,
+			Secret:    secret,
+			CreatedAt: time.Now(),
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s Webhook %d was created but its secret could not be saved: %v\n", qc.Colorize("Error:", qc.ColorRed), hookID, describeError(err))
+		return
+	}
+
+	path, _ := webhooksFile()
+	fmt.Printf("%s Created webhook %d on %s\n", qc.Colorize("Success:", qc.ColorGreen), hookID, project.Name)
+	fmt.Printf("  Secret stored in %s\n", path)
+}
+
+// rotateWebhook replaces the secret of a project's webhook
+func rotateWebhook(ctx context.Context, project Project) {
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	webhook, ok := webhooks[project.Name]
+	if !ok {
+		fmt.Printf("%s No webhook stored for %s\n", qc.Colorize("Error:", qc.ColorRed), project.Name)
+		return
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	switch project.Platform {
+	case "github":
+		var client *GitHubClient
+		client, err = NewGitHubClient(ctx, project.Name)
+		if err == nil {
+			err = client.UpdateWebhookSecret(project.Owner, project.Repo, webhook.HookID, webhook.URL, secret)
+		}
+	case "gitlab":
+		var client *GitLabClient
+		client, err = NewGitLabClient(ctx, project.Name)
+		if err == nil {
+			err = client.UpdateProjectWebhookSecret(project.Name, int(webhook.HookID), webhook.URL, secret)
+		}
+	default:
+		err = fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+	if err != nil {
+		fmt.Printf("%s Failed to rotate webhook secret: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	err = updateWebhooks(func(webhooks map[string]WebhookConfig) error {
+		webhook.Secret = secret
+		webhook.RotatedAt = time.Now()
+		webhooks[project.Name] = webhook
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s The provider has the new secret but it could not be saved: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Rotated webhook secret for %s\n", qc.Colorize("Success:", qc.ColorGreen), project.Name)
+}
+
+// verifyWebhook sends a test event to a project's webhook and shows recent
+// deliveries where the provider reports them
+func verifyWebhook(ctx context.Context, project Project) {
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	webhook, ok := webhooks[project.Name]
+	if !ok {
+		fmt.Printf("%s No webhook stored for %s\n", qc.Colorize("Error:", qc.ColorRed), project.Name)
+		return
+	}
+
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		if err := client.PingWebhook(project.Owner, project.Repo, webhook.HookID); err != nil {
+			fmt.Printf("%s Failed to ping webhook: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		fmt.Printf("%s Sent ping to %s, waiting for delivery...\n", qc.Colorize("Info:", qc.ColorCyan), webhook.URL)
+		if err := sleepContext(ctx, dispatchPollInterval); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+
+		deliveries, err := client.GetWebhookDeliveries(project.Owner, project.Repo, webhook.HookID, 10)
+		if err != nil {
+			fmt.Printf("%s Failed to get deliveries: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		displayWebhookDeliveries(deliveries)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		if err := client.TestProjectWebhook(project.Name, int(webhook.HookID)); err != nil {
+			fmt.Printf("%s Test delivery to %s failed: %v\n", qc.Colorize("Error:", qc.ColorRed), webhook.URL, describeError(err))
+			return
+		}
+		fmt.Printf("%s Test pipeline event delivered to %s\n", qc.Colorize("Success:", qc.ColorGreen), webhook.URL)
+	default:
+		fmt.Printf("%s Unsupported platform: %s\n", qc.Colorize("Error:", qc.ColorRed), project.Platform)
+	}
+}
+
+// displayWebhookDeliveries lists deliveries, newest first, colored by outcome
+func displayWebhookDeliveries(deliveries []WebhookDelivery) {
+	if len(deliveries) == 0 {
+		fmt.Printf("%s No deliveries recorded yet\n", qc.Colorize("Warning:", qc.ColorYellow))
+		return
+	}
+
+	fmt.Printf("%s\n", qc.Colorize("Recent deliveries:", qc.ColorBlue))
+	for _, delivery := range deliveries {
+		color := qc.ColorGreen
+		if delivery.StatusCode < 200 || delivery.StatusCode >= 300 {
+			color = qc.ColorRed
+		}
+		fmt.Printf("  %s %-14s %s\n",
+			formatAbsoluteTime(delivery.At),
+			delivery.Event,
+			qc.Colorize(strings.TrimSpace(fmt.Sprintf("%d %s", delivery.StatusCode, delivery.Status)), color))
+	}
+}
+
+// deleteWebhook removes a project's webhook from the provider and forgets
+// its secret
+func deleteWebhook(ctx context.Context, project Project) {
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	webhook, ok := webhooks[project.Name]
+	if !ok {
+		fmt.Printf("%s No webhook stored for %s\n", qc.Colorize("Error:", qc.ColorRed), project.Name)
+		return
+	}
+
+	switch project.Platform {
+	case "github":
+		var client *GitHubClient
+		client, err = NewGitHubClient(ctx, project.Name)
+		if err == nil {
+			err = client.DeleteWebhook(project.Owner, project.Repo, webhook.HookID)
+		}
+	case "gitlab":
+		var client *GitLabClient
+		client, err = NewGitLabClient(ctx, project.Name)
+		if err == nil {
+			err = client.DeleteProjectWebhook(project.Name, int(webhook.HookID))
+		}
+	default:
+		err = fmt.Errorf("unsupported platform: %s", project.Platform)
+	}
+	if err != nil {
+		fmt.Printf("%s Failed to delete webhook: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	err = updateWebhooks(func(webhooks map[string]WebhookConfig) error {
+		delete(webhooks, project.Name)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	fmt.Printf("%s Deleted webhook for %s\n", qc.Colorize("Success:", qc.ColorGreen), project.Name)
+}
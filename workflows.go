@@ -3,112 +3,370 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	qc "github.com/bevelwork/quick_color"
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+const (
+	// maxPageSize is the largest page of runs providers return
+	maxPageSize = 100
+	// maxListRuns caps the runs list --all fetches for one project
+	maxListRuns = 1000
 )
 
 // watchWorkflows displays running workflows across all projects
-func watchWorkflows(ctx context.Context, config *Config) {
+func watchWorkflows(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	columnSpec := fs.String("columns", "", "Comma separated columns: "+strings.Join(columnNames(), ","))
+	layoutName := fs.String("layout", "", "Named layout from settings")
+	refresh := fs.String("refresh", "", "Redraw every interval, e.g. 30s (overrides the layout)")
+	logLines := fs.Int("log-lines", defaultLogLines, "Log lines to show for each failed step in run details (0 to hide)")
+	queuedOver := fs.Duration("queued-over", 0, "Only show runs whose jobs waited longer than this for a runner, e.g. 5m")
+	queueAlert := fs.Duration("queue-alert", 0, "Alert when an unfinished run waits longer than this for a runner")
+	followID := fs.String("run", "", "Follow this run until it finishes, printing job transitions, and exit 0 if it succeeded or 1 if not")
+	projectName := fs.String("project", "", "Project of the --run run (default: found from local history)")
+	fs.Parse(args)
+
+	if *followID != "" {
+		followRunByID(ctx, config, *projectName, *followID, *refresh)
+		return
+	}
+
+	layout := WatchLayout{}
+	if *layoutName != "" {
+		l, ok := config.Settings.Layouts[*layoutName]
+		if !ok {
+			fmt.Printf("%s Layout not found: %s\n", qc.Colorize("Error:", qc.ColorRed), *layoutName)
+			return
+		}
+		layout = l
+	}
+	if *columnSpec != "" {
+		layout.Columns = *columnSpec
+	}
+	if *refresh != "" {
+		layout.Refresh = *refresh
+	}
+	if err := layout.validate(); err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	columns, err := parseColumns(layout.Columns, config.Settings)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	interval, _ := layout.refreshInterval()
+
 	if len(config.Projects) == 0 {
 		fmt.Printf("%s No projects tracked. Use 'quick_workflow add .' to add a project.\n", qc.Colorize("Info:", qc.ColorCyan))
 		return
 	}
 
-	fmt.Printf("%s\n", qc.Colorize("Watching workflows across all projects...", qc.ColorBlue))
-	fmt.Println()
+	projects := layout.selectProjects(config.Projects)
+	if len(projects) == 0 {
+		fmt.Printf("%s No tracked projects match the layout\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
 
-	// Collect all workflow runs
-	var allRuns []WorkflowRun
-	for _, project := range config.Projects {
-		runs, err := getWorkflowRunsForProject(ctx, project, 10)
-		if err != nil {
-			fmt.Printf("%s Failed to get workflows for %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, err)
-			continue
+	// Redraw periodically until interrupted
+	if interval > 0 {
+		alerter := newQueueAlerter(*queueAlert)
+		for ctx.Err() == nil {
+			warnRateLimit(ctx, os.Stdout, projects, fetchRequests(needsQueueTimes(columns, *queuedOver+*queueAlert), 10))
+			allRuns, failures := collectRuns(ctx, config, projects, 10)
+			if ctx.Err() != nil {
+				break
+			}
+			allRuns = layout.filterRuns(append(allRuns, staleRuns(config, failures)...))
+			if needsQueueTimes(columns, *queuedOver+*queueAlert) {
+				fillQueueTimes(ctx, allRuns)
+			}
+			fillColumnValues(ctx, config, allRuns, columns)
+			allRuns = filterQueuedOver(allRuns, *queuedOver)
+			superseded := markSuperseded(allRuns)
+			layout.sortRuns(allRuns)
+
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("%s\n", qc.Colorize(fmt.Sprintf("Watching workflows (refreshing every %s, Ctrl+C to stop)...", interval), qc.ColorBlue))
+			fmt.Println()
+			fmt.Println(summarizeRuns(allRuns, len(failures)))
+			if len(allRuns) == 0 {
+				fmt.Printf("%s No workflow runs found\n", qc.Colorize("Info:", qc.ColorCyan))
+			} else {
+				displayGroupedRuns(allRuns, columns, layout.GroupBy)
+			}
+			printFetchFailures(os.Stdout, failures)
+			if superseded > 0 {
+				fmt.Printf("\n%s %d superseded run(s); 'quick_workflow cancel --superseded' cancels them\n", qc.Colorize("Info:", qc.ColorCyan), superseded)
+			}
+			alerter.check(allRuns)
+
+			sleepContext(ctx, interval)
 		}
-		allRuns = append(allRuns, runs...)
+		fmt.Printf("\n%s Stopped watching\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	heading := "Watching workflows across all projects..."
+	if config.Workspace != "" && config.Workspace != "default" {
+		heading = fmt.Sprintf("Watching workflows across workspace %s...", config.Workspace)
+	}
+	fmt.Printf("%s\n", qc.Colorize(heading, qc.ColorBlue))
+	fmt.Println()
+
+	warnRateLimit(ctx, os.Stdout, projects, fetchRequests(needsQueueTimes(columns, *queuedOver+*queueAlert), 10))
+	allRuns, failures := collectRuns(ctx, config, projects, 10)
+	allRuns = layout.filterRuns(append(allRuns, staleRuns(config, failures)...))
+	if needsQueueTimes(columns, *queuedOver+*queueAlert) {
+		fillQueueTimes(ctx, allRuns)
 	}
+	fillColumnValues(ctx, config, allRuns, columns)
+	allRuns = filterQueuedOver(allRuns, *queuedOver)
+	superseded := markSuperseded(allRuns)
 
 	if len(allRuns) == 0 {
 		fmt.Printf("%s No workflow runs found\n", qc.Colorize("Info:", qc.ColorCyan))
+		printFetchFailures(os.Stdout, failures)
 		return
 	}
 
-	// Sort by creation time (newest first)
-	sort.Slice(allRuns, func(i, j int) bool {
-		return allRuns[i].CreatedAt.After(allRuns[j].CreatedAt)
-	})
+	// Sort by the layout (newest first by default)
+	layout.sortRuns(allRuns)
 
 	// Display workflow runs
-	displayWorkflowRuns(allRuns)
+	fmt.Println(summarizeRuns(allRuns, len(failures)))
+	displayGroupedRuns(allRuns, columns, layout.GroupBy)
+	printFetchFailures(os.Stdout, failures)
+	newQueueAlerter(*queueAlert).check(allRuns)
 
-	// Allow user to select a run for details
+	// Allow user to select runs for details
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("%s", qc.Colorize("Select a workflow run for details (number or 'q' to quit): ", qc.ColorYellow))
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		log.Fatal(err)
+	var actions []runPromptAction
+	if superseded > 0 {
+		actions = append(actions, runPromptAction{key: "s", label: fmt.Sprintf("cancel %d superseded", superseded)})
 	}
-	input = strings.TrimSpace(input)
-	
-	if input == "q" || input == "" {
+	if config.Workspace != "" {
+		actions = append(actions, runPromptAction{key: "w", label: "switch workspace"})
+	}
+	indexes, action, ok := pickRuns(reader, allRuns, columns, actions)
+	switch {
+	case !ok:
+		return
+	case action == "w":
+		// Layouts belong to the workspace, so the switched watch starts
+		// from its defaults
+		if selectWorkspace(config, reader) {
+			fmt.Println()
+			watchWorkflows(ctx, config, []string{"--log-lines", strconv.Itoa(*logLines)})
+		}
+		return
+	case action == "s":
+		// Mirrors a concurrency group that cancels in-progress runs
+		fmt.Println()
+		confirmAndApply(ctx, reader, config, cancelOperation, supersededRuns(allRuns), false, false)
 		return
 	}
 
-	runIndex, err := strconv.Atoi(input)
-	if err != nil || runIndex < 1 || runIndex > len(allRuns) {
-		fmt.Println("Invalid selection")
-		return
+	selected := make([]WorkflowRun, len(indexes))
+	for i, index := range indexes {
+		selected[i] = allRuns[index]
+	}
+	showSelectedRuns(ctx, config, reader, selected, columns, *logLines)
+}
+
+// showSelectedRun shows the details of the run selected in watch, with the
+// prompts to act on it
+func showSelectedRun(ctx context.Context, config *Config, reader *bufio.Reader, run WorkflowRun, logLines int) {
+	selectedRun := completeRun(ctx, config, run)
+	jobs, downstream := showWorkflowDetails(ctx, config, selectedRun, logLines)
+	promptMatrixGroups(reader, selectedRun, jobs)
+	promptCopy(ctx, reader, selectedRun, jobs, logLines)
+	promptLogViewer(ctx, reader, selectedRun, jobs)
+	promptJobActions(ctx, config, reader, selectedRun, jobs, downstream, logLines)
+	promptAttempts(ctx, config, reader, selectedRun, logLines)
+	if code := promptFollow(ctx, config, reader, selectedRun); code > 0 && ctx.Err() == nil {
+		os.Exit(code)
 	}
+}
 
-	selectedRun := allRuns[runIndex-1]
-	showWorkflowDetails(ctx, config, selectedRun)
+// variableFlags collects repeated KEY=VALUE flags
+type variableFlags map[string]string
+
+func (v variableFlags) String() string {
+	pairs := make([]string, 0, len(v))
+	for key, value := range v {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v variableFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", s)
+	}
+	v[key] = value
+	return nil
 }
 
 // startWorkflow allows starting a new workflow
 func startWorkflow(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("start", flag.ExitOnError)
+	variables := variableFlags{}
+	fs.Var(variables, "var", "Workflow input (GitHub), pipeline variable (GitLab, Woodpecker), or build parameter (Drone) as KEY=VALUE; repeatable")
+	upstreamPipeline := fs.Int("upstream-pipeline", 0, "GitLab: pass dotenv report variables from this completed pipeline")
+	upstreamProject := fs.String("upstream-project", "", "GitLab: project of the upstream pipeline (default: selected project)")
+	projectName := fs.String("project", "", "Project to start (default: ask)")
+	workflowName := fs.String("workflow", "", "Workflow to trigger (default: the project's default workflow, else ask)")
+	ref := fs.String("ref", "", "Branch to run on (default: the project's default branch, else the repository's)")
+	dryRun := fs.Bool("dry-run", false, "Print the API call that would start the run without making it")
+	fs.Parse(args)
+
 	if len(config.Projects) == 0 {
 		fmt.Printf("%s No projects tracked. Use 'quick_workflow add .' to add a project.\n", qc.Colorize("Info:", qc.ColorCyan))
 		return
 	}
 
 	// Select project
-	selectedProject := selectProject(config)
+	var selectedProject *Project
+	if *projectName != "" {
+		selectedProject = findProject(config, *projectName)
+		if selectedProject == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), *projectName)
+			return
+		}
+	} else {
+		selectedProject = selectProject(config)
+	}
 	if selectedProject == nil {
 		return
 	}
 
-	// Get available workflows
-	workflows, err := getAvailableWorkflows(ctx, *selectedProject)
-	if err != nil {
-		fmt.Printf("%s Failed to get workflows: %v\n", qc.Colorize("Error:", qc.ColorRed), err)
-		return
+	// The project's defaults skip the prompt. Where workflows are the
+	// branches they run on, the branch is the workflow.
+	selectedWorkflow := firstNonEmpty(*workflowName, selectedProject.DefaultWorkflow)
+	selectedRef := firstNonEmpty(*ref, selectedProject.DefaultBranch)
+	if refIsWorkflow(selectedProject.Platform) {
+		selectedWorkflow, selectedRef = firstNonEmpty(*workflowName, selectedRef), ""
 	}
+	if selectedWorkflow == "" {
+		// Get available workflows
+		workflows, err := getAvailableWorkflows(ctx, *selectedProject)
+		if err != nil {
+			fmt.Printf("%s Failed to get workflows: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
 
-	if len(workflows) == 0 {
-		fmt.Printf("%s No workflows available for %s\n", qc.Colorize("Info:", qc.ColorCyan), selectedProject.Name)
-		return
+		if len(workflows) == 0 {
+			fmt.Printf("%s No workflows available for %s\n", qc.Colorize("Info:", qc.ColorCyan), selectedProject.Name)
+			return
+		}
+
+		// Select workflow
+		selectedWorkflow = selectWorkflow(workflows)
+		if selectedWorkflow == "" {
+			return
+		}
 	}
 
-	// Select workflow
-	selectedWorkflow := selectWorkflow(workflows)
-	if selectedWorkflow == "" {
-		return
+	// Pass dotenv variables from the upstream pipeline, like GitLab's
+	// needs:project. Explicit --var values take precedence.
+	if *upstreamPipeline != 0 {
+		if selectedProject.Platform != "gitlab" {
+			fmt.Printf("%s --upstream-pipeline is only supported for GitLab projects\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		upstream := *upstreamProject
+		if upstream == "" {
+			upstream = selectedProject.Name
+		}
+
+		client, err := NewGitLabClient(ctx, selectedProject.Name)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		inherited, err := client.GetPipelineDotenvVariables(upstream, *upstreamPipeline)
+		if err != nil {
+			fmt.Printf("%s Failed to read upstream variables: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		for key, value := range inherited {
+			if _, ok := variables[key]; !ok {
+				variables[key] = value
+			}
+		}
+		fmt.Printf("%s Passing %d dotenv variables from %s pipeline %d\n", qc.Colorize("Info:", qc.ColorCyan), len(inherited), upstream, *upstreamPipeline)
 	}
 
 	// Trigger workflow
-	err = triggerWorkflow(ctx, *selectedProject, selectedWorkflow)
+	if *dryRun {
+		call, err := planTrigger(ctx, *selectedProject, selectedWorkflow, selectedRef, variables)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		printAPICalls([]apiCall{call})
+		fmt.Printf("\n%s Dry run; nothing was triggered\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	on := ""
+	if selectedRef != "" {
+		on = " on " + selectedRef
+	}
+	fmt.Printf("%s Triggering '%s'%s and waiting for the run to appear...\n", qc.Colorize("Info:", qc.ColorCyan), selectedWorkflow, on)
+	run, err := triggerWorkflow(ctx, *selectedProject, selectedWorkflow, selectedRef, variables)
+	parameters := map[string]string{"workflow": selectedWorkflow}
+	if selectedRef != "" {
+		parameters["ref"] = selectedRef
+	}
+	for key, value := range variables {
+		parameters["var."+key] = value
+	}
+	result := "run not located yet"
+	if run != nil {
+		result = fmt.Sprintf("started run %s on %s: %s", run.ID, run.Branch, run.URL)
+	}
+	recordAction(config, "trigger", selectedProject.Name, parameters, result, err)
 	if err != nil {
-		fmt.Printf("%s Failed to trigger workflow: %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		fmt.Printf("%s Failed to trigger workflow: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
 		return
 	}
 
 	fmt.Printf("%s Triggered workflow '%s' for %s\n", qc.Colorize("Success:", qc.ColorGreen), selectedWorkflow, selectedProject.Name)
+
+	triggered := TriggeredRun{
+		Project:     selectedProject.Name,
+		Workflow:    selectedWorkflow,
+		TriggeredAt: time.Now().Format(time.RFC3339),
+	}
+	if run != nil {
+		triggered.Ref = run.Branch
+		triggered.RunID = run.ID
+		triggered.URL = run.URL
+		fmt.Printf("Run: %s\n", qc.ColorizeBold(run.ID, qc.ColorGreen))
+		fmt.Printf("URL: %s\n", run.URL)
+	} else {
+		fmt.Printf("%s The run has not appeared yet; check 'quick_workflow list' shortly\n", qc.Colorize("Info:", qc.ColorCyan))
+	}
+
+	if err := recordTriggeredRun(config, triggered); err != nil {
+		fmt.Printf("%s Failed to record triggered run: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
 }
 
 // listWorkflows shows historical workflow runs
@@ -118,30 +376,106 @@ func listWorkflows(ctx context.Context, config *Config, args []string) {
 		return
 	}
 
-	// Parse limit from args
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	columnSpec := fs.String("columns", "", "Comma separated columns: "+strings.Join(columnNames(), ","))
+	queuedOver := fs.Duration("queued-over", 0, "Only show runs whose jobs waited longer than this for a runner, e.g. 5m")
+	format := fs.String("format", "table", "Output format: table, csv, tsv, or template")
+	fieldSpec := fs.String("fields", "", "Comma separated fields to export with csv or tsv (default: the columns)")
+	templateText := fs.String("template", "", "Go template rendered per run with --format template, e.g. '{{.Project}} {{.Status}}'")
+	all := fs.Bool("all", false, fmt.Sprintf("Fetch the whole run history of each project, up to %d runs", maxListRuns))
+	commit := fs.String("commit", "", "Only show runs of this commit (full or abbreviated SHA)")
+	grep := fs.String("grep", "", "Only show runs whose commit message matches this regular expression (case-insensitive)")
+	interactive := fs.Bool("interactive", false, "Select runs from the list to show, cancel, re-run, or export")
+	causes := fs.Bool("causes", false, "Add the cause column: the probable cause of each failed run, found in its failed job logs")
+	fs.Parse(args)
+
+	// Parse limit from args; flags may also follow it
 	limit := 20
-	if len(args) > 0 {
-		if l, err := strconv.Atoi(args[0]); err == nil {
-			limit = l
+	if fs.NArg() > 0 {
+		l, err := strconv.Atoi(fs.Arg(0))
+		if err != nil {
+			fmt.Printf("%s Usage: quick_workflow list [limit] [flags]\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		limit = min(l, maxListRuns)
+		fs.Parse(fs.Args()[1:])
+		if fs.NArg() > 0 {
+			fmt.Printf("%s Usage: quick_workflow list [limit] [flags]\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+	}
+
+	if *format != "table" && *format != "template" && !isExportFormat(*format) {
+		fmt.Printf("%s Unsupported format: %s (expected table, csv, tsv, or template)\n", qc.Colorize("Error:", qc.ColorRed), *format)
+		return
+	}
+	columns, err := parseColumns(*columnSpec, config.Settings)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+	if *causes && !slices.Contains(columns, "cause") {
+		columns = append(append([]string(nil), columns...), "cause")
+	}
+	search, err := newRunSearch(*commit, *grep)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	if *all {
+		limit = maxListRuns
+	}
+
+	if isExportFormat(*format) {
+		fields, err := parseFields(*fieldSpec, columnNames(), columns)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
 		}
+		exportRuns(ctx, config, search, limit, *queuedOver, slices.Contains(fields, "queued"), func(runs []WorkflowRun) error {
+			fillColumnValues(ctx, config, runs, fields)
+			return writeRunsDelimited(os.Stdout, *format, runs, fields)
+		})
+		return
+	}
+	if *format == "template" {
+		tmpl, err := parseOutputTemplate(*templateText)
+		if err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+		exportRuns(ctx, config, search, limit, *queuedOver, strings.Contains(*templateText, "QueueTime"), func(runs []WorkflowRun) error {
+			for _, run := range runs {
+				if err := writeTemplate(os.Stdout, tmpl, run); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
 	}
 
 	fmt.Printf("%s\n", qc.Colorize("Recent workflow runs:", qc.ColorBlue))
 	fmt.Println()
 
 	// Collect all workflow runs
-	var allRuns []WorkflowRun
-	for _, project := range config.Projects {
-		runs, err := getWorkflowRunsForProject(ctx, project, limit)
-		if err != nil {
-			fmt.Printf("%s Failed to get workflows for %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, err)
-			continue
-		}
-		allRuns = append(allRuns, runs...)
+	projects := activeProjects(config.Projects)
+	warnRateLimit(ctx, os.Stdout, projects, fetchRequests(needsQueueTimes(columns, *queuedOver), limit))
+	allRuns, failures := search.collect(ctx, os.Stdout, config, projects, limit)
+	if search == (runSearch{}) {
+		// Cached runs can't be searched for a commit or message
+		allRuns = append(allRuns, staleRuns(config, failures)...)
+	}
+	if needsQueueTimes(columns, *queuedOver) {
+		fillQueueTimes(ctx, allRuns)
 	}
+	fillColumnValues(ctx, config, allRuns, columns)
+	allRuns = filterQueuedOver(allRuns, *queuedOver)
 
 	if len(allRuns) == 0 {
 		fmt.Printf("%s No workflow runs found\n", qc.Colorize("Info:", qc.ColorCyan))
+		printFetchFailures(os.Stdout, failures)
 		return
 	}
 
@@ -151,28 +485,323 @@ func listWorkflows(ctx context.Context, config *Config, args []string) {
 	})
 
 	// Display workflow runs
-	displayWorkflowRuns(allRuns)
+	fmt.Println(summarizeRuns(allRuns, len(failures)))
+	displayWorkflowRuns(allRuns, columns)
+	printFetchFailures(os.Stdout, failures)
+
+	if *interactive {
+		reader := bufio.NewReader(os.Stdin)
+		indexes, _, ok := pickRuns(reader, allRuns, columns, nil)
+		if !ok {
+			return
+		}
+		selected := make([]WorkflowRun, len(indexes))
+		for i, index := range indexes {
+			selected[i] = allRuns[index]
+		}
+		showSelectedRuns(ctx, config, reader, selected, columns, defaultLogLines)
+	}
+}
+
+// collectRuns fetches recent runs for each project, records them in the
+// local history and the cache of each project's last runs, and returns
+// them with the projects that failed
+func collectRuns(ctx context.Context, config *Config, projects []Project, limit int) ([]WorkflowRun, []fetchFailure) {
+	return collectRunsTo(ctx, os.Stdout, config, projects, limit)
 }
 
-// getWorkflowRunsForProject retrieves workflow runs for a specific project
-func getWorkflowRunsForProject(ctx context.Context, project Project, limit int) ([]WorkflowRun, error) {
+// collectRunsTo is collectRuns reporting warnings to w, so exports written
+// to stdout stay parseable
+func collectRunsTo(ctx context.Context, w io.Writer, config *Config, projects []Project, limit int) ([]WorkflowRun, []fetchFailure) {
+	var allRuns []WorkflowRun
+	var failures []fetchFailure
+	fetched := make(map[string][]WorkflowRun)
+	batched := batchGitHubRuns(ctx, w, config, projects, limit)
+	for _, project := range projects {
+		if runs, ok := batched[project.Name]; ok {
+			allRuns = append(allRuns, runs...)
+			fetched[project.Name] = runs
+			continue
+		}
+		runs, err := getWorkflowRunsForProject(ctx, project, "", limit)
+		if ctx.Err() != nil {
+			// Interrupted; the remaining projects would fail the same way
+			return allRuns, failures
+		}
+		if err != nil {
+			failures = append(failures, fetchFailure{Project: project.Name, Err: err})
+			continue
+		}
+		allRuns = append(allRuns, runs...)
+		fetched[project.Name] = runs
+	}
+
+	if offlineConfig != nil {
+		// Runs read back from the history aren't new observations
+		return allRuns, failures
+	}
+	if err := recordRuns(config, allRuns); err != nil {
+		fmt.Fprintf(w, "%s Failed to record run history: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+	}
+	// A failed write only loses what to show while a project fails
+	_ = cacheRuns(config, fetched, failures, time.Now())
+	return allRuns, failures
+}
+
+// batchGitHubRuns fetches the runs of the GitHub projects with batched
+// GraphQL requests, keyed by project name, when the github_fetch setting
+// asks for it. GraphQL only sees the runs of each branch's head commit, so
+// it is opt-in. Projects missing from the result are fetched over REST, as
+// is everything if GraphQL fails.
+func batchGitHubRuns(ctx context.Context, w io.Writer, config *Config, projects []Project, limit int) map[string][]WorkflowRun {
+	if offlineConfig != nil {
+		return nil
+	}
+	var githubProjects []Project
+	for _, project := range projects {
+		// GraphQL batches go to github.com with the default login, so
+		// projects of other accounts are fetched over REST
+		if project.Platform == "github" && project.Account == "" {
+			githubProjects = append(githubProjects, project)
+		}
+	}
+	if config.Settings.GitHubFetch != "graphql" || len(githubProjects) == 0 {
+		return nil
+	}
+
+	client, err := NewGitHubClient(ctx, "")
+	if err != nil {
+		return nil
+	}
+	found, err := client.GetLatestRunsBatch(githubProjects, limit)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(w, "%s Batched GitHub fetch failed, fetching each project: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		}
+		return nil
+	}
+
+	batched := make(map[string][]WorkflowRun)
+	for _, project := range githubProjects {
+		if runs, ok := found[project.Owner+"/"+project.Repo]; ok {
+			batched[project.Name] = runs
+		}
+	}
+	return batched
+}
+
+// completeRun fills in what a run listed through GraphQL lacks, like the
+// attempt, by fetching it over REST. Other runs are returned as they are.
+func completeRun(ctx context.Context, config *Config, run WorkflowRun) WorkflowRun {
+	if run.Platform != "github" || run.Attempt != 0 {
+		return run
+	}
+	project := findProject(config, run.Project)
+	if project == nil {
+		return run
+	}
+	if full, err := getWorkflowRun(ctx, *project, run.ID); err == nil {
+		return *full
+	}
+	return run
+}
+
+// fillColumnValues fetches what the runs were listed without but the
+// columns show: the attempt of runs listed through GraphQL, GitLab commit
+// messages, and the causes of failures
+func fillColumnValues(ctx context.Context, config *Config, runs []WorkflowRun, columns []string) {
+	if slices.Contains(columns, "attempt") {
+		completeRuns(ctx, config, runs)
+	}
+	if slices.Contains(columns, "subject") {
+		fillCommitMessages(ctx, runs)
+	}
+	if slices.Contains(columns, "cause") {
+		fillFailureCauses(ctx, config, runs)
+	}
+}
+
+// completeRuns completes the runs listed through GraphQL in place, for
+// columns that need what only REST returns
+func completeRuns(ctx context.Context, config *Config, runs []WorkflowRun) {
+	for i := range runs {
+		runs[i] = completeRun(ctx, config, runs[i])
+	}
+}
+
+// showCurrentRepoRuns shows the latest runs for the git repository in the
+// current directory without requiring it to be tracked
+func showCurrentRepoRuns(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("ci", flag.ExitOnError)
+	columnSpec := fs.String("columns", "", "Comma separated columns: "+strings.Join(columnNames(), ","))
+	limit := fs.Int("limit", 10, "Number of runs to show")
+	branch := fs.String("branch", "", "Branch to show (default: current branch)")
+	allBranches := fs.Bool("all-branches", false, "Show runs for every branch")
+	upstream := fs.Bool("upstream", false, "Also show the checks of the branch's pull request on the upstream repository, for branches pushed to a fork")
+	upstreamRemote := fs.String("upstream-remote", "upstream", "Git remote of the upstream repository (default: upstream, else the fork's parent on GitHub)")
+	watch := fs.Bool("watch", false, "With --upstream, follow the pull request's checks until they finish; exit 1 if they block merging")
+	refresh := fs.Duration("refresh", defaultFollowInterval, "How often --watch polls")
+	fs.Parse(args)
+	if *upstream && *allBranches {
+		fmt.Printf("%s --upstream needs a branch\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	if *refresh <= 0 {
+		fmt.Printf("%s Invalid refresh interval: %s\n", qc.Colorize("Error:", qc.ColorRed), *refresh)
+		return
+	}
+
+	columns, err := parseColumns(*columnSpec, config.Settings)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Fatal("Failed to get current directory:", err)
+	}
+
+	project, err := detectProject(cwd)
+	if err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return
+	}
+
+	if *allBranches {
+		*branch = ""
+	} else if *branch == "" {
+		*branch, err = getGitBranch(cwd)
+		if err != nil {
+			fmt.Printf("%s Failed to determine current branch: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			return
+		}
+	}
+
+	header := fmt.Sprintf("Latest runs for %s", project.Name)
+	if *branch != "" {
+		header = fmt.Sprintf("%s on %s", header, *branch)
+	}
+	fmt.Printf("%s\n", qc.Colorize(header+":", qc.ColorBlue))
+	fmt.Println()
+
+	runs, err := getWorkflowRunsForProject(ctx, *project, *branch, *limit)
+	if err != nil {
+		fmt.Printf("%s Failed to get workflows for %s: %v\n", qc.Colorize("Error:", qc.ColorRed), project.Name, describeError(err))
+		return
+	}
+
+	if len(runs) == 0 {
+		fmt.Printf("%s No workflow runs found\n", qc.Colorize("Info:", qc.ColorCyan))
+		// Forks often don't run CI themselves
+		if !*upstream {
+			return
+		}
+	} else {
+		if needsQueueTimes(columns, 0) {
+			fillQueueTimes(ctx, runs)
+		}
+		fillColumnValues(ctx, config, runs, columns)
+		displayWorkflowRuns(runs, columns)
+	}
+
+	if *upstream {
+		parent, err := upstreamProject(ctx, cwd, *project, *upstreamRemote)
+		if err != nil {
+			fmt.Printf("\n%s Failed to find the upstream repository: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+			os.Exit(1)
+		}
+		if code := showUpstreamStatus(ctx, *parent, *project, *branch, *watch, *refresh); code != 0 && ctx.Err() == nil && *watch {
+			os.Exit(code)
+		}
+		return
+	}
+
+	if *branch != "" && (project.Platform == "github" || project.Platform == "gitlab") {
+		status, err := getMergeStatus(ctx, *project, *branch)
+		if err != nil {
+			fmt.Printf("\n%s Failed to get required checks: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+			return
+		}
+		displayMergeStatus(project.Platform, status)
+	}
+}
+
+// getWorkflowRunsForProject retrieves workflow runs for a specific project.
+// An empty branch returns runs for all branches.
+func getWorkflowRunsForProject(ctx context.Context, project Project, branch string, limit int) ([]WorkflowRun, error) {
+	if offlineConfig != nil {
+		return localRuns(offlineConfig, project, branch, limit)
+	}
 	switch project.Platform {
 	case "github":
-		client, err := NewGitHubClient()
+		client, err := NewGitHubClient(ctx, project.Name)
 		if err != nil {
 			return nil, err
 		}
-		return client.GetWorkflowRuns(project.Owner, project.Repo, limit)
+		return client.GetWorkflowRuns(project.Owner, project.Repo, branch, limit)
 	case "gitlab":
-		client, err := NewGitLabClient()
+		client, err := NewGitLabClient(ctx, project.Name)
 		if err != nil {
 			return nil, err
 		}
 		// For GitLab, we need to find the project ID first
 		// This is a simplified approach - in practice, you'd want to store the project ID
-		return client.GetPipelineRuns(project.Name, limit)
+		return client.GetPipelineRuns(project.Name, branch, limit)
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetBuilds(project.Owner, project.Repo, branch, limit)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.GetRuns(project.Name, branch, limit)
 	default:
-		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+		client, err := NewPluginClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.ListRuns(project, branch, limit)
+	}
+}
+
+// getWorkflowRun retrieves a single workflow run for a project
+func getWorkflowRun(ctx context.Context, project Project, runID string) (*WorkflowRun, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetWorkflowRun(project.Owner, project.Repo, runID)
+	case "gitlab":
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPipelineRun(project.Name, runID)
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetBuild(project.Owner, project.Repo, runID)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.GetRun(project.Name, runID)
+	default:
+		client, err := NewPluginClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetRun(project, runID)
 	}
 }
 
@@ -180,122 +809,201 @@ func getWorkflowRunsForProject(ctx context.Context, project Project, limit int)
 func getAvailableWorkflows(ctx context.Context, project Project) ([]string, error) {
 	switch project.Platform {
 	case "github":
-		client, err := NewGitHubClient()
+		client, err := NewGitHubClient(ctx, project.Name)
 		if err != nil {
 			return nil, err
 		}
 		return client.GetWorkflows(project.Owner, project.Repo)
 	case "gitlab":
-		client, err := NewGitLabClient()
+		client, err := NewGitLabClient(ctx, project.Name)
 		if err != nil {
 			return nil, err
 		}
 		return client.GetPipelines(project.Name)
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetBranches(project.Owner, project.Repo)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.GetWorkflows(project.Name)
 	default:
-		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+		client, err := NewPluginClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.ListWorkflows(project)
 	}
 }
 
-// triggerWorkflow triggers a workflow for a project
-func triggerWorkflow(ctx context.Context, project Project, workflowName string) error {
+// triggerWorkflow triggers a workflow for a project with the given inputs or
+// variables and returns the run it created, or nil if the run could not be
+// located yet. On GitHub it runs on ref, or the default branch when ref is
+// empty; elsewhere the workflow names what runs.
+func triggerWorkflow(ctx context.Context, project Project, workflowName, ref string, variables map[string]string) (*WorkflowRun, error) {
 	switch project.Platform {
 	case "github":
-		client, err := NewGitHubClient()
+		client, err := NewGitHubClient(ctx, project.Name)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if ref == "" {
+			if ref, err = client.GetDefaultBranch(project.Owner, project.Repo); err != nil {
+				return nil, err
+			}
 		}
-		// For GitHub, we need to get the workflow file name
-		// This is simplified - in practice, you'd want to map workflow names to file names
-		return client.TriggerWorkflow(project.Owner, project.Repo, workflowName, "main", nil)
+		return client.TriggerWorkflow(project.Owner, project.Repo, workflowName, ref, variables)
 	case "gitlab":
-		client, err := NewGitLabClient()
+		client, err := NewGitLabClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.TriggerPipeline(project.Name, workflowName, variables)
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, project.Platform)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		return client.TriggerBuild(project.Owner, project.Repo, workflowName, variables)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return nil, err
 		}
-		return client.TriggerPipeline(project.Name, workflowName, nil)
+		return client.TriggerRun(project.Name, workflowName)
 	default:
-		return fmt.Errorf("unsupported platform: %s", project.Platform)
+		client, err := NewPluginClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.Trigger(project, workflowName, variables)
 	}
 }
 
-// displayWorkflowRuns displays a list of workflow runs
-func displayWorkflowRuns(runs []WorkflowRun) {
-	longestProject := 0
-	for _, run := range runs {
-		if len(run.Project) > longestProject {
-			longestProject = len(run.Project)
-		}
-	}
+// displayWorkflowRuns displays a list of workflow runs with the given columns
+func displayWorkflowRuns(runs []WorkflowRun, columns []string) {
+	widths := layoutColumns(runs, columns, terminalWidth())
 
 	for i, run := range runs {
 		// Alternate row colors
 		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
-		
-		// Color code the status
-		statusColor := colorWorkflowStatus(run.Status, run.Conclusion)
-		
-		// Format time
-		timeStr := run.CreatedAt.Format("2006-01-02 15:04")
-		
-		entry := fmt.Sprintf(
-			"%3d. %-*s %-20s %s [%s] %s",
-			i+1, longestProject, run.Project, run.Workflow,
-			timeStr, qc.Colorize(run.Status, statusColor),
-			run.Branch,
-		)
-		fmt.Println(qc.Colorize(entry, rowColor))
+		fmt.Println(formatRunRow(i+1, run, columns, widths, rowColor))
 	}
 }
 
-// showWorkflowDetails displays detailed information about a workflow run
-func showWorkflowDetails(ctx context.Context, config *Config, run WorkflowRun) {
+// showWorkflowDetails displays detailed information about a workflow run,
+// including the last logLines lines of each failed step, and returns the
+// run's jobs and downstream pipelines in the order they were listed
+func showWorkflowDetails(ctx context.Context, config *Config, run WorkflowRun, logLines int) ([]Job, []DownstreamPipeline) {
 	fmt.Printf("\n%s\n", qc.Colorize("Workflow Details:", qc.ColorBlue))
 	fmt.Printf("Project: %s\n", qc.ColorizeBold(run.Project, qc.ColorGreen))
 	fmt.Printf("Workflow: %s\n", run.Workflow)
 	fmt.Printf("Status: %s\n", qc.Colorize(run.Status, colorWorkflowStatus(run.Status, run.Conclusion)))
+	if run.Attempt > 1 {
+		fmt.Printf("Attempt: %d\n", run.Attempt)
+	}
 	fmt.Printf("Branch: %s\n", run.Branch)
 	fmt.Printf("Commit: %s\n", run.Commit)
-	fmt.Printf("Created: %s\n", run.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Created: %s (%s)\n", formatAbsoluteTime(run.CreatedAt), formatAgo(time.Since(run.CreatedAt)))
+	fmt.Printf("Duration: %s\n", runDuration(run))
+	if project := findProject(config, run.Project); project != nil && isRunFinished(run.Status) {
+		coverages, err := runCoverages(ctx, config, *project, []WorkflowRun{run})
+		if err != nil {
+			fmt.Printf("%s Failed to get coverage: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		} else if coverage, ok := coverages[run.ID]; ok {
+			fmt.Printf("Coverage: %.1f%%\n", coverage)
+		}
+	}
 	fmt.Printf("URL: %s\n", run.URL)
 	fmt.Println()
 
+	if notes := runNotes(config, run.Project, run.ID); len(notes) > 0 {
+		displayRunNotes(notes)
+		fmt.Println()
+	}
+
 	// Get jobs for this run
 	jobs, err := getJobsForRun(ctx, run)
 	if err != nil {
-		fmt.Printf("%s Failed to get jobs: %v\n", qc.Colorize("Error:", qc.ColorRed), err)
-		return
+		fmt.Printf("%s Failed to get jobs: %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return nil, nil
+	}
+
+	downstream, err := getDownstreamPipelines(ctx, run)
+	if err != nil {
+		fmt.Printf("%s Failed to get downstream pipelines: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
 	}
 
 	if len(jobs) == 0 {
 		fmt.Printf("%s No jobs found for this run\n", qc.Colorize("Info:", qc.ColorCyan))
-		return
+		displayDownstreamPipelines(ctx, downstream)
+		return nil, downstream
 	}
 
-	// Display jobs
+	// Display jobs with their steps, matrix jobs grouped by base name
 	fmt.Printf("%s\n", qc.Colorize("Jobs:", qc.ColorBlue))
-	for i, job := range jobs {
-		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
-		statusColor := colorJobStatus(job.Status, job.Conclusion)
-		
-		entry := fmt.Sprintf(
-			"  %3d. %-30s [%s]",
-			i+1, job.Name,
-			qc.Colorize(job.Status, statusColor),
-		)
-		fmt.Println(qc.Colorize(entry, rowColor))
+	displayJobGroups(run.Platform, jobs)
+
+	var slowestJob string
+	var slowestStepOfRun Step
+	var slowestDuration time.Duration
+	for _, job := range jobs {
+		if len(job.Steps) == 1 && job.Steps[0].Name == job.Name {
+			continue
+		}
+		if slowest, d := slowestStep(job); d > slowestDuration {
+			slowestJob, slowestStepOfRun, slowestDuration = job.Name, job.Steps[slowest], d
+		}
+	}
+
+	if slowestDuration > 0 {
+		fmt.Printf("\n%s %s / %s took %s\n",
+			qc.Colorize("Slowest step:", qc.ColorYellow),
+			slowestJob, slowestStepOfRun.Name, formatDuration(slowestDuration))
+	}
+
+	displayDownstreamPipelines(ctx, downstream)
+
+	if project := findProject(config, run.Project); project != nil && project.Platform == "gitlab" && isRunFinished(run.Status) {
+		showSecurityFindings(ctx, *project, run)
+	}
+
+	if project := findProject(config, run.Project); project != nil {
+		checks, err := getExternalChecks(ctx, *project, run.Commit)
+		if err != nil {
+			fmt.Printf("%s Failed to get external checks: %v\n", qc.Colorize("Warning:", qc.ColorYellow), describeError(err))
+		} else if len(checks) > 0 {
+			fmt.Printf("\n%s\n", qc.Colorize("External checks:", qc.ColorBlue))
+			displayExternalChecks(checks, "  ")
+		}
 	}
+
+	showAnnotations(ctx, run, jobs)
+
+	if logLines > 0 {
+		showFailedLogs(ctx, run, jobs, logLines)
+	}
+	return jobs, downstream
 }
 
 // getJobsForRun retrieves jobs for a specific workflow run
 func getJobsForRun(ctx context.Context, run WorkflowRun) ([]Job, error) {
-	// Parse the project name to extract owner/repo and platform
+	// Parse the project name to extract owner/repo and platform. GitLab
+	// projects may sit in subgroups or be given by ID, and plugins name
+	// projects their own way, so only the built-in platforms need exactly
+	// owner/repo.
 	parts := strings.Split(run.Project, "/")
-	if len(parts) != 2 {
+	if (run.Platform == "github" || run.Platform == "drone" || run.Platform == "woodpecker") && len(parts) != 2 {
 		return nil, fmt.Errorf("invalid project format: %s (expected owner/repo)", run.Project)
 	}
 	
 	owner := parts[0]
-	repo := parts[1]
+	repo := parts[len(parts)-1]
 	
 	// Create a temporary project for API calls
 	project := Project{
@@ -307,19 +1015,35 @@ func getJobsForRun(ctx context.Context, run WorkflowRun) ([]Job, error) {
 
 	switch project.Platform {
 	case "github":
-		client, err := NewGitHubClient()
+		client, err := NewGitHubClient(ctx, run.Project)
 		if err != nil {
 			return nil, err
 		}
-		return client.GetWorkflowJobs(project.Owner, project.Repo, run.ID)
+		return client.GetWorkflowJobs(project.Owner, project.Repo, run.ID, run.Attempt)
 	case "gitlab":
-		client, err := NewGitLabClient()
+		client, err := NewGitLabClient(ctx, run.Project)
 		if err != nil {
 			return nil, err
 		}
 		return client.GetPipelineJobs(project.Name, run.ID)
+	case "drone", "woodpecker":
+		client, err := NewDroneClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetBuildJobs(project.Owner, project.Repo, run.ID)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.GetRunJobs(project.Name, run.ID)
 	default:
-		return nil, fmt.Errorf("unsupported platform: %s", project.Platform)
+		client, err := NewPluginClient(ctx, project.Platform)
+		if err != nil {
+			return nil, err
+		}
+		return client.ListJobs(run)
 	}
 }
 
@@ -329,6 +1053,17 @@ func selectProject(config *Config) *Project {
 		return &config.Projects[0]
 	}
 
+	if useFinder() {
+		items := make([]string, len(config.Projects))
+		for i, project := range config.Projects {
+			items[i] = fmt.Sprintf("%-30s [%s]", project.Name, project.Platform)
+		}
+		if index, ok := pick("Project", items); ok {
+			return &config.Projects[index]
+		}
+		return nil
+	}
+
 	fmt.Printf("%s\n", qc.Colorize("Select a project:", qc.ColorBlue))
 	for i, project := range config.Projects {
 		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
@@ -364,6 +1099,13 @@ func selectWorkflow(workflows []string) string {
 		return workflows[0]
 	}
 
+	if useFinder() {
+		if index, ok := pick("Workflow", workflows); ok {
+			return workflows[index]
+		}
+		return ""
+	}
+
 	fmt.Printf("%s\n", qc.Colorize("Select a workflow:", qc.ColorBlue))
 	for i, workflow := range workflows {
 		rowColor := qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)
@@ -387,6 +1129,71 @@ func selectWorkflow(workflows []string) string {
 	return workflows[index-1]
 }
 
+// isRunFinished reports whether a GitHub or GitLab status is terminal
+func isRunFinished(status string) bool {
+	switch status {
+	case "completed", "success", "failed", "canceled", "cancelled", "skipped":
+		return true
+	default:
+		return false
+	}
+}
+
+// runDuration describes how long a run took, or how long it has been
+// running so far
+func runDuration(run WorkflowRun) string {
+	if run.CreatedAt.IsZero() {
+		return "-"
+	}
+	if isRunFinished(run.Status) {
+		return "took " + formatDuration(run.UpdatedAt.Sub(run.CreatedAt))
+	}
+	return "elapsed " + formatDuration(time.Since(run.CreatedAt))
+}
+
+// stepDuration returns how long a finished step took
+func stepDuration(step Step) (time.Duration, bool) {
+	if step.StartedAt == nil || step.CompletedAt == nil {
+		return 0, false
+	}
+	return step.CompletedAt.Sub(*step.StartedAt), true
+}
+
+// elapsedBetween formats the time between start and end, or the time since
+// start when still running
+func elapsedBetween(start, end *time.Time) string {
+	switch {
+	case start == nil:
+		return "-"
+	case end == nil:
+		return "elapsed " + formatDuration(time.Since(*start))
+	default:
+		return formatDuration(end.Sub(*start))
+	}
+}
+
+// stepMarker returns a one character marker for a step's outcome
+func stepMarker(step Step) string {
+	result := step.Conclusion
+	if result == "" {
+		result = step.Status
+	}
+	switch result {
+	case "success":
+		return "✓"
+	case "failure", "failed", "timed_out":
+		return "✗"
+	case "cancelled", "canceled":
+		return "⊘"
+	case "skipped":
+		return "-"
+	case "in_progress", "running":
+		return "●"
+	default:
+		return "○"
+	}
+}
+
 // colorWorkflowStatus returns a color for workflow status
 func colorWorkflowStatus(status, conclusion string) string {
 	switch status {
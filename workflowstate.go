@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// WorkflowState is a workflow of a project and whether it runs
+type WorkflowState struct {
+	Name string
+	Path string
+	// State is active, or why the workflow is disabled: disabled_manually,
+	// disabled_inactivity, or disabled_fork
+	State string
+}
+
+// Disabled reports whether the workflow is turned off
+func (w WorkflowState) Disabled() bool {
+	return strings.HasPrefix(w.State, "disabled")
+}
+
+// supportsWorkflowStates reports whether workflows of a project's platform
+// can be enabled and disabled
+func supportsWorkflowStates(project Project) bool {
+	return project.Platform == "github" || project.Platform == "mock"
+}
+
+// getWorkflowStates lists the workflows of a project with their state
+func getWorkflowStates(ctx context.Context, project Project) ([]WorkflowState, error) {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetWorkflowStates(project.Owner, project.Repo)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.GetWorkflowStates(project.Name)
+	default:
+		return nil, fmt.Errorf("enabling and disabling workflows is not supported on %s", platformName(project.Platform))
+	}
+}
+
+// setWorkflowEnabled enables or disables a workflow of a project
+func setWorkflowEnabled(ctx context.Context, project Project, workflow string, enabled bool) error {
+	switch project.Platform {
+	case "github":
+		client, err := NewGitHubClient(ctx, project.Name)
+		if err != nil {
+			return err
+		}
+		return client.SetWorkflowEnabled(project.Owner, project.Repo, workflow, enabled)
+	case "mock":
+		client, err := NewMockClient()
+		if err != nil {
+			return err
+		}
+		return client.SetWorkflowEnabled(project.Name, workflow, enabled)
+	default:
+		return fmt.Errorf("enabling and disabling workflows is not supported on %s", platformName(project.Platform))
+	}
+}
+
+// handleWorkflows handles the workflows command: listing the workflows of
+// projects with which are disabled, and enabling or disabling them
+func handleWorkflows(ctx context.Context, config *Config, args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s Usage: quick_workflow workflows list [--disabled] [project...] | enable|disable <project>[,<project>...] <workflow>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		listWorkflowStates(ctx, config, args[1:])
+	case "enable", "disable":
+		enabled := args[0] == "enable"
+		if len(args) != 3 {
+			fmt.Printf("%s Usage: quick_workflow workflows %s <project>[,<project>...] <workflow>\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+			return
+		}
+		var projects []Project
+		for _, name := range splitList(args[1]) {
+			project := findProject(config, name)
+			if project == nil {
+				fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+				return
+			}
+			projects = append(projects, *project)
+		}
+		for _, project := range projects {
+			changeWorkflowState(ctx, config, project, args[2], enabled)
+		}
+	default:
+		fmt.Printf("%s Unknown workflows command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+		fmt.Println("  Commands: list, enable, disable")
+	}
+}
+
+// changeWorkflowState enables or disables one workflow and records it in
+// the action log
+func changeWorkflowState(ctx context.Context, config *Config, project Project, workflow string, enabled bool) {
+	verb := "disable"
+	if enabled {
+		verb = "enable"
+	}
+	err := setWorkflowEnabled(ctx, project, workflow, enabled)
+	recordAction(config, "workflow "+verb, project.Name, map[string]string{"workflow": workflow}, verb+"d workflow "+workflow, err)
+	if err != nil {
+		fmt.Printf("%s Failed to %s %s in %s: %v\n", qc.Colorize("Error:", qc.ColorRed), verb, workflow, project.Name, describeError(err))
+		return
+	}
+	fmt.Printf("%s %sd workflow '%s' in %s\n", qc.Colorize("Success:", qc.ColorGreen), strings.ToUpper(verb[:1])+verb[1:], workflow, project.Name)
+}
+
+// listWorkflowStates lists the workflows of the named projects, or of every
+// tracked project whose platform supports it, marking disabled ones
+func listWorkflowStates(ctx context.Context, config *Config, args []string) {
+	fs := flag.NewFlagSet("workflows list", flag.ExitOnError)
+	disabledOnly := fs.Bool("disabled", false, "Only show disabled workflows")
+	fs.Parse(args)
+
+	var projects []Project
+	for _, name := range fs.Args() {
+		project := findProject(config, name)
+		if project == nil {
+			fmt.Printf("%s Project not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+			return
+		}
+		projects = append(projects, *project)
+	}
+	if len(projects) == 0 {
+		for _, project := range activeProjects(config.Projects) {
+			if supportsWorkflowStates(project) {
+				projects = append(projects, project)
+			}
+		}
+	}
+	if len(projects) == 0 {
+		fmt.Printf("%s No tracked GitHub projects\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+
+	disabled := 0
+	var failures []fetchFailure
+	for _, project := range projects {
+		states, err := getWorkflowStates(ctx, project)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			failures = append(failures, fetchFailure{Project: project.Name, Err: err})
+			continue
+		}
+
+		var shown []WorkflowState
+		nameWidth := 0
+		for _, state := range states {
+			if state.Disabled() {
+				disabled++
+			} else if *disabledOnly {
+				continue
+			}
+			shown = append(shown, state)
+			nameWidth = max(nameWidth, len(state.Name))
+		}
+		if len(shown) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s\n", qc.Colorize(project.Name+":", qc.ColorBlue))
+		for _, state := range shown {
+			mark := qc.Colorize("✔", qc.ColorGreen)
+			label := ""
+			if state.Disabled() {
+				mark = qc.Colorize("⏸", qc.ColorYellow)
+				label = "  " + qc.Colorize(strings.ReplaceAll(state.State, "_", " "), qc.ColorYellow)
+			}
+			entry := strings.TrimRight(fmt.Sprintf("%-*s  %s", nameWidth, state.Name, state.Path), " ")
+			fmt.Printf("  %s %s%s\n", mark, entry, label)
+		}
+	}
+	printFetchFailures(os.Stdout, failures)
+
+	if disabled == 0 {
+		fmt.Printf("\n%s No disabled workflows\n", qc.Colorize("Info:", qc.ColorCyan))
+		return
+	}
+	fmt.Printf("\n%s %d disabled workflow(s); 'quick_workflow workflows enable <project> <workflow>' turns one back on\n", qc.Colorize("Info:", qc.ColorCyan), disabled)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	qc "github.com/bevelwork/quick_workflow/internal/color"
+)
+
+// workspaceEnv selects a workspace like --workspace
+const workspaceEnv = "QUICK_WORKFLOW_WORKSPACE"
+
+// Workspaces are separate sets of projects, layouts, notes, and history
+// within a profile. The default workspace uses the profile's state.json;
+// others live under workspaces/<name>. Auth is shared by the profile.
+
+// validateWorkspaceName rejects names that can't be used as a directory
+func validateWorkspaceName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\:`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid workspace name: %s", name)
+	}
+	return nil
+}
+
+// workspaceStateFile returns the state file of a workspace in dir
+func workspaceStateFile(dir, name string) string {
+	if name == "" || name == "default" {
+		return filepath.Join(dir, "state.json")
+	}
+	return filepath.Join(dir, "workspaces", name, "state.json")
+}
+
+// currentWorkspaceFile records the workspace chosen with 'workspace use'
+func currentWorkspaceFile(dir string) string {
+	return filepath.Join(dir, "workspace")
+}
+
+// currentWorkspace returns the workspace last chosen in dir, or "default"
+func currentWorkspace(dir string) string {
+	data, err := os.ReadFile(currentWorkspaceFile(dir))
+	if err != nil {
+		return "default"
+	}
+	name := strings.TrimSpace(string(data))
+	if validateWorkspaceName(name) != nil {
+		return "default"
+	}
+	return name
+}
+
+// listWorkspaces returns the default workspace and every created workspace
+func listWorkspaces(dir string) []string {
+	names := []string{"default"}
+	entries, err := os.ReadDir(filepath.Join(dir, "workspaces"))
+	if err != nil {
+		return names
+	}
+	var created []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			created = append(created, entry.Name())
+		}
+	}
+	sort.Strings(created)
+	return append(names, created...)
+}
+
+// workspaceExists reports whether a workspace has been created
+func workspaceExists(dir, name string) bool {
+	return name == "default" || dirExists(filepath.Join(dir, "workspaces", name))
+}
+
+// switchWorkspace loads a workspace's state into config and remembers it as
+// the current workspace
+func switchWorkspace(config *Config, name string) error {
+	dir := workspaceRoot(config)
+	if !workspaceExists(dir, name) {
+		return fmt.Errorf("workspace not found: %s", name)
+	}
+
+	switched := &Config{StateFile: workspaceStateFile(dir, name), Workspace: name}
+	if err := loadProjects(switched); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(currentWorkspaceFile(dir), []byte(name+"\n"), 0644); err != nil {
+		return err
+	}
+	*config = *switched
+	return applySettings(config.Settings, absoluteTimeFlag)
+}
+
+// workspaceRoot returns the directory holding the workspaces of config's
+// profile
+func workspaceRoot(config *Config) string {
+	dir := filepath.Dir(config.StateFile)
+	if config.Workspace != "" && config.Workspace != "default" {
+		dir = filepath.Dir(filepath.Dir(dir))
+	}
+	return dir
+}
+
+// selectWorkspace lets the user pick a workspace and switches to it
+func selectWorkspace(config *Config, reader *bufio.Reader) bool {
+	names := listWorkspaces(workspaceRoot(config))
+	entries := make([]string, len(names))
+	for i, name := range names {
+		entries[i] = name
+		if name == config.Workspace {
+			entries[i] += " (current)"
+		}
+	}
+
+	var name string
+	if useFinder() {
+		index, ok := pick("Workspace", entries)
+		if !ok {
+			return false
+		}
+		name = names[index]
+	} else {
+		fmt.Printf("%s\n", qc.Colorize("Select a workspace:", qc.ColorBlue))
+		for i, entry := range entries {
+			fmt.Println(qc.Colorize(fmt.Sprintf("%3d. %s", i+1, entry), qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)))
+		}
+
+		fmt.Printf("%s", qc.Colorize("Select workspace (number): ", qc.ColorYellow))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || index < 1 || index > len(names) {
+			fmt.Println("Invalid selection")
+			return false
+		}
+		name = names[index-1]
+	}
+
+	if err := switchWorkspace(config, name); err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), describeError(err))
+		return false
+	}
+	fmt.Printf("%s Switched to workspace %s\n", qc.Colorize("Success:", qc.ColorGreen), config.Workspace)
+	return true
+}
+
+// handleWorkspace handles the workspace command
+func handleWorkspace(config *Config, args []string) {
+	if config.Workspace == "" {
+		fmt.Printf("%s Workspaces aren't available with --state\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	dir := workspaceRoot(config)
+	if len(args) == 0 || args[0] == "list" {
+		for i, name := range listWorkspaces(dir) {
+			workspace := &Config{StateFile: workspaceStateFile(dir, name)}
+			count := "?"
+			if err := loadProjects(workspace); err == nil {
+				count = strconv.Itoa(len(workspace.Projects))
+			}
+			marker := " "
+			if name == config.Workspace {
+				marker = "*"
+			}
+			fmt.Println(qc.Colorize(fmt.Sprintf("%s %-20s %s project(s)", marker, name, count), qc.AlternatingColor(i, qc.ColorWhite, qc.ColorCyan)))
+		}
+		return
+	}
+
+	if len(args) < 2 {
+		fmt.Printf("%s Usage: quick_workflow workspace list|create|use|delete <name>\n", qc.Colorize("Error:", qc.ColorRed))
+		return
+	}
+	name := args[1]
+	if err := validateWorkspaceName(name); err != nil {
+		fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+		return
+	}
+
+	switch args[0] {
+	case "create":
+		if workspaceExists(dir, name) {
+			fmt.Printf("%s Workspace already exists: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(workspaceStateFile(dir, name)), 0755); err != nil {
+			fmt.Printf("%s Failed to create workspace: %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+		fmt.Printf("%s Created workspace %s. Switch to it with 'quick_workflow workspace use %s'.\n", qc.Colorize("Success:", qc.ColorGreen), name, name)
+	case "use":
+		if err := switchWorkspace(config, name); err != nil {
+			fmt.Printf("%s %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+		fmt.Printf("%s Using workspace %s (%d projects)\n", qc.Colorize("Success:", qc.ColorGreen), name, len(config.Projects))
+	case "delete":
+		if name == "default" || name == config.Workspace {
+			fmt.Printf("%s Can't delete the default or current workspace\n", qc.Colorize("Error:", qc.ColorRed))
+			return
+		}
+		if !workspaceExists(dir, name) {
+			fmt.Printf("%s Workspace not found: %s\n", qc.Colorize("Error:", qc.ColorRed), name)
+			return
+		}
+		if !confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("Delete workspace %s with its projects, notes, and history?", name)) {
+			fmt.Printf("%s Cancelled\n", qc.Colorize("Info:", qc.ColorCyan))
+			return
+		}
+		if err := os.RemoveAll(filepath.Dir(workspaceStateFile(dir, name))); err != nil {
+			fmt.Printf("%s Failed to delete workspace: %v\n", qc.Colorize("Error:", qc.ColorRed), err)
+			return
+		}
+		fmt.Printf("%s Deleted workspace %s\n", qc.Colorize("Success:", qc.ColorGreen), name)
+	default:
+		fmt.Printf("%s Unknown workspace command: %s\n", qc.Colorize("Error:", qc.ColorRed), args[0])
+	}
+}